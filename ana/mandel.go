@@ -0,0 +1,215 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// Mandel computes the solution to Mandel's problem: a poroelastic specimen in plane-strain
+// squeezed between two rigid, frictionless and impermeable plates by a force 2F (per unit
+// thickness) applied @ t=0⁺. The lateral faces (x = ±a) are free and drained.
+//
+//                     2F
+//                     ↓
+//         ███████████████████████████
+//      pl=0 o---------------------o pl=0
+//           |          y          |
+//           |          ↑          |
+//     2b    |          +---→ x    |
+//           |                     |
+//           o---------------------o
+//         ███████████████████████████
+//                     ↑
+//                    2F
+//                     2a
+//
+//  The solution is [1,2]:
+//   p(x,t)   = 2F・B・(1+νu)/(3a)・Σ A_i・(cos(λi・x/a) - cos(λi))・e_i
+//   σyy(x,t) = -F/a - 2F・(νu-ν)/(a・(1-ν))・Σ A_i・cos(λi・x/a)・e_i + 2F/a・Σ A_i・cos(λi)・e_i
+//   ux(x,t)  = [F・ν/(2G・a) - F・νu/(G・a)・Σ A_i・cos(λi)・e_i]・x + F/G・Σ A_i・cos(λi)・sin(λi・x/a)/sin(λi)・e_i
+//   uy(y,t)  = [-F・(1-ν)/(2G・a) + F・(1-νu)/(G・a)・Σ A_i・cos(λi)・e_i]・y
+//  where
+//   A_i = sin(λi) / (λi - sin(λi)・cos(λi))     e_i = exp(-λi²・c・t/a²)
+//  and λi are the positive roots of tan(λ) = (1-ν)/(νu-ν)・λ. The Skempton coefficient B,
+//  the undrained Poisson's coefficient νu and the consolidation coefficient c are
+//   B  = α・M / (K + α²・M)
+//   νu = (3ν + α・B・(1-2ν)) / (3 - α・B・(1-2ν))
+//   c  = 2κ・B²・G・(1-ν)・(1+νu)² / (9・(1-νu)・(νu-ν))
+//  with K and G being the drained bulk and shear moduli and κ = kl/γl.
+//  Compressive forces are positive whereas tensile stresses are positive.
+//  References:
+//   [1] Abousleiman Y, Cheng AHD, Cui L, Detournay E and Roegiers JC (1996) Mandel's problem
+//       revisited. Geotechnique, 46(2) 187-195
+//   [2] Cheng AHD and Detournay E (1988) A direct boundary element method for plane strain
+//       poroelasticity. Int Journal for Numerical and Analytical Methods in Geomechanics, 12 551-572
+type Mandel struct {
+
+	// data
+	a     float64 // half-width of specimen
+	E     float64 // Young's modulus (drained)
+	ν     float64 // Poisson's coefficient (drained)
+	α     float64 // Biot coefficient
+	invM  float64 // 1/M: inverse of Biot modulus; zero means incompressible constituents
+	kl    float64 // liquid conductivity
+	γl    float64 // unit weight of liquid
+	F     float64 // half of the applied force (compression is positive)
+	Nterm int     // number of terms in series
+
+	// derived
+	K  float64   // drained bulk modulus
+	G  float64   // shear modulus
+	B  float64   // Skempton coefficient
+	νu float64   // undrained Poisson's coefficient
+	C  float64   // consolidation coefficient
+	λ  []float64 // roots of tan(λ) = (1-ν)/(νu-ν)・λ
+}
+
+// Init initialises this structure
+func (o *Mandel) Init(prms fun.Prms) {
+
+	// default values
+	o.a = 1.0
+	o.E = 10000.0
+	o.ν = 0.2
+	o.α = 1.0
+	o.invM = 0.0
+	o.kl = 0.01
+	o.γl = 10.0
+	o.F = 100.0
+	o.Nterm = 200
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "a":
+			o.a = p.V
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.ν = p.V
+		case "alpha":
+			o.α = p.V
+		case "invM":
+			o.invM = p.V
+		case "kl":
+			o.kl = p.V
+		case "gamL":
+			o.γl = p.V
+		case "F":
+			o.F = p.V
+		case "Nterm":
+			o.Nterm = int(p.V)
+		}
+	}
+
+	// moduli
+	o.K = o.E / (3.0 * (1.0 - 2.0*o.ν))
+	o.G = o.E / (2.0 * (1.0 + o.ν))
+	o.B = o.α / (o.K*o.invM + o.α*o.α)
+	αB := o.α * o.B * (1.0 - 2.0*o.ν)
+	o.νu = (3.0*o.ν + αB) / (3.0 - αB)
+	κ := o.kl / o.γl
+	o.C = 2.0 * κ * o.B * o.B * o.G * (1.0 - o.ν) * math.Pow(1.0+o.νu, 2) / (9.0 * (1.0 - o.νu) * (o.νu - o.ν))
+
+	// roots
+	o.λ = make([]float64, o.Nterm)
+	r := (1.0 - o.ν) / (o.νu - o.ν)
+	g := func(x float64) float64 { return math.Sin(x) - r*x*math.Cos(x) }
+	for i := 0; i < o.Nterm; i++ {
+		xa := float64(i) * math.Pi
+		xb := xa + math.Pi/2.0
+		if i == 0 {
+			xa = 1e-10
+		}
+		ga := g(xa)
+		for it := 0; it < 200; it++ {
+			xm := (xa + xb) / 2.0
+			gm := g(xm)
+			if ga*gm > 0 {
+				xa, ga = xm, gm
+			} else {
+				xb = xm
+			}
+			if xb-xa < 1e-15*(1.0+xb) {
+				break
+			}
+		}
+		o.λ[i] = (xa + xb) / 2.0
+	}
+}
+
+// Undrained returns the Skempton coefficient B and the undrained Poisson's coefficient νu
+func (o Mandel) Undrained() (B, νu float64) {
+	return o.B, o.νu
+}
+
+// Pressure computes the liquid pressure at x and time t
+func (o Mandel) Pressure(x, t float64) (p float64) {
+	if t <= 0 {
+		return o.F * o.B * (1.0 + o.νu) / (3.0 * o.a)
+	}
+	for i, λ := range o.λ {
+		p += o.coef(i, t) * (math.Cos(λ*x/o.a) - math.Cos(λ))
+	}
+	return 2.0 * o.F * o.B * (1.0 + o.νu) * p / (3.0 * o.a)
+}
+
+// Syy computes the vertical total stress at x and time t
+func (o Mandel) Syy(x, t float64) (σyy float64) {
+	if t <= 0 {
+		return -o.F / o.a
+	}
+	var s1, s2 float64
+	for i, λ := range o.λ {
+		A := o.coef(i, t)
+		s1 += A * math.Cos(λ*x/o.a)
+		s2 += A * math.Cos(λ)
+	}
+	return -o.F/o.a - 2.0*o.F*(o.νu-o.ν)*s1/(o.a*(1.0-o.ν)) + 2.0*o.F*s2/o.a
+}
+
+// Displ computes the displacements at (x,y) and time t
+func (o Mandel) Displ(x, y, t float64) (ux, uy float64) {
+	if t <= 0 {
+		ux = o.F * o.νu * x / (2.0 * o.G * o.a)
+		uy = -o.F * (1.0 - o.νu) * y / (2.0 * o.G * o.a)
+		return
+	}
+	var s1, s2 float64
+	for i, λ := range o.λ {
+		A := o.coef(i, t)
+		s1 += A * math.Cos(λ)
+		s2 += A * math.Cos(λ) * math.Sin(λ*x/o.a) / math.Sin(λ)
+	}
+	ux = (o.F*o.ν/(2.0*o.G*o.a)-o.F*o.νu*s1/(o.G*o.a))*x + o.F*s2/o.G
+	uy = (-o.F*(1.0-o.ν)/(2.0*o.G*o.a) + o.F*(1.0-o.νu)*s1/(o.G*o.a)) * y
+	return
+}
+
+// CheckPressure checks the liquid pressure at x
+func (o Mandel) CheckPressure(tst *testing.T, t, pl, x, tol float64) {
+	chk.Scalar(tst, io.Sf("pl(x=%g,t=%g)", x, t), tol, pl, o.Pressure(x, t))
+}
+
+// CheckDispl checks the displacements at (x,y)
+func (o Mandel) CheckDispl(tst *testing.T, t float64, u, x []float64, tol float64) {
+	ux, uy := o.Displ(x[0], x[1], t)
+	chk.Scalar(tst, "ux", tol, u[0], ux)
+	chk.Scalar(tst, "uy", tol, u[1], uy)
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// coef returns A_i・e_i
+func (o Mandel) coef(i int, t float64) float64 {
+	λ := o.λ[i]
+	return math.Sin(λ) / (λ - math.Sin(λ)*math.Cos(λ)) * math.Exp(-λ*λ*o.C*t/(o.a*o.a))
+}
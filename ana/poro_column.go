@@ -0,0 +1,143 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// PoroColumn computes the solution to the one-dimensional consolidation of a
+// poroelastic column (Terzaghi's problem with Biot coefficient and Biot modulus)
+//
+//              q (applied @ t=0⁺)
+//        ↓↓↓↓↓↓↓↓↓↓↓↓↓  pl = 0 (drained)
+//      ▷ o-----------o ◁
+//      ▷ |           | ◁
+//      ▷ |   E, ν    | ◁
+//   H  ▷ |   α, M    | ◁
+//      ▷ |   kl      | ◁
+//      ▷ o-----------o ◁
+//        △  △  △  △  △  impermeable
+//
+//  The liquid pressure is given by (z = elevation from the bottom)
+//   p(z,t) = p0・Σ 4・(-1)^m/((2m+1)π)・cos((2m+1)・π・z/(2H))・exp(-(2m+1)²・π²・cv・t/(4H²))
+//  with
+//   p0 = α・M・q / (Kv + α²・M)      Kv = E・(1-ν)/((1+ν)・(1-2ν))
+//   cv = κ・M・Kv / (Kv + α²・M)      κ  = kl/γl
+//  where M is the Biot modulus (1/M = nf/Kl + (α-nf)/Ks), Kv is the oedometric (constrained)
+//  modulus and γl is the unit weight of liquid.
+//  Note: invM = 1/M is given instead of M in order to allow incompressible constituents (invM = 0)
+type PoroColumn struct {
+
+	// data
+	H     float64 // height of column
+	E     float64 // Young's modulus (drained)
+	ν     float64 // Poisson's coefficient (drained)
+	α     float64 // Biot coefficient
+	invM  float64 // 1/M: inverse of Biot modulus
+	kl    float64 // liquid conductivity
+	γl    float64 // unit weight of liquid
+	q     float64 // applied vertical load (compression is positive)
+	Nterm int     // number of terms in series
+
+	// derived
+	Kv float64 // oedometric modulus
+	P0 float64 // initial (undrained) liquid pressure
+	Cv float64 // coefficient of consolidation
+}
+
+// Init initialises this structure
+func (o *PoroColumn) Init(prms fun.Prms) {
+
+	// default values
+	o.H = 10.0
+	o.E = 10000.0
+	o.ν = 0.2
+	o.α = 1.0
+	o.invM = 0.0
+	o.kl = 0.01
+	o.γl = 10.0
+	o.q = 100.0
+	o.Nterm = 200
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "H":
+			o.H = p.V
+		case "E":
+			o.E = p.V
+		case "nu":
+			o.ν = p.V
+		case "alpha":
+			o.α = p.V
+		case "invM":
+			o.invM = p.V
+		case "kl":
+			o.kl = p.V
+		case "gamL":
+			o.γl = p.V
+		case "q":
+			o.q = p.V
+		case "Nterm":
+			o.Nterm = int(p.V)
+		}
+	}
+
+	// derived
+	o.Kv = o.E * (1.0 - o.ν) / ((1.0 + o.ν) * (1.0 - 2.0*o.ν))
+	den := o.Kv*o.invM + o.α*o.α
+	o.P0 = o.α * o.q / den
+	o.Cv = (o.kl / o.γl) * o.Kv / den
+}
+
+// Tv returns the time factor Tv = cv・t/H²
+func (o PoroColumn) Tv(t float64) float64 {
+	return o.Cv * t / (o.H * o.H)
+}
+
+// Pressure computes the liquid pressure at elevation z and time t
+func (o PoroColumn) Pressure(z, t float64) (p float64) {
+	if t <= 0 {
+		return o.P0
+	}
+	T := o.Tv(t)
+	for m := 0; m < o.Nterm; m++ {
+		M := float64(2*m+1) * math.Pi / 2.0
+		p += 2.0 * math.Pow(-1, float64(m)) / M * math.Cos(M*z/o.H) * math.Exp(-M*M*T)
+	}
+	return o.P0 * p
+}
+
+// Degree computes the average degree of consolidation U(t) = 1 - ∫p dz / (p0・H)
+func (o PoroColumn) Degree(t float64) (U float64) {
+	if t <= 0 {
+		return 0
+	}
+	T := o.Tv(t)
+	var sum float64
+	for m := 0; m < o.Nterm; m++ {
+		M := float64(2*m+1) * math.Pi / 2.0
+		sum += 2.0 / (M * M) * math.Exp(-M*M*T)
+	}
+	return 1.0 - sum
+}
+
+// Settlement computes the settlement of the top of the column (downwards is positive)
+//  w(t) = H・(q - α・p̄(t)) / Kv   where p̄ is the average pressure in the column
+func (o PoroColumn) Settlement(t float64) float64 {
+	pavg := o.P0 * (1.0 - o.Degree(t))
+	return o.H * (o.q - o.α*pavg) / o.Kv
+}
+
+// CheckPressure checks the liquid pressure at elevation z
+func (o PoroColumn) CheckPressure(tst *testing.T, t, pl, z, tol float64) {
+	chk.Scalar(tst, io.Sf("pl(z=%g,t=%g)", z, t), tol, pl, o.Pressure(z, t))
+}
@@ -0,0 +1,138 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ana
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_column01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("column01")
+
+	// incompressible constituents: Terzaghi's solution
+	var sol PoroColumn
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "H", V: 1},
+		&fun.Prm{N: "q", V: 100},
+	})
+	io.Pforan("Kv = %v  cv = %v\n", sol.Kv, sol.Cv)
+	chk.Scalar(tst, "p0", 1e-15, sol.P0, 100)
+
+	// degree of consolidation
+	tv := func(T float64) float64 { return T * sol.H * sol.H / sol.Cv }
+	chk.Scalar(tst, "U(Tv=0.197)", 1e-3, sol.Degree(tv(0.197)), 0.5)
+	chk.Scalar(tst, "U(Tv=0.848)", 1e-3, sol.Degree(tv(0.848)), 0.9)
+
+	// pressure
+	chk.Scalar(tst, "p(bot,Tv=0.001)", 1e-10, sol.Pressure(0, tv(0.001)), 100)
+	chk.Scalar(tst, "p(top,Tv=0.1)  ", 1e-10, sol.Pressure(1, tv(0.1)), 0)
+	chk.Scalar(tst, "p(bot,Tv=0.1)  ", 1e-4, sol.Pressure(0, tv(0.1)), 94.93053626844703)
+	chk.Scalar(tst, "p(mid,Tv=0.1)  ", 1e-4, sol.Pressure(0.5, tv(0.1)), 73.56513152441901)
+
+	// Biot coefficient and compressible constituents
+	var biot PoroColumn
+	biot.Init(fun.Prms{
+		&fun.Prm{N: "H", V: 10},
+		&fun.Prm{N: "alpha", V: 0.8},
+		&fun.Prm{N: "invM", V: 1e-5},
+		&fun.Prm{N: "q", V: 100},
+	})
+	M := 1.0 / biot.invM
+	io.Pforan("p0 = %v  cv = %v\n", biot.P0, biot.Cv)
+	chk.Scalar(tst, "p0", 1e-10, biot.P0, biot.α*M*biot.q/(biot.Kv+biot.α*biot.α*M))
+	chk.Scalar(tst, "cv", 1e-10, biot.Cv, (biot.kl/biot.γl)*M*biot.Kv/(biot.Kv+biot.α*biot.α*M))
+
+	// settlements: undrained and drained
+	w0 := biot.H * biot.q / (biot.Kv + biot.α*biot.α*M)
+	wf := biot.H * biot.q / biot.Kv
+	chk.Scalar(tst, "w(0)  ", 1e-15, biot.Settlement(0), w0)
+	chk.Scalar(tst, "w(∞)  ", 1e-10, biot.Settlement(10*biot.H*biot.H/biot.Cv), wf)
+	if biot.Settlement(0.1*biot.H*biot.H/biot.Cv) <= w0 {
+		tst.Errorf("settlement must increase with time\n")
+		return
+	}
+}
+
+func Test_mandel01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mandel01")
+
+	// solution
+	var sol Mandel
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "a", V: 1},
+		&fun.Prm{N: "alpha", V: 0.8},
+		&fun.Prm{N: "invM", V: 1e-5},
+		&fun.Prm{N: "F", V: 100},
+		&fun.Prm{N: "Nterm", V: 2000},
+	})
+	B, νu := sol.Undrained()
+	io.Pforan("B = %v  νu = %v  c = %v\n", B, νu, sol.C)
+	io.Pforan("λ = %v\n", sol.λ[:5])
+
+	// undrained coefficients
+	chk.Scalar(tst, "B ", 1e-12, B, sol.α/(sol.K*sol.invM+sol.α*sol.α))
+	Ku := sol.K + sol.α*sol.α/sol.invM
+	chk.Scalar(tst, "νu", 1e-12, νu, (3.0*Ku-2.0*sol.G)/(2.0*(3.0*Ku+sol.G)))
+
+	// roots
+	r := (1.0 - sol.ν) / (sol.νu - sol.ν)
+	for i, λ := range sol.λ {
+		if i > 0 && λ <= sol.λ[i-1] {
+			tst.Errorf("roots must be increasing\n")
+			return
+		}
+		chk.Scalar(tst, io.Sf("tan(λ%d)/λ%d", i, i), 1e-7, math.Tan(λ)/λ, r)
+		if i > 4 {
+			break
+		}
+	}
+
+	// times
+	tc := func(T float64) float64 { return T * sol.a * sol.a / sol.C }
+
+	// initial (undrained) response
+	p0 := sol.Pressure(0, 0)
+	ux0, uy0 := sol.Displ(1, 1, 0)
+	chk.Scalar(tst, "p0      ", 1e-15, p0, sol.F*B*(1.0+νu)/(3.0*sol.a))
+	chk.Scalar(tst, "p(0,0⁺) ", 0.1, sol.Pressure(0, tc(1e-6)), p0)
+	chk.Scalar(tst, "p(½,0⁺) ", 0.1, sol.Pressure(0.5, tc(1e-6)), p0)
+	ux, uy := sol.Displ(1, 1, tc(1e-6))
+	chk.Scalar(tst, "ux(a,0⁺)", 1e-5, ux, ux0)
+	chk.Scalar(tst, "uy(b,0⁺)", 1e-5, uy, uy0)
+
+	// Mandel-Cryer effect
+	if sol.Pressure(0, tc(0.01)) <= p0 {
+		tst.Errorf("pressure at centre must increase at early times (Mandel-Cryer effect)\n")
+		return
+	}
+
+	// drained edges and equilibrium: ∫σyy dx = -F
+	n := 1000
+	h := sol.a / float64(n)
+	for _, T := range []float64{0.001, 0.01, 0.1, 1} {
+		chk.Scalar(tst, io.Sf("p(a,T=%g)", T), 1e-12, sol.Pressure(sol.a, tc(T)), 0)
+		var I float64
+		for i := 0; i < n; i++ {
+			x := float64(i) * h
+			I += (sol.Syy(x, tc(T)) + sol.Syy(x+h, tc(T))) * h / 2.0
+		}
+		chk.Scalar(tst, io.Sf("∫σyy(T=%g)", T), 1e-3, I, -sol.F)
+	}
+
+	// final (drained) response
+	ux, uy = sol.Displ(1, 1, tc(10))
+	chk.Scalar(tst, "p(0,∞) ", 1e-4, sol.Pressure(0, tc(10)), 0)
+	chk.Scalar(tst, "ux(a,∞)", 1e-8, ux, sol.F*sol.ν/(2.0*sol.G))
+	chk.Scalar(tst, "uy(b,∞)", 1e-8, uy, -sol.F*(1.0-sol.ν)/(2.0*sol.G*sol.a))
+}
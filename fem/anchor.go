@@ -0,0 +1,237 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Anchor holds a ground anchor; i.e. a tendon made of rod elements embedded in solids by means
+// of rod-joints. The rod-joints of the free length are debonded whereas the ones of the bond
+// length transfer shear according to their material model
+//  Note: 1) the lock-off (jacking) force P(t) pulls the head node of the tendon along the anchor
+//           axis; the reaction -P(t) of the bearing plate is applied onto the solid element holding
+//           the head, at the location of the head, by means of the shape functions of the solid
+//           computed by the rod-joint of the rod cell at the head
+//        2) the rod cell at the head must belong to the free length; i.e. Lfree must be greater
+//           than or equal to the length of this cell
+//        3) at the end of the stage with the lock-off force, the head is locked onto the bearing
+//           plate by means of a stiff connection (penalty) between the head node and the solid.
+//           The force of this connection is F = Plock・dir + Khead (Dlock - δ) where δ is the
+//           displacement of the head relative to the solid and Plock and Dlock are the force and
+//           relative displacement at lock-off. Thus, the locked-in force is kept and changes with
+//           the deformation of the structure in the following stages. The heads are only locked if
+//           the following stage continues from the state of this stage ("continue" flag)
+type Anchor struct {
+	Dat   *inp.AnchorData // anchor data
+	Rods  []*Rod          // rod elements in the free length (in this processor)
+	Joint *Rjoint         // rod-joint of the rod cell at the head; nil if not in this processor
+	Mhead int             // local index of the head vertex in the rod cell at the head
+	Pfcn  fun.Func        // lock-off force function; nil => no lock-off in this stage
+	Lock  *AnchorLock     // locked head; nil => head is not locked
+	δ     []float64       // [ndim] workspace: displacement of head relative to solid
+}
+
+// AnchorLock holds the state of the head of an anchor at lock-off
+type AnchorLock struct {
+	Plock float64   // force at lock-off
+	Dlock []float64 // [ndim] displacement of head relative to solid at lock-off
+	Khead float64   // stiffness of locked head
+}
+
+// Anchors holds all anchors of a region
+type Anchors struct {
+	Items   []*Anchor           // anchors
+	Tag2idx map[int]int         // maps anchor (rod cells) tag to index in Items
+	Locks   map[int]*AnchorLock // maps anchor tag to locked head. kept between stages
+	nnz     int                 // max number of non-zeros added to Kb
+}
+
+// Reset initialises internal structures
+//  Note: locked heads are kept
+func (o *Anchors) Reset() {
+	o.Items = make([]*Anchor, 0)
+	o.Tag2idx = make(map[int]int)
+	if o.Locks == nil {
+		o.Locks = make(map[int]*AnchorLock)
+	}
+	o.nnz = 0
+}
+
+// Nnz returns the max number of non-zeros added to Kb
+func (o Anchors) Nnz() int {
+	return o.nnz
+}
+
+// Set sets anchors; i.e. debonds the rod-joints of the free length
+//  Note: this function must be called after the rod-joints have been connected
+func (o *Anchors) Set(dat *inp.AnchorData, msh *inp.Mesh, cid2elem []Elem) (setisok bool) {
+	a := &Anchor{Dat: dat, Mhead: -1, Lock: o.Locks[dat.Tag], δ: make([]float64, Global.Ndim)}
+	for cid, _ := range dat.Free {
+		if rod, ok := cid2elem[cid].(*Rod); ok {
+			a.Rods = append(a.Rods, rod)
+		}
+	}
+	for _, c := range msh.Cells {
+		if !c.IsJoint || !dat.Free[c.JlinId] {
+			continue
+		}
+		e, ok := cid2elem[c.Id].(*Rjoint)
+		if !ok {
+			continue
+		}
+		e.Debond = true
+		for m, v := range msh.Cells[c.JlinId].Verts {
+			if v == dat.HeadVid {
+				a.Joint, a.Mhead = e, m
+			}
+		}
+	}
+	if a.Joint != nil {
+		n := Global.Ndim * (1 + len(a.Joint.Nmat))
+		o.nnz += n * n
+	}
+	o.Tag2idx[dat.Tag] = len(o.Items)
+	o.Items = append(o.Items, a)
+	return true
+}
+
+// Lockoff sets the lock-off force of an anchor
+func (o *Anchors) Lockoff(dat *inp.LockoffData) (ok bool) {
+	idx, found := o.Tag2idx[dat.Tag]
+	if LogErrCond(!found, "cannot find anchor with tag = %d to apply lock-off force", dat.Tag) {
+		return
+	}
+	fcn := Global.Sim.Functions.Get(dat.Func)
+	if LogErrCond(fcn == nil, "cannot find function named %q for lock-off force of anchor with tag = %d", dat.Func, dat.Tag) {
+		return
+	}
+	o.Items[idx].Pfcn = fcn
+	return true
+}
+
+// Lock locks the heads of anchors with lock-off forces onto the bearing plates
+//  Note: this function must be called at the end of a stage; i.e. before SetStage reallocates
+//        the elements. The head is released when a new lock-off force is applied
+func (o *Anchors) Lock(sol *Solution) (ok bool) {
+	for _, a := range o.Items {
+		if a.Pfcn == nil || a.Joint == nil {
+			continue
+		}
+		kh := a.Dat.Khead
+		if kh <= 0 {
+			rod := a.Joint.Rod
+			kh = 1000.0 * rod.E * rod.A / a.Dat.Lfree
+		}
+		if LogErrCond(kh <= 0, "cannot lock head of anchor with tag = %d: khead or the E and A parameters of the tendon must be positive", a.Dat.Tag) {
+			return
+		}
+		a.rel_displ(sol)
+		lock := &AnchorLock{Plock: a.Pfcn.F(sol.T, nil), Dlock: make([]float64, len(a.δ)), Khead: kh}
+		copy(lock.Dlock, a.δ)
+		o.Locks[a.Dat.Tag] = lock
+	}
+	return true
+}
+
+// AddToRhs adds the lock-off forces and the forces of locked heads to the augmented fb vector
+func (o Anchors) AddToRhs(fb []float64, sol *Solution) {
+	ndim := Global.Ndim
+	for _, a := range o.Items {
+		if a.Joint == nil {
+			continue
+		}
+		if a.Pfcn == nil && a.Lock == nil {
+			continue
+		}
+		var P float64
+		if a.Pfcn == nil {
+			a.rel_displ(sol)
+			P = a.Lock.Plock
+		} else {
+			P = a.Pfcn.F(sol.T, nil)
+		}
+		for i := 0; i < ndim; i++ {
+			F := P * a.Dat.Dir[i]
+			if a.Pfcn == nil {
+				F += a.Lock.Khead * (a.Lock.Dlock[i] - a.δ[i])
+			}
+			fb[a.Joint.Rod.Umap[i+a.Mhead*ndim]] += F
+			for n, row := range a.Joint.Nmat {
+				fb[a.Joint.Sld.Umap[i+n*ndim]] -= row[a.Mhead] * F
+			}
+		}
+	}
+}
+
+// AddToKb adds the contributions of locked heads to the Jacobian matrix Kb
+func (o Anchors) AddToKb(Kb *la.Triplet) {
+	ndim := Global.Ndim
+	for _, a := range o.Items {
+		if a.Joint == nil || a.Pfcn != nil || a.Lock == nil {
+			continue
+		}
+		kh := a.Lock.Khead
+		for i := 0; i < ndim; i++ {
+			I := a.Joint.Rod.Umap[i+a.Mhead*ndim]
+			Kb.Put(I, I, kh)
+			for n, row := range a.Joint.Nmat {
+				J := a.Joint.Sld.Umap[i+n*ndim]
+				Kb.Put(I, J, -kh*row[a.Mhead])
+				Kb.Put(J, I, -kh*row[a.Mhead])
+				for m, col := range a.Joint.Nmat {
+					Kb.Put(J, a.Joint.Sld.Umap[i+m*ndim], kh*row[a.Mhead]*col[a.Mhead])
+				}
+			}
+		}
+	}
+}
+
+// Force returns the axial force in the free length of anchor
+//  Note: the force is averaged over the integration points of the rods in the free length that
+//        belong to this processor; found is false if there are no such rods
+func (o Anchors) Force(tag int) (P float64, found bool) {
+	idx, ok := o.Tag2idx[tag]
+	if !ok {
+		return
+	}
+	var nip int
+	for _, rod := range o.Items[idx].Rods {
+		for _, s := range rod.States {
+			P += rod.A * s.Sig
+			nip += 1
+		}
+	}
+	if nip == 0 {
+		return
+	}
+	return P / float64(nip), true
+}
+
+// List returns a simple list logging anchors
+func (o Anchors) List() (l string) {
+	for i, a := range o.Items {
+		if i > 0 {
+			l += " "
+		}
+		l += io.Sf("[tag=%d head=%d nfree=%d nbond=%d lockoff=%v locked=%v]", a.Dat.Tag, a.Dat.HeadVid, len(a.Dat.Free), len(a.Dat.Bond), a.Pfcn != nil, a.Lock != nil)
+	}
+	return
+}
+
+// rel_displ computes the displacement of the head relative to the solid
+func (o *Anchor) rel_displ(sol *Solution) {
+	ndim := Global.Ndim
+	for i := 0; i < ndim; i++ {
+		o.δ[i] = sol.Y[o.Joint.Rod.Umap[i+o.Mhead*ndim]]
+		for n, row := range o.Joint.Nmat {
+			o.δ[i] -= row[o.Mhead] * sol.Y[o.Joint.Sld.Umap[i+n*ndim]]
+		}
+	}
+}
@@ -7,12 +7,35 @@ package fem
 import (
 	"math"
 
+	"github.com/cpmech/gofem/msolid"
+
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/tsr"
 )
 
 const SQ2 = math.Sqrt2
 
+// SetCharLength sets the characteristic length of element into the states of models that depend
+// on the size of elements (msolid.LengthDependent); e.g. for regularisation of softening
+//  charlen -- computes the characteristic length; called only for length-dependent models
+func SetCharLength(mdl msolid.Model, states, statesBkp []*msolid.State, charlen func() (float64, bool)) (ok bool) {
+	m, isld := mdl.(msolid.LengthDependent)
+	if !isld {
+		return true
+	}
+	h, ok := charlen()
+	if !ok {
+		return
+	}
+	for i, s := range states {
+		if LogErr(m.SetLength(s, h), "SetCharLength") {
+			return false
+		}
+		statesBkp[i].Set(s)
+	}
+	return true
+}
+
 func IpAddToKt(Kt [][]float64, nne, ndim int, coef float64, G, D [][]float64) {
 	if ndim == 3 {
 		for m := 0; m < nne; m++ {
@@ -156,6 +179,78 @@ func IpStrainsAndIncB(εs, Δεs []float64, nσ, nu int, B [][]float64, u, Δu [
 	}
 }
 
+// IpDefGrad computes the deformation gradient F = I + ∂u/∂X and the incremental deformation
+// gradient FΔ = F・inv(Fn), where Fn = F - ∂Δu/∂X corresponds to the last converged state
+//  F, FΔ, Fi -- [3][3] matrices. Fi is a scratchpad
+//  G         -- [nne][ndim] derivatives of shape functions w.r.t reference coordinates
+func IpDefGrad(F, FΔ, Fi [][]float64, nne, ndim int, u, Δu []float64, Umap []int, G [][]float64) (err error) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			F[i][j], FΔ[i][j] = 0, 0
+		}
+		F[i][i], FΔ[i][i] = 1, 1
+	}
+	var r int
+	for m := 0; m < nne; m++ {
+		for i := 0; i < ndim; i++ {
+			r = Umap[i+m*ndim]
+			for j := 0; j < ndim; j++ {
+				F[i][j] += u[r] * G[m][j]
+				FΔ[i][j] += (u[r] - Δu[r]) * G[m][j] // FΔ := Fn
+			}
+		}
+	}
+	_, err = tsr.Inv(Fi, FΔ)
+	if err != nil {
+		return
+	}
+	la.MatMul(FΔ, 1, F, Fi)
+	return
+}
+
+// IpAddToKtLarge adds the contribution of the spatial tangent modulus A to Kt
+//  Kt[i+m*ndim][k+n*ndim] += coef * g[m][j] * A[i][j][k][l] * g[n][l]
+//  g -- [nne][ndim] derivatives of shape functions w.r.t current coordinates
+func IpAddToKtLarge(Kt [][]float64, nne, ndim int, coef float64, g [][]float64, A [][][][]float64) {
+	for m := 0; m < nne; m++ {
+		for n := 0; n < nne; n++ {
+			for i := 0; i < ndim; i++ {
+				for k := 0; k < ndim; k++ {
+					for j := 0; j < ndim; j++ {
+						for l := 0; l < ndim; l++ {
+							Kt[i+m*ndim][k+n*ndim] += coef * g[m][j] * A[i][j][k][l] * g[n][l]
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// IpLogStrains computes the logarithmic (Hencky) strains ε = ½ ln(b) where b = F・trans(F)
+//  ε -- [nsig] strains (Mandel)
+//  F -- [3][3] deformation gradient
+func IpLogStrains(ε []float64, F [][]float64) (err error) {
+	nsig := len(ε)
+	b := tsr.Alloc2()
+	bm := make([]float64, nsig)
+	λ := make([]float64, 3)
+	P := tsr.M_AllocEigenprojs(nsig)
+	tsr.LeftCauchyGreenDef(b, F)
+	tsr.Ten2Man(bm, b)
+	err = tsr.M_EigenValsProjsNum(P, λ, bm)
+	if err != nil {
+		return
+	}
+	for i := 0; i < nsig; i++ {
+		ε[i] = 0
+		for k := 0; k < 3; k++ {
+			ε[i] += math.Log(λ[k]) * P[k][i] / 2.0
+		}
+	}
+	return
+}
+
 func IpBmatrix_sparse(B *la.Triplet, ndim, nne int, G [][]float64, axisym bool, radius float64, S []float64) {
 	B.Start()
 	if ndim == 3 {
@@ -222,3 +317,40 @@ func StressKeys() []string {
 	}
 	return []string{"sx", "sy", "sz", "sxy", "syz", "szx"}
 }
+
+func StrainKeys() []string {
+	if Global.Ndim == 2 {
+		return []string{"ex", "ey", "ez", "exy"}
+	}
+	return []string{"ex", "ey", "ez", "exy", "eyz", "ezx"}
+}
+
+// FaceLocalSystem computes the local directions {n, e1, e2} of a face (edge in 2D) from its
+// tangent vectors a[k] = dx/dR_k
+//  Q -- [ndim][ndim] local directions in its rows; n is the normal computed with the ordering
+//       of the face vertices (outward normal for faces of solid elements)
+//  a -- [ndim-1][ndim] tangent vectors
+//  J -- the Jacobian of the face; i.e. |a0| (2D) or |a0 × a1| (3D)
+func FaceLocalSystem(Q, a [][]float64) (J float64) {
+	if len(Q) == 2 {
+		J = la.VecNorm(a[0])
+		Q[0][0], Q[0][1] = a[0][1]/J, -a[0][0]/J
+		Q[1][0], Q[1][1] = a[0][0]/J, a[0][1]/J
+		return
+	}
+	n := []float64{
+		a[0][1]*a[1][2] - a[0][2]*a[1][1],
+		a[0][2]*a[1][0] - a[0][0]*a[1][2],
+		a[0][0]*a[1][1] - a[0][1]*a[1][0],
+	}
+	J = la.VecNorm(n)
+	na0 := la.VecNorm(a[0])
+	for i := 0; i < 3; i++ {
+		Q[0][i] = n[i] / J
+		Q[1][i] = a[0][i] / na0
+	}
+	Q[2][0] = Q[0][1]*Q[1][2] - Q[0][2]*Q[1][1]
+	Q[2][1] = Q[0][2]*Q[1][0] - Q[0][0]*Q[1][2]
+	Q[2][2] = Q[0][0]*Q[1][1] - Q[0][1]*Q[1][0]
+	return
+}
@@ -0,0 +1,532 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+)
+
+// BeamCorot implements the co-rotational kinematics of 3D beams
+//
+//	Note: 1) the rotation DOFs (rx, ry, rz) of each node are the components of the total rotation
+//	         vector ψ; i.e. the nodal triad is Q = exp(ψ) E0 where E0 = [e0 e1 e2] is the initial
+//	         local system. Thus nodal moments are work-conjugate to ψ and coincide with the
+//	         spatial moments only if the axis of rotation is fixed. ψ is singular at |ψ| = 2π
+//	      2) the rigid frame Rr = [r1 r2 r3] follows the chord (r1) and the average of the e1
+//	         directions of the nodal triads (r2, r3); the local rotations are θa = log(Rrᵀ Qa).
+//	         See Battini and Pacoste (2002) Co-rotational beam elements with warping effects in
+//	         instability problems, CMAME 191:1755-1789
+type BeamCorot struct {
+
+	// initial configuration
+	L  float64     // initial length
+	X0 []float64   // [3] initial chord vector
+	E0 [][]float64 // [3][3] initial local system (columns: e0, e1, e2)
+
+	// current configuration
+	l  float64        // current length
+	Q  [2][][]float64 // [2][3][3] nodal triads
+	Rr [][]float64    // [3][3] rigid frame
+	r1 []float64      // [3] chord direction
+	r2 []float64      // [3] second axis of rigid frame
+	r3 []float64      // [3] third axis of rigid frame
+	q  [2][]float64   // [2][3] e1 directions of nodal triads
+	qm []float64      // [3] average of q
+	θ  [2][]float64   // [2][3] local rotations
+
+	// derivatives
+	G  [][]float64    // [3][12] local spin of rigid frame: Rrᵀ δwr = G δ
+	W  [][]float64    // [3][12] spin of rigid frame: δwr = Rr G δ
+	Ea [2][][]float64 // [2][3][12] local spins of nodal triads: Rrᵀ δwa - G δ
+	Ba [2][][]float64 // [2][3][12] δθa = Ba δ
+	B  [][]float64    // [7][12] δd = B δ with d = {l - L, θ0, θ1}
+	Tp [2][][]float64 // [2][3][3] tangent operators of ψ: δwa = Tp δψa
+	Ks [][]float64    // [12][12] tangent matrix w.r.t. spins
+
+	// scratchpad
+	fn  []float64      // [7] natural forces {N, m0, m1}
+	v   [2][]float64   // [2][3] inv(T(θa))ᵀ ma
+	fs  []float64      // [12] internal forces w.r.t. spins
+	KB  [][]float64    // [7][12] Kn * B
+	dr  [3][][]float64 // [3][3][12] δr1, δr2 and δr3
+	dq  [2][][]float64 // [2][3][12] δqa
+	dh  [][]float64    // [3][12] δh
+	dg  [2][][]float64 // [2][3][12] δga
+	dν  []float64      // [12] δν
+	dη  []float64      // [12] δη
+	tmp [][]float64    // [3][3] auxiliary matrix
+	S   [][]float64    // [3][3] auxiliary skew matrix
+	x   []float64      // [3] auxiliary vector
+	y   []float64      // [3] auxiliary vector
+	z   []float64      // [3] auxiliary vector
+	row []float64      // [3] auxiliary vector
+}
+
+// beam_corot3_nat holds the indices of the natural deformations {l - L, θ0, θ1} in ul
+var beam_corot3_nat = []int{6, 3, 4, 5, 9, 10, 11}
+
+// Init initialises the co-rotational kinematics of a 3D beam
+//
+//	L -- length of beam
+//	X -- matrix of nodal coordinates [ndim][nnode]
+//	T -- global-to-local transformation matrix
+func (o *BeamCorot) Init(L float64, X, T [][]float64) {
+	o.L = L
+	o.X0 = make([]float64, 3)
+	o.E0 = la.MatAlloc(3, 3)
+	for i := 0; i < 3; i++ {
+		o.X0[i] = X[i][1] - X[i][0]
+		for j := 0; j < 3; j++ {
+			o.E0[i][j] = T[j][i]
+		}
+	}
+	o.Rr = la.MatAlloc(3, 3)
+	o.r1 = make([]float64, 3)
+	o.r2 = make([]float64, 3)
+	o.r3 = make([]float64, 3)
+	o.qm = make([]float64, 3)
+	o.G = la.MatAlloc(3, 12)
+	o.W = la.MatAlloc(3, 12)
+	o.B = la.MatAlloc(7, 12)
+	o.Ks = la.MatAlloc(12, 12)
+	o.fn = make([]float64, 7)
+	o.fs = make([]float64, 12)
+	o.KB = la.MatAlloc(7, 12)
+	o.dh = la.MatAlloc(3, 12)
+	o.dν = make([]float64, 12)
+	o.dη = make([]float64, 12)
+	o.tmp = la.MatAlloc(3, 3)
+	o.S = la.MatAlloc(3, 3)
+	o.x = make([]float64, 3)
+	o.y = make([]float64, 3)
+	o.z = make([]float64, 3)
+	o.row = make([]float64, 3)
+	for a := 0; a < 2; a++ {
+		o.Q[a] = la.MatAlloc(3, 3)
+		o.q[a] = make([]float64, 3)
+		o.θ[a] = make([]float64, 3)
+		o.Ea[a] = la.MatAlloc(3, 12)
+		o.Ba[a] = la.MatAlloc(3, 12)
+		o.Tp[a] = la.MatAlloc(3, 3)
+		o.v[a] = make([]float64, 3)
+		o.dq[a] = la.MatAlloc(3, 12)
+		o.dg[a] = la.MatAlloc(3, 12)
+	}
+	for i := 0; i < 3; i++ {
+		o.dr[i] = la.MatAlloc(3, 12)
+	}
+}
+
+// Deformations computes the local deformations ul for given global displacements ue
+//
+//	Note: ul = {0, 0, 0, θ0, l - L, 0, 0, θ1} where θa are the local rotations
+func (o *BeamCorot) Deformations(ul, ue []float64) {
+
+	// nodal triads: Qa = exp(ψa) E0
+	for a := 0; a < 2; a++ {
+		rot_exp(o.tmp, ue[6*a+3:6*a+6], o.S)
+		la.MatMul(o.Q[a], 1, o.tmp, o.E0)
+		for i := 0; i < 3; i++ {
+			o.q[a][i] = o.Q[a][i][1]
+		}
+	}
+
+	// chord
+	for i := 0; i < 3; i++ {
+		o.r1[i] = o.X0[i] + ue[6+i] - ue[i]
+	}
+	o.l = la.VecNorm(o.r1)
+	la.VecScale(o.r1, 0, 1.0/o.l, o.r1)
+
+	// rigid frame: r3 = r1 × qm / |r1 × qm| and r2 = r3 × r1
+	for i := 0; i < 3; i++ {
+		o.qm[i] = (o.q[0][i] + o.q[1][i]) / 2.0
+	}
+	beam_cross(o.r3, o.r1, o.qm)
+	la.VecScale(o.r3, 0, 1.0/la.VecNorm(o.r3), o.r3)
+	beam_cross(o.r2, o.r3, o.r1)
+	for i := 0; i < 3; i++ {
+		o.Rr[i][0], o.Rr[i][1], o.Rr[i][2] = o.r1[i], o.r2[i], o.r3[i]
+	}
+
+	// local rotations: θa = log(Rrᵀ Qa)
+	la.VecFill(ul, 0)
+	for a := 0; a < 2; a++ {
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				o.tmp[i][j] = 0
+				for k := 0; k < 3; k++ {
+					o.tmp[i][j] += o.Rr[k][i] * o.Q[a][k][j]
+				}
+			}
+		}
+		rot_log(o.θ[a], o.tmp)
+		copy(ul[6*a+3:6*a+6], o.θ[a])
+	}
+	ul[6] = o.l - o.L
+}
+
+// Calc computes the internal forces fi and, if tangent is true, the tangent matrix K for given
+// global displacements ue; ul and Kl are the local deformations and the local stiffness matrix
+//
+//	Note: the variations are first computed w.r.t. the spins δwa of the nodal triads and then
+//	      transformed to the variations δψa by means of δwa = T(ψa) δψa
+func (o *BeamCorot) Calc(fi []float64, K [][]float64, ul []float64, Kl [][]float64, ue []float64, tangent bool) {
+
+	// deformations and natural forces {N, m0, m1}
+	o.Deformations(ul, ue)
+	for a, I := range beam_corot3_nat {
+		o.fn[a] = 0
+		for j := 0; j < 12; j++ {
+			o.fn[a] += Kl[I][j] * ul[j]
+		}
+	}
+
+	// G matrix: local spin of rigid frame. Rows: spin about r1, r2 and r3
+	l := o.l
+	ν := la.VecDot(o.qm, o.r2)
+	η := la.VecDot(o.qm, o.r1) / ν
+	la.MatFill(o.G, 0)
+	beam_cross(o.x, o.q[0], o.r3)
+	beam_cross(o.y, o.q[1], o.r3)
+	for i := 0; i < 3; i++ {
+		o.G[0][i], o.G[0][3+i], o.G[0][6+i], o.G[0][9+i] = η*o.r3[i]/l, o.x[i]/(2.0*ν), -η*o.r3[i]/l, o.y[i]/(2.0*ν)
+		o.G[1][i], o.G[1][6+i] = o.r3[i]/l, -o.r3[i]/l
+		o.G[2][i], o.G[2][6+i] = -o.r2[i]/l, o.r2[i]/l
+	}
+
+	// B matrix: δl = r1・(δu1 - δu0) and δθa = inv(T(θa)) (Rrᵀ δwa - G δ)
+	la.MatFill(o.B, 0)
+	for i := 0; i < 3; i++ {
+		o.B[0][i], o.B[0][6+i] = -o.r1[i], o.r1[i]
+	}
+	for a := 0; a < 2; a++ {
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 12; j++ {
+				o.Ea[a][i][j] = -o.G[i][j]
+			}
+			for j := 0; j < 3; j++ {
+				o.Ea[a][i][6*a+3+j] += o.Rr[j][i]
+			}
+		}
+		rot_Tinv(o.tmp, o.θ[a], o.S)
+		la.MatMul(o.Ba[a], 1, o.tmp, o.Ea[a])
+		la.MatTrVecMul(o.v[a], 1, o.tmp, o.fn[1+3*a:4+3*a]) // va := inv(T(θa))ᵀ ma
+		for i := 0; i < 3; i++ {
+			copy(o.B[1+3*a+i], o.Ba[a][i])
+		}
+	}
+
+	// internal forces: fi = Tᵀ Bᵀ fn
+	la.MatTrVecMul(o.fs, 1, o.B, o.fn)
+	copy(fi, o.fs)
+	for a := 0; a < 2; a++ {
+		rot_T(o.Tp[a], ue[6*a+3:6*a+6], o.S)
+		la.MatTrVecMul(fi[6*a+3:6*a+6], 1, o.Tp[a], o.fs[6*a+3:6*a+6])
+	}
+	if !tangent {
+		return
+	}
+
+	// material stiffness: Bᵀ Kn B
+	for a, I := range beam_corot3_nat {
+		for j := 0; j < 12; j++ {
+			o.KB[a][j] = 0
+			for b, J := range beam_corot3_nat {
+				o.KB[a][j] += Kl[I][J] * o.B[b][j]
+			}
+		}
+	}
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 12; j++ {
+			o.Ks[i][j] = 0
+			for a := 0; a < 7; a++ {
+				o.Ks[i][j] += o.B[a][i] * o.KB[a][j]
+			}
+		}
+	}
+
+	// geometric stiffness due to δr1: N (I - r1⊗r1) / l
+	N := o.fn[0]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			c := -N * o.r1[i] * o.r1[j] / l
+			if i == j {
+				c += N / l
+			}
+			o.Ks[i][j] += c
+			o.Ks[i][6+j] -= c
+			o.Ks[6+i][j] -= c
+			o.Ks[6+i][6+j] += c
+		}
+	}
+
+	// geometric stiffness due to δ(inv(T(θa))ᵀ) ma: Eaᵀ Da Ba
+	for a := 0; a < 2; a++ {
+		rot_dTinvTm(o.tmp, o.θ[a], o.fn[1+3*a:4+3*a])
+		la.MatMul(o.dh, 1, o.tmp, o.Ba[a])
+		for i := 0; i < 12; i++ {
+			for j := 0; j < 12; j++ {
+				for k := 0; k < 3; k++ {
+					o.Ks[i][j] += o.Ea[a][k][i] * o.dh[k][j]
+				}
+			}
+		}
+	}
+
+	// spin of rigid frame and variations of the axes: δri = δwr × ri = -skew(ri) W
+	la.MatMul(o.W, 1, o.Rr, o.G)
+	for k, r := range [][]float64{o.r1, o.r2, o.r3} {
+		beam_skew(o.S, r)
+		la.MatMul(o.dr[k], -1, o.S, o.W)
+	}
+
+	// geometric stiffness due to δRr va: -skew(Rr va) W @ rows of wa
+	for a := 0; a < 2; a++ {
+		la.MatVecMul(o.x, 1, o.Rr, o.v[a])
+		beam_skew(o.S, o.x)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 12; j++ {
+				for k := 0; k < 3; k++ {
+					o.Ks[6*a+3+i][j] -= o.S[i][k] * o.W[k][j]
+				}
+			}
+		}
+	}
+
+	// geometric stiffness due to δGᵀ s with s = v0 + v1
+	//  Gᵀ s = {h, ga0, -h, ga1} where h = (sx η + sy) r3 / l - sz r2 / l and ga = sx (qa × r3) / (2 ν)
+	for a := 0; a < 2; a++ {
+		la.MatFill(o.dq[a], 0)
+		beam_skew(o.S, o.q[a])
+		for i := 0; i < 3; i++ {
+			for k := 0; k < 3; k++ {
+				o.dq[a][i][6*a+3+k] = -o.S[i][k] // δqa = δwa × qa
+			}
+		}
+	}
+	for j := 0; j < 12; j++ {
+		o.dν[j], o.dη[j] = 0, 0
+		for i := 0; i < 3; i++ {
+			dqm := (o.dq[0][i][j] + o.dq[1][i][j]) / 2.0
+			o.dν[j] += dqm*o.r2[i] + o.qm[i]*o.dr[1][i][j]
+			o.dη[j] += dqm*o.r1[i] + o.qm[i]*o.dr[0][i][j]
+		}
+		o.dη[j] = (o.dη[j] - η*o.dν[j]) / ν
+	}
+	sx := o.v[0][0] + o.v[1][0]
+	sy := o.v[0][1] + o.v[1][1]
+	sz := o.v[0][2] + o.v[1][2]
+	c := (sx*η + sy) / l
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 12; j++ {
+			dl := o.B[0][j]
+			o.dh[i][j] = (sx*o.dη[j]/l-c*dl/l)*o.r3[i] + c*o.dr[2][i][j] + sz*dl/(l*l)*o.r2[i] - sz*o.dr[1][i][j]/l
+		}
+	}
+	for a := 0; a < 2; a++ {
+		beam_cross(o.row, o.q[a], o.r3)
+		for j := 0; j < 12; j++ {
+			for i := 0; i < 3; i++ {
+				o.y[i] = o.dq[a][i][j]
+				o.z[i] = o.dr[2][i][j]
+			}
+			beam_cross(o.x, o.y, o.r3)
+			beam_cross(o.y, o.q[a], o.z)
+			for i := 0; i < 3; i++ {
+				o.dg[a][i][j] = sx * ((o.x[i]+o.y[i])/(2.0*ν) - o.row[i]*o.dν[j]/(2.0*ν*ν))
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 12; j++ {
+			o.Ks[i][j] -= o.dh[i][j]
+			o.Ks[6+i][j] += o.dh[i][j]
+			o.Ks[3+i][j] -= o.dg[0][i][j]
+			o.Ks[9+i][j] -= o.dg[1][i][j]
+		}
+	}
+
+	// tangent matrix w.r.t. ψ: K = Tᵀ Ks T + ∂(T(ψa)ᵀ fsa)/∂ψa
+	la.MatCopy(K, 1, o.Ks)
+	for a := 0; a < 2; a++ {
+		k := 6*a + 3
+		for i := 0; i < 12; i++ { // columns: K[:, ψa] = K[:, wa] * Tp
+			copy(o.row, K[i][k:k+3])
+			for j := 0; j < 3; j++ {
+				K[i][k+j] = 0
+				for m := 0; m < 3; m++ {
+					K[i][k+j] += o.row[m] * o.Tp[a][m][j]
+				}
+			}
+		}
+	}
+	for a := 0; a < 2; a++ {
+		k := 6*a + 3
+		for j := 0; j < 12; j++ { // rows: K[ψa, :] = Tpᵀ * K[wa, :]
+			for i := 0; i < 3; i++ {
+				o.row[i] = K[k+i][j]
+			}
+			for i := 0; i < 3; i++ {
+				K[k+i][j] = 0
+				for m := 0; m < 3; m++ {
+					K[k+i][j] += o.Tp[a][m][i] * o.row[m]
+				}
+			}
+		}
+		rot_dTTv(o.tmp, ue[k:k+3], o.fs[k:k+3], o.x)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				K[k+i][k+j] += o.tmp[i][j]
+			}
+		}
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// rot_fcns computes the scalar functions of the angle θ used by the rotation maps
+//
+//	c = sin(θ)/θ, a = (1-cos(θ))/θ², b = (θ-sin(θ))/θ³, e = (1-(θ/2)cot(θ/2))/θ²
+//	a1 = a'/θ, b1 = b'/θ and e1 = e'/θ
+//	Note: series expansions are used for small angles to avoid cancellation errors
+func rot_fcns(θ float64) (c, a, b, e, a1, b1, e1 float64) {
+	θ2 := θ * θ
+	if θ < 0.05 {
+		θ4 := θ2 * θ2
+		c = 1.0 - θ2/6.0 + θ4/120.0
+		a = 0.5 - θ2/24.0 + θ4/720.0
+		b = 1.0/6.0 - θ2/120.0 + θ4/5040.0
+		e = 1.0/12.0 + θ2/720.0 + θ4/30240.0
+		a1 = -1.0/12.0 + θ2/180.0 - θ4/6720.0
+		b1 = -1.0/60.0 + θ2/1260.0 - θ4/60480.0
+		e1 = 1.0/360.0 + θ2/7560.0 + θ4/201600.0
+		return
+	}
+	s, cs := math.Sin(θ), math.Cos(θ)
+	h := θ / 2.0
+	ct := h / math.Tan(h)                                    // (θ/2) cot(θ/2)
+	dct := 0.5/math.Tan(h) - θ/(4.0*math.Sin(h)*math.Sin(h)) // derivative of ct
+	c = s / θ
+	a = (1.0 - cs) / θ2
+	b = (θ - s) / (θ2 * θ)
+	e = (1.0 - ct) / θ2
+	a1 = (θ*s - 2.0 + 2.0*cs) / (θ2 * θ2)
+	b1 = ((1.0-cs)*θ - 3.0*(θ-s)) / (θ2 * θ2 * θ)
+	e1 = (-dct/θ2 - 2.0*(1.0-ct)/(θ2*θ)) / θ
+	return
+}
+
+// rot_exp computes the rotation matrix R = exp(ψ) = I + c Ψ + a Ψ² (Rodrigues) where Ψ = skew(ψ)
+//
+//	S -- [3][3] workspace
+func rot_exp(R [][]float64, ψ []float64, S [][]float64) {
+	c, a, _, _, _, _, _ := rot_fcns(la.VecNorm(ψ))
+	rot_poly(R, c, a, ψ, S)
+}
+
+// rot_T computes the tangent operator T(ψ) = I + a Ψ + b Ψ² such that δw = T(ψ) δψ where δw
+// is the spin of exp(ψ); i.e. δR Rᵀ = skew(δw)
+//
+//	S -- [3][3] workspace
+func rot_T(T [][]float64, ψ []float64, S [][]float64) {
+	_, a, b, _, _, _, _ := rot_fcns(la.VecNorm(ψ))
+	rot_poly(T, a, b, ψ, S)
+}
+
+// rot_Tinv computes the inverse of the tangent operator: inv(T(θ)) = I - ½ Θ + e Θ²
+//
+//	S -- [3][3] workspace
+func rot_Tinv(Ti [][]float64, θ []float64, S [][]float64) {
+	_, _, _, e, _, _, _ := rot_fcns(la.VecNorm(θ))
+	rot_poly(Ti, -0.5, e, θ, S)
+}
+
+// rot_poly computes M = I + α V + β V² where V = skew(v)
+func rot_poly(M [][]float64, α, β float64, v []float64, V [][]float64) {
+	beam_skew(V, v)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			M[i][j] = α * V[i][j]
+			for k := 0; k < 3; k++ {
+				M[i][j] += β * V[i][k] * V[k][j]
+			}
+		}
+		M[i][i] += 1
+	}
+}
+
+// rot_log computes the rotation vector θ = log(R)
+//
+//	Note: |θ| must be smaller than π
+func rot_log(θ []float64, R [][]float64) {
+	θ[0] = R[2][1] - R[1][2]
+	θ[1] = R[0][2] - R[2][0]
+	θ[2] = R[1][0] - R[0][1]
+	s := la.VecNorm(θ) / 2.0                       // sin(|θ|)
+	c := (R[0][0] + R[1][1] + R[2][2] - 1.0) / 2.0 // cos(|θ|)
+	t := math.Atan2(s, c)
+	f := 0.5
+	if t > 1e-8 {
+		f = t / (2.0 * s)
+	}
+	la.VecScale(θ, 0, f, θ)
+}
+
+// rot_dTinvTm computes D = ∂(inv(T(θ))ᵀ m)/∂θ
+//
+//	Note: inv(T(θ))ᵀ m = m + ½ θ × m + e [(θ・m) θ - |θ|² m]
+func rot_dTinvTm(D [][]float64, θ, m []float64) {
+	t := la.VecNorm(θ)
+	_, _, _, e, _, _, e1 := rot_fcns(t)
+	tm := la.VecDot(θ, m)
+	for i := 0; i < 3; i++ {
+		g := tm*θ[i] - t*t*m[i]
+		for j := 0; j < 3; j++ {
+			D[i][j] = e1*g*θ[j] + e*(θ[i]*m[j]-2.0*m[i]*θ[j])
+		}
+		D[i][i] += e * tm
+	}
+	D[0][1], D[0][2] = D[0][1]+0.5*m[2], D[0][2]-0.5*m[1] // - ½ skew(m)
+	D[1][0], D[1][2] = D[1][0]-0.5*m[2], D[1][2]+0.5*m[0]
+	D[2][0], D[2][1] = D[2][0]+0.5*m[1], D[2][1]-0.5*m[0]
+}
+
+// rot_dTTv computes M = ∂(T(ψ)ᵀ v)/∂ψ
+//
+//	Note: T(ψ)ᵀ v = v - a ψ × v + b [(ψ・v) ψ - |ψ|² v]
+//	x -- [3] workspace
+func rot_dTTv(M [][]float64, ψ, v, x []float64) {
+	t := la.VecNorm(ψ)
+	_, a, b, _, a1, b1, _ := rot_fcns(t)
+	pv := la.VecDot(ψ, v)
+	beam_cross(x, ψ, v)
+	for i := 0; i < 3; i++ {
+		g := pv*ψ[i] - t*t*v[i]
+		for j := 0; j < 3; j++ {
+			M[i][j] = (-a1*x[i]+b1*g)*ψ[j] + b*(ψ[i]*v[j]-2.0*v[i]*ψ[j])
+		}
+		M[i][i] += b * pv
+	}
+	M[0][1], M[0][2] = M[0][1]-a*v[2], M[0][2]+a*v[1] // + a skew(v)
+	M[1][0], M[1][2] = M[1][0]+a*v[2], M[1][2]-a*v[0]
+	M[2][0], M[2][1] = M[2][0]-a*v[1], M[2][1]+a*v[0]
+}
+
+// beam_skew computes the skew matrix S such that S w = v × w
+func beam_skew(S [][]float64, v []float64) {
+	S[0][0], S[0][1], S[0][2] = 0, -v[2], v[1]
+	S[1][0], S[1][1], S[1][2] = v[2], 0, -v[0]
+	S[2][0], S[2][1], S[2][2] = -v[1], v[0], 0
+}
+
+// beam_cross computes the cross product c = a × b
+func beam_cross(c, a, b []float64) {
+	c[0] = a[1]*b[2] - a[2]*b[1]
+	c[1] = a[2]*b[0] - a[0]*b[2]
+	c[2] = a[0]*b[1] - a[1]*b[0]
+}
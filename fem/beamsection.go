@@ -0,0 +1,127 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/fun"
+)
+
+// BeamSection computes the properties of cross-sections of beams from their dimensions
+//  Note: 1) the dimensions along the local y direction (e1) are called "heights" (h) and the
+//           dimensions along the local z direction (e2) are called "widths" (b). Thus, Izz
+//           governs bending in the x-y plane and Iyy governs bending in the x-z plane
+//        2) available sections and corresponding parameters:
+//             "rect" -- rectangle:  b, h
+//             "circ" -- circle:     d
+//             "ish"  -- I/H:        b (flange width), h (total height),
+//                                   tf (flange thickness), tw (web thickness)
+//             "pipe" -- pipe:       d (outer diameter), t (wall thickness)
+//        3) the torsional constants of "rect" and "ish" sections are approximations
+//           (thin-walled sections are assumed for "ish")
+type BeamSection struct {
+	A   float64 // cross-sectional area
+	Izz float64 // moment of inertia about z
+	Iyy float64 // moment of inertia about y
+	J   float64 // torsional constant
+}
+
+// Init computes the properties of a section
+//  name -- "rect", "circ", "ish" or "pipe"
+//  prms -- parameters with the dimensions of the section. Other parameters are ignored
+func (o *BeamSection) Init(name string, prms fun.Prms) (ok bool) {
+
+	// dimensions
+	var b, h, d, t, tf, tw float64
+	for _, p := range prms {
+		switch p.N {
+		case "b":
+			b = p.V
+		case "h":
+			h = p.V
+		case "d":
+			d = p.V
+		case "t":
+			t = p.V
+		case "tf":
+			tf = p.V
+		case "tw":
+			tw = p.V
+		}
+	}
+
+	// properties
+	switch name {
+
+	// rectangle
+	case "rect":
+		if LogErrCond(b <= 0 || h <= 0, "section: b and h must be positive for rectangular sections. b=%g and h=%g are incorrect\n", b, h) {
+			return
+		}
+		o.A = b * h
+		o.Izz = b * h * h * h / 12.0
+		o.Iyy = h * b * b * b / 12.0
+		a, c := math.Max(b, h), math.Min(b, h)
+		r := c / a
+		o.J = a * c * c * c * (1.0/3.0 - 0.21*r*(1.0-r*r*r*r/12.0))
+
+	// circle
+	case "circ":
+		if LogErrCond(d <= 0, "section: d must be positive for circular sections. d=%g is incorrect\n", d) {
+			return
+		}
+		o.A = math.Pi * d * d / 4.0
+		o.Izz = math.Pi * d * d * d * d / 64.0
+		o.Iyy = o.Izz
+		o.J = 2.0 * o.Izz
+
+	// I/H
+	case "ish":
+		if LogErrCond(b <= 0 || h <= 0 || tf <= 0 || tw <= 0, "section: b, h, tf and tw must be positive for I/H sections. b=%g, h=%g, tf=%g and tw=%g are incorrect\n", b, h, tf, tw) {
+			return
+		}
+		if LogErrCond(2.0*tf >= h || tw >= b, "section: I/H section is inconsistent: 2*tf=%g must be smaller than h=%g and tw=%g must be smaller than b=%g\n", 2.0*tf, h, tw, b) {
+			return
+		}
+		hw := h - 2.0*tf // height of web
+		o.A = 2.0*b*tf + hw*tw
+		o.Izz = (b*h*h*h - (b-tw)*hw*hw*hw) / 12.0
+		o.Iyy = (2.0*tf*b*b*b + hw*tw*tw*tw) / 12.0
+		o.J = (2.0*b*tf*tf*tf + hw*tw*tw*tw) / 3.0
+
+	// pipe
+	case "pipe":
+		if LogErrCond(d <= 0 || t <= 0 || 2.0*t > d, "section: d and t must be positive and 2*t must not exceed d for pipe sections. d=%g and t=%g are incorrect\n", d, t) {
+			return
+		}
+		di := d - 2.0*t
+		o.A = math.Pi * (d*d - di*di) / 4.0
+		o.Izz = math.Pi * (d*d*d*d - di*di*di*di) / 64.0
+		o.Iyy = o.Izz
+		o.J = 2.0 * o.Izz
+
+	default:
+		LogErrCond(true, "section: cannot find section named %q\n", name)
+		return
+	}
+	return true
+}
+
+// Fill sets the properties that are zero (i.e. not explicitly given) with the values of this section
+func (o BeamSection) Fill(A, Izz, Iyy, J *float64) {
+	if *A == 0 {
+		*A = o.A
+	}
+	if *Izz == 0 {
+		*Izz = o.Izz
+	}
+	if *Iyy == 0 {
+		*Iyy = o.Iyy
+	}
+	if *J == 0 {
+		*J = o.J
+	}
+}
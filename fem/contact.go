@@ -0,0 +1,666 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// constants
+const (
+	CONTACT_NITMAX = 20      // max number of iterations when projecting slave nodes onto master faces
+	CONTACT_TOLXI  = 1e-12   // tolerance for the projection of slave nodes onto master faces
+	CONTACT_TOLIN  = 1.0e-10 // tolerance to check whether projections fall inside master faces
+)
+
+// ContactSeg holds a master segment (face or edge in 2D) of a contact condition
+type ContactSeg struct {
+	Nodes []*Node    // nodes of face
+	Shp   *shp.Shape // shape of face
+	Eqs   []int      // [nverts*ndim] equations of displacements of all nodes on face
+}
+
+// ContactNode holds data of a slave node of a contact condition (node-to-segment)
+//  Note: 1) the slave node is paired with the closest master segment at the beginning of each
+//           time step and the local system {n, e1, e2} at the projection of the slave node is
+//           kept constant during the iterations (small sliding within a time step)
+//        2) Gn > 0 means opening and Gn < 0 penetration; Pn > 0 is a compressive pressure.
+//           Gn = Pn = 0 if the slave node is not paired with any master segment
+//        3) the tangential tractions Pt follow a Coulomb law with an elastic (penalty) stick
+//           region and a radial return onto |Pt| = μ Pn during slip
+type ContactNode struct {
+
+	// data
+	Node   *Node            // slave node
+	Area   float64          // tributary area of slave node
+	Prm    *inp.ContactData // parameters
+	Segs   []*ContactSeg    // candidate master segments
+	Auglag bool             // use augmented Lagrangian method
+
+	// pairing @ beginning of time step
+	Seg  *ContactSeg // closest master segment; nil => not paired
+	Q    [][]float64 // [ndim][ndim] local directions {n, e1, e2} @ projection of slave node
+	B    [][]float64 // [ndim][nl] relative displacements g = B・u; nl = ndim * (1 + nverts)
+	X    []float64   // [nl] initial coordinates corresponding to the equations in Umap
+	Umap []int       // [nl] assembly map: slave equations followed by the equations of master nodes
+	Pt0  []float64   // [ndim-1] tangential tractions @ beginning of time step
+
+	// state
+	Gn   float64     // normal gap
+	Pn   float64     // normal contact pressure
+	Pt   []float64   // [ndim-1] tangential contact tractions
+	Slip bool        // slipping
+	Lam  float64     // augmented Lagrangian: Lagrange multiplier (pressure)
+	D    [][]float64 // [ndim][ndim] tangent: D = -d{Pn,Pt}/d{gn,gt}
+
+	// converged state
+	LamC float64   // converged Lagrange multiplier
+	PtC  []float64 // [ndim] converged tangential traction vector in the global system
+}
+
+// ContactBcs holds all contact conditions of a stage
+type ContactBcs struct {
+	Nodes   []*ContactNode // slave nodes
+	Vid2idx map[int]int    // maps vertex id to index in Nodes
+	Naug    int            // number of augmentations in the current time step
+	NaugMax int            // max number of augmentations per time step
+	nnz     int            // max number of non-zeros added to Kb
+}
+
+// Reset initialises internal structures
+func (o *ContactBcs) Reset() {
+	o.Nodes = make([]*ContactNode, 0)
+	o.Vid2idx = make(map[int]int)
+	o.Naug = 0
+	o.NaugMax = 0
+	o.nnz = 0
+}
+
+// Nnz returns the max number of non-zeros added to Kb
+func (o ContactBcs) Nnz() int {
+	return o.nnz
+}
+
+// Set sets new contact condition between slave vertices and master faces
+func (o *ContactBcs) Set(dat *inp.ContactData, msh *inp.Mesh, vid2node []*Node, cid2active []bool) (setisok bool) {
+
+	// master segments
+	ndim := Global.Ndim
+	ukeys := []string{"ux", "uy", "uz"}[:ndim]
+	pairs, found := msh.FaceTag2cells[dat.Ftag]
+	if LogErrCond(!found, "contact: cannot find faces with tag = %d", dat.Ftag) {
+		return
+	}
+	var segs []*ContactSeg
+	var nvmax int
+	for _, pair := range pairs {
+		c := pair.C
+		if !cid2active[c.Id] || c.IsJoint || c.IsInterface {
+			continue
+		}
+		seg := &ContactSeg{Shp: shp.Get(c.Shp.FaceType)}
+		for _, l := range shp.GetFaceLocalVerts(c.Type, pair.Fid) {
+			nod := vid2node[c.Verts[l]]
+			for _, key := range ukeys {
+				eq := nod.GetEq(key)
+				if LogErrCond(eq < 0, "contact: master node %d does not have %q", nod.Vert.Id, key) {
+					return
+				}
+				seg.Eqs = append(seg.Eqs, eq)
+			}
+			seg.Nodes = append(seg.Nodes, nod)
+		}
+		if len(seg.Nodes) > nvmax {
+			nvmax = len(seg.Nodes)
+		}
+		segs = append(segs, seg)
+	}
+	if LogErrCond(len(segs) == 0, "contact: cannot find active faces with tag = %d", dat.Ftag) {
+		return
+	}
+
+	// slave vertices
+	verts, found := msh.VertTag2verts[dat.Vtag]
+	if LogErrCond(!found, "contact: cannot find vertices with tag = %d", dat.Vtag) {
+		return
+	}
+	vset := make(map[int]bool)
+	for _, v := range verts {
+		if vid2node[v.Id] != nil {
+			vset[v.Id] = true
+		}
+	}
+	areas := contact_areas(msh, vset, cid2active)
+
+	// slave nodes
+	nl := ndim * (1 + nvmax)
+	for _, v := range verts {
+		nod := vid2node[v.Id]
+		if nod == nil {
+			continue // inactive
+		}
+		if LogErrCond(areas[v.Id] <= 0, "contact: cannot compute tributary area of slave vertex %d. slave vertices must be on the boundary of solid elements with faces of type lin2, lin3, tri3 or qua4", v.Id) {
+			return
+		}
+		if _, dup := o.Vid2idx[v.Id]; LogErrCond(dup, "contact: vertex %d cannot be a slave in more than one contact condition", v.Id) {
+			return
+		}
+		for _, key := range ukeys {
+			if LogErrCond(nod.GetEq(key) < 0, "contact: slave node %d does not have %q", v.Id, key) {
+				return
+			}
+		}
+		o.Vid2idx[v.Id] = len(o.Nodes)
+		o.Nodes = append(o.Nodes, &ContactNode{
+			Node:   nod,
+			Area:   areas[v.Id],
+			Prm:    dat,
+			Segs:   segs,
+			Auglag: dat.Method == "auglag",
+			Q:      la.MatAlloc(ndim, ndim),
+			Pt0:    make([]float64, ndim-1),
+			Pt:     make([]float64, ndim-1),
+			D:      la.MatAlloc(ndim, ndim),
+			PtC:    make([]float64, ndim),
+		})
+		o.nnz += nl * nl
+	}
+	if dat.Method == "auglag" && dat.Naug > o.NaugMax {
+		o.NaugMax = dat.Naug
+	}
+
+	// log
+	log.Printf("dom: contact: vtag=%d ftag=%d method=%s nslaves=%d nsegs=%d\n", dat.Vtag, dat.Ftag, dat.Method, len(vset), len(segs))
+	return true
+}
+
+// Detect pairs slave nodes with the closest master segments in the current configuration
+//  Note: must be called at the beginning of each time step
+func (o *ContactBcs) Detect(sol *Solution) {
+	o.Naug = 0
+	for _, c := range o.Nodes {
+		c.Lam = c.LamC
+		c.detect(sol)
+	}
+}
+
+// AddToRhs adds contact forces to the augmented fb vector
+func (o *ContactBcs) AddToRhs(fb []float64, sol *Solution) {
+	for _, c := range o.Nodes {
+		c.calc_state(sol)
+		if c.Pn == 0 {
+			continue
+		}
+		for r, I := range c.Umap {
+			fb[I] += c.Area * c.B[0][r] * c.Pn
+			for k, pt := range c.Pt {
+				fb[I] += c.Area * c.B[k+1][r] * pt
+			}
+		}
+	}
+}
+
+// AddToKb adds the contact contributions to the Jacobian matrix Kb
+//  Note: AddToRhs must be called first in order to compute the state of slave nodes
+func (o *ContactBcs) AddToKb(Kb *la.Triplet) {
+	ndim := Global.Ndim
+	for _, c := range o.Nodes {
+		if c.Pn == 0 {
+			continue
+		}
+		for r, I := range c.Umap {
+			for s, J := range c.Umap {
+				var val float64
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						val += c.B[i][r] * c.D[i][j] * c.B[j][s]
+					}
+				}
+				Kb.Put(I, J, c.Area*val)
+			}
+		}
+	}
+}
+
+// Augment updates the Lagrange multipliers of the augmented Lagrangian method (Uzawa)
+//  Output:
+//   again -- the multipliers were updated and the iterations must continue because
+//            the penetration of some node is larger than the tolerance
+func (o *ContactBcs) Augment(sol *Solution) (again bool) {
+	if o.Naug >= o.NaugMax {
+		return
+	}
+	for _, c := range o.Nodes {
+		if !c.Auglag {
+			continue
+		}
+		c.calc_state(sol)
+		if c.Pn > 0 && -c.Gn > c.Prm.Gtol {
+			again = true
+		}
+	}
+	if !again {
+		return
+	}
+	for _, c := range o.Nodes {
+		if c.Auglag {
+			c.Lam = c.Pn
+		}
+	}
+	o.Naug += 1
+	return
+}
+
+// Commit saves the state of slave nodes after convergence of the time step
+func (o *ContactBcs) Commit(sol *Solution) {
+	for _, c := range o.Nodes {
+		c.calc_state(sol)
+		c.LamC = 0
+		if c.Auglag {
+			c.LamC = c.Pn
+		}
+		for i := 0; i < len(c.PtC); i++ {
+			c.PtC[i] = 0
+			for k, pt := range c.Pt {
+				c.PtC[i] += pt * c.Q[k+1][i]
+			}
+		}
+	}
+}
+
+// Encode encodes the state of slave nodes
+func (o ContactBcs) Encode(enc Encoder) (ok bool) {
+	n := len(o.Nodes)
+	gn, pn, pt := make([]float64, n), make([]float64, n), make([][]float64, n)
+	for i, c := range o.Nodes {
+		gn[i], pn[i], pt[i] = c.Gn, c.Pn, c.PtC
+	}
+	if LogErr(enc.Encode(gn), "ContactBcs: encode") {
+		return
+	}
+	if LogErr(enc.Encode(pn), "ContactBcs: encode") {
+		return
+	}
+	return !LogErr(enc.Encode(pt), "ContactBcs: encode")
+}
+
+// Decode decodes the state of slave nodes
+func (o *ContactBcs) Decode(dec Decoder) (ok bool) {
+	var gn, pn []float64
+	var pt [][]float64
+	if LogErr(dec.Decode(&gn), "ContactBcs: decode") {
+		return
+	}
+	if LogErr(dec.Decode(&pn), "ContactBcs: decode") {
+		return
+	}
+	if LogErr(dec.Decode(&pt), "ContactBcs: decode") {
+		return
+	}
+	if LogErrCond(len(gn) != len(o.Nodes), "ContactBcs: number of slave nodes in file is incorrect. %d != %d", len(gn), len(o.Nodes)) {
+		return
+	}
+	for i, c := range o.Nodes {
+		c.Gn, c.Pn = gn[i], pn[i]
+		copy(c.PtC, pt[i])
+	}
+	return true
+}
+
+// OutVals returns the gap, normal pressure and norm of tangential traction of a slave node
+//  Note: returns found == false if vid is not a slave vertex
+func (o ContactBcs) OutVals(vid int) (gn, pn, pt float64, found bool) {
+	idx, found := o.Vid2idx[vid]
+	if !found {
+		return
+	}
+	c := o.Nodes[idx]
+	return c.Gn, c.Pn, la.VecNorm(c.PtC), true
+}
+
+// List returns a simple list logging the state of slave nodes
+func (o ContactBcs) List() (l string) {
+	for i, c := range o.Nodes {
+		if i > 0 {
+			l += " "
+		}
+		l += io.Sf("[vid=%d A=%g gn=%g pn=%g slip=%v]", c.Node.Vert.Id, c.Area, c.Gn, c.Pn, c.Slip)
+	}
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// detect finds the closest master segment and computes the local system @ projection
+func (o *ContactNode) detect(sol *Solution) {
+
+	// current coordinates of slave node
+	ndim := Global.Ndim
+	xs := make([]float64, ndim)
+	seqs := make([]int, ndim)
+	for i, key := range []string{"ux", "uy", "uz"}[:ndim] {
+		seqs[i] = o.Node.GetEq(key)
+		xs[i] = o.Node.Vert.C[i] + sol.Y[seqs[i]]
+	}
+
+	// closest segment
+	o.Seg = nil
+	var S []float64
+	dist := math.MaxFloat64
+	for _, seg := range o.Segs {
+		if seg.has(o.Node) {
+			continue
+		}
+		Sseg, Q, gn, h, ok := seg.project(xs, sol)
+		if !ok || gn < -h || math.Abs(gn) >= dist {
+			continue
+		}
+		dist = math.Abs(gn)
+		o.Seg, S = seg, Sseg
+		la.MatCopy(o.Q, 1, Q)
+	}
+	if o.Seg == nil {
+		o.Umap, o.B, o.X = nil, nil, nil
+		return
+	}
+
+	// assembly map, initial coordinates and relative displacements matrix
+	nv := len(o.Seg.Nodes)
+	nl := ndim * (1 + nv)
+	o.Umap = append(seqs, o.Seg.Eqs...)
+	o.X = make([]float64, nl)
+	o.B = la.MatAlloc(ndim, nl)
+	for j := 0; j < ndim; j++ {
+		o.X[j] = o.Node.Vert.C[j]
+		for m, nod := range o.Seg.Nodes {
+			o.X[j+(1+m)*ndim] = nod.Vert.C[j]
+		}
+	}
+	for i := 0; i < ndim; i++ {
+		for j := 0; j < ndim; j++ {
+			o.B[i][j] = o.Q[i][j]
+			for m := 0; m < nv; m++ {
+				o.B[i][j+(1+m)*ndim] = -o.Q[i][j] * S[m]
+			}
+		}
+	}
+
+	// tangential tractions in the new local system
+	for k := 0; k < ndim-1; k++ {
+		o.Pt0[k] = la.VecDot(o.Q[k+1], o.PtC)
+	}
+}
+
+// calc_state computes gap, pressure, tractions and tangent of a slave node
+func (o *ContactNode) calc_state(sol *Solution) {
+
+	// clear state
+	o.Gn, o.Pn, o.Slip = 0, 0, false
+	la.VecFill(o.Pt, 0)
+	la.MatFill(o.D, 0)
+	if o.Seg == nil {
+		return
+	}
+
+	// normal gap and pressure
+	for r, I := range o.Umap {
+		o.Gn += o.B[0][r] * (o.X[r] + sol.Y[I])
+	}
+	kn, kt, μ := o.Prm.Kn, o.Prm.Kt, o.Prm.Mu
+	pn := o.Lam - kn*o.Gn
+	if pn <= 0 {
+		return
+	}
+	o.Pn = pn
+	o.D[0][0] = kn
+	if μ == 0 {
+		return
+	}
+
+	// trial tangential tractions
+	nt := len(o.Pt)
+	var τ float64
+	for k := 0; k < nt; k++ {
+		var Δg float64
+		for r, I := range o.Umap {
+			Δg += o.B[k+1][r] * sol.ΔY[I]
+		}
+		o.Pt[k] = o.Pt0[k] - kt*Δg
+		τ += o.Pt[k] * o.Pt[k]
+	}
+	τ = math.Sqrt(τ)
+
+	// stick
+	if τ <= μ*pn {
+		for k := 0; k < nt; k++ {
+			o.D[k+1][k+1] = kt
+		}
+		return
+	}
+
+	// slip
+	o.Slip = true
+	for k := 0; k < nt; k++ {
+		o.Pt[k] *= μ * pn / τ
+	}
+	for k := 0; k < nt; k++ {
+		mk := o.Pt[k] / (μ * pn)
+		o.D[k+1][0] = μ * kn * mk
+		for l := 0; l < nt; l++ {
+			ml := o.Pt[l] / (μ * pn)
+			o.D[k+1][l+1] = μ * pn * kt * (delta(k, l) - mk*ml) / τ
+		}
+	}
+}
+
+// has returns whether the segment contains a given node or not
+func (o ContactSeg) has(nod *Node) bool {
+	for _, n := range o.Nodes {
+		if n == nod {
+			return true
+		}
+	}
+	return false
+}
+
+// project computes the closest point projection of xs onto the segment in the current configuration
+//  Output:
+//   S  -- shape functions @ projection
+//   Q  -- local directions {n, e1, e2} @ projection
+//   gn -- normal gap
+//   h  -- size of segment
+//   ok -- projection converged and falls inside the segment
+func (o ContactSeg) project(xs []float64, sol *Solution) (S []float64, Q [][]float64, gn, h float64, ok bool) {
+
+	// current coordinates and size of segment
+	ndim := len(xs)
+	nv := len(o.Nodes)
+	x := la.MatAlloc(nv, ndim)
+	for m, nod := range o.Nodes {
+		for i := 0; i < ndim; i++ {
+			x[m][i] = nod.Vert.C[i] + sol.Y[o.Eqs[i+m*ndim]]
+		}
+	}
+	for m := 0; m < nv; m++ {
+		for n := m + 1; n < nv; n++ {
+			var d float64
+			for i := 0; i < ndim; i++ {
+				d += (x[m][i] - x[n][i]) * (x[m][i] - x[n][i])
+			}
+			h = max(h, math.Sqrt(d))
+		}
+	}
+
+	// natural coordinates: initial guess @ centroid
+	gnd := o.Shp.Gndim
+	tri := o.Shp.BasicType == "tri3"
+	R := make([]float64, 3)
+	if tri {
+		R[0], R[1] = 1.0/3.0, 1.0/3.0
+	}
+
+	// Gauss-Newton iterations: minimise |xs - x(R)|²
+	S = make([]float64, nv)
+	dSdR := la.MatAlloc(nv, gnd)
+	a := la.MatAlloc(gnd, ndim)
+	y := make([]float64, ndim)
+	r := make([]float64, ndim)
+	var converged bool
+	for it := 0; it < CONTACT_NITMAX; it++ {
+		o.Shp.Func(S, dSdR, R[0], R[1], R[2], true)
+		la.VecFill(y, 0)
+		la.MatFill(a, 0)
+		for m := 0; m < nv; m++ {
+			for i := 0; i < ndim; i++ {
+				y[i] += S[m] * x[m][i]
+				for k := 0; k < gnd; k++ {
+					a[k][i] += dSdR[m][k] * x[m][i]
+				}
+			}
+		}
+		if converged {
+			break
+		}
+		for i := 0; i < ndim; i++ {
+			r[i] = xs[i] - y[i]
+		}
+		var ΔR0, ΔR1 float64
+		if gnd == 1 {
+			ΔR0 = la.VecDot(a[0], r) / la.VecDot(a[0], a[0])
+		} else {
+			g00, g01, g11 := la.VecDot(a[0], a[0]), la.VecDot(a[0], a[1]), la.VecDot(a[1], a[1])
+			b0, b1 := la.VecDot(a[0], r), la.VecDot(a[1], r)
+			det := g00*g11 - g01*g01
+			ΔR0 = (g11*b0 - g01*b1) / det
+			ΔR1 = (g00*b1 - g01*b0) / det
+		}
+		R[0] += ΔR0
+		R[1] += ΔR1
+		converged = math.Abs(ΔR0)+math.Abs(ΔR1) < CONTACT_TOLXI
+	}
+	if !converged {
+		return
+	}
+
+	// check whether projection falls inside segment
+	tol := CONTACT_TOLIN
+	if tri {
+		if R[0] < -tol || R[1] < -tol || R[0]+R[1] > 1+tol {
+			return
+		}
+	} else {
+		for k := 0; k < gnd; k++ {
+			if math.Abs(R[k]) > 1+tol {
+				return
+			}
+		}
+	}
+
+	// local system and gap
+	Q = la.MatAlloc(ndim, ndim)
+	if FaceLocalSystem(Q, a) < shp.MINDET {
+		return
+	}
+	for i := 0; i < ndim; i++ {
+		gn += Q[0][i] * (xs[i] - y[i])
+	}
+	ok = true
+	return
+}
+
+// contact_areas computes the tributary areas of vertices in vset by integrating the shape
+// functions over the faces of active solid cells with all vertices in vset
+//  Note: faces shared by two cells are not on the boundary and are disregarded
+func contact_areas(msh *inp.Mesh, vset map[int]bool, cid2active []bool) (areas map[int]float64) {
+
+	// boundary faces
+	type face struct {
+		c      *inp.Cell
+		lverts []int
+	}
+	var faces []face
+	var keys []string
+	count := make(map[string]int)
+	ndim := Global.Ndim
+	for _, c := range msh.Cells {
+		if !cid2active[c.Id] || c.IsJoint || c.IsInterface || c.Shp == nil || c.Shp.Gndim != ndim {
+			continue
+		}
+		for _, lverts := range c.Shp.FaceLocalV {
+			vids := make([]int, len(lverts))
+			for k, l := range lverts {
+				vids[k] = c.Verts[l]
+			}
+			if !contact_allin(vids, vset) {
+				continue
+			}
+			sort.Ints(vids)
+			key := io.Sf("%v", vids)
+			count[key] += 1
+			faces = append(faces, face{c, lverts})
+			keys = append(keys, key)
+		}
+	}
+
+	// integrate shape functions over faces
+	areas = make(map[int]float64)
+	for idx, f := range faces {
+		if count[keys[idx]] > 1 {
+			continue
+		}
+		fshp := shp.Get(f.c.Shp.FaceType)
+		ips, err := shp.GetIps(fshp.Type, 0)
+		if LogErr(err, "contact: cannot get integration points of faces") {
+			return
+		}
+		nv := len(f.lverts)
+		S := make([]float64, nv)
+		dSdR := la.MatAlloc(nv, fshp.Gndim)
+		a := la.MatAlloc(fshp.Gndim, ndim)
+		Q := la.MatAlloc(ndim, ndim)
+		for _, ip := range ips {
+			fshp.Func(S, dSdR, ip.R, ip.S, ip.T, true)
+			la.MatFill(a, 0)
+			for k := 0; k < fshp.Gndim; k++ {
+				for i := 0; i < ndim; i++ {
+					for m, l := range f.lverts {
+						a[k][i] += msh.Verts[f.c.Verts[l]].C[i] * dSdR[m][k]
+					}
+				}
+			}
+			J := FaceLocalSystem(Q, a)
+			for m, l := range f.lverts {
+				areas[f.c.Verts[l]] += S[m] * J * ip.W
+			}
+		}
+	}
+	return
+}
+
+// contact_allin returns whether all vertices are in vset or not
+func contact_allin(vids []int, vset map[int]bool) bool {
+	for _, vid := range vids {
+		if !vset[vid] {
+			return false
+		}
+	}
+	return true
+}
+
+// delta returns the Kronecker delta
+func delta(i, j int) float64 {
+	if i == j {
+		return 1
+	}
+	return 0
+}
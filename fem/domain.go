@@ -5,6 +5,7 @@
 package fem
 
 import (
+	"bytes"
 	"log"
 	"sort"
 
@@ -80,6 +81,7 @@ type Solution struct {
 
 	// state
 	T      float64   // current time
+	Dt     float64   // current time increment
 	Y      []float64 // DOFs (solution variables); e.g. y = {u, p}
 	Dydt   []float64 // dy/dt
 	D2ydt2 []float64 // d²y/dt²
@@ -123,10 +125,13 @@ type Domain struct {
 	// stage: subsets of elements
 	ElemIntvars []ElemIntvars   // elements with internal vars in this processor
 	ElemConnect []ElemConnector // connector elements in this processor
+	ElemStagg   []ElemStaggered // elements solved by a staggered scheme in this processor
 
 	// stage: coefficients and prescribed forces
 	EssenBcs EssentialBcs // constraints (Lagrange multipliers)
 	PtNatBcs PtNaturalBcs // point loads such as prescribed forces at nodes
+	Contacts ContactBcs   // frictional contact conditions
+	Anchors  Anchors      // ground anchors and their lock-off forces
 
 	// stage: t1 and t2 variables
 	T1eqs []int // first t-derivative variables; e.g.:  dp/dt vars (subset of ykeys)
@@ -148,6 +153,15 @@ type Domain struct {
 
 	// for divergence control
 	bkpSol *Solution // backup solution
+
+	// for transferring the state between stages
+	stgSol      *Solution // solution at the end of the previous stage
+	stgVid2node []*Node   // nodes of the previous stage
+	stgCid2elem []Elem    // elements of the previous stage
+
+	// for computing the liquid mass balance at each time step
+	mbM float64 // mass of liquid at the end of the previous time step
+	mbQ float64 // liquid mass flux leaving the domain at the end of the previous time step
 }
 
 // NewDomain returns a new domain
@@ -169,7 +183,17 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 
 	// backup state
 	if idxstg > 0 {
-		o.create_stage_copy()
+		if LogErrCond(stg.Continue && (stg.HydroSt || stg.GeoSt != nil || stg.IniStress != nil), "stage %d: the state of the previous stage cannot be continued if initial conditions (hydrost, geost or inistress) are given", idxstg) {
+			return
+		}
+		if stg.Continue {
+			o.create_stage_copy()
+			if !o.Anchors.Lock(o.Sol) {
+				return
+			}
+		} else {
+			o.Anchors.Locks = nil // locked heads need the state of the previous stage
+		}
 		if !o.fix_inact_flags(stg.Activate, false) {
 			return
 		}
@@ -198,6 +222,7 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 	// subsets of elements
 	o.ElemConnect = make([]ElemConnector, 0)
 	o.ElemIntvars = make([]ElemIntvars, 0)
+	o.ElemStagg = make([]ElemStaggered, 0)
 
 	// allocate nodes and cells (active only) -------------------------------------------------------
 
@@ -326,6 +351,15 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 		o.NnzKb += nnz
 	}
 
+	// anchors: debond rod-joints along free lengths
+	o.Anchors.Reset()
+	for _, dat := range o.Reg.Anchors {
+		if !o.Anchors.Set(dat, o.Msh, o.Cid2elem) {
+			return
+		}
+	}
+	o.NnzKb += o.Anchors.Nnz()
+
 	// logging
 	log.Printf("dom: stage # %d %s\n", idxstg, stg.Desc)
 	log.Printf("dom: nnodes=%d nelems=%d\n", len(o.Nodes), len(o.Elems))
@@ -335,6 +369,7 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 	// (re)set constraints and prescribed forces structures
 	o.EssenBcs.Reset()
 	o.PtNatBcs.Reset()
+	o.Contacts.Reset()
 
 	// element conditions
 	for _, ec := range stg.EleConds {
@@ -399,6 +434,21 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 		}
 	}
 
+	// contact conditions
+	for _, cd := range stg.Contacts {
+		if !o.Contacts.Set(cd, o.Msh, o.Vid2node, o.Cid2active) {
+			return
+		}
+	}
+	o.NnzKb += o.Contacts.Nnz()
+
+	// lock-off forces of anchors
+	for _, lk := range stg.Lockoffs {
+		if !o.Anchors.Lockoff(lk) {
+			return
+		}
+	}
+
 	// resize slices --------------------------------------------------------------------------------
 
 	// t1 and t2 equations
@@ -462,6 +512,13 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 		}
 	}
 
+	// transfer state of nodes and elements that are active in the previous and this stage
+	if stg.Continue {
+		if !o.recover_stage_copy() {
+			return
+		}
+	}
+
 	// import results from another set of files
 	if stg.Import != nil {
 		sum := ReadSum(stg.Import.Dir, stg.Import.Fnk)
@@ -499,6 +556,8 @@ func (o *Domain) SetStage(idxstg int, stg *inp.Stage, distr bool) (setstageisok
 	if Global.LogBcs {
 		log.Printf("dom: essential boundary conditions:%v", o.EssenBcs.List(stg.Control.Tf))
 		log.Printf("dom: ptnatbcs=%v", o.PtNatBcs.List(stg.Control.Tf))
+		log.Printf("dom: contacts=%v", o.Contacts.List())
+		log.Printf("dom: anchors=%v", o.Anchors.List())
 	}
 	log.Printf("dom: ny=%d nlam=%d nnzKb=%d nnzA=%d nt1eqs=%d nt2eqs=%d", o.Ny, o.Nlam, o.NnzKb, o.NnzA, len(o.T1eqs), len(o.T2eqs))
 
@@ -516,6 +575,11 @@ func (o *Domain) add_element_to_subsets(ele Elem) {
 	if e, ok := ele.(ElemConnector); ok {
 		o.ElemConnect = append(o.ElemConnect, e)
 	}
+	if e, ok := ele.(ElemStaggered); ok {
+		if e.Staggered() {
+			o.ElemStagg = append(o.ElemStagg, e)
+		}
+	}
 }
 
 // star_vars computes starred variables
@@ -551,6 +615,54 @@ func (o *Domain) star_vars(Δt float64) (err error) {
 
 // create_stage_copy creates a copy of current stage => to be used later when activating/deactivating elements
 func (o *Domain) create_stage_copy() {
+	o.stgSol = o.Sol
+	o.stgVid2node = o.Vid2node
+	o.stgCid2elem = o.Cid2elem
+	o.bkpSol = nil // number of equations may change
+}
+
+// recover_stage_copy transfers the primary variables of nodes and the internal variables of
+// elements that are active in both the previous and the current stage
+//  Note: this is only carried out for stages with the "continue" flag; otherwise each stage
+//        starts from the initial conditions given in the stage (e.g. hydrost or geost).
+//        The internal forces of elements deactivated in this stage are then no longer balanced
+//        and are thus applied (e.g. excavation) in the first time step of this stage
+func (o *Domain) recover_stage_copy() (ok bool) {
+	if o.stgSol == nil {
+		return true
+	}
+	for vid, old := range o.stgVid2node {
+		nod := o.Vid2node[vid]
+		if old == nil || nod == nil {
+			continue
+		}
+		for _, dof := range old.Dofs {
+			eq := nod.GetEq(dof.Key)
+			if eq < 0 {
+				continue
+			}
+			o.Sol.Y[eq] = o.stgSol.Y[dof.Eq]
+			if len(o.Sol.Dydt) > 0 && len(o.stgSol.Dydt) > 0 {
+				o.Sol.Dydt[eq] = o.stgSol.Dydt[dof.Eq]
+				o.Sol.D2ydt2[eq] = o.stgSol.D2ydt2[dof.Eq]
+			}
+		}
+	}
+	for cid, old := range o.stgCid2elem {
+		ele := o.Cid2elem[cid]
+		if old == nil || ele == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if !old.Encode(GetEncoder(&buf)) {
+			return
+		}
+		if !ele.Decode(GetDecoder(&buf)) {
+			return
+		}
+	}
+	o.stgSol, o.stgVid2node, o.stgCid2elem = nil, nil, nil
+	return true
 }
 
 // set_act_deact_flags sets inactive flags for new active/inactive elements
@@ -6,51 +6,87 @@ package fem
 
 import (
 	"math"
+	"strings"
 
 	"github.com/cpmech/gofem/inp"
 
 	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
 )
 
-// Beam represents a structural beam element (Euler-Bernoulli, linear elastic)
+// Beam represents a structural beam element (linear elastic) with Euler-Bernoulli or Timoshenko
+// (kappa > 0) kinematics in 2D (ux, uy, rz) or 3D (ux, uy, uz, rx, ry, rz)
+//  Note: extra keycodes: "!vx,!vy,!vz" (orientation; 3D), "!hingeL,!hingeR" (moment releases),
+//        "!oxL,...,!ozR" (rigid end offsets), "!nsta" (stations) and "!corot:1". See beam_reskeys
 type Beam struct {
 
 	// basic data
-	Cid int         // cell/element id
-	X   [][]float64 // matrix of nodal coordinates [ndim][nnode]
-	Nu  int         // total number of unknowns == 2 * nsn
+	Cid  int         // cell/element id
+	X    [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Ndof int         // number of DOFs per node: 3 (2D) or 6 (3D)
+	Nu   int         // total number of unknowns == 2 * ndof
+	L    float64     // length of beam (flexible part)
+	Off  [][]float64 // [2][ndim] rigid end offsets at nodes 0 and 1
 
 	// parameters
-	E   float64 // Young's modulus
-	A   float64 // cross-sectional area
-	Izz float64 // Inertia zz
+	E     float64 // Young's modulus
+	G     float64 // shear modulus
+	A     float64 // cross-sectional area
+	Izz   float64 // Inertia zz
+	Iyy   float64 // Inertia yy (3D)
+	J     float64 // torsional constant (3D)
+	Kappa float64 // shear correction factor (Timoshenko)
 
 	// variables for dynamics
 	Rho  float64  // density of solids
 	Gfcn fun.Func // gravity function
 
 	// vectors and matrices
-	T   [][]float64 // global-to-local transformation matrix [nnode*ndim][nnode*ndim]
+	T   [][]float64 // global-to-local transformation matrix [nnode*ndof][nnode*ndof]
 	Kl  [][]float64 // local K matrix
 	K   [][]float64 // global K matrix
 	Ml  [][]float64 // local M matrices
 	M   [][]float64 // global M matrices
 	Rus []float64   // residual: Rus = fi - fx
 
+	// co-rotational kinematics
+	Corot bool       // co-rotational formulation (large displacements and rotations)
+	Cr3d  *BeamCorot // co-rotational kinematics in 3D
+
+	// moment releases
+	Hinge []int       // local indices of released rotations
+	Tc    [][]float64 // static condensation matrix: ul(all) = Tc * ul(retained) [nu][nu]
+
 	// problem variables
 	Umap []int    // assembly map (location array/element equations)
 	Hasq bool     // has distributed loads
-	QnL  fun.Func // distributed normal load functions: left
-	QnR  fun.Func // distributed normal load functions: right
+	QnL  fun.Func // distributed normal load functions (local y): left
+	QnR  fun.Func // distributed normal load functions (local y): right
+	QzL  fun.Func // distributed normal load functions (local z; 3D): left
+	QzR  fun.Func // distributed normal load functions (local z; 3D): right
 	Qt   fun.Func // distributed tangential load
 
+	// internal forces at stations
+	Nsta int         // number of stations
+	Xsta [][]float64 // [nsta][ndim] coordinates of stations
+	Fsta [][]float64 // [nsta][nres] internal forces at stations. see beam_reskeys
+
 	// scratchpad. computed @ each ip
 	grav []float64 // [ndim] gravity vector
 	fi   []float64 // [nu] internal forces
 	ue   []float64 // local u vector
 	ζe   []float64 // local ζ* vector
 	fxl  []float64 // local external force vector
+	ul   []float64 // local displacements
+	fe   []float64 // local end forces acting on beam
+	fxc  []float64 // condensed local external force vector
+
+	// scratchpad. co-rotational kinematics in 2D
+	fn []float64   // [3] natural forces {N, M0, M1}
+	rc []float64   // [nu] r vector
+	zc []float64   // [nu] z vector
+	Bc [][]float64 // [3][nu] B matrix
 }
 
 // register element
@@ -83,18 +119,13 @@ func init() {
 	// element allocator
 	eallocators["beam"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
 
-		// check
-		if LogErrCond(Global.Ndim == 3, "beam is not implemented for 3D yet") {
-			return nil
-		}
-
 		// basic data
 		var o Beam
 		o.Cid = cid
 		o.X = x
 		ndim := Global.Ndim
-		ndof := 3 * (ndim - 1)
-		o.Nu = ndof * ndim
+		o.Ndof = 3 * (ndim - 1)
+		o.Nu = 2 * o.Ndof
 
 		// parameters
 		matname := edat.Mat
@@ -102,18 +133,50 @@ func init() {
 		if LogErrCond(matdata == nil, "materials database failed on getting %q material\n", matname) {
 			return nil
 		}
+		var ν float64
 		for _, p := range matdata.Prms {
 			switch p.N {
 			case "E":
 				o.E = p.V
+			case "G":
+				o.G = p.V
+			case "nu":
+				ν = p.V
 			case "A":
 				o.A = p.V
 			case "Izz":
 				o.Izz = p.V
+			case "Iyy":
+				o.Iyy = p.V
+			case "J":
+				o.J = p.V
+			case "kappa":
+				o.Kappa = p.V
 			case "rho":
 				o.Rho = p.V
 			}
 		}
+		if o.G == 0 {
+			o.G = o.E / (2.0 * (1.0 + ν))
+		}
+
+		// cross-section: properties computed from the dimensions given in the material's extra
+		// keycodes; e.g. "!section:rect". See BeamSection
+		if name, found := io.Keycode(matdata.Extra, "section"); found {
+			var sec BeamSection
+			if !sec.Init(name, matdata.Prms) {
+				return nil
+			}
+			sec.Fill(&o.A, &o.Izz, &o.Iyy, &o.J)
+		}
+		if LogErrCond(o.E <= 0 || o.A <= 0 || o.Izz <= 0, "beam: E, A and Izz must be positive. E=%g, A=%g and Izz=%g are incorrect\n", o.E, o.A, o.Izz) {
+			return nil
+		}
+		if ndim == 3 {
+			if LogErrCond(o.Iyy <= 0 || o.J <= 0, "beam: Iyy and J must be positive in 3D. Iyy=%g and J=%g are incorrect\n", o.Iyy, o.J) {
+				return nil
+			}
+		}
 
 		// vectors and matrices
 		o.T = la.MatAlloc(o.Nu, o.Nu)
@@ -127,77 +190,63 @@ func init() {
 		o.Rus = make([]float64, o.Nu)
 
 		// T
-		dx := o.X[0][1] - o.X[0][0]
-		dy := o.X[1][1] - o.X[1][0]
-		l := math.Sqrt(dx*dx + dy*dy)
-		c := dx / l
-		s := dy / l
-		o.T[0][0] = c
-		o.T[0][1] = s
-		o.T[1][0] = -s
-		o.T[1][1] = c
-		o.T[2][2] = 1
-		o.T[3][3] = c
-		o.T[3][4] = s
-		o.T[4][3] = -s
-		o.T[4][4] = c
-		o.T[5][5] = 1
-
-		// aux vars
-		ll := l * l
-		m := o.E * o.A / l
-		n := o.E * o.Izz / (ll * l)
-
-		// K
-		o.Kl[0][0] = m
-		o.Kl[0][3] = -m
-		o.Kl[1][1] = 12 * n
-		o.Kl[1][2] = 6 * l * n
-		o.Kl[1][4] = -12 * n
-		o.Kl[1][5] = 6 * l * n
-		o.Kl[2][1] = 6 * l * n
-		o.Kl[2][2] = 4 * ll * n
-		o.Kl[2][4] = -6 * l * n
-		o.Kl[2][5] = 2 * ll * n
-		o.Kl[3][0] = -m
-		o.Kl[3][3] = m
-		o.Kl[4][1] = -12 * n
-		o.Kl[4][2] = -6 * l * n
-		o.Kl[4][4] = 12 * n
-		o.Kl[4][5] = -6 * l * n
-		o.Kl[5][1] = 6 * l * n
-		o.Kl[5][2] = 2 * ll * n
-		o.Kl[5][4] = -6 * l * n
-		o.Kl[5][5] = 4 * ll * n
-		la.MatTrMul3(o.K, 1, o.T, o.Kl, o.T) // K := 1 * trans(T) * Kl * T
+		if !o.calc_T(edat.Extra) {
+			return nil
+		}
 
-		// M
-		m = o.Rho * o.A * l / 420.0
-		o.Ml[0][0] = 140.0 * m
-		o.Ml[0][3] = 70.0 * m
-		o.Ml[1][1] = 156.0 * m
-		o.Ml[1][2] = 22.0 * l * m
-		o.Ml[1][4] = 54.0 * m
-		o.Ml[1][5] = -13.0 * l * m
-		o.Ml[2][1] = 22.0 * l * m
-		o.Ml[2][2] = 4.0 * ll * m
-		o.Ml[2][4] = 13.0 * l * m
-		o.Ml[2][5] = -3.0 * ll * m
-		o.Ml[3][0] = 70.0 * m
-		o.Ml[3][3] = 140.0 * m
-		o.Ml[4][1] = 54.0 * m
-		o.Ml[4][2] = 13.0 * l * m
-		o.Ml[4][4] = 156.0 * m
-		o.Ml[4][5] = -22.0 * l * m
-		o.Ml[5][1] = -13.0 * l * m
-		o.Ml[5][2] = -3.0 * ll * m
-		o.Ml[5][4] = -22.0 * l * m
-		o.Ml[5][5] = 4.0 * ll * m
+		// co-rotational kinematics: distributed loads and rigid end offsets are not available
+		o.Corot = GetCorotFlag(edat.Extra)
+		if o.Corot {
+			if LogErrCond(la.VecNorm(o.Off[0]) > 0 || la.VecNorm(o.Off[1]) > 0, "beam: cid=%d: rigid end offsets cannot be used with co-rotational kinematics\n", cid) {
+				return nil
+			}
+			if ndim == 3 {
+				o.Cr3d = new(BeamCorot)
+				o.Cr3d.Init(o.L, o.X, o.T)
+			} else {
+				o.fn = make([]float64, 3)
+				o.rc = make([]float64, o.Nu)
+				o.zc = make([]float64, o.Nu)
+				o.Bc = la.MatAlloc(3, o.Nu)
+			}
+		}
+
+		// K and M
+		o.calc_Kl()
+		o.calc_Ml()
+		if !o.condense(edat.Extra) {
+			return nil
+		}
+		la.MatTrMul3(o.K, 1, o.T, o.Kl, o.T) // K := 1 * trans(T) * Kl * T
 		la.MatTrMul3(o.M, 1, o.T, o.Ml, o.T) // M := 1 * trans(T) * Ml * T
 
+		// distributed loads
+		o.QnL, o.QnR, o.QzL, o.QzR, o.Qt = &fun.Zero, &fun.Zero, &fun.Zero, &fun.Zero, &fun.Zero
+
+		// stations: equally spaced along the flexible part; in the initial configuration
+		o.Nsta = 5
+		if val, found := io.Keycode(edat.Extra, "nsta"); found {
+			o.Nsta = io.Atoi(val)
+		}
+		if LogErrCond(o.Nsta < 2, "beam: number of stations must be at least 2. nsta=%d is incorrect\n", o.Nsta) {
+			return nil
+		}
+		nres := len(beam_reskeys())
+		o.Xsta = la.MatAlloc(o.Nsta, ndim)
+		o.Fsta = la.MatAlloc(o.Nsta, nres)
+		for i := 0; i < o.Nsta; i++ {
+			ξ := float64(i) / float64(o.Nsta-1)
+			for j := 0; j < ndim; j++ {
+				o.Xsta[i][j] = (1.0-ξ)*(o.X[j][0]+o.Off[0][j]) + ξ*(o.X[j][1]+o.Off[1][j])
+			}
+		}
+
 		// scratchpad. computed @ each ip
 		o.grav = make([]float64, Global.Ndim)
 		o.fi = make([]float64, o.Nu)
+		o.ul = make([]float64, o.Nu)
+		o.fe = make([]float64, o.Nu)
+		o.fxc = make([]float64, o.Nu)
 
 		// return new element
 		return &o
@@ -209,11 +258,10 @@ func (o Beam) Id() int { return o.Cid }
 
 // SetEqs set equations [2][?]. Format of eqs == format of info.Dofs
 func (o *Beam) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
-	ndof := 3 * (Global.Ndim - 1)
 	o.Umap = make([]int, o.Nu)
 	for m := 0; m < 2; m++ {
-		for i := 0; i < ndof; i++ {
-			r := i + m*ndof
+		for i := 0; i < o.Ndof; i++ {
+			r := i + m*o.Ndof
 			o.Umap[r] = eqs[m][i]
 		}
 	}
@@ -230,6 +278,12 @@ func (o *Beam) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
 	}
 
 	// distributed loads
+	if LogErrCond(Global.Ndim == 2 && strings.HasPrefix(key, "qz"), "beam: distributed loads along local z are only available in 3D") {
+		return false
+	}
+	if LogErrCond(o.Corot, "beam: cid=%d: distributed loads cannot be used with co-rotational kinematics", o.Cid) {
+		return false
+	}
 	switch key {
 	case "qn":
 		o.Hasq, o.QnL, o.QnR = true, f, f
@@ -237,6 +291,12 @@ func (o *Beam) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
 		o.Hasq, o.QnL = true, f
 	case "qnR":
 		o.Hasq, o.QnR = true, f
+	case "qz":
+		o.Hasq, o.QzL, o.QzR = true, f, f
+	case "qzL":
+		o.Hasq, o.QzL = true, f
+	case "qzR":
+		o.Hasq, o.QzR = true, f
 	case "qt":
 		o.Hasq, o.Qt = true, f
 	default:
@@ -269,33 +329,26 @@ func (o Beam) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 		o.ue[i] = sol.Y[I]
 	}
 
-	// steady/dynamics
-	if Global.Sim.Data.Steady {
-		la.MatVecMul(o.fi, 1, o.K, o.ue)
+	// internal forces
+	if o.Corot {
+		o.corot(o.ue, false)
 	} else {
+		la.MatVecMul(o.fi, 1, o.K, o.ue)
+	}
+
+	// dynamics
+	if !Global.Sim.Data.Steady {
 		dc := Global.DynCoefs
 		for i := 0; i < o.Nu; i++ {
-			o.fi[i] = 0
 			for j := 0; j < o.Nu; j++ {
-				o.fi[i] += o.M[i][j]*(dc.α1*o.ue[j]-o.ζe[j]) + o.K[i][j]*o.ue[j]
+				o.fi[i] += o.M[i][j] * (dc.α1*o.ue[j] - o.ζe[j])
 			}
 		}
 	}
 
 	// distributed loads
 	if o.Hasq {
-		dx := o.X[0][1] - o.X[0][0]
-		dy := o.X[1][1] - o.X[1][0]
-		l := math.Sqrt(dx*dx + dy*dy)
-		qnL := o.QnL.F(sol.T, nil)
-		qnR := o.QnR.F(sol.T, nil)
-		qt := o.Qt.F(sol.T, nil)
-		o.fxl[0] = qt * l / 2.0
-		o.fxl[1] = l * (7.0*qnL + 3.0*qnR) / 20.0
-		o.fxl[2] = l * l * (3.0*qnL + 2.0*qnR) / 60.0
-		o.fxl[3] = qt * l / 2.0
-		o.fxl[4] = l * (3.0*qnL + 7.0*qnR) / 20.0
-		o.fxl[5] = -l * l * (2.0*qnL + 3.0*qnR) / 60.0
+		o.calc_fxl(sol.T)
 		la.MatTrVecMulAdd(o.fi, -1.0, o.T, o.fxl) // Rus -= fx; fx = trans(T) * fxl
 	}
 
@@ -308,6 +361,12 @@ func (o Beam) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 
 // adds element K to global Jacobian matrix Kb
 func (o Beam) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+	if o.Corot {
+		for i, I := range o.Umap {
+			o.ue[i] = sol.Y[I]
+		}
+		o.corot(o.ue, true)
+	}
 	if Global.Sim.Data.Steady {
 		for i, I := range o.Umap {
 			for j, J := range o.Umap {
@@ -326,21 +385,528 @@ func (o Beam) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 }
 
 // Update perform (tangent) update
+//  Note: it also computes the internal forces at stations
 func (o *Beam) Update(sol *Solution) (ok bool) {
+	o.calc_stations(sol)
 	return true
 }
 
 // Encode encodes internal variables
 func (o Beam) Encode(enc Encoder) (ok bool) {
-	return true
+	return !LogErr(enc.Encode(o.Fsta), "Encode")
 }
 
 // Decode decodes internal variables
 func (o Beam) Decode(dec Decoder) (ok bool) {
+	var fsta [][]float64
+	if LogErr(dec.Decode(&fsta), "Decode") {
+		return
+	}
+	if LogErrCond(len(fsta) != o.Nsta, "beam: cid=%d: number of stations in file (%d) is different from nsta=%d\n", o.Cid, len(fsta), o.Nsta) {
+		return
+	}
+	for i := 0; i < o.Nsta; i++ {
+		copy(o.Fsta[i], fsta[i])
+	}
 	return true
 }
 
-// OutIpsData returns data from all integration points for output
+// OutIpsData returns the internal forces at stations along the beam
+//  Note: the internal forces are the components, in the local system, of the force and moment
+//        acting on the positive face of a cut (with normal e0) at each station. Thus N > 0 is
+//        tension and M > 0 (2D) is sagging (compression on the e1 side). The keys are:
+//          2D: "N", "V", "M"
+//          3D: "N", "Vy", "Vz", "T", "My", "Mz"
+//        Inertia forces are disregarded
 func (o Beam) OutIpsData() (data []*OutIpData) {
+	keys := beam_reskeys()
+	for i := 0; i < o.Nsta; i++ {
+		v := make(map[string]*float64)
+		for j, key := range keys {
+			v[key] = &o.Fsta[i][j]
+		}
+		data = append(data, &OutIpData{o.Id(), o.Xsta[i], v})
+	}
 	return
 }
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// calc_T computes the length of beam and the global-to-local transformation matrix
+//  Note: the local system {e0, e1, e2} has e0 along the axis (from node 0 to node 1); e1 and e2
+//        are the principal axes of the cross-section: bending about e2 (z) is governed by Izz and
+//        bending about e1 (y) by Iyy
+func (o *Beam) calc_T(extra string) (ok bool) {
+
+	// rigid end offsets (global coordinates) from the nodes to the ends of the flexible part
+	ndim := Global.Ndim
+	o.Off = la.MatAlloc(2, ndim)
+	for m, side := range []string{"L", "R"} {
+		for i, key := range []string{"ox", "oy", "oz"}[:ndim] {
+			if val, found := io.Keycode(extra, key+side); found {
+				o.Off[m][i] = io.Atof(val)
+			}
+		}
+	}
+
+	// axis
+	e0 := make([]float64, 3)
+	for i := 0; i < ndim; i++ {
+		e0[i] = (o.X[i][1] + o.Off[1][i]) - (o.X[i][0] + o.Off[0][i])
+	}
+	o.L = la.VecNorm(e0)
+	if LogErrCond(o.L < 1e-10, "beam: cid=%d: length of beam is too small: %g\n", o.Cid, o.L) {
+		return
+	}
+	la.VecScale(e0, 0, 1.0/o.L, e0)
+
+	// 2D
+	if ndim == 2 {
+		c, s := e0[0], e0[1]
+		for m := 0; m < 2; m++ {
+			o.T[0+m*3][0+m*3] = c
+			o.T[0+m*3][1+m*3] = s
+			o.T[1+m*3][0+m*3] = -s
+			o.T[1+m*3][1+m*3] = c
+			o.T[2+m*3][2+m*3] = 1
+		}
+		o.add_offsets()
+		return true
+	}
+
+	// orientation vector: e2 = normalised(v - (v・e0) e0) and e1 = e2 × e0. The default v is the
+	// global z direction (or y for beams parallel to z); thus beams in the x-y plane behave as in 2D
+	v := []float64{0, 0, 1}
+	if math.Abs(e0[2]) > 1.0-1e-10 {
+		v = []float64{0, 1, 0}
+	}
+	for i, key := range []string{"vx", "vy", "vz"} {
+		if val, found := io.Keycode(extra, key); found {
+			v[i] = io.Atof(val)
+		}
+	}
+
+	// local system
+	e2 := make([]float64, 3)
+	ve0 := la.VecDot(v, e0)
+	for i := 0; i < 3; i++ {
+		e2[i] = v[i] - ve0*e0[i]
+	}
+	ne2 := la.VecNorm(e2)
+	if LogErrCond(ne2 < 1e-10, "beam: cid=%d: orientation vector v=%v must not be parallel to the axis of beam\n", o.Cid, v) {
+		return
+	}
+	la.VecScale(e2, 0, 1.0/ne2, e2)
+	e1 := []float64{
+		e2[1]*e0[2] - e2[2]*e0[1],
+		e2[2]*e0[0] - e2[0]*e0[2],
+		e2[0]*e0[1] - e2[1]*e0[0],
+	}
+
+	// T
+	for b := 0; b < 4; b++ {
+		for j := 0; j < 3; j++ {
+			o.T[0+b*3][j+b*3] = e0[j]
+			o.T[1+b*3][j+b*3] = e1[j]
+			o.T[2+b*3][j+b*3] = e2[j]
+		}
+	}
+	o.add_offsets()
+	return true
+}
+
+// add_offsets modifies T to account for rigid end offsets: T := T * H where H maps the nodal
+// displacements and rotations to the displacements and rotations at the ends of the flexible part
+//  Note: the length, local system, distributed loads and stations refer to the flexible part;
+//        the rigid offsets are massless
+func (o *Beam) add_offsets() {
+
+	// skip if there are no offsets
+	if la.VecNorm(o.Off[0]) == 0 && la.VecNorm(o.Off[1]) == 0 {
+		return
+	}
+
+	// H = I + S where S gives the displacements due to rotations: θ × offset
+	n := o.Ndof
+	H := la.MatAlloc(o.Nu, o.Nu)
+	for i := 0; i < o.Nu; i++ {
+		H[i][i] = 1
+	}
+	for m := 0; m < 2; m++ {
+		d := o.Off[m]
+		k := m * n
+		if n == 3 {
+			H[k+0][k+2] = -d[1]
+			H[k+1][k+2] = d[0]
+			continue
+		}
+		H[k+0][k+4], H[k+0][k+5] = d[2], -d[1]
+		H[k+1][k+3], H[k+1][k+5] = -d[2], d[0]
+		H[k+2][k+3], H[k+2][k+4] = d[1], -d[0]
+	}
+	T := la.MatAlloc(o.Nu, o.Nu)
+	la.MatCopy(T, 1, o.T)
+	la.MatMul(o.T, 1, T, H)
+}
+
+// condense performs the static condensation of released rotations (hinges) by modifying Kl and Ml
+//  Note: "!hingeL:?" (node 0) and "!hingeR:?" (node 1) where ? = "z" (about local z), "y" (about
+//        local y; 3D) or "yz" (3D). The rotation of a node must be restrained by another element or
+//        boundary condition if all beams connected to it are released at this node
+func (o *Beam) condense(extra string) (ok bool) {
+
+	// released rotations
+	n := o.Ndof
+	for m, side := range []string{"L", "R"} {
+		val, found := io.Keycode(extra, "hinge"+side)
+		if !found {
+			continue
+		}
+		switch {
+		case n == 3 && (val == "z" || val == ""):
+			o.Hinge = append(o.Hinge, m*n+2)
+		case n == 3:
+			LogErrCond(true, "beam: cid=%d: hinge%s must be \"z\" in 2D. %q is incorrect\n", o.Cid, side, val)
+			return
+		case val == "y":
+			o.Hinge = append(o.Hinge, m*n+4)
+		case val == "z":
+			o.Hinge = append(o.Hinge, m*n+5)
+		case val == "yz":
+			o.Hinge = append(o.Hinge, m*n+4, m*n+5)
+		default:
+			LogErrCond(true, "beam: cid=%d: hinge%s must be \"y\", \"z\" or \"yz\" in 3D. %q is incorrect\n", o.Cid, side, val)
+			return
+		}
+	}
+	if len(o.Hinge) == 0 {
+		return true
+	}
+
+	// inverse of stiffness matrix of released rotations
+	nc := len(o.Hinge)
+	Kcc := la.MatAlloc(nc, nc)
+	Kcci := la.MatAlloc(nc, nc)
+	for i, I := range o.Hinge {
+		for j, J := range o.Hinge {
+			Kcc[i][j] = o.Kl[I][J]
+		}
+	}
+	if LogErr(la.MatInvG(Kcci, Kcc, 1e-10), io.Sf("beam: cid=%d: cannot condense released rotations", o.Cid)) {
+		return
+	}
+
+	// condensation matrix: ul(released) = -inv(Kcc) * Kcr * ul(retained)
+	released := make([]bool, o.Nu)
+	for _, I := range o.Hinge {
+		released[I] = true
+	}
+	o.Tc = la.MatAlloc(o.Nu, o.Nu)
+	for j := 0; j < o.Nu; j++ {
+		if released[j] {
+			continue
+		}
+		o.Tc[j][j] = 1
+		for i, I := range o.Hinge {
+			for k, K := range o.Hinge {
+				o.Tc[I][j] -= Kcci[i][k] * o.Kl[K][j]
+			}
+		}
+	}
+
+	// condensed matrices
+	Kl := la.MatAlloc(o.Nu, o.Nu)
+	Ml := la.MatAlloc(o.Nu, o.Nu)
+	la.MatCopy(Kl, 1, o.Kl)
+	la.MatCopy(Ml, 1, o.Ml)
+	la.MatTrMul3(o.Kl, 1, o.Tc, Kl, o.Tc) // Kl := trans(Tc) * Kl * Tc
+	la.MatTrMul3(o.Ml, 1, o.Tc, Ml, o.Tc) // Ml := trans(Tc) * Ml * Tc
+	return true
+}
+
+// calc_Kl computes the local stiffness matrix
+func (o *Beam) calc_Kl() {
+
+	// shear deformation parameters (Timoshenko); shear areas = kappa * A
+	l := o.L
+	ll := l * l
+	var Φy, Φz float64
+	if o.Kappa > 0 {
+		Φy = 12.0 * o.E * o.Izz / (o.G * o.Kappa * o.A * ll)
+		Φz = 12.0 * o.E * o.Iyy / (o.G * o.Kappa * o.A * ll)
+	}
+
+	// axial
+	n := o.Ndof
+	m := o.E * o.A / l
+	o.Kl[0][0], o.Kl[0][n] = m, -m
+	o.Kl[n][0], o.Kl[n][n] = -m, m
+
+	// bending in the local x-y plane: v and rz
+	iv, ir := 1, n-1
+	o.beam_bending(iv, ir, o.E*o.Izz/((1.0+Φy)*ll*l), Φy, 1)
+
+	// 2D
+	if n == 3 {
+		return
+	}
+
+	// torsion
+	m = o.G * o.J / l
+	o.Kl[3][3], o.Kl[3][9] = m, -m
+	o.Kl[9][3], o.Kl[9][9] = -m, m
+
+	// bending in the local x-z plane: w and ry
+	o.beam_bending(2, 4, o.E*o.Iyy/((1.0+Φz)*ll*l), Φz, -1)
+}
+
+// beam_bending adds the bending stiffness in one plane to Kl
+//  iu  -- local index of transverse displacement @ node 0
+//  ir  -- local index of rotation @ node 0
+//  sgn -- sign relating rotation and slope: 1 => r = du/dx; -1 => r = -du/dx
+func (o *Beam) beam_bending(iu, ir int, c, Φ, sgn float64) {
+	l := o.L
+	ll := l * l
+	n := o.Ndof
+	idx := []int{iu, ir, iu + n, ir + n}
+	k := [][]float64{
+		{12, sgn * 6 * l, -12, sgn * 6 * l},
+		{sgn * 6 * l, (4 + Φ) * ll, -sgn * 6 * l, (2 - Φ) * ll},
+		{-12, -sgn * 6 * l, 12, -sgn * 6 * l},
+		{sgn * 6 * l, (2 - Φ) * ll, -sgn * 6 * l, (4 + Φ) * ll},
+	}
+	for i, I := range idx {
+		for j, J := range idx {
+			o.Kl[I][J] = c * k[i][j]
+		}
+	}
+}
+
+// calc_Ml computes the local (consistent) mass matrix
+//  Note: shear deformation and rotary inertia are disregarded
+func (o *Beam) calc_Ml() {
+
+	// axial
+	l := o.L
+	ll := l * l
+	n := o.Ndof
+	m := o.Rho * o.A * l / 420.0
+	o.Ml[0][0], o.Ml[0][n] = 140.0*m, 70.0*m
+	o.Ml[n][0], o.Ml[n][n] = 70.0*m, 140.0*m
+
+	// transverse
+	planes := [][]int{{1, n - 1}} // {iu, ir}
+	sgns := []float64{1}
+	if n == 6 {
+		planes = append(planes, []int{2, 4})
+		sgns = append(sgns, -1)
+	}
+	for p, pl := range planes {
+		s := sgns[p]
+		idx := []int{pl[0], pl[1], pl[0] + n, pl[1] + n}
+		mm := [][]float64{
+			{156, s * 22 * l, 54, -s * 13 * l},
+			{s * 22 * l, 4 * ll, s * 13 * l, -3 * ll},
+			{54, s * 13 * l, 156, -s * 22 * l},
+			{-s * 13 * l, -3 * ll, -s * 22 * l, 4 * ll},
+		}
+		for i, I := range idx {
+			for j, J := range idx {
+				o.Ml[I][J] = m * mm[i][j]
+			}
+		}
+	}
+
+	// torsion: polar moment of inertia ≈ Iyy + Izz
+	if n == 6 {
+		m = o.Rho * (o.Iyy + o.Izz) * l / 6.0
+		o.Ml[3][3], o.Ml[3][9] = 2.0*m, m
+		o.Ml[9][3], o.Ml[9][9] = m, 2.0*m
+	}
+}
+
+// calc_fxl computes the local vector of external forces due to distributed loads
+func (o *Beam) calc_fxl(t float64) {
+
+	// axial
+	l := o.L
+	n := o.Ndof
+	qt := o.Qt.F(t, nil)
+	o.fxl[0] = qt * l / 2.0
+	o.fxl[n] = qt * l / 2.0
+
+	// local y
+	qnL := o.QnL.F(t, nil)
+	qnR := o.QnR.F(t, nil)
+	o.fxl[1] = l * (7.0*qnL + 3.0*qnR) / 20.0
+	o.fxl[n-1] = l * l * (3.0*qnL + 2.0*qnR) / 60.0
+	o.fxl[1+n] = l * (3.0*qnL + 7.0*qnR) / 20.0
+	o.fxl[2*n-1] = -l * l * (2.0*qnL + 3.0*qnR) / 60.0
+
+	// local z
+	if n == 6 {
+		qzL := o.QzL.F(t, nil)
+		qzR := o.QzR.F(t, nil)
+		o.fxl[2] = l * (7.0*qzL + 3.0*qzR) / 20.0
+		o.fxl[4] = -l * l * (3.0*qzL + 2.0*qzR) / 60.0
+		o.fxl[8] = l * (3.0*qzL + 7.0*qzR) / 20.0
+		o.fxl[10] = l * l * (2.0*qzL + 3.0*qzR) / 60.0
+	}
+
+	// condensation of released rotations
+	if len(o.Hinge) > 0 {
+		la.MatTrVecMul(o.fxc, 1, o.Tc, o.fxl) // fxc := trans(Tc) * fxl
+		copy(o.fxl, o.fxc)
+	}
+}
+
+// calc_stations computes the internal forces at stations
+func (o *Beam) calc_stations(sol *Solution) {
+
+	// local end forces acting on beam: fe = Kl * ul - fxl
+	for i, I := range o.Umap {
+		o.ue[i] = sol.Y[I]
+	}
+	switch {
+	case o.Cr3d != nil:
+		o.Cr3d.Deformations(o.ul, o.ue)
+	case o.Corot:
+		o.corot_ul(o.ue)
+	default:
+		la.MatVecMul(o.ul, 1, o.T, o.ue)
+	}
+	if o.Hasq {
+		o.calc_fxl(sol.T)
+	}
+	for i := 0; i < o.Nu; i++ {
+		o.fe[i] = -o.fxl[i]
+		for j := 0; j < o.Nu; j++ {
+			o.fe[i] += o.Kl[i][j] * o.ul[j]
+		}
+	}
+
+	// distributed loads
+	qt := o.Qt.F(sol.T, nil)
+	qnL := o.QnL.F(sol.T, nil)
+	qnR := o.QnR.F(sol.T, nil)
+	qzL := o.QzL.F(sol.T, nil)
+	qzR := o.QzR.F(sol.T, nil)
+
+	// equilibrium of the portion of beam between node 0 and each station
+	l := o.L
+	n := o.Ndof
+	for i := 0; i < o.Nsta; i++ {
+		x := l * float64(i) / float64(o.Nsta-1)
+		Fy := o.fe[1] + qnL*x + (qnR-qnL)*x*x/(2.0*l) // resultant of transverse forces along y
+		my := qnL*x*x/2.0 + (qnR-qnL)*x*x*x/(6.0*l)   // moment of loads along y about station
+		o.Fsta[i][0] = -o.fe[0] - qt*x                // N
+		if n == 3 {
+			o.Fsta[i][1] = -Fy                       // V
+			o.Fsta[i][2] = -o.fe[2] + o.fe[1]*x + my // M
+			continue
+		}
+		Fz := o.fe[2] + qzL*x + (qzR-qzL)*x*x/(2.0*l) // resultant of transverse forces along z
+		mz := qzL*x*x/2.0 + (qzR-qzL)*x*x*x/(6.0*l)   // moment of loads along z about station
+		o.Fsta[i][1] = -Fy                            // Vy
+		o.Fsta[i][2] = -Fz                            // Vz
+		o.Fsta[i][3] = -o.fe[3]                       // T
+		o.Fsta[i][4] = -o.fe[4] - o.fe[2]*x - mz      // My
+		o.Fsta[i][5] = -o.fe[5] + o.fe[1]*x + my      // Mz
+	}
+}
+
+// beam_reskeys returns the keys of internal forces at stations
+func beam_reskeys() []string {
+	if Global.Ndim == 3 {
+		return []string{"N", "Vy", "Vz", "T", "My", "Mz"}
+	}
+	return []string{"N", "V", "M"}
+}
+
+// corot_ul computes the local deformations (co-rotational; 2D) for given global displacements ue
+// and returns the current length and the current direction of the chord
+//  Note: ul = {0, 0, θ0 - α, L - L0, 0, θ1 - α} where α is the rotation of the chord
+func (o *Beam) corot_ul(ue []float64) (l, c, s float64) {
+
+	// current chord
+	c0, s0 := o.T[0][0], o.T[0][1]
+	dx := o.L*c0 + ue[3] - ue[0]
+	dy := o.L*s0 + ue[4] - ue[1]
+	l = math.Sqrt(dx*dx + dy*dy)
+	c, s = dx/l, dy/l
+
+	// rigid rotation and local deformations
+	α := math.Atan2(c0*s-s0*c, c0*c+s0*s)
+	la.VecFill(o.ul, 0)
+	o.ul[2] = beam_angle(ue[2] - α)
+	o.ul[3] = l - o.L
+	o.ul[5] = beam_angle(ue[5] - α)
+	return
+}
+
+// beam_corot2_nat holds the indices of the natural deformations {L - L0, θ0 - α, θ1 - α} in ul (2D)
+var beam_corot2_nat = []int{3, 2, 5}
+
+// corot computes the internal forces fi and, if tangent is true, the tangent stiffness K
+// (co-rotational) for given global displacements ue
+//  Note: the rigid body motion of the chord is removed and the local deformations are related
+//        to the local forces by the linear stiffness Kl; the geometric stiffness is added to K and
+//        the mass matrix refers to the initial configuration. See Crisfield (1991) Non-linear Finite Element Analysis of Solids and Structures, Vol 1,
+//        Chapter 7. In 3D, the nodal rotations are the components of the total rotation vector;
+//        see BeamCorot. In 2D, K = Bᵀ Kn B + N z⊗z / L + (M0 + M1) (r⊗z + z⊗r) / L²
+//        where Kn is the natural (3x3) stiffness, B = [r, e2 - z/L, e5 - z/L]ᵀ,
+//        r = {-c, -s, 0, c, s, 0} and z = {s, -c, 0, -s, c, 0}
+func (o *Beam) corot(ue []float64, tangent bool) {
+
+	// 3D
+	if o.Cr3d != nil {
+		o.Cr3d.Calc(o.fi, o.K, o.ul, o.Kl, ue, tangent)
+		return
+	}
+
+	// local deformations and forces
+	l, c, s := o.corot_ul(ue)
+	nat := beam_corot2_nat
+	for a, I := range nat {
+		o.fn[a] = 0
+		for j := 0; j < o.Nu; j++ {
+			o.fn[a] += o.Kl[I][j] * o.ul[j]
+		}
+	}
+	N, M0, M1 := o.fn[0], o.fn[1], o.fn[2]
+
+	// B matrix
+	r, z := o.rc, o.zc
+	r[0], r[1], r[2], r[3], r[4], r[5] = -c, -s, 0, c, s, 0
+	z[0], z[1], z[2], z[3], z[4], z[5] = s, -c, 0, -s, c, 0
+	for j := 0; j < o.Nu; j++ {
+		o.Bc[0][j] = r[j]
+		o.Bc[1][j] = -z[j] / l
+		o.Bc[2][j] = -z[j] / l
+	}
+	o.Bc[1][2] += 1
+	o.Bc[2][5] += 1
+
+	// internal forces
+	la.MatTrVecMul(o.fi, 1, o.Bc, o.fn) // fi := trans(B) * fn
+	if !tangent {
+		return
+	}
+
+	// tangent matrix
+	for i := 0; i < o.Nu; i++ {
+		for j := 0; j < o.Nu; j++ {
+			o.K[i][j] = N*z[i]*z[j]/l + (M0+M1)*(r[i]*z[j]+z[i]*r[j])/(l*l)
+			for a, I := range nat {
+				for b, J := range nat {
+					o.K[i][j] += o.Bc[a][i] * o.Kl[I][J] * o.Bc[b][j]
+				}
+			}
+		}
+	}
+}
+
+// beam_angle returns the angle a in the range (-π, π]
+func beam_angle(a float64) float64 {
+	return a - 2.0*math.Pi*math.Ceil((a-math.Pi)/(2.0*math.Pi))
+}
@@ -0,0 +1,529 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mtransp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemC implements an element for advection-dispersion of solutes with sorption and decay
+//  Balance of solute mass (see mtransp.Model):
+//   (θw + ρb・ds/dc)・∂c/∂t + q・∇c - div(D・∇c) + λ・(θw・c + ρb・s) = 0
+//  The Darcy flux q and the volumetric water content θw are either:
+//   (1) constant and given by keycodes in the Extra field; e.g. "!qx:0 !qy:1e-5 !thw:0.3"; or
+//   (2) computed at the integration points of a coupled p- or u-p-element (see ElemPC and ElemUPC)
+//  The streamline-upwind/Petrov-Galerkin (SUPG) stabilisation is activated with "!supg:1"
+//  Natural boundary conditions:
+//   "qc" -- prescribed dispersive flux of solute (positive means outflow): -(D・∇c)・n = qb
+//  Output @ ips: "qlx", "qly", "qlz" (Darcy flux), "thw" (water content), "jax", "jay", "jaz"
+//  (advective flux q・c) and "jdx", "jdy", "jdz" (dispersive flux -D・∇c)
+type ElemC struct {
+
+	// basic data
+	Cid int         // cell/element id
+	X   [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Shp *shp.Shape  // shape structure
+	Nc  int         // total number of unknowns == number of vertices
+
+	// integration points
+	IpsElem []*shp.Ipoint // integration points of element
+	IpsFace []*shp.Ipoint // integration points corresponding to faces
+
+	// material model and flags
+	Mdl  *mtransp.Model // model
+	Supg bool           // use SUPG stabilisation
+
+	// problem variables
+	Cmap []int // assembly map (location array/element equations)
+
+	// natural boundary conditions
+	NatBcs []*NaturalBc // natural boundary conditions
+
+	// flow variables @ ips; set by coupled elements
+	q  [][]float64 // [nip][ndim] Darcy flux
+	θw []float64   // [nip] volumetric water content
+
+	// fluxes of solute @ ips; for output
+	ja [][]float64 // [nip][ndim] advective flux: q・c
+	jd [][]float64 // [nip][ndim] dispersive flux: -D・∇c
+
+	// local starred variables
+	ψc []float64 // [nip] ψc* = β1.c + β2.dcdt
+
+	// scratchpad. computed @ each ip
+	c   float64     // c: concentration
+	gc  []float64   // [ndim] ∇c: gradient of concentration
+	ct  float64     // rate of concentration: ct = β1・c - ψc*
+	s   float64     // sorbed concentration
+	ds  float64     // ∂s/∂c
+	d2s float64     // ∂²s/∂c²
+	a   float64     // non-dispersive terms of the strong form
+	τ   float64     // SUPG stabilisation parameter
+	W   []float64   // [nverts] weighting functions: W = S + τ・q・G
+	D   [][]float64 // [ndim][ndim] dispersion tensor
+	Kcc [][]float64 // [nc][nc] Kcc := dRc/dc consistent tangent matrix
+
+	// scratchpad for coupled elements. computed @ each ip
+	dDdθw [][]float64   // [ndim][ndim] ∂D/∂θw
+	dDdq  [][][]float64 // [ndim][ndim][ndim] ∂D/∂q
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["c"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// number of nodes in element
+		nverts := shp.GetNverts(cellType)
+
+		// solution variables
+		ykeys := []string{"c"}
+		info.Dofs = make([][]string, nverts)
+		for m := 0; m < nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"c": "qc"}
+
+		// t1 and t2 variables
+		info.T1vars = ykeys
+		return &info
+	}
+
+	// element allocator
+	eallocators["c"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemC
+		o.Cid = cid
+		o.X = x
+		o.Shp = shp.Get(cellType)
+		o.Nc = o.Shp.Nverts
+
+		// integration points
+		o.IpsElem, o.IpsFace = GetIntegrationPoints(edat.Nip, edat.Nipf, cellType)
+		if o.IpsElem == nil || o.IpsFace == nil {
+			return nil
+		}
+		nip := len(o.IpsElem)
+
+		// model
+		o.Mdl = GetAndInitTranspModel(edat.Mat)
+		if o.Mdl == nil {
+			return nil
+		}
+
+		// flags and constant flow variables
+		ndim := Global.Ndim
+		var qcte []float64
+		var θwcte float64
+		o.Supg, _, _, qcte, θwcte = GetTranspFlags(edat.Extra, ndim)
+
+		// flow variables
+		o.q = la.MatAlloc(nip, ndim)
+		o.θw = make([]float64, nip)
+		for idx := 0; idx < nip; idx++ {
+			copy(o.q[idx], qcte)
+			o.θw[idx] = θwcte
+		}
+
+		// fluxes of solute
+		o.ja = la.MatAlloc(nip, ndim)
+		o.jd = la.MatAlloc(nip, ndim)
+
+		// local starred variables
+		o.ψc = make([]float64, nip)
+
+		// scratchpad. computed @ each ip
+		o.gc = make([]float64, ndim)
+		o.W = make([]float64, o.Nc)
+		o.D = la.MatAlloc(ndim, ndim)
+		o.Kcc = la.MatAlloc(o.Nc, o.Nc)
+		o.dDdθw = la.MatAlloc(ndim, ndim)
+		o.dDdq = make([][][]float64, ndim)
+		for i := 0; i < ndim; i++ {
+			o.dDdq[i] = la.MatAlloc(ndim, ndim)
+		}
+
+		// set natural boundary conditions
+		for _, fc := range faceConds {
+			o.NatBcs = append(o.NatBcs, &NaturalBc{fc.Cond, fc.FaceId, fc.Func, fc.Extra})
+		}
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemC) Id() int { return o.Cid }
+
+// SetEqs sets equations
+func (o *ElemC) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+	o.Cmap = make([]int, o.Nc)
+	for m := 0; m < o.Shp.Nverts; m++ {
+		o.Cmap[m] = eqs[m][0]
+	}
+	return true
+}
+
+// SetEleConds sets element conditions
+func (o *ElemC) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	return true
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ElemC) InterpStarVars(sol *Solution) (ok bool) {
+
+	// skip if steady
+	if Global.Sim.Data.Steady {
+		return true
+	}
+
+	// for each integration point
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.Shp.CalcAtIp(o.X, ip, false), "InterpStarVars") {
+			return
+		}
+
+		// interpolate starred variables
+		o.ψc[idx] = 0
+		for m := 0; m < o.Shp.Nverts; m++ {
+			o.ψc[idx] += o.Shp.S[m] * sol.Psi[o.Cmap[m]]
+		}
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemC) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+
+	// for each integration point
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	var coef float64
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		o.ipterms(idx)
+		coef = o.Shp.J * ip.W
+		G := o.Shp.G
+
+		// add negative of residual term to fb
+		for m := 0; m < nverts; m++ {
+			r := o.Cmap[m]
+			fb[r] -= coef * o.W[m] * o.a
+			for i := 0; i < ndim; i++ {
+				for j := 0; j < ndim; j++ {
+					fb[r] -= coef * G[m][i] * o.D[i][j] * o.gc[j]
+				}
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	return o.add_natbcs_to_rhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemC) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// clear matrices
+	la.MatFill(o.Kcc, 0)
+
+	// for each integration point
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	β1 := Global.DynCoefs.β1
+	if Global.Sim.Data.Steady {
+		β1 = 0
+	}
+	ρb, λ := o.Mdl.RhoB, o.Mdl.Lam
+	var coef, θw, dadc, qG float64
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		o.ipterms(idx)
+		coef = o.Shp.J * ip.W
+		S := o.Shp.S
+		G := o.Shp.G
+		θw = o.θw[idx]
+
+		// Kcc := dRc/dc
+		dadc = ρb*o.d2s*o.ct + (θw+ρb*o.ds)*β1 + λ*(θw+ρb*o.ds)
+		for n := 0; n < nverts; n++ {
+			qG = 0
+			for i := 0; i < ndim; i++ {
+				qG += o.q[idx][i] * G[n][i]
+			}
+			for m := 0; m < nverts; m++ {
+				o.Kcc[m][n] += coef * o.W[m] * (dadc*S[n] + qG)
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						o.Kcc[m][n] += coef * G[m][i] * o.D[i][j] * G[n][j]
+					}
+				}
+			}
+		}
+	}
+
+	// add to sparse matrix Kb
+	for i, I := range o.Cmap {
+		for j, J := range o.Cmap {
+			Kb.Put(I, J, o.Kcc[i][j])
+		}
+	}
+	return true
+}
+
+// Update performs (tangent) update
+//  Note: only the fluxes of solute are updated here; for output
+func (o *ElemC) Update(sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	for idx, _ := range o.IpsElem {
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		o.Mdl.Disp(o.D, nil, nil, o.θw[idx], o.q[idx])
+		for i := 0; i < ndim; i++ {
+			o.ja[idx][i] = o.q[idx][i] * o.c
+			o.jd[idx][i] = 0
+			for j := 0; j < ndim; j++ {
+				o.jd[idx][i] -= o.D[i][j] * o.gc[j]
+			}
+		}
+	}
+	return true
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemC) Encode(enc Encoder) (ok bool) {
+	for _, v := range []interface{}{o.q, o.θw, o.ja, o.jd} {
+		if LogErr(enc.Encode(v), "Encode") {
+			return
+		}
+	}
+	return true
+}
+
+// Decode decodes internal variables
+func (o ElemC) Decode(dec Decoder) (ok bool) {
+	for _, v := range []interface{}{&o.q, &o.θw, &o.ja, &o.jd} {
+		if LogErr(dec.Decode(v), "Decode") {
+			return
+		}
+	}
+	return true
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemC) OutIpsData() (data []*OutIpData) {
+	ndim := Global.Ndim
+	for idx, ip := range o.IpsElem {
+		x := o.Shp.IpRealCoords(o.X, ip)
+		v := map[string]*float64{"thw": &o.θw[idx]}
+		for i, key := range []string{"x", "y", "z"}[:ndim] {
+			v["ql"+key] = &o.q[idx][i]
+			v["ja"+key] = &o.ja[idx][i]
+			v["jd"+key] = &o.jd[idx][i]
+		}
+		data = append(data, &OutIpData{o.Id(), x, v})
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ipvars computes current values @ integration points. idx == index of integration point
+func (o *ElemC) ipvars(idx int, sol *Solution) (ok bool) {
+
+	// interpolation functions and gradients
+	if LogErr(o.Shp.CalcAtIp(o.X, o.IpsElem[idx], true), "ipvars") {
+		return
+	}
+
+	// clear c and its gradient @ ip
+	ndim := Global.Ndim
+	o.c = 0
+	for i := 0; i < ndim; i++ {
+		o.gc[i] = 0
+	}
+
+	// compute c and its gradient @ ip by means of interpolating from nodes
+	for m := 0; m < o.Shp.Nverts; m++ {
+		r := o.Cmap[m]
+		o.c += o.Shp.S[m] * sol.Y[r]
+		for i := 0; i < ndim; i++ {
+			o.gc[i] += o.Shp.G[m][i] * sol.Y[r]
+		}
+	}
+
+	// rate of concentration
+	o.ct = 0
+	if !Global.Sim.Data.Steady {
+		o.ct = Global.DynCoefs.β1*o.c - o.ψc[idx]
+	}
+	return true
+}
+
+// ipterms computes the sorbed concentration, the non-dispersive terms of the strong form,
+// the dispersion tensor and the weighting functions @ ip. ipvars must be called first
+func (o *ElemC) ipterms(idx int) {
+
+	// sorption and dispersion
+	θw, q := o.θw[idx], o.q[idx]
+	ρb, λ := o.Mdl.RhoB, o.Mdl.Lam
+	o.s, o.ds, o.d2s = o.Mdl.Sorb(o.c)
+	o.Mdl.Disp(o.D, nil, nil, θw, q)
+
+	// non-dispersive terms
+	o.a = (θw+ρb*o.ds)*o.ct + λ*(θw*o.c+ρb*o.s)
+	for i := 0; i < Global.Ndim; i++ {
+		o.a += q[i] * o.gc[i]
+	}
+
+	// weighting functions
+	o.τ = 0
+	if o.Supg {
+		o.τ = o.supg_τ(q)
+	}
+	for m := 0; m < o.Shp.Nverts; m++ {
+		o.W[m] = o.Shp.S[m]
+		for i := 0; i < Global.Ndim; i++ {
+			o.W[m] += o.τ * q[i] * o.Shp.G[m][i]
+		}
+	}
+}
+
+// disp_derivs computes the derivatives of the dispersion tensor w.r.t θw and q @ ip
+func (o *ElemC) disp_derivs(idx int) {
+	o.Mdl.Disp(o.D, o.dDdθw, o.dDdq, o.θw[idx], o.q[idx])
+}
+
+// add_flow_derivs adds coef・∂rc/∂x to column n of Kcx, where x is an unknown of a coupled flow
+// element and dθw := ∂θw/∂x and dq := ∂q/∂x @ ip. ipvars, ipterms and disp_derivs must be called first
+//  Note: the derivative of the SUPG parameter is neglected
+func (o *ElemC) add_flow_derivs(Kcx [][]float64, n int, coef, dθw float64, dq []float64) {
+	ndim := Global.Ndim
+	G := o.Shp.G
+	var tmp, dq_gc, dq_Gm, dDij float64
+	for i := 0; i < ndim; i++ {
+		dq_gc += dq[i] * o.gc[i]
+	}
+	for m := 0; m < o.Shp.Nverts; m++ {
+		dq_Gm = 0
+		for i := 0; i < ndim; i++ {
+			dq_Gm += dq[i] * G[m][i]
+		}
+		tmp = o.W[m]*(dθw*(o.ct+o.Mdl.Lam*o.c)+dq_gc) + o.τ*dq_Gm*o.a
+		for i := 0; i < ndim; i++ {
+			for j := 0; j < ndim; j++ {
+				dDij = o.dDdθw[i][j] * dθw
+				for k := 0; k < ndim; k++ {
+					dDij += o.dDdq[i][j][k] * dq[k]
+				}
+				tmp += G[m][i] * dDij * o.gc[j]
+			}
+		}
+		Kcx[m][n] += coef * tmp
+	}
+}
+
+// supg_τ computes the SUPG stabilisation parameter with the optimal upwind function
+//  τ = h・(coth(Pe) - 1/Pe) / (2・|q|)  with  Pe = |q|・h / (2・q・D・q / |q|²)
+// where h is the element length along the streamline. o.D and the gradients must be computed first
+func (o *ElemC) supg_τ(q []float64) float64 {
+	ndim := Global.Ndim
+	qn := la.VecNorm(q)
+	if qn < 1e-15 {
+		return 0
+	}
+	var sum, qDq float64
+	for m := 0; m < o.Shp.Nverts; m++ {
+		qG := 0.0
+		for i := 0; i < ndim; i++ {
+			qG += q[i] * o.Shp.G[m][i]
+		}
+		sum += math.Abs(qG)
+	}
+	if sum < 1e-15 {
+		return 0
+	}
+	h := 2.0 * qn / sum
+	for i := 0; i < ndim; i++ {
+		for j := 0; j < ndim; j++ {
+			qDq += q[i] * o.D[i][j] * q[j]
+		}
+	}
+	ξ := 1.0
+	if qDq > 0 {
+		Pe := qn * qn * qn * h / (2.0 * qDq)
+		if Pe < 1e-3 {
+			ξ = Pe / 3.0 // coth(Pe) - 1/Pe ≈ Pe/3 for small Pe
+		} else {
+			ξ = 1.0/math.Tanh(Pe) - 1.0/Pe
+		}
+	}
+	return h * ξ / (2.0 * qn)
+}
+
+// add_natbcs_to_rhs adds natural boundary conditions to rhs
+func (o ElemC) add_natbcs_to_rhs(fb []float64, sol *Solution) (ok bool) {
+
+	// compute surface integral
+	var qb float64
+	for _, nbc := range o.NatBcs {
+
+		// skip conditions of other elements
+		if nbc.Key != "qc" {
+			continue
+		}
+
+		// flux prescribed
+		qb = nbc.Fcn.F(sol.T, nil)
+
+		// loop over ips of face
+		for _, ipf := range o.IpsFace {
+
+			// interpolation functions and gradients @ face
+			iface := nbc.IdxFace
+			if LogErr(o.Shp.CalcAtFaceIp(o.X, ipf, iface), "add_natbcs_to_rhs") {
+				return
+			}
+			Sf := o.Shp.Sf
+			coef := ipf.W * la.VecNorm(o.Shp.Fnvec)
+			for i, m := range o.Shp.FaceLocalV[iface] {
+				fb[o.Cmap[m]] -= coef * Sf[i] * qb
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,403 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/msolid"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// Interface implements a zero-thickness interface element between two faces of solid elements;
+// e.g. for joints, faults or soil-structure contact. The cells are of type "int-lin2", "int-lin3"
+// (2D) or "int-tri3", "int-tri6", "int-qua4", "int-qua8" (3D) with vertices ordered as
+// [bottom face vertices..., top face vertices...].
+//  Note: 1) the local system at each integration point is {n, e1, e2} where n is the normal
+//           to the bottom face pointing towards the top face; i.e. the bottom face vertices
+//           must follow the ordering of the face of the solid below (outward normal)
+//        2) the relative displacements are w = Q・(u_top - u_bot) = {wn, ws1, ws2}, where Q holds
+//           the local directions in its rows; wn > 0 means opening
+//        3) the geometry is computed with the initial coordinates (small displacements)
+type Interface struct {
+
+	// basic data
+	Cid int         // cell/element id
+	X   [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Shp *shp.Shape  // shape structure of faces
+	Nf  int         // number of vertices on each face
+	Nu  int         // total number of unknowns == 2 * ndim * nf
+
+	// parameters
+	Thickness float64 // thickness (for plane-stress)
+
+	// integration points
+	IpsElem []*shp.Ipoint // integration points of element
+
+	// geometry @ integration points
+	Q    [][][]float64 // [nip][ndim][ndim] local directions {n, e1, e2} @ ip
+	B    [][][]float64 // [nip][ndim][nu] relative displacements w = B・u @ ip
+	Coef []float64     // [nip] J・W・thickness @ ip
+
+	// vectors and matrices
+	K [][]float64 // global K matrix
+	D [][]float64 // [ndim][ndim] consistent tangent of tractions w.r.t relative displacements
+
+	// problem variables
+	Umap []int // assembly map (location array/element equations)
+
+	// material model and internal variables
+	Mdl       msolid.InterfaceM1 // material model
+	States    []*msolid.State    // [nip] internal states
+	StatesBkp []*msolid.State    // [nip] backup internal states
+
+	// scratchpad. computed @ each ip
+	Δw []float64 // [ndim] increment of relative displacements
+}
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["interface"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// number of nodes in element
+		nverts := 2 * shp.GetNverts(inp.InterfaceFaceType(cellType))
+		if LogErrCond(nverts < 0, "cannot find interface cell type = %q", cellType) {
+			return nil
+		}
+
+		// solution variables
+		ykeys := []string{"ux", "uy"}
+		if Global.Ndim == 3 {
+			ykeys = []string{"ux", "uy", "uz"}
+		}
+		info.Dofs = make([][]string, nverts)
+		for m := 0; m < nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"ux": "fx", "uy": "fy", "uz": "fz"}
+
+		// t1 and t2 variables
+		info.T2vars = ykeys
+		return &info
+	}
+
+	// element allocator
+	eallocators["interface"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o Interface
+		o.Cid = cid
+		o.X = x
+		o.Shp = shp.Get(inp.InterfaceFaceType(cellType))
+		if LogErrCond(o.Shp == nil, "cannot find interface cell type = %q", cellType) {
+			return nil
+		}
+		ndim := Global.Ndim
+		if LogErrCond(o.Shp.Gndim != ndim-1, "Interface: cid=%d: cell type %q cannot be used in %dD analyses\n", cid, cellType, ndim) {
+			return nil
+		}
+		if LogErrCond(Global.Sim.Data.Axisym, "Interface: cid=%d: axisymmetric analyses are not available\n", cid) {
+			return nil
+		}
+		o.Nf = o.Shp.Nverts
+		o.Nu = 2 * ndim * o.Nf
+
+		// flags
+		_, _, o.Thickness = GetSolidFlags(edat.Extra)
+
+		// material model
+		matdata := Global.Sim.Mdb.Get(edat.Mat)
+		if LogErrCond(matdata == nil, "materials database failed on getting %q material\n", edat.Mat) {
+			return nil
+		}
+		if LogErr(o.Mdl.Init(ndim, matdata.Prms), "cannot initialise model for Interface element") {
+			return nil
+		}
+
+		// integration points
+		var err error
+		o.IpsElem, err = shp.GetIps(o.Shp.Type, edat.Nip)
+		if LogErr(err, "GetIps failed") {
+			return nil
+		}
+		nip := len(o.IpsElem)
+
+		// geometry @ integration points
+		o.Q = make([][][]float64, nip)
+		o.B = make([][][]float64, nip)
+		o.Coef = make([]float64, nip)
+		S := make([]float64, o.Nf)
+		dSdR := la.MatAlloc(o.Nf, o.Shp.Gndim)
+		a := la.MatAlloc(o.Shp.Gndim, ndim) // tangent vectors: a[k] = dx/dR_k
+		for idx, ip := range o.IpsElem {
+
+			// shape functions and tangent vectors of bottom face
+			o.Shp.Func(S, dSdR, ip.R, ip.S, ip.T, true)
+			la.MatFill(a, 0)
+			for k := 0; k < o.Shp.Gndim; k++ {
+				for i := 0; i < ndim; i++ {
+					for m := 0; m < o.Nf; m++ {
+						a[k][i] += o.X[i][m] * dSdR[m][k]
+					}
+				}
+			}
+
+			// local directions
+			Q := la.MatAlloc(ndim, ndim)
+			J := FaceLocalSystem(Q, a)
+			if LogErrCond(J < shp.MINDET, "Interface: cid=%d: Jacobian is too small: %g\n", cid, J) {
+				return nil
+			}
+
+			// relative displacements matrix
+			B := la.MatAlloc(ndim, o.Nu)
+			for m := 0; m < o.Nf; m++ {
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						B[i][j+m*ndim] = -Q[i][j] * S[m]
+						B[i][j+(o.Nf+m)*ndim] = Q[i][j] * S[m]
+					}
+				}
+			}
+			o.Q[idx], o.B[idx] = Q, B
+			o.Coef[idx] = J * ip.W * o.Thickness
+		}
+
+		// scratchpad
+		o.K = la.MatAlloc(o.Nu, o.Nu)
+		o.D = la.MatAlloc(ndim, ndim)
+		o.Δw = make([]float64, ndim)
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o Interface) Id() int { return o.Cid }
+
+// SetEqs set equations
+func (o *Interface) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+	ndim := Global.Ndim
+	o.Umap = make([]int, o.Nu)
+	for m := 0; m < 2*o.Nf; m++ {
+		for i := 0; i < ndim; i++ {
+			r := i + m*ndim
+			o.Umap[r] = eqs[m][i]
+		}
+	}
+	return true
+}
+
+// SetEleConds set element conditions
+func (o *Interface) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	return true
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *Interface) InterpStarVars(sol *Solution) (ok bool) {
+	return true
+}
+
+// adds -R to global residual vector fb
+func (o Interface) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	for idx, _ := range o.IpsElem {
+		B := o.B[idx]
+		t := o.States[idx].Sig
+		for r := 0; r < o.Nu; r++ {
+			for i := 0; i < ndim; i++ {
+				fb[o.Umap[r]] -= o.Coef[idx] * B[i][r] * t[i] // -fi
+			}
+		}
+	}
+	return true
+}
+
+// adds element K to global Jacobian matrix Kb
+func (o Interface) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// zero K matrix
+	la.MatFill(o.K, 0)
+
+	// for each integration point
+	ndim := Global.Ndim
+	for idx, _ := range o.IpsElem {
+
+		// consistent tangent
+		if LogErr(o.Mdl.CalcD(o.D, o.States[idx], firstIt), "AddToKb") {
+			return
+		}
+
+		// add contribution to consistent tangent matrix
+		B := o.B[idx]
+		for r := 0; r < o.Nu; r++ {
+			for c := 0; c < o.Nu; c++ {
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						o.K[r][c] += o.Coef[idx] * B[i][r] * o.D[i][j] * B[j][c]
+					}
+				}
+			}
+		}
+	}
+
+	// add K to sparse matrix Kb
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.K[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+func (o *Interface) Update(sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	for idx, _ := range o.IpsElem {
+
+		// increment of relative displacements
+		B := o.B[idx]
+		for i := 0; i < ndim; i++ {
+			o.Δw[i] = 0
+			for r := 0; r < o.Nu; r++ {
+				o.Δw[i] += B[i][r] * sol.ΔY[o.Umap[r]]
+			}
+		}
+
+		// call model update => update tractions
+		if LogErr(o.Mdl.Update(o.States[idx], o.Δw), "Update") {
+			return
+		}
+	}
+	return true
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o Interface) Ipoints() (coords [][]float64) {
+	coords = la.MatAlloc(len(o.IpsElem), Global.Ndim)
+	for idx, ip := range o.IpsElem {
+		coords[idx] = o.Shp.IpRealCoords(o.X, ip) // uses only the first Nf vertices (bottom face)
+	}
+	return
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+//  Note: the initial tractions are computed from the stresses in ivs (if any) with t = Q・σ・n
+func (o *Interface) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+
+	// allocate slices of states
+	nip := len(o.IpsElem)
+	o.States = make([]*msolid.State, nip)
+	o.StatesBkp = make([]*msolid.State, nip)
+
+	// for each integration point
+	for i := 0; i < nip; i++ {
+		o.States[i], _ = o.Mdl.InitIntVars()
+		o.StatesBkp[i] = o.States[i].GetCopy()
+	}
+
+	// initial tractions
+	if _, ok := ivs["sx"]; ok {
+		ndim := Global.Ndim
+		σ := make([]float64, 6)
+		tvec := make([]float64, ndim)
+		for k := 0; k < nip; k++ {
+			for i := 0; i < 6; i++ {
+				σ[i] = 0
+			}
+			Ivs2sigmas(σ, k, ivs)
+			n := o.Q[k][0]
+			tvec[0] = σ[0]*n[0] + σ[3]*n[1]
+			tvec[1] = σ[3]*n[0] + σ[1]*n[1]
+			if ndim == 3 {
+				tvec[0] += σ[5] * n[2]
+				tvec[1] += σ[4] * n[2]
+				tvec[2] = σ[5]*n[0] + σ[4]*n[1] + σ[2]*n[2]
+			}
+			la.MatVecMul(o.States[k].Sig, 1, o.Q[k], tvec)
+			o.StatesBkp[k].Set(o.States[k])
+		}
+	}
+	return true
+}
+
+// BackupIvs create copy of internal variables
+func (o *Interface) BackupIvs() (ok bool) {
+	for i, s := range o.StatesBkp {
+		s.Set(o.States[i])
+	}
+	return true
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Interface) RestoreIvs() (ok bool) {
+	for i, s := range o.States {
+		s.Set(o.StatesBkp[i])
+	}
+	return true
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Interface) Ureset(sol *Solution) (ok bool) {
+	for _, s := range o.States {
+		for i := 0; i < len(s.Phi); i++ {
+			s.Phi[i] = 0
+		}
+	}
+	return o.BackupIvs()
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o Interface) Encode(enc Encoder) (ok bool) {
+	return !LogErr(enc.Encode(o.States), "Encode")
+}
+
+// Decode decodes internal variables
+func (o Interface) Decode(dec Decoder) (ok bool) {
+	if LogErr(dec.Decode(&o.States), "Decode") {
+		return
+	}
+	return o.BackupIvs()
+}
+
+// OutIpsData returns data from all integration points for output
+//  Note: "wn" is the opening and "ws" (2D) or "ws1" and "ws2" (3D) are the slip displacements;
+//        "tn" and "ts" (2D) or "ts1" and "ts2" (3D) are the corresponding tractions
+func (o Interface) OutIpsData() (data []*OutIpData) {
+	ndim := Global.Ndim
+	for idx, ip := range o.IpsElem {
+		s := o.States[idx]
+		x := o.Shp.IpRealCoords(o.X, ip)
+		v := map[string]*float64{
+			"wn": &s.Phi[0],
+			"tn": &s.Sig[0],
+		}
+		if ndim == 2 {
+			v["ws"] = &s.Phi[1]
+			v["ts"] = &s.Sig[1]
+		} else {
+			v["ws1"], v["ws2"] = &s.Phi[1], &s.Phi[2]
+			v["ts1"], v["ts2"] = &s.Sig[1], &s.Sig[2]
+		}
+		data = append(data, &OutIpData{o.Id(), x, v})
+	}
+	return
+}
@@ -55,6 +55,12 @@ type ElemP struct {
 	Emat      [][]float64 // [nverts][nips] extrapolator matrix
 	DoExtrap  bool        // do extrapolation of ρl and Cpl => for use with flux and seepage conditions
 
+	// post-processing of liquid flux (see LiqFlux and NodalLiqFlux); allocated when first needed
+	klrN []float64   // [nverts] klr (including the viscosity ratio) extrapolated to nodes
+	ρLN  []float64   // [nverts] ρL extrapolated to nodes
+	ρwlN [][]float64 // [nverts][ndim] ρl・wl extrapolated to nodes
+	rx   []float64   // [3] natural coordinates of point x in LiqFlux
+
 	// seepage face
 	Nf         int         // number of fl variables
 	HasSeep    bool        // indicates if this element has seepage faces
@@ -67,9 +73,17 @@ type ElemP struct {
 	Hst        []bool      // [nf] set hydrostatic plmax
 	Plmax      [][]float64 // [nf][nipsFace] specified plmax (not corrected by multiplier)
 
+	// atmospheric boundary (rainfall/evaporation with ponding and limiting suction)
+	AtmPlmax []float64 // [nbcs] maximum liquid pressure at "atm" faces; i.e. γl・ponding depth
+	AtmPlmin []float64 // [nbcs] minimum liquid pressure at "atm" faces; i.e. -limiting suction
+	AtmHasLo []bool    // [nbcs] "atm" face has limiting suction; otherwise evaporation is never reduced
+
 	// local starred variables
 	ψl []float64 // [nip] ψl* = β1.p + β2.dpdt
 
+	// thermal coupling (see ElemPT)
+	θt []float64 // [nip] rate of temperature @ ip: θt = β1・θ - ψθ*; nil if not coupled
+
 	// scratchpad. computed @ each ip
 	g   []float64   // [ndim] gravity vector
 	pl  float64     // pl: liquid pressure
@@ -107,7 +121,7 @@ func init() {
 		info.Y2F = map[string]string{"pl": "ql"}
 
 		// vertices on seepage faces
-		lverts := GetVertsWithCond(faceConds, "seep")
+		lverts := GetVertsWithCond(faceConds, "seep", "atm")
 		for _, m := range lverts {
 			if m < nverts { // avoid adding vertices of superelement (e.g. qua8 vertices in this qua4 cell)
 				info.Dofs[m] = append(info.Dofs[m], "fl")
@@ -162,7 +176,7 @@ func init() {
 
 		// vertices on seepage faces
 		var seepverts []int
-		lverts := GetVertsWithCond(faceConds, "seep")
+		lverts := GetVertsWithCond(faceConds, "seep", "atm")
 		for _, m := range lverts {
 			if m < o.Np { // avoid adding vertices of superelement (e.g. qua8 vertices in this qua4 cell)
 				seepverts = append(seepverts, m)
@@ -195,7 +209,7 @@ func init() {
 			o.NatBcs = append(o.NatBcs, &NaturalBc{fc.Cond, fc.FaceId, fc.Func, fc.Extra})
 
 			// allocate extrapolation structures
-			if fc.Cond == "ql" || fc.Cond == "seep" {
+			if fc.Cond == "ql" || fc.Cond == "seep" || fc.Cond == "atm" {
 				nv := o.Shp.Nverts
 				nip := len(o.IpsElem)
 				o.ρl_ex = make([]float64, nv)
@@ -216,6 +230,22 @@ func init() {
 					o.Hst[idx] = (s_val == "hst")
 				}
 			}
+
+			// atmospheric condition structures: ponding pressure and limiting suction
+			if fc.Cond == "atm" {
+				if len(o.AtmPlmax) == 0 {
+					o.AtmPlmax = make([]float64, len(faceConds))
+					o.AtmPlmin = make([]float64, len(faceConds))
+					o.AtmHasLo = make([]bool, len(faceConds))
+				}
+				if s_val, found := io.Keycode(fc.Extra, "hpond"); found {
+					o.AtmPlmax[idx] = o.γl * io.Atof(s_val)
+				}
+				if s_val, found := io.Keycode(fc.Extra, "pcmax"); found {
+					o.AtmPlmin[idx] = -io.Atof(s_val)
+					o.AtmHasLo[idx] = true
+				}
+			}
 		}
 
 		// return new element
@@ -282,7 +312,7 @@ func (o ElemP) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 	β1 := Global.DynCoefs.β1
 	ndim := Global.Ndim
 	nverts := o.Shp.Nverts
-	var coef, plt, klr, RhoL, ρl, Cpl float64
+	var coef, plt, θt, klr, vr, RhoL, ρl, Cpl, Cθ float64
 	var err error
 	for idx, ip := range o.IpsElem {
 
@@ -298,16 +328,24 @@ func (o ElemP) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 		plt = β1*o.pl - o.ψl[idx]
 		klr = o.Mdl.Cnd.Klr(o.States[idx].Sl)
 		RhoL = o.States[idx].RhoL
+		vr, _ = o.Mdl.ViscRatio(o.States[idx].Temp)
 		ρl, Cpl, err = o.States[idx].Lvars(o.Mdl)
 		if LogErr(err, "calc of tpm variables failed") {
 			return
 		}
+		if o.θt != nil {
+			θt = o.θt[idx]
+			Cθ, _, err = o.States[idx].Tvars(o.Mdl)
+			if LogErr(err, "calc of thermal tpm variables failed") {
+				return
+			}
+		}
 
 		// compute ρwl. see Eq. (6) of [1]
 		for i := 0; i < ndim; i++ {
 			o.ρwl[i] = 0
 			for j := 0; j < ndim; j++ {
-				o.ρwl[i] += klr * o.Mdl.Klsat[i][j] * (RhoL*o.g[j] - o.gpl[j])
+				o.ρwl[i] += vr * klr * o.Mdl.Klsat[i][j] * (RhoL*o.g[j] - o.gpl[j])
 			}
 		}
 
@@ -317,7 +355,7 @@ func (o ElemP) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 		// add negative of residual term to fb. see Eqs. (12) and (17) of [1]
 		for m := 0; m < nverts; m++ {
 			r := o.Pmap[m]
-			fb[r] -= coef * S[m] * Cpl * plt
+			fb[r] -= coef * S[m] * (Cpl*plt + Cθ*θt)
 			for i := 0; i < ndim; i++ {
 				fb[r] += coef * G[m][i] * o.ρwl[i] // += coef * div(ρl*wl)
 			}
@@ -354,7 +392,7 @@ func (o ElemP) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 	// for each integration point
 	Cl := o.Mdl.Cl
 	β1 := Global.DynCoefs.β1
-	var coef, plt, klr, RhoL, ρl, Cpl, dCpldpl, dklrdpl float64
+	var coef, plt, θt, klr, vr, RhoL, ρl, Cpl, dCpldpl, dklrdpl, dCθdpl float64
 	var err error
 	for idx, ip := range o.IpsElem {
 
@@ -370,18 +408,26 @@ func (o ElemP) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 		plt = β1*o.pl - o.ψl[idx]
 		klr = o.Mdl.Cnd.Klr(o.States[idx].Sl)
 		RhoL = o.States[idx].RhoL
+		vr, _ = o.Mdl.ViscRatio(o.States[idx].Temp)
 		ρl, Cpl, dCpldpl, dklrdpl, err = o.States[idx].Lderivs(o.Mdl)
 		if LogErr(err, "calc of tpm derivatives failed") {
 			return
 		}
+		if o.θt != nil {
+			θt = o.θt[idx]
+			_, dCθdpl, err = o.States[idx].Tvars(o.Mdl)
+			if LogErr(err, "calc of thermal tpm derivatives failed") {
+				return
+			}
+		}
 
 		// Kpp := dRpl/dpl. see Eqs. (18), (A.2) and (A.3) of [1]
 		for n := 0; n < nverts; n++ {
 			for j := 0; j < ndim; j++ {
-				o.tmp[j] = S[n]*dklrdpl*(RhoL*o.g[j]-o.gpl[j]) + klr*(S[n]*Cl*o.g[j]-G[n][j])
+				o.tmp[j] = vr * (S[n]*dklrdpl*(RhoL*o.g[j]-o.gpl[j]) + klr*(S[n]*Cl*o.g[j]-G[n][j]))
 			}
 			for m := 0; m < nverts; m++ {
-				o.Kpp[m][n] += coef * S[m] * S[n] * (dCpldpl*plt + β1*Cpl)
+				o.Kpp[m][n] += coef * S[m] * S[n] * (dCpldpl*plt + dCθdpl*θt + β1*Cpl)
 				for i := 0; i < ndim; i++ {
 					for j := 0; j < ndim; j++ {
 						o.Kpp[m][n] -= coef * G[m][i] * o.Mdl.Klsat[i][j] * o.tmp[j]
@@ -570,6 +616,142 @@ func (o *ElemP) Ureset(sol *Solution) (ok bool) {
 	return true
 }
 
+// post-processing //////////////////////////////////////////////////////////////////////////////////
+
+// LiqFlux computes the liquid flux ρl・wl at a point with real coordinates x inside this element
+//  Note: klr and ρL are extrapolated from integration points to nodes and then interpolated to x.
+//        The Darcy velocity can be computed with ρl・wl / ρL
+func (o *ElemP) LiqFlux(ρwl, x []float64, sol *Solution) (ρL float64, err error) {
+
+	// extrapolate klr (including the viscosity ratio) and ρL to nodes
+	err = o.flux_alloc()
+	if err != nil {
+		return
+	}
+	E := o.Emat
+	nverts := o.Shp.Nverts
+	for m := 0; m < nverts; m++ {
+		o.klrN[m], o.ρLN[m] = 0, 0
+		for idx, s := range o.States {
+			vr, _ := o.Mdl.ViscRatio(s.Temp)
+			o.klrN[m] += E[m][idx] * vr * o.Mdl.Cnd.Klr(s.Sl)
+			o.ρLN[m] += E[m][idx] * s.RhoL
+		}
+	}
+
+	// shape functions and gradients @ x
+	err = o.Shp.InvMap(o.rx, x, o.X)
+	if err != nil {
+		return
+	}
+	err = o.Shp.CalcAtR(o.X, o.rx, true)
+	if err != nil {
+		return
+	}
+
+	// gravity
+	ndim := Global.Ndim
+	o.g[ndim-1] = 0
+	if o.Gfcn != nil {
+		o.g[ndim-1] = -o.Gfcn.F(sol.T, nil)
+	}
+
+	// klr, ρL and ∇pl @ x
+	var klr float64
+	la.VecFill(o.gpl, 0)
+	for m := 0; m < nverts; m++ {
+		klr += o.Shp.S[m] * o.klrN[m]
+		ρL += o.Shp.S[m] * o.ρLN[m]
+		for i := 0; i < ndim; i++ {
+			o.gpl[i] += o.Shp.G[m][i] * sol.Y[o.Pmap[m]]
+		}
+	}
+
+	// flux
+	for i := 0; i < ndim; i++ {
+		ρwl[i] = 0
+		for j := 0; j < ndim; j++ {
+			ρwl[i] += klr * o.Mdl.Klsat[i][j] * (ρL*o.g[j] - o.gpl[j])
+		}
+	}
+	return
+}
+
+// NodalLiqFlux extrapolates the liquid flux ρl・wl and ρL from integration points to nodes
+//  Output: ρwlN[nverts][ndim] and ρLN[nverts] -- internal arrays; do not modify them
+//  Note: no inverse mapping is needed; hence, this is cheaper than LiqFlux when values
+//        at many points on faces are required. See Domain.FaceLiqFlux
+func (o *ElemP) NodalLiqFlux(sol *Solution) (ρwlN [][]float64, ρLN []float64, err error) {
+	err = o.flux_alloc()
+	if err != nil {
+		return
+	}
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	for m := 0; m < nverts; m++ {
+		o.ρLN[m] = 0
+		la.VecFill(o.ρwlN[m], 0)
+	}
+	E := o.Emat
+	var klr, vr, RhoL float64
+	for idx, ip := range o.IpsElem {
+
+		// ∇pl and gravity @ ip
+		err = o.Shp.CalcAtIp(o.X, ip, true)
+		if err != nil {
+			return
+		}
+		o.g[ndim-1] = 0
+		if o.Gfcn != nil {
+			o.g[ndim-1] = -o.Gfcn.F(sol.T, nil)
+		}
+		la.VecFill(o.gpl, 0)
+		for m := 0; m < nverts; m++ {
+			for i := 0; i < ndim; i++ {
+				o.gpl[i] += o.Shp.G[m][i] * sol.Y[o.Pmap[m]]
+			}
+		}
+
+		// ρl・wl @ ip. see Eq. (6) of [1]
+		s := o.States[idx]
+		klr = o.Mdl.Cnd.Klr(s.Sl)
+		vr, _ = o.Mdl.ViscRatio(s.Temp)
+		RhoL = s.RhoL
+		for i := 0; i < ndim; i++ {
+			o.ρwl[i] = 0
+			for j := 0; j < ndim; j++ {
+				o.ρwl[i] += vr * klr * o.Mdl.Klsat[i][j] * (RhoL*o.g[j] - o.gpl[j])
+			}
+		}
+
+		// extrapolate
+		for m := 0; m < nverts; m++ {
+			o.ρLN[m] += E[m][idx] * RhoL
+			for i := 0; i < ndim; i++ {
+				o.ρwlN[m][i] += E[m][idx] * o.ρwl[i]
+			}
+		}
+	}
+	return o.ρwlN, o.ρLN, nil
+}
+
+// LiqMass computes the mass of liquid within this element; i.e. the integral of ρl over the element
+func (o *ElemP) LiqMass() (mass float64, err error) {
+	var ρl float64
+	for idx, ip := range o.IpsElem {
+		err = o.Shp.CalcAtIp(o.X, ip, true)
+		if err != nil {
+			return
+		}
+		ρl, _, err = o.States[idx].Lvars(o.Mdl)
+		if err != nil {
+			return
+		}
+		mass += ρl * o.Shp.J * ip.W
+	}
+	return
+}
+
 // writer ///////////////////////////////////////////////////////////////////////////////////////////
 
 // Encode encodes internal variables
@@ -630,6 +812,28 @@ func (o *ElemP) ipvars(idx int, sol *Solution) (ok bool) {
 	return true
 }
 
+// flux_alloc allocates the extrapolator matrix and the arrays used by LiqFlux and NodalLiqFlux
+//  Note: Emat is computed once; e.g. if there are no flux or seepage conditions
+func (o *ElemP) flux_alloc() (err error) {
+	if o.ρwlN != nil {
+		return
+	}
+	nverts := o.Shp.Nverts
+	if o.Emat == nil {
+		E := la.MatAlloc(nverts, len(o.IpsElem))
+		err = o.Shp.Extrapolator(E, o.IpsElem)
+		if err != nil {
+			return
+		}
+		o.Emat = E
+	}
+	o.klrN = make([]float64, nverts)
+	o.ρLN = make([]float64, nverts)
+	o.ρwlN = la.MatAlloc(nverts, Global.Ndim)
+	o.rx = make([]float64, 3)
+	return
+}
+
 // fipvars computes current values @ face integration points
 func (o *ElemP) fipvars(fidx int, sol *Solution) (ρl, pl, fl float64) {
 	Sf := o.Shp.Sf
@@ -647,7 +851,7 @@ func (o ElemP) add_natbcs_to_rhs(fb []float64, sol *Solution) (ok bool) {
 
 	// compute surface integral
 	var tmp float64
-	var ρl, pl, fl, plmax, g, rmp, rx, rf float64
+	var ρl, pl, fl, plmax, g, σ, rmp, rx, rf float64
 	for idx, nbc := range o.NatBcs {
 
 		// tmp := plmax shift or qlb
@@ -696,6 +900,25 @@ func (o ElemP) add_natbcs_to_rhs(fb []float64, sol *Solution) (ok bool) {
 				//io.Pfyel("pl=%g plmax=%g g=%g rmp=%g rx=%g rf=%g\n", pl, plmax, g, rmp, rx, rf)
 				//panic("stop")
 
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					μ := o.Vid2seepId[m]
+					fb[o.Pmap[m]] -= coef * Sf[i] * rx
+					fb[o.Fmap[μ]] -= coef * Sf[i] * rf
+				}
+			case "atm":
+
+				// variables extrapolated to face
+				ρl, pl, fl = o.fipvars(iface, sol)
+
+				// compute residuals
+				g, σ = o.atm_gap(idx, pl, tmp)
+				rmp = 0
+				if σ != 0 {
+					rmp = o.ramp(fl + o.κ*g)
+				}
+				rx = ρl * (tmp + σ*rmp) // flux corrected by ponding or limiting suction
+				rf = fl - rmp
+
 				for i, m := range o.Shp.FaceLocalV[iface] {
 					μ := o.Vid2seepId[m]
 					fb[o.Pmap[m]] -= coef * Sf[i] * rx
@@ -724,11 +947,11 @@ func (o ElemP) add_natbcs_to_jac(sol *Solution) (ok bool) {
 	// compute surface integral
 	nverts := o.Shp.Nverts
 	var shift float64
-	var ρl, pl, fl, plmax, g, rmp, rmpD float64
+	var ρl, pl, fl, plmax, g, σ, rmp, rmpD float64
 	var drxdpl, drxdfl, drfdpl, drfdfl float64
 	for idx, nbc := range o.NatBcs {
 
-		// plmax shift
+		// plmax shift or qlb
 		shift = nbc.Fcn.F(sol.T, nil)
 
 		// loop over ips of face
@@ -777,12 +1000,57 @@ func (o ElemP) add_natbcs_to_jac(sol *Solution) (ok bool) {
 						}
 					}
 				}
+			case "atm":
+
+				// variables extrapolated to face
+				ρl, pl, fl = o.fipvars(iface, sol)
+
+				// compute derivatives; note that dg/dpl = σ and σ² = 1
+				g, σ = o.atm_gap(idx, pl, shift)
+				rmp, rmpD = 0, 0
+				if σ != 0 {
+					rmp = o.ramp(fl + o.κ*g)
+					rmpD = o.rampD1(fl + o.κ*g)
+				}
+				drxdpl = ρl * o.κ * rmpD
+				drxdfl = ρl * σ * rmpD
+				drfdpl = -o.κ * σ * rmpD
+				drfdfl = 1.0 - rmpD
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					μ := o.Vid2seepId[m]
+					for j, n := range o.Shp.FaceLocalV[iface] {
+						ν := o.Vid2seepId[n]
+						o.Kpp[m][n] += coef * Sf[i] * Sf[j] * drxdpl
+						o.Kpf[m][ν] += coef * Sf[i] * Sf[j] * drxdfl
+						o.Kfp[μ][n] += coef * Sf[i] * Sf[j] * drfdpl
+						o.Kff[μ][ν] += coef * Sf[i] * Sf[j] * drfdfl
+					}
+					for n := 0; n < nverts; n++ {
+						for l, r := range o.Shp.FaceLocalV[iface] {
+							o.Kpp[m][n] += coef * Sf[i] * Sf[l] * o.dρldpl_ex[r][n] * (shift + σ*rmp)
+						}
+					}
+				}
 			}
 		}
 	}
 	return true
 }
 
+// atm_gap returns the gap function g and the switch σ for atmospheric conditions
+//  Note: qlb < 0 (rainfall) => g = pl - plmax and σ = +1; i.e. inflow is reduced once ponding is reached
+//        qlb > 0 (evaporation) => g = plmin - pl and σ = -1; i.e. outflow is reduced once the limiting suction is reached
+//        σ = 0 means that the flux is never corrected
+func (o ElemP) atm_gap(idx int, pl, qlb float64) (g, σ float64) {
+	if qlb > 0 {
+		if o.AtmHasLo[idx] {
+			return o.AtmPlmin[idx] - pl, -1
+		}
+		return 0, 0
+	}
+	return pl - o.AtmPlmax[idx], 1
+}
+
 // ramp implements the ramp function
 func (o *ElemP) ramp(x float64) float64 {
 	if o.Macaulay {
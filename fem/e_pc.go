@@ -0,0 +1,419 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemPC represents an element for the transport of solutes in porous media coupled to seepage;
+// i.e. the Darcy flux q = ρl・wl / ρL and the water content θw = nf・sl computed by the p-element
+// drive the advection-dispersion equation of the c-element (see ElemC)
+//  Note: the material must be a group with the porous materials ('c', 'l' and 'p') and a transport
+//        ('tr') material; e.g. "!c:cnd1 !l:lrm1 !p:pm1 !tr:trn1"
+//  Coupling:
+//   monolithic (default) -- q and θw are computed with the current pl; the consistent tangent
+//                           Kcp := dRc/dpl is assembled, except for the derivative of the SUPG
+//                           parameter, which is neglected
+//   lagged ("!lag:1")     -- q and θw are computed at the beginning of each time step with the
+//                           converged pl of the previous step; thus Kcp is null. Transient only
+//   sequential ("!seq:1") -- staggered solution within each time step: the flow equations are
+//                           solved first, with the transport equations frozen; then, the transport
+//                           equations are solved with q and θw computed from the converged pl
+type ElemPC struct {
+
+	// auxiliary
+	Fconds []*FaceCond // face conditions; e.g. seepage faces
+	Ctype  string      // cell type
+
+	// underlying elements
+	P *ElemP // p-element
+	C *ElemC // c-element
+
+	// flags
+	Lag  bool // lagged coupling: flow variables of transport are frozen during each time step
+	Seq  bool // sequential (staggered) coupling: flow is solved before transport
+	Pass int  // current pass of sequential coupling: 0 == all, 1 == flow, 2 == transport
+
+	// scratchpad. computed @ each ip
+	dθwdpl []float64   // [np] ∂θw/∂pl^n
+	dqdpl  [][]float64 // [np][ndim] ∂q/∂pl^n
+	Kcp    [][]float64 // [nc][np] Kcp := dRc/dpl consistent tangent matrix
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["pc"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// underlying cells info
+		p_info := infogetters["p"](cellType, faceConds)
+		c_info := infogetters["c"](cellType, faceConds)
+
+		// solution variables
+		nverts := shp.GetNverts(cellType)
+		info.Dofs = make([][]string, nverts)
+		for i, dofs := range p_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+		for i, dofs := range c_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+
+		// maps
+		info.Y2F = p_info.Y2F
+		for key, val := range c_info.Y2F {
+			info.Y2F[key] = val
+		}
+
+		// t1 variables
+		info.T1vars = append([]string{}, p_info.T1vars...)
+		info.T1vars = append(info.T1vars, c_info.T1vars...)
+		return &info
+	}
+
+	// element allocator
+	eallocators["pc"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemPC
+		o.Fconds = faceConds
+		o.Ctype = cellType
+
+		// flags
+		_, o.Lag, o.Seq, _, _ = GetTranspFlags(edat.Extra, Global.Ndim)
+		if LogErrCond(o.Lag && Global.Sim.Data.Steady, "ElemPC: cid=%d: lagged coupling requires transient simulations\n", cid) {
+			return nil
+		}
+		if LogErrCond(o.Lag && o.Seq, "ElemPC: cid=%d: lagged and sequential couplings cannot be used together\n", cid) {
+			return nil
+		}
+
+		// allocate p-element
+		p_elem := eallocators["p"](cellType, faceConds, cid, edat, x)
+		if LogErrCond(p_elem == nil, "cannot allocate underlying p-element") {
+			return nil
+		}
+		o.P = p_elem.(*ElemP)
+
+		// make sure c-element uses the same number of integration points than p-element
+		edat.Nip = len(o.P.IpsElem)
+
+		// allocate c-element
+		c_elem := eallocators["c"](cellType, faceConds, cid, edat, x)
+		if LogErrCond(c_elem == nil, "cannot allocate underlying c-element") {
+			return nil
+		}
+		o.C = c_elem.(*ElemC)
+
+		// scratchpad. computed @ each ip
+		ndim := Global.Ndim
+		o.dθwdpl = make([]float64, o.P.Np)
+		o.dqdpl = la.MatAlloc(o.P.Np, ndim)
+		o.Kcp = la.MatAlloc(o.C.Nc, o.P.Np)
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemPC) Id() int { return o.P.Id() }
+
+// SetEqs set equations
+func (o *ElemPC) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+
+	// p: equations
+	p_info := infogetters["p"](o.Ctype, o.Fconds)
+	nverts := len(p_info.Dofs)
+	p_eqs := make([][]int, nverts)
+	c_eqs := make([][]int, nverts)
+	for i := 0; i < nverts; i++ {
+		nkeys := len(p_info.Dofs[i])
+		p_eqs[i] = make([]int, nkeys)
+		for j := 0; j < nkeys; j++ {
+			p_eqs[i][j] = eqs[i][j]
+		}
+
+		// c: equations
+		c_eqs[i] = []int{eqs[i][nkeys]}
+	}
+
+	// set equations
+	if !o.P.SetEqs(p_eqs, nil) {
+		return
+	}
+	return o.C.SetEqs(c_eqs, nil)
+}
+
+// SetEleConds set element conditions
+func (o *ElemPC) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	if !o.P.SetEleConds(key, f, extra) {
+		return
+	}
+	return o.C.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+//  Note: with lagged coupling, the flow variables are frozen here
+func (o *ElemPC) InterpStarVars(sol *Solution) (ok bool) {
+	if !o.P.InterpStarVars(sol) {
+		return
+	}
+	if !o.C.InterpStarVars(sol) {
+		return
+	}
+	if o.Lag {
+		return o.set_flow(sol)
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemPC) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+	if !o.P.AddToRhs(fb, sol) {
+		return
+	}
+	if o.Pass == 1 {
+		return true // transport frozen
+	}
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	return o.C.AddToRhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemPC) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// Kpp, Kpf, Kfp, Kff
+	if !o.P.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// transport frozen: δc = 0
+	if o.Pass == 1 {
+		for _, I := range o.C.Cmap {
+			Kb.Put(I, I, 1)
+		}
+		return true
+	}
+
+	// Kcc
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	if !o.C.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// lagged or sequential coupling: flow variables do not depend on current pl
+	if o.frozen_flow() {
+		return true
+	}
+
+	// clear matrices
+	la.MatFill(o.Kcp, 0)
+
+	// for each integration point
+	ndim := Global.Ndim
+	nverts := o.P.Shp.Nverts
+	Cl := o.P.Mdl.Cl
+	var coef, klr, vr, RhoL, dklrdpl, Ccb, nf float64
+	var err error
+	for idx, ip := range o.P.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.P.ipvars(idx, sol) {
+			return
+		}
+		if !o.C.ipvars(idx, sol) {
+			return
+		}
+		o.C.ipterms(idx)
+		o.C.disp_derivs(idx)
+		coef = o.P.Shp.J * ip.W
+		S := o.P.Shp.S
+		G := o.P.Shp.G
+
+		// tpm variables
+		state := o.P.States[idx]
+		klr = o.P.Mdl.Cnd.Klr(state.Sl)
+		RhoL = state.RhoL
+		vr, _ = o.P.Mdl.ViscRatio(state.Temp)
+		_, _, _, dklrdpl, err = state.Lderivs(o.P.Mdl)
+		if LogErr(err, "calc of tpm derivatives failed") {
+			return
+		}
+		Ccb, err = o.P.Mdl.Ccb(state)
+		if LogErr(err, "calc of tpm derivatives failed") {
+			return
+		}
+		nf = 1.0 - (1.0-state.Divus)*state.Ns0
+
+		// derivatives of flow variables w.r.t pl^n
+		for n := 0; n < nverts; n++ {
+			o.dθwdpl[n] = -nf * Ccb * S[n]
+			for i := 0; i < ndim; i++ {
+				o.dqdpl[n][i] = 0
+				for j := 0; j < ndim; j++ {
+					o.dqdpl[n][i] += vr * o.P.Mdl.Klsat[i][j] * (S[n]*dklrdpl*(o.P.g[j]-o.P.gpl[j]/RhoL) + klr*(S[n]*Cl*o.P.gpl[j]/(RhoL*RhoL)-G[n][j]/RhoL))
+				}
+			}
+		}
+
+		// Kcp := dRc/dpl
+		for n := 0; n < nverts; n++ {
+			o.C.add_flow_derivs(o.Kcp, n, coef, o.dθwdpl[n], o.dqdpl[n])
+		}
+	}
+
+	// add Kcp to sparse matrix Kb
+	//    _         _
+	//   |  Kpp  0   |
+	//   |_ Kcp Kcc _|
+	//
+	for i, I := range o.C.Cmap {
+		for j, J := range o.P.Pmap {
+			Kb.Put(I, J, o.Kcp[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+func (o *ElemPC) Update(sol *Solution) (ok bool) {
+	if !o.P.Update(sol) {
+		return
+	}
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	return o.C.Update(sol)
+}
+
+// staggered scheme /////////////////////////////////////////////////////////////////////////////////
+
+// Staggered returns whether the sequential coupling is active
+func (o ElemPC) Staggered() bool { return o.Seq }
+
+// SetPass sets the current pass of the sequential coupling
+//  Note: the flow variables of transport are computed here with the converged pl of the first pass.
+//        In the second pass, the flow equations remain in the system, but they are already satisfied
+//        and do not depend on c; thus pl does not change
+func (o *ElemPC) SetPass(pass int, sol *Solution) (ok bool) {
+	o.Pass = pass
+	if pass == 2 {
+		return o.set_flow(sol)
+	}
+	return true
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o ElemPC) Ipoints() (coords [][]float64) {
+	return o.P.Ipoints()
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *ElemPC) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+	return o.P.SetIniIvs(sol, ivs)
+}
+
+// BackupIvs create copy of internal variables
+func (o *ElemPC) BackupIvs() (ok bool) {
+	return o.P.BackupIvs()
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *ElemPC) RestoreIvs() (ok bool) {
+	return o.P.RestoreIvs()
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *ElemPC) Ureset(sol *Solution) (ok bool) {
+	return o.P.Ureset(sol)
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemPC) Encode(enc Encoder) (ok bool) {
+	if !o.P.Encode(enc) {
+		return
+	}
+	return o.C.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o ElemPC) Decode(dec Decoder) (ok bool) {
+	if !o.P.Decode(dec) {
+		return
+	}
+	return o.C.Decode(dec)
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemPC) OutIpsData() (data []*OutIpData) {
+	data = o.P.OutIpsData()
+	c_dat := o.C.OutIpsData()
+	chk.IntAssert(len(data), len(c_dat))
+	for i, d := range data {
+		for key, val := range c_dat[i].V {
+			d.V[key] = val
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// frozen_flow returns whether the flow variables of transport are kept constant during iterations
+func (o ElemPC) frozen_flow() bool {
+	return o.Lag || o.Pass == 2
+}
+
+// set_flow computes the Darcy flux and the water content @ integration points of c-element
+func (o *ElemPC) set_flow(sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	var klr, vr, RhoL float64
+	for idx, _ := range o.P.IpsElem {
+		if !o.P.ipvars(idx, sol) {
+			return
+		}
+		state := o.P.States[idx]
+		klr = o.P.Mdl.Cnd.Klr(state.Sl)
+		RhoL = state.RhoL
+		vr, _ = o.P.Mdl.ViscRatio(state.Temp)
+		for i := 0; i < ndim; i++ {
+			o.C.q[idx][i] = 0
+			for j := 0; j < ndim; j++ {
+				o.C.q[idx][i] += vr * klr * o.P.Mdl.Klsat[i][j] * (o.P.g[j] - o.P.gpl[j]/RhoL)
+			}
+		}
+		o.C.θw[idx] = (1.0 - (1.0-state.Divus)*state.Ns0) * state.Sl
+	}
+	return true
+}
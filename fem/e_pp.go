@@ -0,0 +1,688 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mporous"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemPP implements an element for transient liquid-gas flow analyses
+//  Note: the formulation follows [1] with the gas phase treated as the liquid phase;
+//        i.e. both pl and pg are primary variables
+//  References:
+//   [1] Pedroso DM (2015) A solution to transient seepage in unsaturated porous media.
+//       Computer Methods in Applied Mechanics and Engineering, 285 791-816,
+//       http://dx.doi.org/10.1016/j.cma.2014.12.009
+type ElemPP struct {
+
+	// basic data
+	Cid int         // cell/element id
+	X   [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Shp *shp.Shape  // shape structure
+	Np  int         // number of vertices == number of pl or pg unknowns
+
+	// integration points
+	IpsElem []*shp.Ipoint // integration points of element
+	IpsFace []*shp.Ipoint // integration points corresponding to faces
+
+	// material model
+	Mdl *mporous.Model // model
+
+	// problem variables
+	Plmap []int // assembly map (location array/element equations) of pl
+	Pgmap []int // assembly map (location array/element equations) of pg
+
+	// internal variables
+	States    []*mporous.State
+	StatesBkp []*mporous.State
+
+	// gravity
+	Gfcn fun.Func // gravity function
+
+	// natural boundary conditions
+	NatBcs []*NaturalBc // natural boundary conditions
+
+	// flux boundary conditions (qb == \bar{q})
+	ρl_ex     []float64   // [nverts] ρl extrapolted to nodes => if has qlb (flux)
+	ρg_ex     []float64   // [nverts] ρg extrapolted to nodes => if has qgb (flux)
+	dρldpl_ex [][]float64 // [nverts][nverts] Cpl extrapolted to nodes => if has flux
+	dρldpg_ex [][]float64 // [nverts][nverts] Cpg extrapolted to nodes => if has flux
+	dρgdpl_ex [][]float64 // [nverts][nverts] Dpl extrapolted to nodes => if has flux
+	dρgdpg_ex [][]float64 // [nverts][nverts] Dpg extrapolted to nodes => if has flux
+	Emat      [][]float64 // [nverts][nips] extrapolator matrix
+	DoExtrap  bool        // do extrapolation of ρl, ρg and derivatives => for use with flux conditions
+
+	// local starred variables
+	ψl []float64 // [nip] ψl* = β1.pl + β2.dpldt
+	ψg []float64 // [nip] ψg* = β1.pg + β2.dpgdt
+
+	// scratchpad. computed @ each ip
+	g   []float64   // [ndim] gravity vector
+	pl  float64     // pl: liquid pressure
+	pg  float64     // pg: gas pressure
+	gpl []float64   // [ndim] ∇pl: gradient of liquid pressure
+	gpg []float64   // [ndim] ∇pg: gradient of gas pressure
+	ρwl []float64   // [ndim] ρl*wl: weighted liquid relative velocity
+	ρwg []float64   // [ndim] ρg*wg: weighted gas relative velocity
+	tl1 []float64   // [ndim] temporary (auxiliary) vector
+	tl2 []float64   // [ndim] temporary (auxiliary) vector
+	tg1 []float64   // [ndim] temporary (auxiliary) vector
+	tg2 []float64   // [ndim] temporary (auxiliary) vector
+	Kll [][]float64 // [np][np] Kll := dRpl/dpl consistent tangent matrix
+	Klg [][]float64 // [np][np] Klg := dRpl/dpg consistent tangent matrix
+	Kgl [][]float64 // [np][np] Kgl := dRpg/dpl consistent tangent matrix
+	Kgg [][]float64 // [np][np] Kgg := dRpg/dpg consistent tangent matrix
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["pp"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// number of nodes in element
+		nverts := shp.GetNverts(cellType)
+
+		// solution variables
+		ykeys := []string{"pl", "pg"}
+		info.Dofs = make([][]string, nverts)
+		for m := 0; m < nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"pl": "ql", "pg": "qg"}
+
+		// t1 and t2 variables
+		info.T1vars = ykeys
+		return &info
+	}
+
+	// element allocator
+	eallocators["pp"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemPP
+		o.Cid = cid
+		o.X = x
+		o.Shp = shp.Get(cellType)
+		o.Np = o.Shp.Nverts
+
+		// integration points
+		o.IpsElem, o.IpsFace = GetIntegrationPoints(edat.Nip, edat.Nipf, cellType)
+		if o.IpsElem == nil || o.IpsFace == nil {
+			return nil
+		}
+		nip := len(o.IpsElem)
+
+		// models
+		o.Mdl = GetAndInitPorousModel(edat.Mat)
+		if o.Mdl == nil {
+			return nil
+		}
+		if LogErrCond(o.Mdl.RhoG0 <= 0, "pp element requires a positive initial gas density RhoG0. RhoG0=%g is incorrect", o.Mdl.RhoG0) {
+			return nil
+		}
+
+		// local starred variables
+		o.ψl = make([]float64, nip)
+		o.ψg = make([]float64, nip)
+
+		// scratchpad. computed @ each ip
+		ndim := Global.Ndim
+		o.g = make([]float64, ndim)
+		o.gpl = make([]float64, ndim)
+		o.gpg = make([]float64, ndim)
+		o.ρwl = make([]float64, ndim)
+		o.ρwg = make([]float64, ndim)
+		o.tl1 = make([]float64, ndim)
+		o.tl2 = make([]float64, ndim)
+		o.tg1 = make([]float64, ndim)
+		o.tg2 = make([]float64, ndim)
+		o.Kll = la.MatAlloc(o.Np, o.Np)
+		o.Klg = la.MatAlloc(o.Np, o.Np)
+		o.Kgl = la.MatAlloc(o.Np, o.Np)
+		o.Kgg = la.MatAlloc(o.Np, o.Np)
+
+		// set natural boundary conditions
+		for _, fc := range faceConds {
+			o.NatBcs = append(o.NatBcs, &NaturalBc{fc.Cond, fc.FaceId, fc.Func, fc.Extra})
+
+			// allocate extrapolation structures
+			if (fc.Cond == "ql" || fc.Cond == "qg") && !o.DoExtrap {
+				nv := o.Shp.Nverts
+				o.ρl_ex = make([]float64, nv)
+				o.ρg_ex = make([]float64, nv)
+				o.dρldpl_ex = la.MatAlloc(nv, nv)
+				o.dρldpg_ex = la.MatAlloc(nv, nv)
+				o.dρgdpl_ex = la.MatAlloc(nv, nv)
+				o.dρgdpg_ex = la.MatAlloc(nv, nv)
+				o.Emat = la.MatAlloc(nv, nip)
+				o.DoExtrap = true
+				if LogErr(o.Shp.Extrapolator(o.Emat, o.IpsElem), "element allocation") {
+					return nil
+				}
+			}
+		}
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemPP) Id() int { return o.Cid }
+
+// SetEqs sets equations
+func (o *ElemPP) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+	o.Plmap = make([]int, o.Np)
+	o.Pgmap = make([]int, o.Np)
+	for m := 0; m < o.Shp.Nverts; m++ {
+		o.Plmap[m] = eqs[m][0]
+		o.Pgmap[m] = eqs[m][1]
+	}
+	return true
+}
+
+// SetEleConds sets element conditions
+func (o *ElemPP) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	if key == "g" { // gravity
+		o.Gfcn = f
+	}
+	return true
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ElemPP) InterpStarVars(sol *Solution) (ok bool) {
+
+	// for each integration point
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.Shp.CalcAtIp(o.X, ip, true), "InterpStarVars") {
+			return
+		}
+
+		// interpolate starred variables
+		o.ψl[idx], o.ψg[idx] = 0, 0
+		for m := 0; m < o.Shp.Nverts; m++ {
+			o.ψl[idx] += o.Shp.S[m] * sol.Psi[o.Plmap[m]]
+			o.ψg[idx] += o.Shp.S[m] * sol.Psi[o.Pgmap[m]]
+		}
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemPP) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+
+	// clear variables
+	if o.DoExtrap {
+		la.VecFill(o.ρl_ex, 0)
+		la.VecFill(o.ρg_ex, 0)
+	}
+
+	// for each integration point
+	β1 := Global.DynCoefs.β1
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	var coef, plt, pgt, klr, kgr, RhoL, RhoG, ρl, ρg, Cpl, Cpg, Dpl, Dpg float64
+	var err error
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		coef = o.Shp.J * ip.W
+		S := o.Shp.S
+		G := o.Shp.G
+
+		// tpm variables
+		plt = β1*o.pl - o.ψl[idx]
+		pgt = β1*o.pg - o.ψg[idx]
+		klr = o.Mdl.Cnd.Klr(o.States[idx].Sl)
+		kgr = o.Mdl.Cnd.Kgr(1.0 - o.States[idx].Sl)
+		RhoL = o.States[idx].RhoL
+		RhoG = o.States[idx].RhoG
+		ρl, ρg, Cpl, Cpg, Dpl, Dpg, err = o.States[idx].LGvars(o.Mdl)
+		if LogErr(err, "calc of tpm variables failed") {
+			return
+		}
+
+		// compute ρwl and ρwg
+		for i := 0; i < ndim; i++ {
+			o.ρwl[i], o.ρwg[i] = 0, 0
+			for j := 0; j < ndim; j++ {
+				o.ρwl[i] += klr * o.Mdl.Klsat[i][j] * (RhoL*o.g[j] - o.gpl[j])
+				o.ρwg[i] += (RhoG / o.Mdl.RhoG0) * kgr * o.Mdl.Kgsat[i][j] * (RhoG*o.g[j] - o.gpg[j])
+			}
+		}
+
+		// add negative of residual term to fb
+		for m := 0; m < nverts; m++ {
+			rl, rg := o.Plmap[m], o.Pgmap[m]
+			fb[rl] -= coef * S[m] * (Cpl*plt + Cpg*pgt)
+			fb[rg] -= coef * S[m] * (Dpl*plt + Dpg*pgt)
+			for i := 0; i < ndim; i++ {
+				fb[rl] += coef * G[m][i] * o.ρwl[i] // += coef * div(ρl*wl)
+				fb[rg] += coef * G[m][i] * o.ρwg[i] // += coef * div(ρg*wg)
+			}
+			if o.DoExtrap {
+				o.ρl_ex[m] += o.Emat[m][idx] * ρl
+				o.ρg_ex[m] += o.Emat[m][idx] * ρg
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	if len(o.NatBcs) > 0 {
+		return o.add_natbcs_to_rhs(fb, sol)
+	}
+	return true
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemPP) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// clear matrices
+	la.MatFill(o.Kll, 0)
+	la.MatFill(o.Klg, 0)
+	la.MatFill(o.Kgl, 0)
+	la.MatFill(o.Kgg, 0)
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	if o.DoExtrap {
+		la.VecFill(o.ρl_ex, 0)
+		la.VecFill(o.ρg_ex, 0)
+		la.MatFill(o.dρldpl_ex, 0)
+		la.MatFill(o.dρldpg_ex, 0)
+		la.MatFill(o.dρgdpl_ex, 0)
+		la.MatFill(o.dρgdpg_ex, 0)
+	}
+
+	// for each integration point
+	Cl := o.Mdl.Cl
+	Cg := o.Mdl.Cg
+	β1 := Global.DynCoefs.β1
+	var coef, plt, pgt, klr, kgr, RhoL, RhoG, a, ρl, ρg, Cpl, Cpg, Dpl, Dpg float64
+	var dCpldpl, dCpldpg, dCpgdpl, dCpgdpg, dDpldpl, dDpldpg, dDpgdpl, dDpgdpg, dklrdpl, dkgrdpl float64
+	var hl, hg float64
+	var err error
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		coef = o.Shp.J * ip.W
+		S := o.Shp.S
+		G := o.Shp.G
+
+		// tpm variables
+		plt = β1*o.pl - o.ψl[idx]
+		pgt = β1*o.pg - o.ψg[idx]
+		klr = o.Mdl.Cnd.Klr(o.States[idx].Sl)
+		kgr = o.Mdl.Cnd.Kgr(1.0 - o.States[idx].Sl)
+		RhoL = o.States[idx].RhoL
+		RhoG = o.States[idx].RhoG
+		a = RhoG / o.Mdl.RhoG0
+		ρl, ρg, Cpl, Cpg, Dpl, Dpg, err = o.States[idx].LGvars(o.Mdl)
+		if LogErr(err, "calc of tpm variables failed") {
+			return
+		}
+		dCpldpl, dCpldpg, dCpgdpl, dCpgdpg, dDpldpl, dDpldpg, dDpgdpl, dDpgdpg, dklrdpl, dkgrdpl, err = o.States[idx].LGderivs(o.Mdl)
+		if LogErr(err, "calc of tpm derivatives failed") {
+			return
+		}
+
+		// Kll, Klg, Kgl and Kgg
+		for n := 0; n < nverts; n++ {
+
+			// derivatives of ρwl and ρwg (without Klsat and Kgsat)
+			for j := 0; j < ndim; j++ {
+				hl = RhoL*o.g[j] - o.gpl[j]
+				hg = RhoG*o.g[j] - o.gpg[j]
+				o.tl1[j] = S[n]*dklrdpl*hl + klr*(S[n]*Cl*o.g[j]-G[n][j])
+				o.tl2[j] = -S[n] * dklrdpl * hl
+				o.tg1[j] = a * S[n] * dkgrdpl * hg
+				o.tg2[j] = S[n]*(Cg/o.Mdl.RhoG0)*kgr*hg - a*S[n]*dkgrdpl*hg + a*kgr*(S[n]*Cg*o.g[j]-G[n][j])
+			}
+
+			// contributions
+			for m := 0; m < nverts; m++ {
+				o.Kll[m][n] += coef * S[m] * S[n] * (dCpldpl*plt + dCpgdpl*pgt + β1*Cpl)
+				o.Klg[m][n] += coef * S[m] * S[n] * (dCpldpg*plt + dCpgdpg*pgt + β1*Cpg)
+				o.Kgl[m][n] += coef * S[m] * S[n] * (dDpldpl*plt + dDpgdpl*pgt + β1*Dpl)
+				o.Kgg[m][n] += coef * S[m] * S[n] * (dDpldpg*plt + dDpgdpg*pgt + β1*Dpg)
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						o.Kll[m][n] -= coef * G[m][i] * o.Mdl.Klsat[i][j] * o.tl1[j]
+						o.Klg[m][n] -= coef * G[m][i] * o.Mdl.Klsat[i][j] * o.tl2[j]
+						o.Kgl[m][n] -= coef * G[m][i] * o.Mdl.Kgsat[i][j] * o.tg1[j]
+						o.Kgg[m][n] -= coef * G[m][i] * o.Mdl.Kgsat[i][j] * o.tg2[j]
+					}
+				}
+				if o.DoExtrap {
+					o.dρldpl_ex[m][n] += o.Emat[m][idx] * Cpl * S[n]
+					o.dρldpg_ex[m][n] += o.Emat[m][idx] * Cpg * S[n]
+					o.dρgdpl_ex[m][n] += o.Emat[m][idx] * Dpl * S[n]
+					o.dρgdpg_ex[m][n] += o.Emat[m][idx] * Dpg * S[n]
+				}
+			}
+			if o.DoExtrap {
+				o.ρl_ex[n] += o.Emat[n][idx] * ρl
+				o.ρg_ex[n] += o.Emat[n][idx] * ρg
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	if len(o.NatBcs) > 0 {
+		if !o.add_natbcs_to_jac(sol) {
+			return
+		}
+	}
+
+	// add to sparse matrix Kb
+	for i, I := range o.Plmap {
+		for j, J := range o.Plmap {
+			Kb.Put(I, J, o.Kll[i][j])
+		}
+		for j, J := range o.Pgmap {
+			Kb.Put(I, J, o.Klg[i][j])
+		}
+	}
+	for i, I := range o.Pgmap {
+		for j, J := range o.Plmap {
+			Kb.Put(I, J, o.Kgl[i][j])
+		}
+		for j, J := range o.Pgmap {
+			Kb.Put(I, J, o.Kgg[i][j])
+		}
+	}
+	return true
+}
+
+// Update performs (tangent) update
+func (o *ElemPP) Update(sol *Solution) (ok bool) {
+
+	// for each integration point
+	var Δpl, Δpg float64
+	for idx, _ := range o.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.Shp.CalcAtIp(o.X, o.IpsElem[idx], false), "Update") {
+			return
+		}
+
+		// compute Δpl and Δpg @ ip by means of interpolating from nodes
+		Δpl, Δpg = 0, 0
+		for m := 0; m < o.Shp.Nverts; m++ {
+			Δpl += o.Shp.S[m] * sol.ΔY[o.Plmap[m]]
+			Δpg += o.Shp.S[m] * sol.ΔY[o.Pgmap[m]]
+		}
+
+		// update state
+		if LogErr(o.Mdl.Update(o.States[idx], Δpl, Δpg, 0), "update failed") {
+			return
+		}
+	}
+	return true
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o ElemPP) Ipoints() (coords [][]float64) {
+	coords = la.MatAlloc(len(o.IpsElem), Global.Ndim)
+	for idx, ip := range o.IpsElem {
+		coords[idx] = o.Shp.IpRealCoords(o.X, ip)
+	}
+	return
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *ElemPP) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+
+	// extract slices from ivs map (may be nil)
+	ρLvals := ivs["ρL"]
+	ρGvals := ivs["ρG"]
+
+	// allocate slices of states
+	nip := len(o.IpsElem)
+	o.States = make([]*mporous.State, nip)
+	o.StatesBkp = make([]*mporous.State, nip)
+
+	// for each integration point
+	var err error
+	var ρL, ρG, pl, pg float64
+	for idx, _ := range o.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.Shp.CalcAtIp(o.X, o.IpsElem[idx], false), "SetIniIvs") {
+			return
+		}
+
+		// get densities @ ip
+		if len(ρLvals) > 0 {
+			ρL = ρLvals[idx]
+		} else {
+			ρL = o.Mdl.RhoL0
+		}
+		if len(ρGvals) > 0 {
+			ρG = ρGvals[idx]
+		} else {
+			ρG = o.Mdl.RhoG0
+		}
+
+		// compute pl and pg @ ip by means of interpolating from nodes
+		pl, pg = 0, 0
+		for m := 0; m < o.Shp.Nverts; m++ {
+			pl += o.Shp.S[m] * sol.Y[o.Plmap[m]]
+			pg += o.Shp.S[m] * sol.Y[o.Pgmap[m]]
+		}
+
+		// state initialisation
+		o.States[idx], err = o.Mdl.NewState(ρL, ρG, pl, pg, 0)
+		if LogErr(err, "SetIniIvs") {
+			return
+		}
+
+		// backup copy
+		o.StatesBkp[idx] = o.States[idx].GetCopy()
+	}
+	return true
+}
+
+// BackupIvs creates copy of internal variables
+func (o *ElemPP) BackupIvs() (ok bool) {
+	for i, s := range o.StatesBkp {
+		s.Set(o.States[i])
+	}
+	return true
+}
+
+// RestoreIvs restores internal variables from copies
+func (o *ElemPP) RestoreIvs() (ok bool) {
+	for i, s := range o.States {
+		s.Set(o.StatesBkp[i])
+	}
+	return true
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *ElemPP) Ureset(sol *Solution) (ok bool) {
+	return true
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemPP) Encode(enc Encoder) (ok bool) {
+	return !LogErr(enc.Encode(o.States), "Encode")
+}
+
+// Decode decodes internal variables
+func (o ElemPP) Decode(dec Decoder) (ok bool) {
+	if LogErr(dec.Decode(&o.States), "Decode") {
+		return
+	}
+	return o.BackupIvs()
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemPP) OutIpsData() (data []*OutIpData) {
+	for idx, ip := range o.IpsElem {
+		s := o.States[idx]
+		x := o.Shp.IpRealCoords(o.X, ip)
+		v := map[string]*float64{"pl": &s.Pl, "pg": &s.Pg, "sl": &s.Sl, "divus": &s.Divus, "ns0": &s.Ns0}
+		data = append(data, &OutIpData{o.Id(), x, v})
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ipvars computes current values @ integration points. idx == index of integration point
+func (o *ElemPP) ipvars(idx int, sol *Solution) (ok bool) {
+
+	// interpolation functions and gradients
+	if LogErr(o.Shp.CalcAtIp(o.X, o.IpsElem[idx], true), "ipvars") {
+		return
+	}
+
+	// gravity
+	ndim := Global.Ndim
+	o.g[ndim-1] = 0
+	if o.Gfcn != nil {
+		o.g[ndim-1] = -o.Gfcn.F(sol.T, nil)
+	}
+
+	// clear pl, pg and their gradients @ ip
+	o.pl, o.pg = 0, 0
+	for i := 0; i < ndim; i++ {
+		o.gpl[i], o.gpg[i] = 0, 0
+	}
+
+	// compute pl, pg and their gradients @ ip by means of interpolating from nodes
+	for m := 0; m < o.Shp.Nverts; m++ {
+		rl, rg := o.Plmap[m], o.Pgmap[m]
+		o.pl += o.Shp.S[m] * sol.Y[rl]
+		o.pg += o.Shp.S[m] * sol.Y[rg]
+		for i := 0; i < ndim; i++ {
+			o.gpl[i] += o.Shp.G[m][i] * sol.Y[rl]
+			o.gpg[i] += o.Shp.G[m][i] * sol.Y[rg]
+		}
+	}
+	return true
+}
+
+// add_natbcs_to_rhs adds natural boundary conditions to rhs
+func (o ElemPP) add_natbcs_to_rhs(fb []float64, sol *Solution) (ok bool) {
+
+	// compute surface integral
+	var qb, ρ float64
+	for _, nbc := range o.NatBcs {
+
+		// prescribed flux
+		qb = nbc.Fcn.F(sol.T, nil)
+
+		// loop over ips of face
+		for _, ipf := range o.IpsFace {
+
+			// interpolation functions and gradients @ face
+			iface := nbc.IdxFace
+			if LogErr(o.Shp.CalcAtFaceIp(o.X, ipf, iface), "add_natbcs_to_rhs") {
+				return
+			}
+			Sf := o.Shp.Sf
+			Jf := la.VecNorm(o.Shp.Fnvec)
+			coef := ipf.W * Jf
+
+			// select natural boundary condition type
+			switch nbc.Key {
+			case "ql":
+				ρ = 0
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					ρ += Sf[i] * o.ρl_ex[m]
+				}
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					fb[o.Plmap[m]] -= coef * ρ * qb * Sf[i]
+				}
+			case "qg":
+				ρ = 0
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					ρ += Sf[i] * o.ρg_ex[m]
+				}
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					fb[o.Pgmap[m]] -= coef * ρ * qb * Sf[i]
+				}
+			}
+		}
+	}
+	return true
+}
+
+// add_natbcs_to_jac adds contribution from natural boundary conditions to Jacobian
+func (o ElemPP) add_natbcs_to_jac(sol *Solution) (ok bool) {
+
+	// compute surface integral
+	nverts := o.Shp.Nverts
+	var qb float64
+	for _, nbc := range o.NatBcs {
+
+		// prescribed flux
+		qb = nbc.Fcn.F(sol.T, nil)
+
+		// loop over ips of face
+		for _, ipf := range o.IpsFace {
+
+			// interpolation functions and gradients @ face
+			iface := nbc.IdxFace
+			if LogErr(o.Shp.CalcAtFaceIp(o.X, ipf, iface), "add_natbcs_to_jac") {
+				return
+			}
+			Sf := o.Shp.Sf
+			Jf := la.VecNorm(o.Shp.Fnvec)
+			coef := ipf.W * Jf
+
+			// select natural boundary condition type
+			switch nbc.Key {
+			case "ql":
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					for n := 0; n < nverts; n++ {
+						for l, r := range o.Shp.FaceLocalV[iface] {
+							o.Kll[m][n] += coef * Sf[i] * Sf[l] * o.dρldpl_ex[r][n] * qb
+							o.Klg[m][n] += coef * Sf[i] * Sf[l] * o.dρldpg_ex[r][n] * qb
+						}
+					}
+				}
+			case "qg":
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					for n := 0; n < nverts; n++ {
+						for l, r := range o.Shp.FaceLocalV[iface] {
+							o.Kgl[m][n] += coef * Sf[i] * Sf[l] * o.dρgdpl_ex[r][n] * qb
+							o.Kgg[m][n] += coef * Sf[i] * Sf[l] * o.dρgdpg_ex[r][n] * qb
+						}
+					}
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,437 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemPT represents an element for thermo-hydraulic analyses of porous media with one-way coupling;
+// i.e. the temperature field changes the density and the viscosity of the liquid (see mporous.Model)
+//  Note: the material must be a group with the porous materials ('c', 'l' and 'p') and a thermal
+//        ('t') material; e.g. "!c:cnd1 !l:lrm1 !p:pm1 !t:thm1"
+type ElemPT struct {
+
+	// auxiliary
+	Fconds []*FaceCond // face conditions; e.g. seepage faces
+	Ctype  string      // cell type
+
+	// underlying elements
+	P *ElemP // p-element
+	T *ElemT // t-element
+
+	// scratchpad. computed @ each ip
+	Kpt [][]float64 // [np][nt] Kpt := dRpl/dθ consistent tangent matrix
+
+	// for seepage face derivatives
+	dρldθ_ex [][]float64 // [nverts][nverts] ∂ρl/∂θ extrapolated to nodes => if has qb (flux)
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["pt"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// underlying cells info
+		p_info := infogetters["p"](cellType, faceConds)
+		t_info := infogetters["t"](cellType, faceConds)
+
+		// solution variables
+		nverts := shp.GetNverts(cellType)
+		info.Dofs = make([][]string, nverts)
+		for i, dofs := range p_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+		for i, dofs := range t_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+
+		// maps
+		info.Y2F = p_info.Y2F
+		for key, val := range t_info.Y2F {
+			info.Y2F[key] = val
+		}
+
+		// t1 variables
+		info.T1vars = append([]string{}, p_info.T1vars...)
+		info.T1vars = append(info.T1vars, t_info.T1vars...)
+		return &info
+	}
+
+	// element allocator
+	eallocators["pt"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemPT
+		o.Fconds = faceConds
+		o.Ctype = cellType
+
+		// allocate p-element
+		p_elem := eallocators["p"](cellType, faceConds, cid, edat, x)
+		if LogErrCond(p_elem == nil, "cannot allocate underlying p-element") {
+			return nil
+		}
+		o.P = p_elem.(*ElemP)
+
+		// make sure t-element uses the same number of integration points than p-element
+		edat.Nip = len(o.P.IpsElem)
+
+		// allocate t-element
+		t_elem := eallocators["t"](cellType, faceConds, cid, edat, x)
+		if LogErrCond(t_elem == nil, "cannot allocate underlying t-element") {
+			return nil
+		}
+		o.T = t_elem.(*ElemT)
+
+		// activate thermal terms in p-element
+		o.P.θt = make([]float64, len(o.P.IpsElem))
+
+		// scratchpad. computed @ each ip
+		o.Kpt = la.MatAlloc(o.P.Np, o.T.Nt)
+
+		// seepage terms
+		if o.P.DoExtrap {
+			o.dρldθ_ex = la.MatAlloc(o.P.Shp.Nverts, o.T.Nt)
+		}
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemPT) Id() int { return o.P.Id() }
+
+// SetEqs set equations
+func (o *ElemPT) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+
+	// p: equations
+	p_info := infogetters["p"](o.Ctype, o.Fconds)
+	nverts := len(p_info.Dofs)
+	p_eqs := make([][]int, nverts)
+	t_eqs := make([][]int, nverts)
+	for i := 0; i < nverts; i++ {
+		nkeys := len(p_info.Dofs[i])
+		p_eqs[i] = make([]int, nkeys)
+		for j := 0; j < nkeys; j++ {
+			p_eqs[i][j] = eqs[i][j]
+		}
+
+		// t: equations
+		t_eqs[i] = []int{eqs[i][nkeys]}
+	}
+
+	// set equations
+	if !o.P.SetEqs(p_eqs, nil) {
+		return
+	}
+	return o.T.SetEqs(t_eqs, nil)
+}
+
+// SetEleConds set element conditions
+func (o *ElemPT) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	if !o.P.SetEleConds(key, f, extra) {
+		return
+	}
+	return o.T.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ElemPT) InterpStarVars(sol *Solution) (ok bool) {
+	if !o.P.InterpStarVars(sol) {
+		return
+	}
+	return o.T.InterpStarVars(sol)
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemPT) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+	if !o.set_rates(sol) {
+		return
+	}
+	if !o.P.AddToRhs(fb, sol) {
+		return
+	}
+	return o.T.AddToRhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemPT) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// Kpp, Kpf, Kfp, Kff and Ktt
+	if !o.set_rates(sol) {
+		return
+	}
+	if !o.P.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+	if !o.T.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// clear matrices
+	la.MatFill(o.Kpt, 0)
+	if o.P.DoExtrap {
+		la.MatFill(o.dρldθ_ex, 0)
+	}
+
+	// for each integration point
+	β1 := Global.DynCoefs.β1
+	ndim := Global.Ndim
+	nverts := o.P.Shp.Nverts
+	dρLdθ := -o.P.Mdl.BetaL * o.P.Mdl.RhoL0
+	var coef, plt, klr, vr, dvrdθ, RhoL, Cθ, dCpldθ float64
+	var err error
+	for idx, ip := range o.P.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.P.ipvars(idx, sol) {
+			return
+		}
+		coef = o.P.Shp.J * ip.W
+		S := o.P.Shp.S
+		G := o.P.Shp.G
+
+		// tpm variables; note that ∂Cpl/∂θ = ∂Cθ/∂pl
+		s := o.P.States[idx]
+		plt = β1*o.P.pl - o.P.ψl[idx]
+		klr = o.P.Mdl.Cnd.Klr(s.Sl)
+		RhoL = s.RhoL
+		vr, dvrdθ = o.P.Mdl.ViscRatio(s.Temp)
+		Cθ, dCpldθ, err = s.Tvars(o.P.Mdl)
+		if LogErr(err, "calc of thermal tpm variables failed") {
+			return
+		}
+
+		// ∂(ρl・wl)/∂θ without Sn
+		for j := 0; j < ndim; j++ {
+			o.P.tmp[j] = klr * (dvrdθ*(RhoL*o.P.g[j]-o.P.gpl[j]) + vr*dρLdθ*o.P.g[j])
+		}
+
+		// Kpt := ∂Rpl^m/∂θ^n
+		for m := 0; m < nverts; m++ {
+			for n := 0; n < nverts; n++ {
+				o.Kpt[m][n] += coef * S[m] * S[n] * (dCpldθ*plt + β1*Cθ)
+				for i := 0; i < ndim; i++ {
+					for j := 0; j < ndim; j++ {
+						o.Kpt[m][n] -= coef * G[m][i] * o.P.Mdl.Klsat[i][j] * o.P.tmp[j] * S[n]
+					}
+				}
+				if o.P.DoExtrap {
+					o.dρldθ_ex[m][n] += o.P.Emat[m][idx] * Cθ * S[n]
+				}
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	if o.P.HasSeep {
+		if !o.add_natbcs_to_jac(sol) {
+			return
+		}
+	}
+
+	// add Kpt to sparse matrix Kb
+	//    _             _
+	//   |  Kpp Kpf Kpt  |
+	//   |  Kfp Kff  0   |
+	//   |_  0   0  Ktt _|
+	//
+	for i, I := range o.P.Pmap {
+		for j, J := range o.T.Tmap {
+			Kb.Put(I, J, o.Kpt[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+func (o *ElemPT) Update(sol *Solution) (ok bool) {
+
+	// p: update states with Δpl
+	if !o.P.Update(sol) {
+		return
+	}
+
+	// for each integration point
+	var Δθ float64
+	for idx, ip := range o.P.IpsElem {
+
+		// interpolation functions
+		if LogErr(o.T.Shp.CalcAtIp(o.T.X, ip, false), "Update") {
+			return
+		}
+
+		// compute Δθ @ ip
+		Δθ = 0
+		for m := 0; m < o.T.Shp.Nverts; m++ {
+			Δθ += o.T.Shp.S[m] * sol.ΔY[o.T.Tmap[m]]
+		}
+
+		// p: update temperature and density of liquid
+		o.P.Mdl.UpdateTemp(o.P.States[idx], Δθ)
+	}
+	return o.T.Update(sol)
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o ElemPT) Ipoints() (coords [][]float64) {
+	return o.P.Ipoints()
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+//  Note: the temperature of states is initialised with the values in sol
+func (o *ElemPT) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+	if !o.P.SetIniIvs(sol, ivs) {
+		return
+	}
+	var θ float64
+	for idx, ip := range o.P.IpsElem {
+		if LogErr(o.T.Shp.CalcAtIp(o.T.X, ip, false), "SetIniIvs") {
+			return
+		}
+		θ = 0
+		for m := 0; m < o.T.Shp.Nverts; m++ {
+			θ += o.T.Shp.S[m] * sol.Y[o.T.Tmap[m]]
+		}
+		o.P.States[idx].Temp = θ
+		o.P.StatesBkp[idx].Temp = θ
+	}
+	return true
+}
+
+// BackupIvs create copy of internal variables
+func (o *ElemPT) BackupIvs() (ok bool) {
+	return o.P.BackupIvs()
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *ElemPT) RestoreIvs() (ok bool) {
+	return o.P.RestoreIvs()
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *ElemPT) Ureset(sol *Solution) (ok bool) {
+	return o.P.Ureset(sol)
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemPT) Encode(enc Encoder) (ok bool) {
+	return o.P.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o ElemPT) Decode(dec Decoder) (ok bool) {
+	return o.P.Decode(dec)
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemPT) OutIpsData() (data []*OutIpData) {
+	data = o.P.OutIpsData()
+	chk.IntAssert(len(data), len(o.P.States))
+	for i, d := range data {
+		d.V["temp"] = &o.P.States[i].Temp
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// set_rates computes the rates of temperature @ integration points of p-element
+func (o *ElemPT) set_rates(sol *Solution) (ok bool) {
+	for idx, _ := range o.P.IpsElem {
+		if !o.T.ipvars(idx, sol) {
+			return
+		}
+		o.P.θt[idx] = o.T.rate(idx)
+	}
+	return true
+}
+
+// add_natbcs_to_jac adds contribution from natural boundary conditions to Jacobian
+func (o ElemPT) add_natbcs_to_jac(sol *Solution) (ok bool) {
+
+	// compute surface integral
+	nverts := o.T.Nt
+	var shift float64
+	var pl, fl, plmax, g, σ, rmp float64
+	for idx, nbc := range o.P.NatBcs {
+
+		// plmax shift or qlb
+		shift = nbc.Fcn.F(sol.T, nil)
+
+		// loop over ips of face
+		for jdx, ipf := range o.P.IpsFace {
+
+			// interpolation functions and gradients @ face
+			iface := nbc.IdxFace
+			if LogErr(o.P.Shp.CalcAtFaceIp(o.P.X, ipf, iface), "pt: add_natbcs_to_jac") {
+				return
+			}
+			Sf := o.P.Shp.Sf
+			Jf := la.VecNorm(o.P.Shp.Fnvec)
+			coef := ipf.W * Jf
+
+			// select natural boundary condition type
+			switch nbc.Key {
+			case "seep":
+
+				// variables extrapolated to face
+				_, pl, fl = o.P.fipvars(iface, sol)
+				plmax = o.P.Plmax[idx][jdx] - shift
+				if plmax < 0 {
+					plmax = 0
+				}
+
+				// compute derivatives
+				g = pl - plmax
+				rmp = o.P.ramp(fl + o.P.κ*g)
+				for i, m := range o.P.Shp.FaceLocalV[iface] {
+					for n := 0; n < nverts; n++ {
+						for l, r := range o.P.Shp.FaceLocalV[iface] {
+							o.Kpt[m][n] += coef * Sf[i] * Sf[l] * o.dρldθ_ex[r][n] * rmp
+						}
+					}
+				}
+			case "atm":
+
+				// variables extrapolated to face
+				_, pl, fl = o.P.fipvars(iface, sol)
+
+				// compute derivatives
+				g, σ = o.P.atm_gap(idx, pl, shift)
+				rmp = 0
+				if σ != 0 {
+					rmp = o.P.ramp(fl + o.P.κ*g)
+				}
+				for i, m := range o.P.Shp.FaceLocalV[iface] {
+					for n := 0; n < nverts; n++ {
+						for l, r := range o.P.Shp.FaceLocalV[iface] {
+							o.Kpt[m][n] += coef * Sf[i] * Sf[l] * o.dρldθ_ex[r][n] * (shift + σ*rmp)
+						}
+					}
+				}
+			}
+		}
+	}
+	return true
+}
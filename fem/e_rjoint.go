@@ -58,9 +58,9 @@ type Rjoint struct {
 	Ny   int           // total number of dofs == rod.Nu + sld.Nu
 
 	// essential
-	Rod *Rod            // rod element
-	Sld *ElemU          // solid element
-	Mdl msolid.RjointM1 // material model
+	Rod *Rod               // rod element
+	Sld *ElemU             // solid element
+	Mdl msolid.RjointModel // material model
 
 	// parameters
 	h  float64 // perimeter of rod element; Eq (34)
@@ -68,7 +68,8 @@ type Rjoint struct {
 	k2 float64 // lateral stiffness; Eq (37)
 
 	// optional data
-	Ncns bool // use non-consistent model
+	Ncns   bool // use non-consistent model
+	Debond bool // no shear transfer (e.g. free length of anchors); only lateral springs are active
 
 	// shape functions evaluations and extrapolator matrices
 	Nmat [][]float64 // [sldNn][rodNn] shape functions of solids @ [N]odes of rod element
@@ -157,6 +158,10 @@ func (o *Rjoint) Connect(cid2elem []Elem, c *inp.Cell) (nnzK int, ok bool) {
 	}
 
 	// initialise model
+	o.Mdl = msolid.GetRjointModel(Global.Sim.Data.FnameKey, matname, matdata.Model, false)
+	if LogErrCond(o.Mdl == nil, "cannot find rod-joint model named %s\n", matdata.Model) {
+		return
+	}
 	if LogErr(o.Mdl.Init(matdata.Prms), "cannot initialise model for Rjoint element") {
 		return
 	}
@@ -485,9 +490,12 @@ func (o *Rjoint) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool)
 		coef = ip.W * rodH.J
 
 		// model derivatives
-		DτDω, DτDσc, err = o.Mdl.CalcD(o.States[idx], firstIt)
-		if LogErr(err, "AddToKb") {
-			return
+		DτDω, DτDσc = 0, 0
+		if !o.Debond {
+			DτDω, DτDσc, err = o.Mdl.CalcD(o.States[idx], firstIt)
+			if LogErr(err, "AddToKb") {
+				return
+			}
 		}
 
 		// compute derivatives
@@ -539,7 +547,7 @@ func (o *Rjoint) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool)
 							Dp2Du_nj += o.Pmat[m][idx] * o.T2[idx][i] * o.DσNoDu[m][i][n][j]
 						}
 					}
-					DσcDu_nj = (Dp1Du_nj + Dp2Du_nj) / 2.0
+					DσcDu_nj = -(Dp1Du_nj + Dp2Du_nj) / 2.0 // σc = -(p1 + p2) / 2; see Update
 				}
 
 				// ∂wb/∂us Eq (A.5)
@@ -706,8 +714,10 @@ func (o *Rjoint) Update(sol *Solution) (ok bool) {
 		}
 
 		// update model
-		if LogErr(o.Mdl.Update(o.States[idx], σc, Δwb0), "Update") {
-			return
+		if !o.Debond {
+			if LogErr(o.Mdl.Update(o.States[idx], σc, Δwb0), "Update") {
+				return
+			}
 		}
 		o.States[idx].Phi[0] += o.k1 * Δwb1 // qn1
 		o.States[idx].Phi[1] += o.k2 * Δwb2 // qn2
@@ -5,6 +5,8 @@
 package fem
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/msolid"
 	"github.com/cpmech/gofem/shp"
@@ -15,6 +17,16 @@ import (
 )
 
 // Rod represents a structural rod element (for only axial loads)
+//  Note: a prestressing axial force P(t) can be applied during a stage by means of the element
+//        condition "prestress". P(t) is converted into an initial (pre-) strain P/(E A) passed to
+//        the material model; thus the actual axial force depends on the stiffness of the
+//        surrounding structure. P(t) is applied in addition to the axial force at the beginning
+//        of the stage; e.g. a constant function applies the full force in the first time step
+//  Note: co-rotational (geometrically nonlinear) kinematics is selected with "!corot:1" in the
+//        extra keycodes of elements (lin2 only). The strain is then ε = (l - L0) / L0 where l is
+//        the current length and the geometric stiffness (A σ / l) (I - e⊗e) is added to K,
+//        where e is the current unit vector along the rod. Thus large rotations, cable sag and
+//        snap-through (under displacement control) can be analysed
 type Rod struct {
 
 	// basic data
@@ -25,6 +37,11 @@ type Rod struct {
 
 	// parameters
 	A float64 // cross-sectional area
+	E float64 // Young's modulus (for computing pre-strains)
+
+	// co-rotational kinematics
+	Corot bool    // co-rotational formulation (large displacements and rotations)
+	L0    float64 // initial length
 
 	// variables for dynamics
 	Rho  float64  // density of solids
@@ -39,7 +56,10 @@ type Rod struct {
 	Rus []float64   // residual: Rus = fi - fx
 
 	// problem variables
-	Umap []int // assembly map (location array/element equations)
+	Umap   []int    // assembly map (location array/element equations)
+	Pfcn   fun.Func // prestressing force function
+	Pre    float64  // prestressing force applied up to the last converged state
+	PreNew float64  // prestressing force applied up to the current state
 
 	// material model and internal variables
 	Model     msolid.OnedSolid
@@ -51,6 +71,7 @@ type Rod struct {
 	us   []float64 // [ndim] displacements @ ip
 	fi   []float64 // [nu] internal forces
 	ue   []float64 // local u vector
+	e    []float64 // [ndim] current unit vector along rod (co-rotational)
 }
 
 // register element
@@ -117,6 +138,8 @@ func init() {
 			switch p.N {
 			case "A":
 				o.A = p.V
+			case "E":
+				o.E = p.V
 			case "rho":
 				o.Rho = p.V
 			}
@@ -133,6 +156,19 @@ func init() {
 		}
 		nip = len(o.IpsElem)
 
+		// co-rotational kinematics
+		o.Corot = GetCorotFlag(edat.Extra)
+		if o.Corot {
+			if LogErrCond(o.Shp.Type != "lin2", "rod: cid=%d: co-rotational kinematics requires lin2 cells. %q is incorrect\n", cid, o.Shp.Type) {
+				return nil
+			}
+			for i := 0; i < ndim; i++ {
+				o.L0 += (o.X[i][1] - o.X[i][0]) * (o.X[i][1] - o.X[i][0])
+			}
+			o.L0 = math.Sqrt(o.L0)
+			o.e = make([]float64, ndim)
+		}
+
 		// scratchpad. computed @ each ip
 		o.K = la.MatAlloc(o.Nu, o.Nu)
 		o.M = la.MatAlloc(o.Nu, o.Nu)
@@ -181,8 +217,14 @@ func (o *Rod) InterpStarVars(sol *Solution) (ok bool) {
 
 // SetEleConds set element conditions
 func (o *Rod) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
-	if key == "g" {
+	switch key {
+	case "g":
 		o.Gfcn = f
+	case "prestress":
+		if LogErrCond(o.E <= 0 || o.A <= 0, "rod: E and A must be positive for prestressing. E=%g and A=%g are incorrect\n", o.E, o.A) {
+			return
+		}
+		o.Pfcn = f
 	}
 	return true
 }
@@ -190,6 +232,24 @@ func (o *Rod) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
 // adds -R to global residual vector fb
 func (o Rod) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 
+	// prestressing force not yet passed to the material model (first iteration of time step)
+	Δσpre := o.prestress_pending(sol.T)
+
+	// co-rotational
+	if o.Corot {
+		N := o.corot_force() + o.A*Δσpre
+		for i, I := range o.Umap {
+			o.ue[i] = sol.Y[I]
+		}
+		o.corot_geom(o.ue)
+		ndim := Global.Ndim
+		for i := 0; i < ndim; i++ {
+			fb[o.Umap[i]] += N * o.e[i]      // -fi
+			fb[o.Umap[i+ndim]] -= N * o.e[i] // -fi
+		}
+		return true
+	}
+
 	// for each integration point
 	nverts := o.Shp.Nverts
 	ndim := Global.Ndim
@@ -204,7 +264,7 @@ func (o Rod) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 		coef := ip.W
 		Jvec := o.Shp.Jvec3d
 		G := o.Shp.Gvec
-		σ := o.States[idx].Sig
+		σ := o.States[idx].Sig + Δσpre
 
 		// update fb with internal forces
 		for m := 0; m < nverts; m++ {
@@ -224,6 +284,19 @@ func (o Rod) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 	la.MatFill(o.K, 0)
 	la.MatFill(o.M, 0)
 
+	// co-rotational
+	if o.Corot {
+		if !o.corot_K(sol, firstIt) {
+			return
+		}
+		for i, I := range o.Umap {
+			for j, J := range o.Umap {
+				Kb.Put(I, J, o.K[i][j])
+			}
+		}
+		return true
+	}
+
 	// for each integration point
 	nverts := o.Shp.Nverts
 	ndim := Global.Ndim
@@ -270,6 +343,32 @@ func (o Rod) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 // Update perform (tangent) update
 func (o *Rod) Update(sol *Solution) (ok bool) {
 
+	// pre-strain increment due to prestressing
+	Δεpre := 0.0
+	if o.Pfcn != nil {
+		o.PreNew = o.Pfcn.F(sol.T, nil)
+		Δεpre = (o.PreNew - o.Pre) / (o.E * o.A)
+	}
+
+	// co-rotational: strain increment from the current and last converged lengths
+	if o.Corot {
+		for i, I := range o.Umap {
+			o.ue[i] = sol.Y[I] - sol.ΔY[I]
+		}
+		l0 := o.corot_geom(o.ue)
+		for i, I := range o.Umap {
+			o.ue[i] = sol.Y[I]
+		}
+		l := o.corot_geom(o.ue)
+		Δε := (l - l0) / o.L0
+		for _, s := range o.States {
+			if LogErr(o.Model.Update(s, 0.0, Δε+Δεpre), "Update") {
+				return
+			}
+		}
+		return true
+	}
+
 	// for each integration point
 	nverts := o.Shp.Nverts
 	ndim := Global.Ndim
@@ -295,7 +394,7 @@ func (o *Rod) Update(sol *Solution) (ok bool) {
 		}
 
 		// call model update => update stresses
-		if LogErr(o.Model.Update(o.States[idx], 0.0, Δε), "Update") {
+		if LogErr(o.Model.Update(o.States[idx], 0.0, Δε+Δεpre), "Update") {
 			return
 		}
 	}
@@ -344,6 +443,7 @@ func (o *Rod) SetIvs(zvars map[string][]float64) (ok bool) {
 
 // BackupIvs create copy of internal variables
 func (o *Rod) BackupIvs() (ok bool) {
+	o.Pre = o.PreNew
 	for i, s := range o.StatesBkp {
 		s.Set(o.States[i])
 	}
@@ -413,3 +513,65 @@ func (o *Rod) ipvars(idx int, sol *Solution) (ok bool) {
 	}
 	return true
 }
+
+// prestress_pending returns the increment of axial stress corresponding to the prestressing force
+// that has not been passed to the material model yet
+//  Note: the pre-strain is applied in Update; thus, at the first iteration of each time step, the
+//        increment of prestressing force must be added to the residual such that the Newton loop
+//        does not stop before the prestress is applied
+func (o Rod) prestress_pending(t float64) float64 {
+	if o.Pfcn == nil {
+		return 0
+	}
+	return (o.Pfcn.F(t, nil) - o.PreNew) / o.A
+}
+
+// corot_geom computes the current length and unit vector e along the rod for given (local) vector
+// of nodal displacements ue
+func (o *Rod) corot_geom(ue []float64) (l float64) {
+	ndim := Global.Ndim
+	for i := 0; i < ndim; i++ {
+		o.e[i] = o.X[i][1] + ue[i+ndim] - o.X[i][0] - ue[i]
+		l += o.e[i] * o.e[i]
+	}
+	l = math.Sqrt(l)
+	for i := 0; i < ndim; i++ {
+		o.e[i] /= l
+	}
+	return
+}
+
+// corot_force computes the axial force N = A σ (average over integration points)
+func (o Rod) corot_force() (N float64) {
+	for idx, ip := range o.IpsElem {
+		N += ip.W * o.A * o.States[idx].Sig / 2.0
+	}
+	return
+}
+
+// corot_K computes the tangent stiffness matrix of the co-rotational formulation:
+//  K = [[k, -k], [-k, k]]  with  k = (A E / L0) e⊗e + (N / l) (I - e⊗e)
+func (o *Rod) corot_K(sol *Solution, firstIt bool) (ok bool) {
+	var AE float64
+	for idx, ip := range o.IpsElem {
+		E, err := o.Model.CalcD(o.States[idx], firstIt)
+		if LogErr(err, "AddToKb") {
+			return
+		}
+		AE += ip.W * o.A * E / 2.0
+	}
+	N := o.corot_force()
+	for i, I := range o.Umap {
+		o.ue[i] = sol.Y[I]
+	}
+	l := o.corot_geom(o.ue)
+	ndim := Global.Ndim
+	for i := 0; i < ndim; i++ {
+		for j := 0; j < ndim; j++ {
+			k := (AE/o.L0-N/l)*o.e[i]*o.e[j] + N/l*delta(i, j)
+			o.K[i][j], o.K[i][j+ndim] = k, -k
+			o.K[i+ndim][j], o.K[i+ndim][j+ndim] = -k, k
+		}
+	}
+	return true
+}
@@ -0,0 +1,879 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/msolid"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// Shell represents a flat (facet) Reissner-Mindlin plate/shell element with six DOFs per node
+// (ux, uy, uz, rx, ry, rz) based on the MITC4 (qua4) and MITC9 (qua9) formulations
+//  Note: extra keycodes: "!thick", "!nlay" and "!drill"; element conditions: "g" and "qn".
+//        Translations may be shared with "u" elements. See shell_reskeys
+type Shell struct {
+
+	// basic data
+	Cid int         // cell/element id
+	X   [][]float64 // [ndim][nverts] matrix of nodal coordinates
+	Shp *shp.Shape  // shape structure
+	Nu  int         // total number of unknowns == 6 * nverts
+
+	// parameters
+	Thick float64 // thickness
+	Nlay  int     // number of layers
+	G     float64 // shear modulus
+	Gs    float64 // transverse shear modulus (including shear correction factor)
+	Drill float64 // coefficient of drilling stiffness (× G)
+	Rho   float64 // density
+
+	// geometry
+	R  [][]float64 // [3][3] rotation matrix with rows {e0, e1, e2}
+	Xl [][]float64 // [2][nverts] local coordinates of nodes
+	T  [][]float64 // [nu][nu] global-to-local transformation matrix: ul = T * u
+
+	// integration points
+	IpsElem []*shp.Ipoint // in-plane integration points
+	Zs      []float64     // [nz] coordinates of integration points along thickness
+	Wz      []float64     // [nz] weights of integration points along thickness
+	Xip     [][]float64   // [nip][ndim] real coordinates of integration points
+	Sip     [][]float64   // [nip][nverts] shape functions at integration points
+	Aip     []float64     // [nip] area of integration points: J * W
+
+	// strain-displacement matrices (local system)
+	Bm [][][]float64 // [nip][3][nu] membrane strains {εxx, εyy, γxy}
+	Bb [][][]float64 // [nip][3][nu] curvatures {κxx, κyy, κxy}
+	Bs [][][]float64 // [nip][2][nu] transverse shear strains {γxz, γyz} (MITC)
+	Bd [][]float64   // [nip][nu] drilling constraint
+
+	// material model and internal variables
+	Model     msolid.Model
+	MdlSmall  msolid.Small
+	States    [][]*msolid.State // [nip][nz] states
+	StatesBkp [][]*msolid.State // [nip][nz] backup states
+	Res       [][]float64       // [nip][nres] stress resultants. see shell_reskeys
+
+	// conditions
+	Gfcn fun.Func // gravity function
+	Qfcn fun.Func // pressure function
+
+	// vectors and matrices
+	Kl   [][]float64 // [nu][nu] local K matrix
+	K    [][]float64 // [nu][nu] global K matrix
+	M    [][]float64 // [nu][nu] global M matrix
+	Umap []int       // assembly map (location array/element equations)
+
+	// scratchpad. computed @ each ip
+	D   [][]float64 // [4][4] consistent modulus (Mandel)
+	C   [][]float64 // [3][3] in-plane modulus (engineering shear strain)
+	Bz  [][]float64 // [3][nu] in-plane strains at z: Bm + z * Bb
+	ue  []float64   // [nu] global displacements
+	ζe  []float64   // [nu] global ζ* vector
+	ul  []float64   // [nu] local displacements
+	Δul []float64   // [nu] local increments of displacements
+	fi  []float64   // [nu] local internal forces
+	fe  []float64   // [nu] global internal forces
+	σ   []float64   // [3] in-plane stresses {σxx, σyy, σxy}
+	ε   []float64   // [4] strains (Mandel)
+	Δε  []float64   // [4] increments of strains (Mandel)
+}
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["shell"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// solution variables
+		nverts := shp.GetNverts(cellType)
+		ykeys := []string{"ux", "uy", "uz", "rx", "ry", "rz"}
+		info.Dofs = make([][]string, nverts)
+		for m := 0; m < nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"ux": "fx", "uy": "fy", "uz": "fz", "rx": "mx", "ry": "my", "rz": "mz"}
+
+		// t1 and t2 variables
+		info.T2vars = ykeys
+		return &info
+	}
+
+	// element allocator
+	eallocators["shell"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// check
+		if LogErrCond(Global.Ndim != 3, "shell: cid=%d: shell elements require 3D meshes; i.e. at least one vertex with z ≠ 0\n", cid) {
+			return nil
+		}
+		if LogErrCond(cellType != "qua4" && cellType != "qua9", "shell: cid=%d: cell type must be qua4 (MITC4) or qua9 (MITC9). %q is incorrect\n", cid, cellType) {
+			return nil
+		}
+
+		// basic data
+		var o Shell
+		o.Cid = cid
+		o.X = x
+		o.Shp = shp.Get(cellType)
+		nverts := o.Shp.Nverts
+		o.Nu = 6 * nverts
+
+		// flags: the thickness is divided into nlay layers, each one with 2 Gauss points; the drilling
+		// stiffness (drill * G) couples θz to the in-plane rotation ½(∂v/∂x - ∂u/∂y)
+		o.Nlay = 1
+		if val, found := io.Keycode(edat.Extra, "nlay"); found {
+			o.Nlay = io.Atoi(val)
+		}
+		if val, found := io.Keycode(edat.Extra, "thick"); found {
+			o.Thick = io.Atof(val)
+		}
+		o.Drill = 1e-3
+		if val, found := io.Keycode(edat.Extra, "drill"); found {
+			o.Drill = io.Atof(val)
+		}
+		if LogErrCond(o.Thick <= 0 || o.Nlay < 1 || o.Drill < 0, "shell: cid=%d: thick and nlay must be positive and drill must be non-negative. thick=%g, nlay=%d and drill=%g are incorrect\n", cid, o.Thick, o.Nlay, o.Drill) {
+			return nil
+		}
+
+		// integration points
+		var err error
+		o.IpsElem, err = shp.GetIps(cellType, edat.Nip)
+		if LogErr(err, io.Sf("shell: cid=%d: cannot get integration points with nip=%d", cid, edat.Nip)) {
+			return nil
+		}
+		h := o.Thick / float64(o.Nlay)
+		for i := 0; i < o.Nlay; i++ {
+			zc := -o.Thick/2.0 + (float64(i)+0.5)*h
+			d := h / (2.0 * math.Sqrt(3.0))
+			o.Zs = append(o.Zs, zc-d, zc+d)
+			o.Wz = append(o.Wz, h/2.0, h/2.0)
+		}
+
+		// model
+		if !o.init_model(edat.Mat) {
+			return nil
+		}
+
+		// geometry and strain-displacement matrices
+		if !o.calc_T() {
+			return nil
+		}
+		if !o.calc_B() {
+			return nil
+		}
+
+		// vectors and matrices
+		o.Kl = la.MatAlloc(o.Nu, o.Nu)
+		o.K = la.MatAlloc(o.Nu, o.Nu)
+		o.M = la.MatAlloc(o.Nu, o.Nu)
+		o.calc_M()
+
+		// scratchpad. computed @ each ip
+		o.D = la.MatAlloc(4, 4)
+		o.C = la.MatAlloc(3, 3)
+		o.Bz = la.MatAlloc(3, o.Nu)
+		o.ue = make([]float64, o.Nu)
+		o.ζe = make([]float64, o.Nu)
+		o.ul = make([]float64, o.Nu)
+		o.Δul = make([]float64, o.Nu)
+		o.fi = make([]float64, o.Nu)
+		o.fe = make([]float64, o.Nu)
+		o.σ = make([]float64, 3)
+		o.ε = make([]float64, 4)
+		o.Δε = make([]float64, 4)
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o Shell) Id() int { return o.Cid }
+
+// SetEqs set equations
+func (o *Shell) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+	o.Umap = make([]int, o.Nu)
+	for m := 0; m < o.Shp.Nverts; m++ {
+		for i := 0; i < 6; i++ {
+			o.Umap[i+m*6] = eqs[m][i]
+		}
+	}
+	return true
+}
+
+// SetEleConds set element conditions
+//  Note: "g" is the gravity (along -z; dynamics) and "qn" is the pressure along e2
+func (o *Shell) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	switch key {
+	case "g":
+		o.Gfcn = f
+	case "qn":
+		o.Qfcn = f
+	default:
+		LogErrCond(true, "cannot handle boundary condition named %q", key)
+		return false
+	}
+	return true
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *Shell) InterpStarVars(sol *Solution) (ok bool) {
+
+	// steady
+	if Global.Sim.Data.Steady {
+		return true
+	}
+
+	// dynamics
+	for i, I := range o.Umap {
+		o.ζe[i] = sol.Zet[I]
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o *Shell) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+
+	// local displacements
+	for i, I := range o.Umap {
+		o.ue[i] = sol.Y[I]
+	}
+	la.MatVecMul(o.ul, 1, o.T, o.ue)
+
+	// internal forces (local)
+	la.VecFill(o.fi, 0)
+	for idx := range o.IpsElem {
+		dA := o.Aip[idx]
+
+		// membrane and bending
+		for k, z := range o.Zs {
+			o.calc_Bz(idx, z)
+			s := o.States[idx][k].Sig
+			o.σ[0], o.σ[1], o.σ[2] = s[0], s[1], s[3]/SQ2
+			la.MatTrVecMulAdd(o.fi, dA*o.Wz[k], o.Bz, o.σ) // fi += dA * wz * tr(Bz) * σ
+		}
+
+		// transverse shear and drilling
+		coef := dA * o.Gs * o.Thick
+		for _, row := range o.Bs[idx] {
+			shell_addb(o.fi, coef*la.VecDot(row, o.ul), row)
+		}
+		coef = dA * o.Drill * o.G * o.Thick
+		shell_addb(o.fi, coef*la.VecDot(o.Bd[idx], o.ul), o.Bd[idx])
+
+		// pressure
+		if o.Qfcn != nil {
+			q := o.Qfcn.F(sol.T, o.Xip[idx])
+			for m, S := range o.Sip[idx] {
+				o.fi[2+m*6] -= q * S * dA
+			}
+		}
+	}
+
+	// global internal forces
+	la.MatTrVecMul(o.fe, 1, o.T, o.fi) // fe := trans(T) * fi
+
+	// dynamics
+	if !Global.Sim.Data.Steady {
+		dc := Global.DynCoefs
+		for i := 0; i < o.Nu; i++ {
+			for j := 0; j < o.Nu; j++ {
+				o.fe[i] += o.M[i][j] * (dc.α1*o.ue[j] - o.ζe[j])
+			}
+		}
+		if o.Gfcn != nil {
+			for idx := range o.IpsElem {
+				g := o.Gfcn.F(sol.T, o.Xip[idx])
+				for m, S := range o.Sip[idx] {
+					o.fe[2+m*6] += o.Rho * o.Thick * g * S * o.Aip[idx]
+				}
+			}
+		}
+	}
+
+	// add to fb
+	for i, I := range o.Umap {
+		fb[I] -= o.fe[i]
+	}
+	return true
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o *Shell) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// local stiffness
+	la.MatFill(o.Kl, 0)
+	for idx := range o.IpsElem {
+		dA := o.Aip[idx]
+
+		// membrane and bending
+		for k, z := range o.Zs {
+			if LogErr(o.MdlSmall.CalcD(o.D, o.States[idx][k], firstIt), "AddToKb") {
+				return
+			}
+			o.calc_C()
+			o.calc_Bz(idx, z)
+			shell_addBtCB(o.Kl, dA*o.Wz[k], o.Bz, o.C)
+		}
+
+		// transverse shear and drilling
+		coef := dA * o.Gs * o.Thick
+		for _, row := range o.Bs[idx] {
+			shell_addbtb(o.Kl, coef, row)
+		}
+		shell_addbtb(o.Kl, dA*o.Drill*o.G*o.Thick, o.Bd[idx])
+	}
+	la.MatTrMul3(o.K, 1, o.T, o.Kl, o.T) // K := 1 * trans(T) * Kl * T
+
+	// add to Kb
+	if Global.Sim.Data.Steady {
+		for i, I := range o.Umap {
+			for j, J := range o.Umap {
+				Kb.Put(I, J, o.K[i][j])
+			}
+		}
+		return true
+	}
+	dc := Global.DynCoefs
+	for i, I := range o.Umap {
+		for j, J := range o.Umap {
+			Kb.Put(I, J, o.M[i][j]*dc.α1+o.K[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+//  Note: it also computes the stress resultants
+func (o *Shell) Update(sol *Solution) (ok bool) {
+
+	// local displacements and increments
+	for i, I := range o.Umap {
+		o.ue[i] = sol.Y[I]
+	}
+	la.MatVecMul(o.ul, 1, o.T, o.ue)
+	for i, I := range o.Umap {
+		o.ue[i] = sol.ΔY[I]
+	}
+	la.MatVecMul(o.Δul, 1, o.T, o.ue)
+
+	// for each integration point
+	for idx := range o.IpsElem {
+		for k, z := range o.Zs {
+			o.calc_Bz(idx, z)
+			o.ε[0], o.ε[1], o.ε[3] = la.VecDot(o.Bz[0], o.ul), la.VecDot(o.Bz[1], o.ul), la.VecDot(o.Bz[2], o.ul)/SQ2
+			o.Δε[0], o.Δε[1], o.Δε[3] = la.VecDot(o.Bz[0], o.Δul), la.VecDot(o.Bz[1], o.Δul), la.VecDot(o.Bz[2], o.Δul)/SQ2
+			if LogErr(o.MdlSmall.Update(o.States[idx][k], o.ε, o.Δε), "Update") {
+				return
+			}
+		}
+	}
+	o.calc_resultants()
+	return true
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+//  Note: these are the points on the mid-surface
+func (o Shell) Ipoints() (coords [][]float64) {
+	coords = la.MatAlloc(len(o.IpsElem), Global.Ndim)
+	for idx := range o.IpsElem {
+		copy(coords[idx], o.Xip[idx])
+	}
+	return
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+//  Note: initial stresses are not considered
+func (o *Shell) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+	nip := len(o.IpsElem)
+	nz := len(o.Zs)
+	o.States = make([][]*msolid.State, nip)
+	o.StatesBkp = make([][]*msolid.State, nip)
+	o.Res = la.MatAlloc(nip, len(shell_reskeys()))
+	for i := 0; i < nip; i++ {
+		o.States[i] = make([]*msolid.State, nz)
+		o.StatesBkp[i] = make([]*msolid.State, nz)
+		for k := 0; k < nz; k++ {
+			var err error
+			o.States[i][k], err = o.Model.InitIntVars()
+			if LogErr(err, "SetIniIvs") {
+				return
+			}
+			o.StatesBkp[i][k] = o.States[i][k].GetCopy()
+		}
+		if !SetCharLength(o.Model, o.States[i], o.StatesBkp[i], o.CharLength) {
+			return
+		}
+	}
+	return true
+}
+
+// CharLength computes the characteristic length of element: h = sqrt(area of mid-surface)
+func (o *Shell) CharLength() (h float64, ok bool) {
+	for _, a := range o.Aip {
+		h += a
+	}
+	return math.Sqrt(h), true
+}
+
+// BackupIvs create copy of internal variables
+func (o *Shell) BackupIvs() (ok bool) {
+	for i, states := range o.StatesBkp {
+		for k, s := range states {
+			s.Set(o.States[i][k])
+		}
+	}
+	return true
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *Shell) RestoreIvs() (ok bool) {
+	for i, states := range o.States {
+		for k, s := range states {
+			s.Set(o.StatesBkp[i][k])
+		}
+	}
+	return true
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *Shell) Ureset(sol *Solution) (ok bool) {
+	return true
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o Shell) Encode(enc Encoder) (ok bool) {
+	if LogErr(enc.Encode(o.States), "Encode") {
+		return
+	}
+	return !LogErr(enc.Encode(o.Res), "Encode")
+}
+
+// Decode decodes internal variables
+func (o Shell) Decode(dec Decoder) (ok bool) {
+	if LogErr(dec.Decode(&o.States), "Decode") {
+		return
+	}
+	var res [][]float64
+	if LogErr(dec.Decode(&res), "Decode") {
+		return
+	}
+	if LogErrCond(len(res) != len(o.Res), "shell: cid=%d: number of integration points in file (%d) is different from nip=%d\n", o.Cid, len(res), len(o.Res)) {
+		return
+	}
+	for i := range o.Res {
+		copy(o.Res[i], res[i])
+	}
+	return o.BackupIvs()
+}
+
+// OutIpsData returns the stress resultants (per unit length) at integration points
+//  Note: the keys are "nxx", "nyy", "nxy", "mxx", "myy", "mxy", "qx" and "qy"; see calc_resultants
+func (o Shell) OutIpsData() (data []*OutIpData) {
+	keys := shell_reskeys()
+	for idx := range o.IpsElem {
+		v := make(map[string]*float64)
+		for j, key := range keys {
+			v[key] = &o.Res[idx][j]
+		}
+		data = append(data, &OutIpData{o.Id(), o.Xip[idx], v})
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// shell_reskeys returns the keys of stress resultants
+func shell_reskeys() []string {
+	return []string{"nxx", "nyy", "nxy", "mxx", "myy", "mxy", "qx", "qy"}
+}
+
+// init_model gets and initialises the solid model (plane-stress) and the elastic parameters
+//  Note: the transverse shear response is linear elastic with G computed from the material
+//        parameters and the shear correction factor 5/6
+func (o *Shell) init_model(matname string) (ok bool) {
+
+	// material data
+	matdata := Global.Sim.Mdb.Get(matname)
+	if LogErrCond(matdata == nil, "materials database failed on getting %q material\n", matname) {
+		return
+	}
+
+	// model: a separated database key is used because solid elements may share the material
+	o.Model = msolid.GetModel(Global.Sim.Data.FnameKey+"_shell", matname, matdata.Model, false)
+	if LogErrCond(o.Model == nil, "cannot find solid model named %q", matdata.Model) {
+		return
+	}
+	// plane-stress: models that do not support plane-stress return an error
+	if LogErr(o.Model.Init(2, true, matdata.Prms), "shell: solid model initialisation failed (plane-stress is required)") {
+		return
+	}
+	var isSmall bool
+	o.MdlSmall, isSmall = o.Model.(msolid.Small)
+	if LogErrCond(!isSmall, "shell: cid=%d: model %q must be a small strain model\n", o.Cid, matdata.Model) {
+		return
+	}
+
+	// parameters
+	var E, ν float64
+	for _, p := range matdata.Prms {
+		switch p.N {
+		case "E":
+			E = p.V
+		case "nu":
+			ν = p.V
+		case "G":
+			o.G = p.V
+		case "rho":
+			o.Rho = p.V
+		}
+	}
+	if o.G == 0 {
+		o.G = E / (2.0 * (1.0 + ν))
+	}
+	if LogErrCond(o.G <= 0, "shell: cid=%d: shear modulus must be positive; E and nu or G are required. G=%g is incorrect\n", o.Cid, o.G) {
+		return
+	}
+	o.Gs = 5.0 * o.G / 6.0
+	return true
+}
+
+// calc_T computes the local system, the local coordinates of nodes and the global-to-local
+// transformation matrix
+//  Note: e2 is normal to the mid-surface at the centre and e0 is along r. The nodes are projected
+//        onto the plane of the element; i.e. warped elements are treated as flat
+func (o *Shell) calc_T() (ok bool) {
+
+	// base vectors at centre
+	nverts := o.Shp.Nverts
+	S := make([]float64, nverts)
+	dSdR := la.MatAlloc(nverts, 2)
+	o.Shp.Func(S, dSdR, 0, 0, 0, true)
+	gr := make([]float64, 3)
+	gs := make([]float64, 3)
+	xc := make([]float64, 3)
+	for i := 0; i < 3; i++ {
+		for m := 0; m < nverts; m++ {
+			gr[i] += dSdR[m][0] * o.X[i][m]
+			gs[i] += dSdR[m][1] * o.X[i][m]
+			xc[i] += S[m] * o.X[i][m]
+		}
+	}
+
+	// local system
+	e0 := make([]float64, 3)
+	e2 := shell_cross(gr, gs)
+	ne0, ne2 := la.VecNorm(gr), la.VecNorm(e2)
+	if LogErrCond(ne2 < 1e-10*ne0*ne0, "shell: cid=%d: element is degenerated\n", o.Cid) {
+		return
+	}
+	la.VecScale(e0, 0, 1.0/ne0, gr)
+	la.VecScale(e2, 0, 1.0/ne2, e2)
+	e1 := shell_cross(e2, e0)
+	o.R = [][]float64{e0, e1, e2}
+
+	// local coordinates
+	o.Xl = la.MatAlloc(2, nverts)
+	for m := 0; m < nverts; m++ {
+		for i := 0; i < 3; i++ {
+			o.Xl[0][m] += e0[i] * (o.X[i][m] - xc[i])
+			o.Xl[1][m] += e1[i] * (o.X[i][m] - xc[i])
+		}
+	}
+
+	// T: translations and rotations
+	o.T = la.MatAlloc(o.Nu, o.Nu)
+	for b := 0; b < 2*nverts; b++ {
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				o.T[i+b*3][j+b*3] = o.R[i][j]
+			}
+		}
+	}
+	return true
+}
+
+// calc_B computes the strain-displacement matrices at integration points (local system)
+//  Note: the local DOFs of node m are {u, v, w, θx, θy, θz} with u = z θy and v = -z θx; thus:
+//        κxx = ∂θy/∂x, κyy = -∂θx/∂y, κxy = ∂θy/∂y - ∂θx/∂x, γxz = ∂w/∂x + θy and γyz = ∂w/∂y - θx
+func (o *Shell) calc_B() (ok bool) {
+
+	// allocate
+	nip := len(o.IpsElem)
+	nverts := o.Shp.Nverts
+	o.Xip = la.MatAlloc(nip, 3)
+	o.Sip = la.MatAlloc(nip, nverts)
+	o.Aip = make([]float64, nip)
+	o.Bm = make([][][]float64, nip)
+	o.Bb = make([][][]float64, nip)
+	o.Bs = make([][][]float64, nip)
+	o.Bd = la.MatAlloc(nip, o.Nu)
+
+	// auxiliary
+	S := make([]float64, nverts)
+	dSdR := la.MatAlloc(nverts, 2)
+	dxdR := la.MatAlloc(2, 2)
+	dRdx := la.MatAlloc(2, 2)
+	G := la.MatAlloc(nverts, 2)
+	γR := la.MatAlloc(2, o.Nu) // covariant shear strains
+
+	// for each integration point
+	for idx, ip := range o.IpsElem {
+
+		// Jacobian and gradients
+		var J float64
+		if J, ok = o.calc_jac(S, dSdR, dxdR, dRdx, ip.R, ip.S); !ok {
+			return
+		}
+		for m := 0; m < nverts; m++ {
+			G[m][0] = dSdR[m][0]*dRdx[0][0] + dSdR[m][1]*dRdx[1][0]
+			G[m][1] = dSdR[m][0]*dRdx[0][1] + dSdR[m][1]*dRdx[1][1]
+		}
+		o.Aip[idx] = J * ip.W
+		copy(o.Sip[idx], S)
+		for i := 0; i < 3; i++ {
+			for m := 0; m < nverts; m++ {
+				o.Xip[idx][i] += S[m] * o.X[i][m]
+			}
+		}
+
+		// membrane, bending and drilling
+		Bm := la.MatAlloc(3, o.Nu)
+		Bb := la.MatAlloc(3, o.Nu)
+		Bd := o.Bd[idx]
+		for m := 0; m < nverts; m++ {
+			u, v, θx, θy, θz := 0+m*6, 1+m*6, 3+m*6, 4+m*6, 5+m*6
+			Bm[0][u] = G[m][0]
+			Bm[1][v] = G[m][1]
+			Bm[2][u], Bm[2][v] = G[m][1], G[m][0]
+			Bb[0][θy] = G[m][0]
+			Bb[1][θx] = -G[m][1]
+			Bb[2][θx], Bb[2][θy] = -G[m][0], G[m][1]
+			Bd[u], Bd[v], Bd[θz] = 0.5*G[m][1], -0.5*G[m][0], S[m]
+		}
+		o.Bm[idx], o.Bb[idx] = Bm, Bb
+
+		// transverse shear: covariant strains interpolated from tying points (MITC); this avoids
+		// the shear locking of the displacement-based interpolation used above
+		la.MatFill(γR, 0)
+		for k := 0; k < 2; k++ {
+			pts, h := shell_tying(nverts, k, ip.R, ip.S)
+			for p, pt := range pts {
+				if !o.add_covariant_shear(γR[k], h[p], k, pt[0], pt[1]) {
+					return
+				}
+			}
+		}
+
+		// transverse shear: cartesian components γi = dRj/dxi γRj
+		Bs := la.MatAlloc(2, o.Nu)
+		for i := 0; i < 2; i++ {
+			for r := 0; r < o.Nu; r++ {
+				Bs[i][r] = dRdx[0][i]*γR[0][r] + dRdx[1][i]*γR[1][r]
+			}
+		}
+		o.Bs[idx] = Bs
+	}
+	return true
+}
+
+// calc_jac computes the shape functions, their derivatives and the Jacobian of the in-plane
+// mapping at (r,s) using the local coordinates of nodes
+func (o *Shell) calc_jac(S []float64, dSdR, dxdR, dRdx [][]float64, r, s float64) (J float64, ok bool) {
+	o.Shp.Func(S, dSdR, r, s, 0, true)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			dxdR[i][j] = 0
+			for m := 0; m < o.Shp.Nverts; m++ {
+				dxdR[i][j] += o.Xl[i][m] * dSdR[m][j]
+			}
+		}
+	}
+	J = dxdR[0][0]*dxdR[1][1] - dxdR[0][1]*dxdR[1][0]
+	if LogErrCond(J <= 0, "shell: cid=%d: Jacobian is not positive: J=%g\n", o.Cid, J) {
+		return
+	}
+	dRdx[0][0], dRdx[0][1] = dxdR[1][1]/J, -dxdR[0][1]/J
+	dRdx[1][0], dRdx[1][1] = -dxdR[1][0]/J, dxdR[0][0]/J
+	return J, true
+}
+
+// add_covariant_shear adds h times the covariant transverse shear strain γk (k=0: r; k=1: s)
+// computed at the tying point (r,s) to row; i.e. γk = ∂w/∂Rk + ∂x/∂Rk θy - ∂y/∂Rk θx
+func (o *Shell) add_covariant_shear(row []float64, h float64, k int, r, s float64) (ok bool) {
+	nverts := o.Shp.Nverts
+	S := make([]float64, nverts)
+	dSdR := la.MatAlloc(nverts, 2)
+	dxdR := la.MatAlloc(2, 2)
+	dRdx := la.MatAlloc(2, 2)
+	if _, ok = o.calc_jac(S, dSdR, dxdR, dRdx, r, s); !ok {
+		return
+	}
+	for m := 0; m < nverts; m++ {
+		row[2+m*6] += h * dSdR[m][k]
+		row[3+m*6] -= h * dxdR[1][k] * S[m]
+		row[4+m*6] += h * dxdR[0][k] * S[m]
+	}
+	return true
+}
+
+// calc_M computes the consistent mass matrix (global system)
+//  Note: the rotary inertia of θx and θy is included; the drilling rotation is massless
+func (o *Shell) calc_M() {
+	Ml := la.MatAlloc(o.Nu, o.Nu)
+	mt := o.Rho * o.Thick
+	mr := o.Rho * o.Thick * o.Thick * o.Thick / 12.0
+	for idx := range o.IpsElem {
+		S := o.Sip[idx]
+		for m := 0; m < o.Shp.Nverts; m++ {
+			for n := 0; n < o.Shp.Nverts; n++ {
+				NN := S[m] * S[n] * o.Aip[idx]
+				for i := 0; i < 3; i++ {
+					Ml[i+m*6][i+n*6] += mt * NN
+				}
+				for i := 3; i < 5; i++ {
+					Ml[i+m*6][i+n*6] += mr * NN
+				}
+			}
+		}
+	}
+	la.MatTrMul3(o.M, 1, o.T, Ml, o.T) // M := 1 * trans(T) * Ml * T
+}
+
+// calc_Bz computes the in-plane strain-displacement matrix at a distance z from the mid-surface
+func (o *Shell) calc_Bz(idx int, z float64) {
+	for i := 0; i < 3; i++ {
+		for r := 0; r < o.Nu; r++ {
+			o.Bz[i][r] = o.Bm[idx][i][r] + z*o.Bb[idx][i][r]
+		}
+	}
+}
+
+// calc_C converts the plane-stress modulus D (Mandel) to C (engineering shear strain)
+func (o *Shell) calc_C() {
+	idx := []int{0, 1, 3}
+	coef := []float64{1, 1, 1.0 / SQ2}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.C[i][j] = o.D[idx[i]][idx[j]] * coef[i] * coef[j]
+		}
+	}
+}
+
+// calc_resultants computes the stress resultants (per unit length; local system) at integration
+// points: membrane forces, moments (∫ z σ dz) and transverse shear forces
+func (o *Shell) calc_resultants() {
+	for idx := range o.IpsElem {
+		res := o.Res[idx]
+		la.VecFill(res, 0)
+		for k, z := range o.Zs {
+			s := o.States[idx][k].Sig
+			σ := []float64{s[0], s[1], s[3] / SQ2}
+			for i := 0; i < 3; i++ {
+				res[i] += o.Wz[k] * σ[i]
+				res[3+i] += o.Wz[k] * z * σ[i]
+			}
+		}
+		for i, row := range o.Bs[idx] {
+			res[6+i] = o.Gs * o.Thick * la.VecDot(row, o.ul)
+		}
+	}
+}
+
+// shell_tying returns the tying points (natural coordinates) of the covariant transverse shear
+// strain γk (k=0: r; k=1: s) and the corresponding interpolation functions evaluated at (r,s)
+//  MITC4: γr is tied at (0,∓1) and γs at (∓1,0) with linear interpolation
+//  MITC9: γr is tied at (±a,{-1,0,1}) and γs at ({-1,0,1},±a) with a = 1/√3; the interpolation
+//         is linear along the direction k and quadratic along the other direction
+func shell_tying(nverts, k int, r, s float64) (pts [][]float64, h []float64) {
+	if nverts == 4 {
+		if k == 0 {
+			return [][]float64{{0, -1}, {0, 1}}, []float64{(1 - s) / 2, (1 + s) / 2}
+		}
+		return [][]float64{{-1, 0}, {1, 0}}, []float64{(1 - r) / 2, (1 + r) / 2}
+	}
+	a := 1.0 / math.Sqrt(3.0)
+	x, y := r, s
+	if k == 1 {
+		x, y = s, r
+	}
+	L := []float64{(1 - x/a) / 2, (1 + x/a) / 2}
+	l := []float64{y * (y - 1) / 2, 1 - y*y, y * (y + 1) / 2}
+	lin := []float64{-a, a}
+	quad := []float64{-1, 0, 1}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			if k == 0 {
+				pts = append(pts, []float64{lin[i], quad[j]})
+			} else {
+				pts = append(pts, []float64{quad[j], lin[i]})
+			}
+			h = append(h, L[i]*l[j])
+		}
+	}
+	return
+}
+
+// shell_addBtCB adds coef * tr(B) * C * B to K
+func shell_addBtCB(K [][]float64, coef float64, B, C [][]float64) {
+	nu := len(K)
+	CB := la.MatAlloc(len(C), nu)
+	la.MatMul(CB, 1, C, B)
+	for i := 0; i < nu; i++ {
+		for k := 0; k < len(B); k++ {
+			if B[k][i] == 0 {
+				continue
+			}
+			for j := 0; j < nu; j++ {
+				K[i][j] += coef * B[k][i] * CB[k][j]
+			}
+		}
+	}
+}
+
+// shell_addb adds coef * b to f
+func shell_addb(f []float64, coef float64, b []float64) {
+	for i, bi := range b {
+		f[i] += coef * bi
+	}
+}
+
+// shell_addbtb adds coef * b ⊗ b to K
+func shell_addbtb(K [][]float64, coef float64, b []float64) {
+	for i, bi := range b {
+		if bi == 0 {
+			continue
+		}
+		for j, bj := range b {
+			K[i][j] += coef * bi * bj
+		}
+	}
+}
+
+// shell_cross computes the cross product u × v
+func shell_cross(u, v []float64) []float64 {
+	return []float64{
+		u[1]*v[2] - u[2]*v[1],
+		u[2]*v[0] - u[0]*v[2],
+		u[0]*v[1] - u[1]*v[0],
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/mtherm"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemT implements an element for transient heat conduction analyses
+//  Balance of energy:
+//   ρc(θ)・dθ/dt + div(q) = 0   with   q = -k(θ)・∇θ
+//  Natural boundary conditions:
+//   "qt"  -- prescribed heat flux (positive means outflow): q・n = qb
+//   "cnv" -- convection: q・n = h・(θ - θ∞), where θ∞ is given by the function
+//            and the coefficient h by the keycode 'h' in Extra; e.g. "!h:10"
+type ElemT struct {
+
+	// basic data
+	Cid int         // cell/element id
+	X   [][]float64 // matrix of nodal coordinates [ndim][nnode]
+	Shp *shp.Shape  // shape structure
+	Nt  int         // total number of unknowns == number of vertices
+
+	// integration points
+	IpsElem []*shp.Ipoint // integration points of element
+	IpsFace []*shp.Ipoint // integration points corresponding to faces
+
+	// material model
+	Mdl mtherm.Model // model
+
+	// problem variables
+	Tmap []int // assembly map (location array/element equations)
+
+	// natural boundary conditions
+	NatBcs []*NaturalBc // natural boundary conditions
+	Hcnv   []float64    // [nbcs] convection coefficients of "cnv" faces
+
+	// local starred variables
+	ψθ []float64 // [nip] ψθ* = β1.θ + β2.dθdt
+
+	// scratchpad. computed @ each ip
+	θ   float64     // θ: temperature
+	gθ  []float64   // [ndim] ∇θ: gradient of temperature
+	Ktt [][]float64 // [nt][nt] Ktt := dRθ/dθ consistent tangent matrix
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["t"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// number of nodes in element
+		nverts := shp.GetNverts(cellType)
+
+		// solution variables
+		ykeys := []string{"temp"}
+		info.Dofs = make([][]string, nverts)
+		for m := 0; m < nverts; m++ {
+			info.Dofs[m] = ykeys
+		}
+
+		// maps
+		info.Y2F = map[string]string{"temp": "qt"}
+
+		// t1 and t2 variables
+		info.T1vars = ykeys
+		return &info
+	}
+
+	// element allocator
+	eallocators["t"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemT
+		o.Cid = cid
+		o.X = x
+		o.Shp = shp.Get(cellType)
+		o.Nt = o.Shp.Nverts
+
+		// integration points
+		o.IpsElem, o.IpsFace = GetIntegrationPoints(edat.Nip, edat.Nipf, cellType)
+		if o.IpsElem == nil || o.IpsFace == nil {
+			return nil
+		}
+		nip := len(o.IpsElem)
+
+		// model
+		o.Mdl = GetAndInitThermModel(edat.Mat)
+		if o.Mdl == nil {
+			return nil
+		}
+
+		// local starred variables
+		o.ψθ = make([]float64, nip)
+
+		// scratchpad. computed @ each ip
+		o.gθ = make([]float64, Global.Ndim)
+		o.Ktt = la.MatAlloc(o.Nt, o.Nt)
+
+		// set natural boundary conditions
+		o.Hcnv = make([]float64, len(faceConds))
+		for idx, fc := range faceConds {
+			o.NatBcs = append(o.NatBcs, &NaturalBc{fc.Cond, fc.FaceId, fc.Func, fc.Extra})
+			if fc.Cond == "cnv" {
+				s_val, found := io.Keycode(fc.Extra, "h")
+				if LogErrCond(!found, "ElemT: cid=%d: convection coefficient 'h' must be given in Extra field of \"cnv\" condition; e.g. \"!h:10\"\n", cid) {
+					return nil
+				}
+				o.Hcnv[idx] = io.Atof(s_val)
+			}
+		}
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemT) Id() int { return o.Cid }
+
+// SetEqs sets equations
+func (o *ElemT) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+	o.Tmap = make([]int, o.Nt)
+	for m := 0; m < o.Shp.Nverts; m++ {
+		o.Tmap[m] = eqs[m][0]
+	}
+	return true
+}
+
+// SetEleConds sets element conditions
+func (o *ElemT) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	return true
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ElemT) InterpStarVars(sol *Solution) (ok bool) {
+
+	// skip if steady
+	if Global.Sim.Data.Steady {
+		return true
+	}
+
+	// for each integration point
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.Shp.CalcAtIp(o.X, ip, false), "InterpStarVars") {
+			return
+		}
+
+		// interpolate starred variables
+		o.ψθ[idx] = 0
+		for m := 0; m < o.Shp.Nverts; m++ {
+			o.ψθ[idx] += o.Shp.S[m] * sol.Psi[o.Tmap[m]]
+		}
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemT) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+
+	// for each integration point
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	var coef, θt, k, ρc float64
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		coef = o.Shp.J * ip.W
+		S := o.Shp.S
+		G := o.Shp.G
+
+		// material variables
+		θt = o.rate(idx)
+		k = o.Mdl.Cond(o.θ)
+		ρc = o.Mdl.Capa(o.θ)
+
+		// add negative of residual term to fb
+		for m := 0; m < nverts; m++ {
+			r := o.Tmap[m]
+			fb[r] -= coef * S[m] * ρc * θt
+			for i := 0; i < ndim; i++ {
+				fb[r] -= coef * G[m][i] * k * o.gθ[i] // -= coef * G・(-q)
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	return o.add_natbcs_to_rhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemT) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// clear matrices
+	la.MatFill(o.Ktt, 0)
+
+	// for each integration point
+	ndim := Global.Ndim
+	nverts := o.Shp.Nverts
+	β1 := Global.DynCoefs.β1
+	if Global.Sim.Data.Steady {
+		β1 = 0
+	}
+	var coef, θt, k, dkdθ, ρc, dρcdθ float64
+	for idx, ip := range o.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.ipvars(idx, sol) {
+			return
+		}
+		coef = o.Shp.J * ip.W
+		S := o.Shp.S
+		G := o.Shp.G
+
+		// material variables
+		θt = o.rate(idx)
+		k = o.Mdl.Cond(o.θ)
+		dkdθ = o.Mdl.DcondDθ(o.θ)
+		ρc = o.Mdl.Capa(o.θ)
+		dρcdθ = o.Mdl.DcapaDθ(o.θ)
+
+		// Ktt := dRθ/dθ
+		for m := 0; m < nverts; m++ {
+			for n := 0; n < nverts; n++ {
+				o.Ktt[m][n] += coef * S[m] * S[n] * (dρcdθ*θt + β1*ρc)
+				for i := 0; i < ndim; i++ {
+					o.Ktt[m][n] += coef * G[m][i] * (k*G[n][i] + dkdθ*S[n]*o.gθ[i])
+				}
+			}
+		}
+	}
+
+	// contribution from natural boundary conditions
+	if !o.add_natbcs_to_jac(sol) {
+		return
+	}
+
+	// add to sparse matrix Kb
+	for i, I := range o.Tmap {
+		for j, J := range o.Tmap {
+			Kb.Put(I, J, o.Ktt[i][j])
+		}
+	}
+	return true
+}
+
+// Update performs (tangent) update
+func (o *ElemT) Update(sol *Solution) (ok bool) {
+	return true
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemT) Encode(enc Encoder) (ok bool) {
+	return true
+}
+
+// Decode decodes internal variables
+func (o ElemT) Decode(dec Decoder) (ok bool) {
+	return true
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemT) OutIpsData() (data []*OutIpData) {
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ipvars computes current values @ integration points. idx == index of integration point
+func (o *ElemT) ipvars(idx int, sol *Solution) (ok bool) {
+
+	// interpolation functions and gradients
+	if LogErr(o.Shp.CalcAtIp(o.X, o.IpsElem[idx], true), "ipvars") {
+		return
+	}
+
+	// clear θ and its gradient @ ip
+	ndim := Global.Ndim
+	o.θ = 0
+	for i := 0; i < ndim; i++ {
+		o.gθ[i] = 0
+	}
+
+	// compute θ and its gradient @ ip by means of interpolating from nodes
+	for m := 0; m < o.Shp.Nverts; m++ {
+		r := o.Tmap[m]
+		o.θ += o.Shp.S[m] * sol.Y[r]
+		for i := 0; i < ndim; i++ {
+			o.gθ[i] += o.Shp.G[m][i] * sol.Y[r]
+		}
+	}
+	return true
+}
+
+// rate returns the rate of temperature θt = β1・θ - ψθ* @ ip; zero if steady. ipvars must be called first
+func (o *ElemT) rate(idx int) float64 {
+	if Global.Sim.Data.Steady {
+		return 0
+	}
+	return Global.DynCoefs.β1*o.θ - o.ψθ[idx]
+}
+
+// add_natbcs_to_rhs adds natural boundary conditions to rhs
+func (o ElemT) add_natbcs_to_rhs(fb []float64, sol *Solution) (ok bool) {
+
+	// compute surface integral
+	var val, θ float64
+	for idx, nbc := range o.NatBcs {
+
+		// skip conditions of other elements
+		if nbc.Key != "qt" && nbc.Key != "cnv" {
+			continue
+		}
+
+		// val := qb or θ∞
+		val = nbc.Fcn.F(sol.T, nil)
+
+		// loop over ips of face
+		for _, ipf := range o.IpsFace {
+
+			// interpolation functions and gradients @ face
+			iface := nbc.IdxFace
+			if LogErr(o.Shp.CalcAtFaceIp(o.X, ipf, iface), "add_natbcs_to_rhs") {
+				return
+			}
+			Sf := o.Shp.Sf
+			coef := ipf.W * la.VecNorm(o.Shp.Fnvec)
+
+			// select natural boundary condition type
+			switch nbc.Key {
+			case "qt":
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					fb[o.Tmap[m]] -= coef * Sf[i] * val
+				}
+			case "cnv":
+				θ = 0
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					θ += Sf[i] * sol.Y[o.Tmap[m]]
+				}
+				for i, m := range o.Shp.FaceLocalV[iface] {
+					fb[o.Tmap[m]] -= coef * Sf[i] * o.Hcnv[idx] * (θ - val)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// add_natbcs_to_jac adds contribution from natural boundary conditions to Jacobian
+func (o ElemT) add_natbcs_to_jac(sol *Solution) (ok bool) {
+	for idx, nbc := range o.NatBcs {
+		if nbc.Key != "cnv" {
+			continue
+		}
+		for _, ipf := range o.IpsFace {
+			iface := nbc.IdxFace
+			if LogErr(o.Shp.CalcAtFaceIp(o.X, ipf, iface), "add_natbcs_to_jac") {
+				return
+			}
+			Sf := o.Shp.Sf
+			coef := ipf.W * la.VecNorm(o.Shp.Fnvec)
+			for i, m := range o.Shp.FaceLocalV[iface] {
+				for j, n := range o.Shp.FaceLocalV[iface] {
+					o.Ktt[m][n] += coef * Sf[i] * Sf[j] * o.Hcnv[idx]
+				}
+			}
+		}
+	}
+	return true
+}
@@ -5,6 +5,8 @@
 package fem
 
 import (
+	"math"
+
 	"github.com/cpmech/gofem/inp"
 	"github.com/cpmech/gofem/msolid"
 	"github.com/cpmech/gofem/shp"
@@ -38,9 +40,10 @@ type ElemU struct {
 	IpsFace []*shp.Ipoint // integration points corresponding to faces
 
 	// material model and internal variables
-	Model    msolid.Model // material model
-	MdlSmall msolid.Small // model specialisation for small strains
-	MdlLarge msolid.Large // model specialisation for large deformations
+	Model    msolid.Model         // material model
+	MdlSmall msolid.Small         // model specialisation for small strains
+	MdlLarge msolid.Large         // model specialisation for large deformations
+	MdlRate  msolid.RateDependent // model specialisation for rate-dependent models (may be nil)
 
 	// internal variables
 	States    []*msolid.State // [nip] states
@@ -52,6 +55,13 @@ type ElemU struct {
 	// natural boundary conditions
 	NatBcs []*NaturalBc
 
+	// undrained analysis with effective stress model ("undrained A"): σ = σ' - pwex・I
+	Undrained bool      // undrained behaviour is active
+	Kw        float64   // equivalent bulk modulus of pore water = BulkL / nf0 (penalty)
+	UndFcn    fun.Func  // switches undrained behaviour on (f(t) > 0) or off (f(t) ≤ 0); may be nil
+	Pwex      []float64 // [nip] excess pore-water pressures
+	PwexBkp   []float64 // [nip] backup excess pore-water pressures
+
 	// local starred variables
 	ζs    [][]float64 // [nip][ndim] t2 star vars: ζ* = α1.u + α2.v + α3.a
 	χs    [][]float64 // [nip][ndim] t2 star vars: χ* = α4.u + α5.v + α6.a
@@ -68,6 +78,22 @@ type ElemU struct {
 	// strains
 	ε  []float64 // total (updated) strains
 	Δε []float64 // incremental strains leading to updated strains
+	σ  []float64 // total stresses in undrained analyses
+
+	// thermal strains; set by coupled elements before calling ipupdate (see ElemUT)
+	εθ  float64 // isotropic thermal strain: α・(θ - θref)
+	Δεθ float64 // increment of isotropic thermal strain: α・Δθ
+
+	// large deformations (updated Lagrangian)
+	xc [][]float64     // [ndim][nverts] current coordinates of nodes
+	F  [][]float64     // [3][3] deformation gradient @ ip
+	FΔ [][]float64     // [3][3] incremental deformation gradient @ ip
+	Fi [][]float64     // [3][3] scratchpad for inverse of F
+	A  [][][][]float64 // [3][3][3][3] spatial tangent modulus
+	J0 float64         // Jacobian of reference configuration @ ip
+
+	// output of large deformations; refreshed by Update and Decode
+	Eps [][]float64 // [nip][nsig] logarithmic strains @ ips. see OutIpsData
 
 	// for debugging
 	fex []float64 // x-components of external surface forces
@@ -120,6 +146,7 @@ func init() {
 
 		// parse flags
 		o.UseB, o.Debug, o.Thickness = GetSolidFlags(edat.Extra)
+		o.Undrained = GetUndrainedFlag(edat.Extra)
 
 		// integration points
 		o.IpsElem, o.IpsFace = GetIntegrationPoints(edat.Nip, edat.Nipf, cellType)
@@ -142,6 +169,9 @@ func init() {
 		case msolid.Large:
 			o.MdlLarge = m
 		}
+		if m, ok := o.Model.(msolid.RateDependent); ok {
+			o.MdlRate = m
+		}
 
 		// parameters
 		for _, p := range prms {
@@ -173,6 +203,34 @@ func init() {
 		o.ε = make([]float64, nsig)
 		o.Δε = make([]float64, nsig)
 
+		// undrained analysis
+		if o.Undrained {
+			if LogErrCond(Global.Sim.Data.Pstress, "ElemU: cid=%d: undrained analyses are not available with plane-stress\n", cid) {
+				return nil
+			}
+			o.Kw = GetWaterBulkModulus(edat.Mat)
+			if o.Kw == 0 {
+				return nil
+			}
+			o.σ = make([]float64, nsig)
+		}
+
+		// large deformations
+		if o.MdlLarge != nil {
+			if LogErrCond(o.UseB || Global.Sim.Data.Axisym, "ElemU: cid=%d: large deformation analyses cannot be run with B matrix or axisymmetry\n", cid) {
+				return nil
+			}
+			if LogErrCond(o.Undrained, "ElemU: cid=%d: undrained analyses are not available with large deformations\n", cid) {
+				return nil
+			}
+			o.xc = la.MatAlloc(ndim, o.Shp.Nverts)
+			o.F = tsr.Alloc2()
+			o.FΔ = tsr.Alloc2()
+			o.Fi = tsr.Alloc2()
+			o.A = tsr.Alloc4()
+			o.Eps = la.MatAlloc(len(o.IpsElem), nsig)
+		}
+
 		// variables for debugging
 		if o.Debug {
 			o.fex = make([]float64, o.Shp.Nverts)
@@ -212,8 +270,14 @@ func (o *ElemU) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
 
 // SetEleConds set element conditions
 func (o *ElemU) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
-	if key == "g" { // gravity
+	switch key {
+	case "g": // gravity
 		o.Gfcn = f
+	case "undrained": // switch undrained behaviour on/off
+		if LogErrCond(!o.Undrained, "ElemU: eid=%d: the 'undrained' condition requires the !undrained:1 flag in element's extra data\n", o.Id()) {
+			return
+		}
+		o.UndFcn = f
 	}
 	return true
 }
@@ -283,20 +347,24 @@ func (o *ElemU) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 				coef *= radius
 			}
 			IpBmatrix(o.B, ndim, nverts, G, Global.Sim.Data.Axisym, radius, S)
-			la.MatTrVecMulAdd(o.fi, coef, o.B, o.States[idx].Sig) // fi += coef * tr(B) * σ
+			la.MatTrVecMulAdd(o.fi, coef, o.B, o.total_stress(idx, sol.T)) // fi += coef * tr(B) * σ
 		} else {
+			σ := o.total_stress(idx, sol.T)
 			for m := 0; m < nverts; m++ {
 				for i := 0; i < ndim; i++ {
 					r := o.Umap[i+m*ndim]
 					for j := 0; j < ndim; j++ {
-						fb[r] -= coef * tsr.M2T(o.States[idx].Sig, i, j) * G[m][j] // -fi
+						fb[r] -= coef * tsr.M2T(σ, i, j) * G[m][j] // -fi
 					}
 				}
 			}
 		}
 
-		// dynamic term
+		// dynamic term (mass is integrated over the reference configuration in large deformations)
 		if !Global.Sim.Data.Steady {
+			if o.MdlLarge != nil {
+				coef = o.J0 * ip.W * o.Thickness
+			}
 			for m := 0; m < nverts; m++ {
 				for i := 0; i < ndim; i++ {
 					r := o.Umap[i+m*ndim]
@@ -345,22 +413,41 @@ func (o *ElemU) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 		S := o.Shp.S
 		G := o.Shp.G
 
-		// consistent tangent model matrix
-		if LogErr(o.MdlSmall.CalcD(o.D, o.States[idx], firstIt), "AddToKb") {
-			return
-		}
-
-		// add contribution to consistent tangent matrix
-		if o.UseB {
-			radius := 1.0
-			if Global.Sim.Data.Axisym {
-				radius = o.Shp.AxisymGetRadius(o.X)
-				coef *= radius
+		// large deformations: material plus geometric tangent; mass is integrated over the reference configuration
+		if o.MdlLarge != nil {
+			if LogErr(o.MdlLarge.CalcA(o.A, o.States[idx], firstIt), "AddToKb") {
+				return
 			}
-			IpBmatrix(o.B, ndim, nverts, G, Global.Sim.Data.Axisym, radius, S)
-			la.MatTrMulAdd3(o.K, coef, o.B, o.D, o.B) // K += coef * tr(B) * D * B
+			IpAddToKtLarge(o.K, nverts, ndim, coef, G, o.A)
+			coef = o.J0 * ip.W * o.Thickness
 		} else {
-			IpAddToKt(o.K, nverts, ndim, coef, G, o.D)
+
+			// consistent tangent model matrix
+			if LogErr(o.MdlSmall.CalcD(o.D, o.States[idx], firstIt), "AddToKb") {
+				return
+			}
+
+			// undrained analysis: add bulk modulus of pore water (plane-stress is rejected at allocation)
+			if o.undrained(sol.T) {
+				for i := 0; i < 3; i++ {
+					for j := 0; j < 3; j++ {
+						o.D[i][j] += o.Kw
+					}
+				}
+			}
+
+			// add contribution to consistent tangent matrix
+			if o.UseB {
+				radius := 1.0
+				if Global.Sim.Data.Axisym {
+					radius = o.Shp.AxisymGetRadius(o.X)
+					coef *= radius
+				}
+				IpBmatrix(o.B, ndim, nverts, G, Global.Sim.Data.Axisym, radius, S)
+				la.MatTrMulAdd3(o.K, coef, o.B, o.D, o.B) // K += coef * tr(B) * D * B
+			} else {
+				IpAddToKt(o.K, nverts, ndim, coef, G, o.D)
+			}
 		}
 
 		// dynamic term
@@ -377,6 +464,13 @@ func (o *ElemU) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 		}
 	}
 
+	// large deformations: stiffness due to follower loads
+	if o.MdlLarge != nil {
+		if !o.add_surfloads_to_kb(sol) {
+			return
+		}
+	}
+
 	// add K to sparse matrix Kb
 	for i, I := range o.Umap {
 		for j, J := range o.Umap {
@@ -402,6 +496,11 @@ func (o *ElemU) Update(sol *Solution) (ok bool) {
 			return
 		}
 	}
+
+	// logarithmic strains for output
+	if o.MdlLarge != nil {
+		return o.log_strains()
+	}
 	return true
 }
 
@@ -416,6 +515,17 @@ func (o ElemU) Ipoints() (coords [][]float64) {
 	return
 }
 
+// CharLength computes the characteristic length of element: h = (area or volume)^(1/ndim)
+func (o *ElemU) CharLength() (h float64, ok bool) {
+	for _, ip := range o.IpsElem {
+		if LogErr(o.Shp.CalcAtIp(o.X, ip, false), "CharLength") {
+			return
+		}
+		h += o.Shp.J * ip.W
+	}
+	return math.Pow(h, 1.0/float64(Global.Ndim)), true
+}
+
 // SetIniIvs sets initial ivs for given values in sol and ivs map
 func (o *ElemU) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
 
@@ -430,6 +540,11 @@ func (o *ElemU) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
 		o.StatesBkp[i] = o.States[i].GetCopy()
 	}
 
+	// characteristic length for regularisation of softening models
+	if !SetCharLength(o.Model, o.States, o.StatesBkp, o.CharLength) {
+		return
+	}
+
 	// initial stresses
 	if _, ok := ivs["sx"]; ok {
 		for i := 0; i < nip; i++ {
@@ -437,6 +552,12 @@ func (o *ElemU) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
 			copy(o.StatesBkp[i].Sig, o.States[i].Sig)
 		}
 	}
+
+	// excess pore-water pressures
+	if o.Undrained {
+		o.Pwex = make([]float64, nip)
+		o.PwexBkp = make([]float64, nip)
+	}
 	return true
 }
 
@@ -445,6 +566,7 @@ func (o *ElemU) BackupIvs() (ok bool) {
 	for i, s := range o.StatesBkp {
 		s.Set(o.States[i])
 	}
+	copy(o.PwexBkp, o.Pwex)
 	return true
 }
 
@@ -453,16 +575,15 @@ func (o *ElemU) RestoreIvs() (ok bool) {
 	for i, s := range o.States {
 		s.Set(o.StatesBkp[i])
 	}
+	copy(o.Pwex, o.PwexBkp)
 	return true
 }
 
 // Ureset fixes internal variables after u (displacements) have been zeroed
+//  Note: the deformation gradient depends on the total displacements in large deformation analyses
 func (o *ElemU) Ureset(sol *Solution) (ok bool) {
-	for idx, _ := range o.IpsElem {
-		if len(o.States[idx].F) > 0 {
-			la.MatFill(o.States[idx].F, 0)
-			la.MatFill(o.StatesBkp[idx].F, 0)
-		}
+	if LogErrCond(o.MdlLarge != nil, "ElemU: eid=%d: displacements cannot be zeroed in large deformation analyses\n", o.Id()) {
+		return
 	}
 	return true
 }
@@ -471,7 +592,13 @@ func (o *ElemU) Ureset(sol *Solution) (ok bool) {
 
 // Encode encodes internal variables
 func (o ElemU) Encode(enc Encoder) (ok bool) {
-	return !LogErr(enc.Encode(o.States), "Encode")
+	if LogErr(enc.Encode(o.States), "Encode") {
+		return
+	}
+	if o.Undrained {
+		return !LogErr(enc.Encode(o.Pwex), "Encode")
+	}
+	return true
 }
 
 // Decode decodes internal variables
@@ -479,6 +606,16 @@ func (o ElemU) Decode(dec Decoder) (ok bool) {
 	if LogErr(dec.Decode(&o.States), "Decode") {
 		return
 	}
+	if o.Undrained {
+		if LogErr(dec.Decode(&o.Pwex), "Decode") {
+			return
+		}
+	}
+	if o.MdlLarge != nil {
+		if !o.log_strains() {
+			return
+		}
+	}
 	return o.BackupIvs()
 }
 
@@ -492,6 +629,14 @@ func (o ElemU) OutIpsData() (data []*OutIpData) {
 		for i, key := range sigmas {
 			v[key] = &s.Sig[i]
 		}
+		if o.Undrained {
+			v["pwex"] = &o.Pwex[idx]
+		}
+		if o.MdlLarge != nil {
+			for i, key := range StrainKeys() {
+				v[key] = &o.Eps[idx][i]
+			}
+		}
 		data = append(data, &OutIpData{o.Id(), x, v})
 	}
 	return
@@ -499,9 +644,29 @@ func (o ElemU) OutIpsData() (data []*OutIpData) {
 
 // auxiliary ////////////////////////////////////////////////////////////////////////////////////////
 
+// log_strains computes the logarithmic strains at all ips from the deformation gradients in states
+func (o *ElemU) log_strains() (ok bool) {
+	for idx, s := range o.States {
+		if LogErr(IpLogStrains(o.Eps[idx], s.F), "log_strains") {
+			return
+		}
+	}
+	return true
+}
+
 // ipudpate updates internal state
 func (o *ElemU) ipupdate(idx int, S []float64, G [][]float64, sol *Solution) (ok bool) {
 
+	// time increment for rate-dependent models
+	if o.MdlRate != nil {
+		o.MdlRate.SetDt(sol.Dt)
+	}
+
+	// large deformations: F and FΔ have been computed by ipvars
+	if o.MdlLarge != nil {
+		return !LogErr(o.MdlLarge.Update(o.States[idx], o.F, o.FΔ), "ipupdate")
+	}
+
 	// compute strains
 	ndim := Global.Ndim
 	nverts := o.Shp.Nverts
@@ -516,13 +681,52 @@ func (o *ElemU) ipupdate(idx int, S []float64, G [][]float64, sol *Solution) (ok
 		IpStrainsAndInc(o.ε, o.Δε, nverts, ndim, sol.Y, sol.ΔY, o.Umap, G)
 	}
 
+	// remove thermal strains (zero if not coupled)
+	for i := 0; i < 3; i++ {
+		o.ε[i] -= o.εθ
+		o.Δε[i] -= o.Δεθ
+	}
+
 	// call model update => update stresses
 	if LogErr(o.MdlSmall.Update(o.States[idx], o.ε, o.Δε), "ipupdate") {
 		return
 	}
+
+	// excess pore-water pressure: Δpwex = -Kw・Δεv (drained: pwex dissipates)
+	if o.Undrained {
+		if o.undrained(sol.T) {
+			o.Pwex[idx] -= o.Kw * (o.Δε[0] + o.Δε[1] + o.Δε[2])
+		} else {
+			o.Pwex[idx] = 0
+		}
+	}
+	return true
+}
+
+// undrained tells whether the undrained behaviour is active at time t
+func (o ElemU) undrained(t float64) bool {
+	if !o.Undrained {
+		return false
+	}
+	if o.UndFcn != nil {
+		return o.UndFcn.F(t, nil) > 0
+	}
 	return true
 }
 
+// total_stress returns the total stresses σ = σ' - pwex・I at integration point idx
+//  Note: it returns the effective stresses if the undrained behaviour is not active
+func (o *ElemU) total_stress(idx int, t float64) []float64 {
+	if !o.undrained(t) {
+		return o.States[idx].Sig
+	}
+	copy(o.σ, o.States[idx].Sig)
+	for i := 0; i < 3; i++ {
+		o.σ[i] -= o.Pwex[idx]
+	}
+	return o.σ
+}
+
 // ipvars computes current values @ integration points. idx == index of integration point
 func (o *ElemU) ipvars(idx int, sol *Solution) (ok bool) {
 
@@ -531,6 +735,18 @@ func (o *ElemU) ipvars(idx int, sol *Solution) (ok bool) {
 		return
 	}
 
+	// large deformations: deformation gradient and gradients w.r.t current coordinates
+	if o.MdlLarge != nil {
+		o.J0 = o.Shp.J
+		if LogErr(IpDefGrad(o.F, o.FΔ, o.Fi, o.Shp.Nverts, Global.Ndim, sol.Y, sol.ΔY, o.Umap, o.Shp.G), "ipvars") {
+			return
+		}
+		o.current_coords(sol)
+		if LogErr(o.Shp.CalcAtIp(o.xc, o.IpsElem[idx], true), "ipvars") {
+			return
+		}
+	}
+
 	// skip if steady (this must be after CalcAtIp, because callers will need S and G)
 	if Global.Sim.Data.Steady {
 		return true
@@ -570,10 +786,17 @@ func (o *ElemU) add_surfloads_to_rhs(fb []float64, sol *Solution) (ok bool) {
 		}
 	}
 
+	// follower loads in large deformation analyses
+	x := o.X
+	if o.MdlLarge != nil {
+		o.current_coords(sol)
+		x = o.xc
+	}
+
 	// compute surface integral
 	for _, load := range o.NatBcs {
 		for _, ip := range o.IpsFace {
-			if LogErr(o.Shp.CalcAtFaceIp(o.X, ip, load.IdxFace), "add_surfloads_to_rhs") {
+			if LogErr(o.Shp.CalcAtFaceIp(x, ip, load.IdxFace), "add_surfloads_to_rhs") {
 				return
 			}
 			switch load.Key {
@@ -602,3 +825,47 @@ func (o *ElemU) add_surfloads_to_rhs(fb []float64, sol *Solution) (ok bool) {
 	}
 	return true
 }
+
+// add_surfloads_to_kb adds the stiffness due to follower surface loads to K
+//  Note: in large deformation analyses, the normal vector (times Jf) of faces is computed
+//        with the current coordinates; thus the external forces depend on u
+func (o *ElemU) add_surfloads_to_kb(sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	o.current_coords(sol)
+	for _, load := range o.NatBcs {
+		for _, ip := range o.IpsFace {
+			if LogErr(o.Shp.CalcAtFaceIp(o.xc, ip, load.IdxFace), "add_surfloads_to_kb") {
+				return
+			}
+			switch load.Key {
+			case "qn", "qn0", "aqn":
+				o.Shp.CalcFaceNvecDeriv()
+				coef := ip.W * load.Fcn.F(sol.T, nil) * o.Thickness
+				Sf := o.Shp.Sf
+				lverts := o.Shp.FaceLocalV[load.IdxFace]
+				for j, m := range lverts {
+					for i := 0; i < ndim; i++ {
+						r := i + m*ndim
+						for k, n := range lverts {
+							for l := 0; l < ndim; l++ {
+								c := l + n*ndim
+								o.K[r][c] -= coef * Sf[j] * o.Shp.DFnvec[i][k][l] // -dfe/du
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// current_coords computes the current coordinates of nodes: x = X + u
+func (o *ElemU) current_coords(sol *Solution) {
+	ndim := Global.Ndim
+	for m := 0; m < o.Shp.Nverts; m++ {
+		for i := 0; i < ndim; i++ {
+			o.xc[i][m] = o.X[i][m] + sol.Y[o.Umap[i+m*ndim]]
+		}
+	}
+}
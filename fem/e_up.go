@@ -25,6 +25,7 @@ import (
 //   [2] Pedroso DM (2015) A solution to transient seepage in unsaturated porous media.
 //       Computer Methods in Applied Mechanics and Engineering, 285 791-816,
 //       http://dx.doi.org/10.1016/j.cma.2014.12.009
+//  Note: the total stress is σ = σ' - α・p・I, where α is the Biot coefficient of the porous model
 type ElemUP struct {
 
 	// auxiliary
@@ -116,6 +117,9 @@ func init() {
 			return nil
 		}
 		o.U = u_elem.(*ElemU)
+		if LogErrCond(o.U.Undrained, "ElemUP: cid=%d: the undrained flag is not available in coupled u-p analyses; pore pressures are computed directly\n", cid) {
+			return nil
+		}
 
 		// make sure p-element uses the same nubmer of integration points than u-element
 		edat.Nip = len(o.U.IpsElem)
@@ -321,7 +325,7 @@ func (o ElemUP) AddToRhs(fb []float64, sol *Solution) (ok bool) {
 				for j := 0; j < ndim; j++ {
 					fb[r] -= coef * tsr.M2T(σe, i, j) * G[m][j]
 				}
-				fb[r] += coef * p * G[m][i]
+				fb[r] += coef * o.P.Mdl.Alpha * p * G[m][i]
 			}
 		}
 	}
@@ -438,7 +442,7 @@ func (o ElemUP) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 					}
 
 					// add ∂rl/∂pl^n and ∂p/∂pl^n: Eqs (A.9) and (A.11) of [1]
-					o.Kup[c][n] += coef * (S[m]*Sb[n]*dρdpl*o.bs[j] - G[m][j]*Sb[n]*dpdpl)
+					o.Kup[c][n] += coef * (S[m]*Sb[n]*dρdpl*o.bs[j] - G[m][j]*Sb[n]*o.P.Mdl.Alpha*dpdpl)
 
 					// for seepage face
 					if o.P.DoExtrap {
@@ -465,7 +469,7 @@ func (o ElemUP) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
 
 				// inner summation term in Eq (22) of [2]
 				if o.P.DoExtrap {
-					o.P.dρldpl_ex[m][n] += o.P.Emat[m][idx] * Cpl * Sb[n]
+					o.P.dρldpl_ex[m][n] += o.P.Emat[m][idx] * dρdpl * Sb[n]
 				}
 			}
 
@@ -751,10 +755,10 @@ func (o ElemUP) add_natbcs_to_jac(sol *Solution) (ok bool) {
 	ndim := Global.Ndim
 	u_nverts := o.U.Shp.Nverts
 	var shift float64
-	var pl, fl, plmax, g, rmp float64
+	var pl, fl, plmax, g, σ, rmp float64
 	for idx, nbc := range o.P.NatBcs {
 
-		// plmax shift
+		// plmax shift or qlb
 		shift = nbc.Fcn.F(sol.T, nil)
 
 		// loop over ips of face
@@ -793,6 +797,27 @@ func (o ElemUP) add_natbcs_to_jac(sol *Solution) (ok bool) {
 						}
 					}
 				}
+			case "atm":
+
+				// variables extrapolated to face
+				_, pl, fl = o.P.fipvars(iface, sol)
+
+				// compute derivatives
+				g, σ = o.P.atm_gap(idx, pl, shift)
+				rmp = 0
+				if σ != 0 {
+					rmp = o.P.ramp(fl + o.P.κ*g)
+				}
+				for i, m := range o.P.Shp.FaceLocalV[iface] {
+					for n := 0; n < u_nverts; n++ {
+						for j := 0; j < ndim; j++ {
+							c := j + n*ndim
+							for l, r := range o.P.Shp.FaceLocalV[iface] {
+								o.Kpu[m][c] += coef * Sf[i] * Sf[l] * o.dρldus_ex[r][c] * (shift + σ*rmp)
+							}
+						}
+					}
+				}
 			}
 		}
 	}
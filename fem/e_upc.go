@@ -0,0 +1,431 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// ElemUPC represents an element for the transport of solutes in deformable porous media; i.e. the
+// Darcy flux q = ρl・wl / ρL and the water content θw = nf・sl computed by the u-p element drive the
+// advection-dispersion equation of the c-element (see ElemC and ElemPC)
+//  Note: the material must be a group with the porous materials ('c', 'l' and 'p'), a solid ('s')
+//        and a transport ('tr') material; e.g. "!c:cnd1 !l:lrm1 !p:pm1 !s:sld1 !tr:trn1"
+//  Coupling: see ElemPC; e.g. "!lag:1" or "!seq:1". In the monolithic form, Kcp := dRc/dpl and Kcu := dRc/dus are assembled;
+//            the latter accounts for the changes of porosity (θw) and for the acceleration of the
+//            solids (q), which drive the Darcy flux of the u-p formulation
+type ElemUPC struct {
+
+	// auxiliary
+	Fconds []*FaceCond // face conditions; e.g. seepage faces
+
+	// underlying elements
+	UP *ElemUP // u-p-element
+	C  *ElemC  // c-element
+
+	// flags
+	Lag  bool // lagged coupling: flow variables of transport are frozen during each time step
+	Seq  bool // sequential (staggered) coupling: u-p is solved before transport
+	Pass int  // current pass of sequential coupling: 0 == all, 1 == u-p, 2 == transport
+
+	// scratchpad. computed @ each ip
+	dθwdpl []float64   // [np] ∂θw/∂pl^n
+	dqdpl  [][]float64 // [np][ndim] ∂q/∂pl^n
+	dθwdus []float64   // [nu] ∂θw/∂us^n
+	dqdus  [][]float64 // [nu][ndim] ∂q/∂us^n
+	Kcp    [][]float64 // [nc][np] Kcp := dRc/dpl consistent tangent matrix
+	Kcu    [][]float64 // [nc][nu] Kcu := dRc/dus consistent tangent matrix
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["upc"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// p-element and c-element cell type
+		p_cellType := cellType
+		lbb := !Global.Sim.Data.NoLBB
+		if lbb {
+			p_cellType = shp.GetBasicType(cellType)
+		}
+
+		// underlying cells info
+		up_info := infogetters["up"](cellType, faceConds)
+		c_info := infogetters["c"](p_cellType, faceConds)
+
+		// solution variables
+		nverts := shp.GetNverts(cellType)
+		info.Dofs = make([][]string, nverts)
+		for i, dofs := range up_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+		for i, dofs := range c_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+
+		// maps
+		info.Y2F = up_info.Y2F
+		for key, val := range c_info.Y2F {
+			info.Y2F[key] = val
+		}
+
+		// t1 and t2 variables
+		info.T1vars = append([]string{}, up_info.T1vars...)
+		info.T1vars = append(info.T1vars, c_info.T1vars...)
+		info.T2vars = up_info.T2vars
+		return &info
+	}
+
+	// element allocator
+	eallocators["upc"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemUPC
+		o.Fconds = faceConds
+
+		// flags
+		_, o.Lag, o.Seq, _, _ = GetTranspFlags(edat.Extra, Global.Ndim)
+		if LogErrCond(o.Lag && Global.Sim.Data.Steady, "ElemUPC: cid=%d: lagged coupling requires transient simulations\n", cid) {
+			return nil
+		}
+		if LogErrCond(o.Lag && o.Seq, "ElemUPC: cid=%d: lagged and sequential couplings cannot be used together\n", cid) {
+			return nil
+		}
+
+		// allocate u-p-element
+		up_elem := eallocators["up"](cellType, faceConds, cid, edat, x)
+		if LogErrCond(up_elem == nil, "cannot allocate underlying u-p-element") {
+			return nil
+		}
+		o.UP = up_elem.(*ElemUP)
+
+		// make sure c-element uses the same number of integration points than u-element
+		edat.Nip = len(o.UP.U.IpsElem)
+
+		// allocate c-element with the same cell type of the p-element
+		c_elem := eallocators["c"](o.UP.CtypeP, faceConds, cid, edat, x)
+		if LogErrCond(c_elem == nil, "cannot allocate underlying c-element") {
+			return nil
+		}
+		o.C = c_elem.(*ElemC)
+
+		// scratchpad. computed @ each ip
+		ndim := Global.Ndim
+		o.dθwdpl = make([]float64, o.UP.P.Np)
+		o.dqdpl = la.MatAlloc(o.UP.P.Np, ndim)
+		o.dθwdus = make([]float64, o.UP.U.Nu)
+		o.dqdus = la.MatAlloc(o.UP.U.Nu, ndim)
+		o.Kcp = la.MatAlloc(o.C.Nc, o.UP.P.Np)
+		o.Kcu = la.MatAlloc(o.C.Nc, o.UP.U.Nu)
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemUPC) Id() int { return o.UP.Id() }
+
+// SetEqs set equations
+func (o *ElemUPC) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+
+	// c: equations; after the u-p ones @ each vertex of the p-element
+	up_info := infogetters["up"](o.UP.CtypeU, o.Fconds)
+	c_nverts := o.C.Shp.Nverts
+	c_eqs := make([][]int, c_nverts)
+	for i := 0; i < c_nverts; i++ {
+		c_eqs[i] = []int{eqs[i][len(up_info.Dofs[i])]}
+	}
+
+	// set equations
+	if !o.UP.SetEqs(eqs, mixedform_eqs) {
+		return
+	}
+	return o.C.SetEqs(c_eqs, nil)
+}
+
+// SetEleConds set element conditions
+func (o *ElemUPC) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	if !o.UP.SetEleConds(key, f, extra) {
+		return
+	}
+	return o.C.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+//  Note: with lagged coupling, the flow variables are frozen here
+func (o *ElemUPC) InterpStarVars(sol *Solution) (ok bool) {
+	if !o.UP.InterpStarVars(sol) {
+		return
+	}
+	if !o.C.InterpStarVars(sol) {
+		return
+	}
+	if o.Lag {
+		return o.set_flow(sol)
+	}
+	return true
+}
+
+// AddToRhs adds -R to global residual vector fb
+func (o ElemUPC) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+	if !o.UP.AddToRhs(fb, sol) {
+		return
+	}
+	if o.Pass == 1 {
+		return true // transport frozen
+	}
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	return o.C.AddToRhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemUPC) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// Kuu, Kup, Kpu, Kpp, Kpf, Kfp, Kff
+	if !o.UP.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// transport frozen: δc = 0
+	if o.Pass == 1 {
+		for _, I := range o.C.Cmap {
+			Kb.Put(I, I, 1)
+		}
+		return true
+	}
+
+	// Kcc
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	if !o.C.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// lagged or sequential coupling: flow variables do not depend on current pl and us
+	if o.frozen_flow() {
+		return true
+	}
+
+	// clear matrices
+	la.MatFill(o.Kcp, 0)
+	la.MatFill(o.Kcu, 0)
+
+	// for each integration point
+	dc := Global.DynCoefs
+	ndim := Global.Ndim
+	u_nverts := o.UP.U.Shp.Nverts
+	p_nverts := o.UP.P.Shp.Nverts
+	Cl := o.UP.P.Mdl.Cl
+	var coef, klr, RhoL, dklrdpl, Ccb, nf float64
+	var err error
+	var c int
+	for idx, ip := range o.C.IpsElem {
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.UP.ipvars(idx, sol) {
+			return
+		}
+		if !o.C.ipvars(idx, sol) {
+			return
+		}
+		o.C.ipterms(idx)
+		o.C.disp_derivs(idx)
+		coef = o.C.Shp.J * ip.W
+		S := o.UP.U.Shp.S
+		G := o.UP.U.Shp.G
+		Sb := o.UP.P.Shp.S
+		Gb := o.UP.P.Shp.G
+
+		// tpm variables
+		state := o.UP.P.States[idx]
+		klr = o.UP.P.Mdl.Cnd.Klr(state.Sl)
+		RhoL = state.RhoL
+		_, _, _, dklrdpl, err = state.Lderivs(o.UP.P.Mdl)
+		if LogErr(err, "calc of tpm derivatives failed") {
+			return
+		}
+		Ccb, err = o.UP.P.Mdl.Ccb(state)
+		if LogErr(err, "calc of tpm derivatives failed") {
+			return
+		}
+		nf = 1.0 - (1.0-state.Divus)*state.Ns0
+
+		// derivatives of flow variables w.r.t pl^n; with q = klr・Klsat・(-bs - ∇pl/ρL)
+		for n := 0; n < p_nverts; n++ {
+			o.dθwdpl[n] = -nf * Ccb * Sb[n]
+			for i := 0; i < ndim; i++ {
+				o.dqdpl[n][i] = 0
+				for j := 0; j < ndim; j++ {
+					o.dqdpl[n][i] += o.UP.P.Mdl.Klsat[i][j] * (Sb[n]*dklrdpl*(-o.UP.bs[j]-o.UP.P.gpl[j]/RhoL) + klr*(Sb[n]*Cl*o.UP.P.gpl[j]/(RhoL*RhoL)-Gb[n][j]/RhoL))
+				}
+			}
+			o.C.add_flow_derivs(o.Kcp, n, coef, o.dθwdpl[n], o.dqdpl[n])
+		}
+
+		// derivatives of flow variables w.r.t us^n; with θw = (1 - (1 - div(us))・ns0)・sl
+		for n := 0; n < u_nverts; n++ {
+			for j := 0; j < ndim; j++ {
+				c = j + n*ndim
+				o.dθwdus[c] = state.Ns0 * state.Sl * G[n][j]
+				for i := 0; i < ndim; i++ {
+					o.dqdus[c][i] = -klr * o.UP.P.Mdl.Klsat[i][j] * dc.α1 * S[n]
+				}
+				o.C.add_flow_derivs(o.Kcu, c, coef, o.dθwdus[c], o.dqdus[c])
+			}
+		}
+	}
+
+	// add Kcp and Kcu to sparse matrix Kb
+	//    _             _
+	//   |  Kuu Kup  0   |
+	//   |  Kpu Kpp  0   |
+	//   |_ Kcu Kcp Kcc _|
+	//
+	for i, I := range o.C.Cmap {
+		for j, J := range o.UP.P.Pmap {
+			Kb.Put(I, J, o.Kcp[i][j])
+		}
+		for j, J := range o.UP.U.Umap {
+			Kb.Put(I, J, o.Kcu[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+func (o *ElemUPC) Update(sol *Solution) (ok bool) {
+	if !o.UP.Update(sol) {
+		return
+	}
+	if !o.frozen_flow() {
+		if !o.set_flow(sol) {
+			return
+		}
+	}
+	return o.C.Update(sol)
+}
+
+// staggered scheme /////////////////////////////////////////////////////////////////////////////////
+
+// Staggered returns whether the sequential coupling is active
+func (o ElemUPC) Staggered() bool { return o.Seq }
+
+// SetPass sets the current pass of the sequential coupling
+//  Note: see ElemPC.SetPass
+func (o *ElemUPC) SetPass(pass int, sol *Solution) (ok bool) {
+	o.Pass = pass
+	if pass == 2 {
+		return o.set_flow(sol)
+	}
+	return true
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o ElemUPC) Ipoints() (coords [][]float64) {
+	return o.UP.Ipoints()
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *ElemUPC) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+	return o.UP.SetIniIvs(sol, ivs)
+}
+
+// BackupIvs create copy of internal variables
+func (o *ElemUPC) BackupIvs() (ok bool) {
+	return o.UP.BackupIvs()
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *ElemUPC) RestoreIvs() (ok bool) {
+	return o.UP.RestoreIvs()
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *ElemUPC) Ureset(sol *Solution) (ok bool) {
+	return o.UP.Ureset(sol)
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemUPC) Encode(enc Encoder) (ok bool) {
+	if !o.UP.Encode(enc) {
+		return
+	}
+	return o.C.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o ElemUPC) Decode(dec Decoder) (ok bool) {
+	if !o.UP.Decode(dec) {
+		return
+	}
+	return o.C.Decode(dec)
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemUPC) OutIpsData() (data []*OutIpData) {
+	data = o.UP.OutIpsData()
+	c_dat := o.C.OutIpsData()
+	chk.IntAssert(len(data), len(c_dat))
+	for i, d := range data {
+		for key, val := range c_dat[i].V {
+			d.V[key] = val
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// frozen_flow returns whether the flow variables of transport are kept constant during iterations
+func (o ElemUPC) frozen_flow() bool {
+	return o.Lag || o.Pass == 2
+}
+
+// set_flow computes the Darcy flux and the water content @ integration points of c-element
+//  Note: q = ρl・wl / ρL = klr・Klsat・hl / ρL is the flux of the u-p formulation; see ElemUP
+func (o *ElemUPC) set_flow(sol *Solution) (ok bool) {
+	ndim := Global.Ndim
+	var klr float64
+	for idx, _ := range o.C.IpsElem {
+		if !o.UP.ipvars(idx, sol) {
+			return
+		}
+		state := o.UP.P.States[idx]
+		klr = o.UP.P.Mdl.Cnd.Klr(state.Sl)
+		for i := 0; i < ndim; i++ {
+			o.C.q[idx][i] = 0
+			for j := 0; j < ndim; j++ {
+				o.C.q[idx][i] += klr * o.UP.P.Mdl.Klsat[i][j] * o.UP.hl[j] / state.RhoL
+			}
+		}
+		o.C.θw[idx] = (1.0 - (1.0-state.Divus)*state.Ns0) * state.Sl
+	}
+	return true
+}
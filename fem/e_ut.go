@@ -0,0 +1,356 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gofem/inp"
+	"github.com/cpmech/gofem/shp"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// ElemUT represents an element for thermo-mechanical analyses of solids with one-way coupling;
+// i.e. the temperature field induces the thermal strains εθ = α・(θ - θref)・I
+//  Note: the material must be a group with a solid ('s') and a thermal ('t') material; e.g. "!s:sld1 !t:thm1"
+//        Only small strain solid models can be used
+type ElemUT struct {
+
+	// auxiliary
+	Fconds []*FaceCond // face conditions
+	CtypeU string      // u: cell type
+	CtypeT string      // t: cell type
+
+	// underlying elements
+	U *ElemU // u-element
+	T *ElemT // t-element
+
+	// parameters
+	α    float64 // coefficient of linear thermal expansion
+	θref float64 // reference temperature; i.e. corresponding to null thermal strains
+
+	// scratchpad. computed @ each ip
+	dm  []float64   // [nsig] dm = D:I; derivative of stress w.r.t isotropic strain
+	Kut [][]float64 // [nu][nt] Kut := dRus/dθ consistent tangent matrix
+}
+
+// initialisation ///////////////////////////////////////////////////////////////////////////////////
+
+// register element
+func init() {
+
+	// information allocator
+	infogetters["ut"] = func(cellType string, faceConds []*FaceCond) *Info {
+
+		// new info
+		var info Info
+
+		// underlying cells info
+		u_info := infogetters["u"](cellType, faceConds)
+		t_info := infogetters["t"](ut_cellTypeT(cellType), faceConds)
+
+		// solution variables
+		nverts := shp.GetNverts(cellType)
+		info.Dofs = make([][]string, nverts)
+		for i, dofs := range u_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+		for i, dofs := range t_info.Dofs {
+			info.Dofs[i] = append(info.Dofs[i], dofs...)
+		}
+
+		// maps
+		info.Y2F = u_info.Y2F
+		for key, val := range t_info.Y2F {
+			info.Y2F[key] = val
+		}
+
+		// t1 and t2 variables
+		info.T1vars = t_info.T1vars
+		info.T2vars = u_info.T2vars
+		return &info
+	}
+
+	// element allocator
+	eallocators["ut"] = func(cellType string, faceConds []*FaceCond, cid int, edat *inp.ElemData, x [][]float64) Elem {
+
+		// basic data
+		var o ElemUT
+		o.Fconds = faceConds
+		o.CtypeU = cellType
+		o.CtypeT = ut_cellTypeT(cellType)
+
+		// allocate u element
+		u_elem := eallocators["u"](o.CtypeU, faceConds, cid, edat, x)
+		if LogErrCond(u_elem == nil, "cannot allocate underlying u-element") {
+			return nil
+		}
+		o.U = u_elem.(*ElemU)
+		if LogErrCond(o.U.MdlSmall == nil, "ElemUT: cid=%d: only small strain solid models can be used in thermo-mechanical analyses\n", cid) {
+			return nil
+		}
+
+		// make sure t-element uses the same number of integration points than u-element
+		edat.Nip = len(o.U.IpsElem)
+
+		// allocate t-element
+		t_elem := eallocators["t"](o.CtypeT, faceConds, cid, edat, x)
+		if LogErrCond(t_elem == nil, "cannot allocate underlying t-element") {
+			return nil
+		}
+		o.T = t_elem.(*ElemT)
+
+		// parameters
+		o.α = o.T.Mdl.Alpha()
+		o.θref = o.T.Mdl.Tref()
+
+		// scratchpad. computed @ each ip
+		o.dm = make([]float64, 2*Global.Ndim)
+		o.Kut = la.MatAlloc(o.U.Nu, o.T.Nt)
+
+		// return new element
+		return &o
+	}
+}
+
+// implementation ///////////////////////////////////////////////////////////////////////////////////
+
+// Id returns the cell Id
+func (o ElemUT) Id() int { return o.U.Id() }
+
+// SetEqs set equations
+func (o *ElemUT) SetEqs(eqs [][]int, mixedform_eqs []int) (ok bool) {
+
+	// u: equations
+	u_info := infogetters["u"](o.CtypeU, o.Fconds)
+	u_nverts := len(u_info.Dofs)
+	u_eqs := make([][]int, u_nverts)
+	for i := 0; i < u_nverts; i++ {
+		nkeys := len(u_info.Dofs[i])
+		u_eqs[i] = make([]int, nkeys)
+		for j := 0; j < nkeys; j++ {
+			u_eqs[i][j] = eqs[i][j]
+		}
+	}
+
+	// t: equations
+	t_info := infogetters["t"](o.CtypeT, o.Fconds)
+	t_nverts := len(t_info.Dofs)
+	t_eqs := make([][]int, t_nverts)
+	for i := 0; i < t_nverts; i++ {
+		start := len(u_info.Dofs[i])
+		nkeys := len(t_info.Dofs[i])
+		t_eqs[i] = make([]int, nkeys)
+		for j := 0; j < nkeys; j++ {
+			t_eqs[i][j] = eqs[i][start+j]
+		}
+	}
+
+	// set equations
+	if !o.U.SetEqs(u_eqs, mixedform_eqs) {
+		return
+	}
+	return o.T.SetEqs(t_eqs, nil)
+}
+
+// SetEleConds set element conditions
+func (o *ElemUT) SetEleConds(key string, f fun.Func, extra string) (ok bool) {
+	if !o.U.SetEleConds(key, f, extra) {
+		return
+	}
+	return o.T.SetEleConds(key, f, extra)
+}
+
+// InterpStarVars interpolates star variables to integration points
+func (o *ElemUT) InterpStarVars(sol *Solution) (ok bool) {
+	if !o.U.InterpStarVars(sol) {
+		return
+	}
+	return o.T.InterpStarVars(sol)
+}
+
+// AddToRhs adds -R to global residual vector fb
+//  Note: the thermal strains are taken into account when updating the stresses
+func (o ElemUT) AddToRhs(fb []float64, sol *Solution) (ok bool) {
+	if !o.U.AddToRhs(fb, sol) {
+		return
+	}
+	return o.T.AddToRhs(fb, sol)
+}
+
+// AddToKb adds element K to global Jacobian matrix Kb
+func (o ElemUT) AddToKb(Kb *la.Triplet, sol *Solution, firstIt bool) (ok bool) {
+
+	// Kuu and Ktt
+	if !o.U.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+	if !o.T.AddToKb(Kb, sol, firstIt) {
+		return
+	}
+
+	// clear matrices
+	la.MatFill(o.Kut, 0)
+
+	// for each integration point
+	ndim := Global.Ndim
+	nsig := 2 * ndim
+	u_nverts := o.U.Shp.Nverts
+	t_nverts := o.T.Shp.Nverts
+	var coef float64
+	for idx, ip := range o.U.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.T.Shp.CalcAtIp(o.T.X, ip, false), "AddToKb") {
+			return
+		}
+		if LogErr(o.U.Shp.CalcAtIp(o.U.X, ip, true), "AddToKb") {
+			return
+		}
+		coef = o.U.Shp.J * ip.W * o.U.Thickness
+		S := o.U.Shp.S
+		G := o.U.Shp.G
+		Sb := o.T.Shp.S
+
+		// dm := D:I
+		if LogErr(o.U.MdlSmall.CalcD(o.U.D, o.U.States[idx], firstIt), "AddToKb") {
+			return
+		}
+		for i := 0; i < nsig; i++ {
+			o.dm[i] = o.U.D[i][0] + o.U.D[i][1] + o.U.D[i][2]
+		}
+
+		// Kut := ∂Rus^m/∂θ^n = -∫ G^m・(D:I)・α・Sb^n
+		if o.U.UseB {
+			radius := 1.0
+			if Global.Sim.Data.Axisym {
+				radius = o.U.Shp.AxisymGetRadius(o.U.X)
+				coef *= radius
+			}
+			IpBmatrix(o.U.B, ndim, u_nverts, G, Global.Sim.Data.Axisym, radius, S)
+			for c := 0; c < o.U.Nu; c++ {
+				for n := 0; n < t_nverts; n++ {
+					for k := 0; k < nsig; k++ {
+						o.Kut[c][n] -= coef * o.U.B[k][c] * o.dm[k] * o.α * Sb[n]
+					}
+				}
+			}
+		} else {
+			for m := 0; m < u_nverts; m++ {
+				for i := 0; i < ndim; i++ {
+					r := i + m*ndim
+					for n := 0; n < t_nverts; n++ {
+						for j := 0; j < ndim; j++ {
+							o.Kut[r][n] -= coef * G[m][j] * tsr.M2T(o.dm, i, j) * o.α * Sb[n]
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// add Kut to sparse matrix Kb
+	//    _         _
+	//   |  Kuu Kut  |
+	//   |_  0  Ktt _|
+	//
+	for i, I := range o.U.Umap {
+		for j, J := range o.T.Tmap {
+			Kb.Put(I, J, o.Kut[i][j])
+		}
+	}
+	return true
+}
+
+// Update perform (tangent) update
+func (o *ElemUT) Update(sol *Solution) (ok bool) {
+
+	// for each integration point
+	var θ, Δθ float64
+	for idx, ip := range o.U.IpsElem {
+
+		// interpolation functions and gradients
+		if LogErr(o.T.Shp.CalcAtIp(o.T.X, ip, false), "Update") {
+			return
+		}
+
+		// compute θ and Δθ @ ip
+		θ, Δθ = 0, 0
+		for m := 0; m < o.T.Shp.Nverts; m++ {
+			r := o.T.Tmap[m]
+			θ += o.T.Shp.S[m] * sol.Y[r]
+			Δθ += o.T.Shp.S[m] * sol.ΔY[r]
+		}
+
+		// interpolation functions, gradients and variables @ ip
+		if !o.U.ipvars(idx, sol) {
+			return
+		}
+
+		// u: update internal state with thermal strains
+		o.U.εθ = o.α * (θ - o.θref)
+		o.U.Δεθ = o.α * Δθ
+		if !o.U.ipupdate(idx, o.U.Shp.S, o.U.Shp.G, sol) {
+			return
+		}
+	}
+	return o.T.Update(sol)
+}
+
+// internal variables ///////////////////////////////////////////////////////////////////////////////
+
+// Ipoints returns the real coordinates of integration points [nip][ndim]
+func (o ElemUT) Ipoints() (coords [][]float64) {
+	return o.U.Ipoints()
+}
+
+// SetIniIvs sets initial ivs for given values in sol and ivs map
+func (o *ElemUT) SetIniIvs(sol *Solution, ivs map[string][]float64) (ok bool) {
+	return o.U.SetIniIvs(sol, ivs)
+}
+
+// BackupIvs create copy of internal variables
+func (o *ElemUT) BackupIvs() (ok bool) {
+	return o.U.BackupIvs()
+}
+
+// RestoreIvs restore internal variables from copies
+func (o *ElemUT) RestoreIvs() (ok bool) {
+	return o.U.RestoreIvs()
+}
+
+// Ureset fixes internal variables after u (displacements) have been zeroed
+func (o *ElemUT) Ureset(sol *Solution) (ok bool) {
+	return o.U.Ureset(sol)
+}
+
+// writer ///////////////////////////////////////////////////////////////////////////////////////////
+
+// Encode encodes internal variables
+func (o ElemUT) Encode(enc Encoder) (ok bool) {
+	return o.U.Encode(enc)
+}
+
+// Decode decodes internal variables
+func (o ElemUT) Decode(dec Decoder) (ok bool) {
+	return o.U.Decode(dec)
+}
+
+// OutIpsData returns data from all integration points for output
+func (o ElemUT) OutIpsData() (data []*OutIpData) {
+	return o.U.OutIpsData()
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// ut_cellTypeT returns the cell type of the t-element; i.e. the basic type (e.g. qua4 for qua8),
+// unless NoLBB is set. Thus, the thermal strains are interpolated in the same way as the strains
+func ut_cellTypeT(cellType string) string {
+	if Global.Sim.Data.NoLBB {
+		return cellType
+	}
+	return shp.GetBasicType(cellType)
+}
@@ -59,6 +59,14 @@ type ElemIntvars interface {
 	Ureset(sol *Solution) (ok bool)                              // fixes internal variables after u (displacements) have been zeroed
 }
 
+// ElemStaggered defines coupled elements solved by a staggered (sequential) scheme; e.g. flow and
+// transport: the first set of equations is solved within the time step and then the second set is
+// solved with the converged values of the first set. pass == 0 means a monolithic solution
+type ElemStaggered interface {
+	Staggered() bool                           // returns whether the staggered scheme is active; e.g. "!seq:1"
+	SetPass(pass int, sol *Solution) (ok bool) // sets the current pass: 1 == first set, 2 == second set
+}
+
 // Info holds all information required to set a simulation stage
 type Info struct {
 
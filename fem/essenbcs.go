@@ -153,7 +153,20 @@ func (o *EssentialBcs) Set(key string, nodes []*Node, fcn fun.Func, extra string
 	chk.IntAssertLessThan(0, len(nodes)) // 0 < len(nod)
 
 	// rigid element
+	//  Note: with "!dof:uy", for instance, only the uy dofs are tied; e.g. frictionless rigid plates
 	if key == "rigid" {
+		if dkey, found := io.Keycode(extra, "dof"); found {
+			a := nodes[0].GetDof(dkey)
+			if LogErrCond(a == nil, "rigid: cannot find dof = %q in node\n", dkey) {
+				return false // problem
+			}
+			for i := 1; i < len(nodes); i++ {
+				if b := nodes[i].GetDof(dkey); b != nil {
+					o.add(key, []int{a.Eq, b.Eq}, []float64{1, -1}, &fun.Zero)
+				}
+			}
+			return true // success
+		}
 		a := nodes[0].Dofs
 		for i := 1; i < len(nodes); i++ {
 			for j, b := range nodes[i].Dofs {
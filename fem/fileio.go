@@ -158,11 +158,60 @@ func (o *Domain) ReadIvs(dir, fnkey string, tidx, proc int) (ok bool) {
 	return true
 }
 
+// SaveCon saves the state of contact nodes to a file which name is set with tidx (time output index)
+//  Note: nothing is saved if there are no contact conditions
+func (o Domain) SaveCon(tidx int) (ok bool) {
+
+	// skip if not root or without contacts
+	if !Global.Root || len(o.Contacts.Nodes) == 0 {
+		return true
+	}
+
+	// buffer and encoder
+	var buf bytes.Buffer
+	enc := GetEncoder(&buf)
+
+	// encode contacts
+	if !o.Contacts.Encode(enc) {
+		return
+	}
+
+	// save file
+	fn := out_con_path(Global.Dirout, Global.Fnkey, tidx, Global.Rank)
+	return save_file("SaveCon", "contacts", fn, &buf)
+}
+
+// ReadCon reads the state of contact nodes from a file which name is set with tidx (time output index)
+//  Note: nothing is read if there are no contact conditions
+func (o *Domain) ReadCon(dir, fnkey string, tidx int) (ok bool) {
+
+	// skip if without contacts
+	if len(o.Contacts.Nodes) == 0 {
+		return true
+	}
+
+	// open file
+	fn := out_con_path(dir, fnkey, tidx, 0) // 0 => reading always from proc # 0
+	fil, err := os.Open(fn)
+	if LogErr(err, "ReadCon") {
+		return
+	}
+	defer func() {
+		LogErr(fil.Close(), "ReadCon: cannot close file")
+	}()
+
+	// decode contacts
+	return o.Contacts.Decode(GetDecoder(fil))
+}
+
 // Out performs output of Solution and Internal values to files
 func (o *Domain) Out(tidx int) (ok bool) {
 	if !o.SaveSol(tidx) {
 		return
 	}
+	if !o.SaveCon(tidx) {
+		return
+	}
 	return o.SaveIvs(tidx)
 }
 
@@ -183,6 +232,9 @@ func (o *Domain) In(sum *Summary, tidx int, allInOne bool) (ok bool) {
 				return
 			}
 		}
+		if !o.ReadCon(sum.Dirout, sum.Fnkey, tidx) {
+			return
+		}
 		return o.ReadSol(sum.Dirout, sum.Fnkey, tidx)
 	}
 
@@ -190,6 +242,9 @@ func (o *Domain) In(sum *Summary, tidx int, allInOne bool) (ok bool) {
 	if !o.ReadIvs(sum.Dirout, sum.Fnkey, tidx, Global.Rank) {
 		return
 	}
+	if !o.ReadCon(sum.Dirout, sum.Fnkey, tidx) {
+		return
+	}
 	return o.ReadSol(sum.Dirout, sum.Fnkey, tidx)
 }
 
@@ -203,6 +258,10 @@ func out_ele_path(dir, fnkey string, tidx, proc int) string {
 	return path.Join(dir, io.Sf("%s_p%d_ele_%010d.%s", fnkey, proc, tidx, Global.Enc))
 }
 
+func out_con_path(dir, fnkey string, tidx, proc int) string {
+	return path.Join(dir, io.Sf("%s_p%d_con_%010d.%s", fnkey, proc, tidx, Global.Enc))
+}
+
 func save_file(function, category, filename string, buf *bytes.Buffer) (ok bool) {
 	fil, err := os.Create(filename)
 	if LogErr(err, function) {
@@ -0,0 +1,126 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/mpi"
+)
+
+// ElemPofElem returns the underlying p-element of ele or nil
+func ElemPofElem(ele Elem) *ElemP {
+	switch e := ele.(type) {
+	case *ElemP:
+		return e
+	case *ElemUP:
+		return e.P
+	case *ElemPT:
+		return e.P
+	case *ElemPC:
+		return e.P
+	case *ElemUPC:
+		return e.UP.P
+	}
+	return nil
+}
+
+// LiqMass integrates the mass of liquid ∫ρl dΩ over all p-elements of this domain
+func (o *Domain) LiqMass() (M float64, err error) {
+	var m float64
+	for _, ele := range o.Elems {
+		e := ElemPofElem(ele)
+		if e == nil {
+			continue
+		}
+		m, err = e.LiqMass()
+		if err != nil {
+			return
+		}
+		M += m
+	}
+	return
+}
+
+// FaceLiqFlux integrates the liquid mass flux ∫ρl・wl・n dΓ over faces with a given tag
+//  volumetric -- divide ρl・wl by ρL; i.e. integrate the Darcy velocity
+//  Note: n is the outward normal; hence, positive values mean outflow. ρl・wl and ρL are
+//        extrapolated to the nodes of each element and then interpolated to the face ips
+func (o *Domain) FaceLiqFlux(ftag int, volumetric bool) (Q float64, err error) {
+	ndim := o.Msh.Ndim
+	ρwl := make([]float64, ndim)
+	var ρwlN [][]float64
+	var ρLN []float64
+	var ρL float64
+	for _, c := range o.Msh.Cells {
+		e := ElemPofElem(o.Cid2elem[c.Id])
+		if e == nil {
+			continue
+		}
+		ρwlN = nil
+		for iface, tag := range c.FTags {
+			if tag != ftag {
+				continue
+			}
+			if ρwlN == nil {
+				ρwlN, ρLN, err = e.NodalLiqFlux(o.Sol)
+				if err != nil {
+					return
+				}
+			}
+			for _, ipf := range e.IpsFace {
+				err = e.Shp.CalcAtFaceIp(e.X, ipf, iface)
+				if err != nil {
+					return
+				}
+				Sf := e.Shp.Sf
+				nvec := e.Shp.Fnvec // outward normal multiplied by Jf
+				ρL = 0
+				la.VecFill(ρwl, 0)
+				for j, m := range e.Shp.FaceLocalV[iface] {
+					ρL += Sf[j] * ρLN[m]
+					for i := 0; i < ndim; i++ {
+						ρwl[i] += Sf[j] * ρwlN[m][i]
+					}
+				}
+				if volumetric {
+					Q += ipf.W * la.VecDot(ρwl, nvec) / ρL
+				} else {
+					Q += ipf.W * la.VecDot(ρwl, nvec)
+				}
+			}
+		}
+	}
+	return
+}
+
+// mass_balance computes the liquid mass-balance error of the last time step:
+//  mberr = M(t) - M(t-Δt) + Δt・(Q(t-Δt) + Q(t)) / 2
+//  where M is the mass of liquid and Q is the liquid mass flux leaving the domain through the faces
+//  with tags given in Sim.Data.MassBal. In distributed runs, M and Q are summed over all processors
+//  first -- only record M and Q; e.g. at the beginning of a stage
+func (o *Domain) mass_balance(first bool) (mberr float64, ok bool) {
+	M, e := o.LiqMass()
+	if LogErr(e, "cannot compute mass of liquid") {
+		return
+	}
+	var Q, q float64
+	for _, tag := range Global.Sim.Data.MassBal {
+		q, e = o.FaceLiqFlux(tag, false)
+		if LogErr(e, "cannot compute flux of liquid") {
+			return
+		}
+		Q += q
+	}
+	if Global.Distr {
+		MQ, w := []float64{M, Q}, make([]float64, 2)
+		mpi.AllReduceSum(MQ, w)
+		M, Q = MQ[0], MQ[1]
+	}
+	if !first {
+		mberr = M - o.mbM + o.Sol.Dt*(o.mbQ+Q)/2.0
+	}
+	o.mbM, o.mbQ = M, Q
+	return mberr, true
+}
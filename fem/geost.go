@@ -45,6 +45,7 @@ type GeoLayer struct {
 	Cl    float64    // liquid compressibility
 	RhoS0 float64    // initial density of solids
 	nf0   float64    // initial (constant) porosity
+	Alpha float64    // Biot coefficient
 	K0    float64    // earth-pressure at rest
 	Dpl   float64    // liquid pressure added by this layer
 	DsigV float64    // absolute value of vertical stress increment added by this layer
@@ -252,7 +253,7 @@ func (o *Domain) SetGeoSt(stg *inp.Stage) (ok bool) {
 					}
 					pl[i], ρL[i] = s.pl, s.ρL
 					p := s.pl * sl
-					σVe := -s.σV + p
+					σVe := -s.σV + lay.Alpha*p
 					σHe := lay.K0 * σVe
 					sx[i], sy[i], sz[i] = σHe, σVe, σHe
 					if ndim == 3 {
@@ -282,6 +283,7 @@ func (o *GeoLayer) get_porous_parameters(reg *inp.Region, ctag int) (ok bool) {
 		return
 	}
 	//var RhoL0, BulkL float64
+	o.Alpha = 1
 	if matname, found := io.Keycode(mat.Extra, "p"); found {
 		m := Global.Sim.Mdb.Get(matname)
 		for _, p := range m.Prms {
@@ -290,6 +292,8 @@ func (o *GeoLayer) get_porous_parameters(reg *inp.Region, ctag int) (ok bool) {
 				o.RhoS0 = p.V
 			case "nf0":
 				o.nf0 = p.V
+			case "alpha":
+				o.Alpha = p.V
 			}
 		}
 	}
@@ -299,6 +303,9 @@ func (o *GeoLayer) get_porous_parameters(reg *inp.Region, ctag int) (ok bool) {
 	if LogErrCond(o.nf0 < 1e-7, "geost: initial porosity nf0=%g is incorrect", o.nf0) {
 		return
 	}
+	if LogErrCond(o.Alpha <= 0 || o.Alpha > 1, "geost: Biot coefficient alpha=%g is incorrect", o.Alpha) {
+		return
+	}
 	return true
 }
 
@@ -317,7 +324,7 @@ func (o GeoLayers) String() string {
 		if i > 0 {
 			l += ",\n"
 		}
-		l += io.Sf("  { \"Tags\":%v, \"Zmin\":%g, \"Zmax\":%g, \"nf0\":%g, \"RhoS0\":%g, \"Cl\":%g\n", lay.Tags, lay.Zmin, lay.Zmax, lay.nf0, lay.RhoS0, lay.Cl)
+		l += io.Sf("  { \"Tags\":%v, \"Zmin\":%g, \"Zmax\":%g, \"nf0\":%g, \"RhoS0\":%g, \"alpha\":%g, \"Cl\":%g\n", lay.Tags, lay.Zmin, lay.Zmax, lay.nf0, lay.RhoS0, lay.Alpha, lay.Cl)
 		l += "    \"Nodes\":["
 		for j, nod := range lay.Nodes {
 			if j > 0 {
@@ -63,6 +63,24 @@ func GetSolidFlags(extra string) (useB, debug bool, thickness float64) {
 	return
 }
 
+// GetUndrainedFlag returns the flag for undrained (total stress) analyses with effective stress models
+func GetUndrainedFlag(extra string) (undrained bool) {
+	if s_und, found := io.Keycode(extra, "undrained"); found {
+		undrained = io.Atob(s_und)
+	}
+	return
+}
+
+// GetCorotFlag returns the flag for co-rotational (geometrically nonlinear) kinematics of
+// structural elements
+//  Note: rods and beams accept it in 2D and 3D; e.g. "!corot:1"
+func GetCorotFlag(extra string) (corot bool) {
+	if s_corot, found := io.Keycode(extra, "corot"); found {
+		corot = io.Atob(s_corot)
+	}
+	return
+}
+
 func GetSeepFaceFlags(extra string) (Macaulay bool, BetRamp, Kappa float64) {
 
 	// defaults
@@ -86,3 +104,41 @@ func GetSeepFaceFlags(extra string) (Macaulay bool, BetRamp, Kappa float64) {
 	}
 	return
 }
+
+func GetTranspFlags(extra string, ndim int) (supg, lag, seq bool, q []float64, θw float64) {
+
+	// defaults
+	supg = false
+	lag = false
+	seq = false
+	q = make([]float64, ndim)
+	θw = 1.0
+
+	// use SUPG stabilisation ?
+	if s_supg, found := io.Keycode(extra, "supg"); found {
+		supg = io.Atob(s_supg)
+	}
+
+	// lagged coupling with flow ?
+	if s_lag, found := io.Keycode(extra, "lag"); found {
+		lag = io.Atob(s_lag)
+	}
+
+	// sequential (staggered) coupling with flow ?
+	if s_seq, found := io.Keycode(extra, "seq"); found {
+		seq = io.Atob(s_seq)
+	}
+
+	// constant Darcy flux
+	for i, key := range []string{"qx", "qy", "qz"}[:ndim] {
+		if s_q, found := io.Keycode(extra, key); found {
+			q[i] = io.Atof(s_q)
+		}
+	}
+
+	// constant volumetric water content
+	if s_thw, found := io.Keycode(extra, "thw"); found {
+		θw = io.Atof(s_thw)
+	}
+	return
+}
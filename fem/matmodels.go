@@ -9,6 +9,8 @@ import (
 	"github.com/cpmech/gofem/mporous"
 	"github.com/cpmech/gofem/mreten"
 	"github.com/cpmech/gofem/msolid"
+	"github.com/cpmech/gofem/mtherm"
+	"github.com/cpmech/gofem/mtransp"
 
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
@@ -95,3 +97,114 @@ func GetAndInitSolidModel(matname string, ndim int) (msolid.Model, fun.Prms) {
 	// results
 	return mdl, matdata.Prms
 }
+
+// GetAndInitThermModel gets heat conduction model from material name
+//  Note: with grouped materials, the 't' subkey in Extra selects the thermal material.
+//        It returns nil on errors, after logging
+func GetAndInitThermModel(matname string) mtherm.Model {
+
+	// material name
+	matdata := Global.Sim.Mdb.Get(matname)
+	if LogErrCond(matdata == nil, "materials database failed on getting %q (thermal) material\n", matname) {
+		return nil
+	}
+
+	// handle groups
+	if matdata.Model == "group" {
+		t_matname, found := io.Keycode(matdata.Extra, "t")
+		if LogErrCond(!found, "cannot find thermal model in grouped material data. 't' subkey needed in Extra field") {
+			return nil
+		}
+		matname = t_matname
+		matdata = Global.Sim.Mdb.Get(matname)
+		if LogErrCond(matdata == nil, "materials database failed on getting %q (thermal/sub) material\n", matname) {
+			return nil
+		}
+	}
+
+	// initialise model
+	mdl := mtherm.GetModel(Global.Sim.Data.FnameKey, matname, matdata.Model, false)
+	if LogErrCond(mdl == nil, "cannot find thermal model named %q", matdata.Model) {
+		return nil
+	}
+	if LogErr(mdl.Init(matdata.Prms), "thermal model initialisation failed") {
+		return nil
+	}
+	return mdl
+}
+
+// GetAndInitTranspModel gets solute transport model from material name
+//  Note: with grouped materials, the 'tr' subkey in Extra selects the transport material.
+//        It returns nil on errors, after logging
+func GetAndInitTranspModel(matname string) *mtransp.Model {
+
+	// material name
+	matdata := Global.Sim.Mdb.Get(matname)
+	if LogErrCond(matdata == nil, "materials database failed on getting %q (transport) material\n", matname) {
+		return nil
+	}
+
+	// handle groups
+	if matdata.Model == "group" {
+		tr_matname, found := io.Keycode(matdata.Extra, "tr")
+		if LogErrCond(!found, "cannot find transport model in grouped material data. 'tr' subkey needed in Extra field") {
+			return nil
+		}
+		matname = tr_matname
+		matdata = Global.Sim.Mdb.Get(matname)
+		if LogErrCond(matdata == nil, "materials database failed on getting %q (transport/sub) material\n", matname) {
+			return nil
+		}
+	}
+
+	// initialise model
+	if LogErrCond(matdata.Model != "transp", "model of transport material %q must be \"transp\"; %q is incorrect\n", matname, matdata.Model) {
+		return nil
+	}
+	mdl := mtransp.GetModel(Global.Sim.Data.FnameKey, matname, false)
+	if LogErr(mdl.Init(matdata.Prms), "transport model initialisation failed") {
+		return nil
+	}
+	return mdl
+}
+
+// GetWaterBulkModulus computes the equivalent bulk modulus of pore water Kw = BulkL / nf0 for
+// undrained analyses with effective stress models
+//  Note: the 'p' subkey in Extra of a grouped material selects the porous material where nf0
+//        and BulkL are read from. It returns zero on errors, after logging
+func GetWaterBulkModulus(matname string) (Kw float64) {
+
+	// material name
+	matdata := Global.Sim.Mdb.Get(matname)
+	if LogErrCond(matdata == nil, "materials database failed on getting %q (porous) material\n", matname) {
+		return
+	}
+
+	// handle groups
+	if LogErrCond(matdata.Model != "group", "material %q must be a 'group' with porous data for undrained analyses\n", matname) {
+		return
+	}
+	p_matname, found := io.Keycode(matdata.Extra, "p")
+	if LogErrCond(!found, "cannot find porous model in grouped material data. 'p' subkey needed in Extra field") {
+		return
+	}
+	matdata = Global.Sim.Mdb.Get(p_matname)
+	if LogErrCond(matdata == nil, "materials database failed on getting %q (porous/sub) material\n", p_matname) {
+		return
+	}
+
+	// parameters
+	var nf0, BulkL float64
+	for _, p := range matdata.Prms {
+		switch p.N {
+		case "nf0":
+			nf0 = p.V
+		case "BulkL":
+			BulkL = p.V
+		}
+	}
+	if LogErrCond(nf0 <= 0 || BulkL <= 0, "porosity nf0=%g and liquid bulk modulus BulkL=%g must be positive for undrained analyses\n", nf0, BulkL) {
+		return
+	}
+	return BulkL / nf0
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/cpmech/gofem/mporous"
 	"github.com/cpmech/gofem/mreten"
 	"github.com/cpmech/gofem/msolid"
+	"github.com/cpmech/gofem/mtherm"
+	"github.com/cpmech/gofem/mtransp"
 
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
@@ -174,6 +176,11 @@ func Run() (runisok bool) {
 			if !d.Out(tidx) {
 				break
 			}
+			if len(Global.Sim.Data.MassBal) > 0 {
+				if _, ok := d.mass_balance(true); !ok {
+					break
+				}
+			}
 		}
 		if Stop() {
 			return
@@ -185,6 +192,8 @@ func Run() (runisok bool) {
 		mreten.LogModels()
 		mporous.LogModels()
 		msolid.LogModels()
+		mtherm.LogModels()
+		mtransp.LogModels()
 
 		// skip stage?
 		if stg.Skip {
@@ -223,6 +232,7 @@ func Run() (runisok bool) {
 			t += Δt
 			for _, d := range domains {
 				d.Sol.T = t
+				d.Sol.Dt = Δt
 			}
 			Δtout = DtOut.F(t, nil)
 
@@ -234,6 +244,7 @@ func Run() (runisok bool) {
 			}
 
 			// for all domains
+			mberr, mbok := 0.0, len(Global.Sim.Data.MassBal) > 0
 			for _, d := range domains {
 
 				// backup solution if divergence control is on
@@ -258,11 +269,30 @@ func Run() (runisok bool) {
 						d.Sol.T = t
 						ndiverg += 1
 						md *= 0.5
+						mbok = false
 						continue
 					}
 					ndiverg = 0
 					md = 1.0
 				}
+
+				// liquid mass balance
+				if mbok {
+					δmb, ok := d.mass_balance(false)
+					if !ok {
+						return
+					}
+					mberr += δmb
+				}
+			}
+
+			// record liquid mass balance
+			if mbok {
+				sum.MbTimes = append(sum.MbTimes, t)
+				sum.MbErrs = append(sum.MbErrs, mberr)
+				if Global.Sim.Data.ShowR {
+					io.Pf("%13.6e%4s%23.15e\n", t, "mb", mberr)
+				}
 			}
 
 			// perform output
@@ -302,11 +332,72 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 		return
 	}
 
+	// pair contact nodes with master segments
+	d.Contacts.Detect(d.Sol)
+
+	// monolithic solution
+	if len(d.ElemStagg) == 0 {
+		diverging, ok = iterate(t, d, sum, true)
+		if ok && !diverging {
+			d.Contacts.Commit(d.Sol)
+		}
+		return
+	}
+
+	// staggered solution: the second pass starts from the converged state of the first one and
+	// the internal variables are backed up here only; i.e. they always refer to the beginning of
+	// the time step, since the update of secondary variables uses the total increments ΔY
+	for _, e := range d.ElemIntvars {
+		e.BackupIvs()
+	}
+	defer func() {
+		for _, e := range d.ElemStagg {
+			e.SetPass(0, d.Sol)
+		}
+	}()
+	for pass := 1; pass <= 2; pass++ {
+		for _, e := range d.ElemStagg {
+			if LogErrCond(!e.SetPass(pass, d.Sol), "cannot set pass %d of staggered solution; e.g. transfer of flow variables failed\n", pass) {
+				break
+			}
+		}
+		if Stop() {
+			return
+		}
+		diverging, ok = iterate(t, d, sum, false)
+		if !ok || diverging {
+			return
+		}
+	}
+	d.Contacts.Commit(d.Sol)
+	return
+}
+
+// iterate runs the Newton-Raphson iterations. backup indicates whether the internal variables must
+// be backed up at the first iteration
+func iterate(t float64, d *Domain, sum *Summary, backup bool) (diverging, ok bool) {
+
 	// auxiliary variables
 	var it int
 	var largFb, largFb0, Lδu float64
 	var prevFb, prevLδu float64
 
+	// augmented Lagrangian: update contact multipliers upon convergence
+	augment := func() bool {
+		if d.Contacts.Augment(d.Sol) {
+			prevFb, prevLδu = math.Inf(1), math.Inf(1) // disregard divergence control in next iteration
+			if it == 0 && backup {
+				// the backup block below is skipped; thus backup here the converged state of the
+				// previous time step, which is restored in the next iteration
+				for _, e := range d.ElemIntvars {
+					e.BackupIvs()
+				}
+			}
+			return true
+		}
+		return false
+	}
+
 	// message
 	if Global.Sim.Data.ShowR {
 		io.Pfyel("\n%13s%4s%23s%23s\n", "t", "it", "largFb", "Lδu")
@@ -331,6 +422,9 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 			return
 		}
 
+		// lock-off forces and locked heads of anchors; added by the processor holding the rod-joint at the head
+		d.Anchors.AddToRhs(d.Fb, d.Sol)
+
 		// join all fb
 		if Global.Distr {
 			mpi.AllReduceSum(d.Fb, d.Wb) // this must be done here because there might be nodes sharing boundary conditions
@@ -342,6 +436,9 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 		// essential boundary conditioins; e.g. constraints
 		d.EssenBcs.AddToRhs(d.Fb, d.Sol)
 
+		// contact forces
+		d.Contacts.AddToRhs(d.Fb, d.Sol)
+
 		// debug
 		if Global.Debug {
 			//la.PrintVec("fb", d.Fb[:d.Ny], "%13.10f ", false)
@@ -363,12 +460,18 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 		} else {
 			// check convergence on Lf0
 			if largFb < Global.Sim.Solver.FbTol*largFb0 { // converged on fb
+				if augment() {
+					continue
+				}
 				break
 			}
 		}
 
 		// check convergence on fb_min
 		if largFb < Global.Sim.Solver.FbMin { // converged with smallest value of fb
+			if augment() {
+				continue
+			}
 			break
 		}
 
@@ -396,14 +499,18 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 				return
 			}
 
+			// locked heads of anchors
+			d.Anchors.AddToKb(d.Kb)
+
 			// debug
 			if Global.DebugKb != nil {
 				Global.DebugKb(d, it)
 			}
 
-			// join A and tr(A) matrices into Kb
+			// join A and tr(A) matrices into Kb and add contact contributions
 			if Global.Root {
 				d.Kb.PutMatAndMatT(&d.EssenBcs.A)
+				d.Contacts.AddToKb(d.Kb)
 			}
 
 			// initialise linear solver
@@ -458,7 +565,7 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 		}
 
 		// backup / restore
-		if it == 0 {
+		if it == 0 && backup {
 			// create backup copy of all secondary variables
 			for _, e := range d.ElemIntvars {
 				e.BackupIvs()
@@ -490,6 +597,9 @@ func run_iterations(t, Δt float64, d *Domain, sum *Summary) (diverging, ok bool
 
 		// stop if converged on δu
 		if Lδu < Global.Sim.Solver.Itol {
+			if augment() {
+				continue
+			}
 			break
 		}
 
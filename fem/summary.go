@@ -18,6 +18,8 @@ type Summary struct {
 	Nproc    int         // number of processors used in last last run; equal to 1 if not distributed
 	OutTimes []float64   // [nOutTimes] output times
 	Resids   utl.DblList // [nTimes][nIter] residuals (if Stat is on; includes all stages)
+	MbTimes  []float64   // [nTimes] times of steps with liquid mass balance (if MassBal is given; includes all stages)
+	MbErrs   []float64   // [nTimes] liquid mass-balance error of each step (if MassBal is given; includes all stages)
 	Dirout   string      // directory where results are stored
 	Fnkey    string      // filename key of simulation
 }
@@ -5,11 +5,14 @@
 package fem
 
 import (
+	"math"
 	"sort"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
 )
 
 func Test_beam01(tst *testing.T) {
@@ -88,3 +91,454 @@ func Test_beam01(tst *testing.T) {
 	chk.Ints(tst, "constrained ux equations", ct_ux_eqs, []int{0})
 	chk.Ints(tst, "constrained uy equations", ct_uy_eqs, []int{1, 4})
 }
+
+func Test_beam02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam02")
+
+	// run simulation
+	if !Start("data/beam02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+	for _, nod := range d.Nodes {
+		chk.IntAssert(len(nod.Dofs), 6)
+	}
+
+	// properties
+	E, ν, A, Izz, Iyy, J := 1000.0, 0.25, 0.1, 0.002, 0.001, 0.003
+	G := E / (2.0 * (1.0 + ν))
+	F := []float64{10, -20, 30}
+	M := []float64{5, 0, -5}
+
+	// check tip displacements and rotations of Euler-Bernoulli (cell 0) and Timoshenko (cell 1) cantilevers
+	for cid, tip := range []int{1, 3} {
+
+		// local system
+		e := d.Elems[cid].(*Beam)
+		l := e.L
+		chk.Scalar(tst, "l", 1e-15, l, 3)
+		e0, e1, e2 := e.T[0][:3], e.T[1][:3], e.T[2][:3]
+		chk.Scalar(tst, "e0・e1", 1e-15, la.VecDot(e0, e1), 0)
+		chk.Scalar(tst, "e0・e2", 1e-15, la.VecDot(e0, e2), 0)
+		chk.Scalar(tst, "e1・e2", 1e-15, la.VecDot(e1, e2), 0)
+		v := []float64{0, 0, 1}
+		if cid == 1 {
+			v = []float64{1, 0, 0}
+		}
+		if la.VecDot(v, e2) <= 0 || math.Abs(la.VecDot(v, e1)) > 1e-15 {
+			tst.Errorf("e2 must be aligned with the orientation vector\n")
+			return
+		}
+
+		// local loads
+		N, Py, Pz := la.VecDot(F, e0), la.VecDot(F, e1), la.VecDot(F, e2)
+		T, My, Mz := la.VecDot(M, e0), la.VecDot(M, e1), la.VecDot(M, e2)
+
+		// local solution
+		var sv, sw float64 // shear deformation
+		if cid == 1 {
+			κ := 0.8
+			sv, sw = Py*l/(κ*G*A), Pz*l/(κ*G*A)
+		}
+		ll := l * l
+		u := N * l / (E * A)
+		v1 := Py*ll*l/(3.0*E*Izz) + sv + Mz*ll/(2.0*E*Izz)
+		w := Pz*ll*l/(3.0*E*Iyy) + sw - My*ll/(2.0*E*Iyy)
+		θx := T * l / (G * J)
+		θy := -Pz*ll/(2.0*E*Iyy) + My*l/(E*Iyy)
+		θz := Py*ll/(2.0*E*Izz) + Mz*l/(E*Izz)
+
+		// global solution
+		U := make([]float64, 3)
+		Θ := make([]float64, 3)
+		for i := 0; i < 3; i++ {
+			U[i] = u*e0[i] + v1*e1[i] + w*e2[i]
+			Θ[i] = θx*e0[i] + θy*e1[i] + θz*e2[i]
+		}
+		io.Pforan("cid=%d: U=%v Θ=%v\n", cid, U, Θ)
+		nod := d.Vid2node[tip]
+		for i, key := range []string{"ux", "uy", "uz"} {
+			chk.Scalar(tst, io.Sf("%s @ %d", key, tip), 1e-11, d.Sol.Y[nod.GetEq(key)], U[i])
+		}
+		for i, key := range []string{"rx", "ry", "rz"} {
+			chk.Scalar(tst, io.Sf("%s @ %d", key, tip), 1e-11, d.Sol.Y[nod.GetEq(key)], Θ[i])
+		}
+
+		// internal forces: equilibrium of the portion between station and tip
+		for i, x := range e.Xsta {
+			a := l - la.VecDot([]float64{x[0] - e.X[0][0], x[1] - e.X[1][0], x[2] - e.X[2][0]}, e0) // distance to tip
+			chk.Vector(tst, io.Sf("F @ sta %d", i), 1e-11, e.Fsta[i], []float64{N, Py, Pz, T, My - a*Pz, Mz + a*Py})
+		}
+	}
+}
+
+func Test_beam03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam03")
+
+	// run simulation
+	if !Start("data/beam03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// check: default local system of beam along x is {x, y, z}
+	e := d.Elems[0].(*Beam)
+	chk.Vector(tst, "e0", 1e-15, e.T[0][:3], []float64{1, 0, 0})
+	chk.Vector(tst, "e1", 1e-15, e.T[1][:3], []float64{0, 1, 0})
+	chk.Vector(tst, "e2", 1e-15, e.T[2][:3], []float64{0, 0, 1})
+
+	// check: simply supported beam with uniform loads along local y and z
+	E, Izz, Iyy, L := 1000.0, 0.002, 0.001, 2.0
+	qn, qz := 2.0, -1.0
+	mid, left := d.Vid2node[1], d.Vid2node[0]
+	chk.Scalar(tst, "uy @ mid", 1e-14, d.Sol.Y[mid.GetEq("uy")], 5.0*qn*math.Pow(L, 4)/(384.0*E*Izz))
+	chk.Scalar(tst, "uz @ mid", 1e-14, d.Sol.Y[mid.GetEq("uz")], 5.0*qz*math.Pow(L, 4)/(384.0*E*Iyy))
+	chk.Scalar(tst, "rz @ left", 1e-14, d.Sol.Y[left.GetEq("rz")], qn*math.Pow(L, 3)/(24.0*E*Izz))
+	chk.Scalar(tst, "ry @ left", 1e-14, d.Sol.Y[left.GetEq("ry")], -qz*math.Pow(L, 3)/(24.0*E*Iyy))
+	chk.Scalar(tst, "rz @ mid", 1e-14, d.Sol.Y[mid.GetEq("rz")], 0)
+	chk.Scalar(tst, "ry @ mid", 1e-14, d.Sol.Y[mid.GetEq("ry")], 0)
+
+	// check internal forces: N, Vy, Vz, T, My, Mz
+	for _, elem := range d.Elems {
+		e := elem.(*Beam)
+		for i, x := range e.Xsta {
+			X := x[0]
+			Vy := qn * (L/2.0 - X)
+			Vz := qz * (L/2.0 - X)
+			My := -qz * X * (L - X) / 2.0
+			Mz := qn * X * (L - X) / 2.0
+			chk.Vector(tst, io.Sf("F @ cid=%d sta=%d", e.Cid, i), 1e-12, e.Fsta[i], []float64{0, Vy, Vz, 0, -My, -Mz})
+		}
+	}
+}
+
+func Test_beam04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam04")
+
+	// run simulation
+	if !Start("data/beam04.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// check section
+	e := d.Elems[0].(*Beam)
+	b, h := 0.1, 0.2
+	chk.Scalar(tst, "A", 1e-15, e.A, b*h)
+	chk.Scalar(tst, "Izz", 1e-15, e.Izz, b*h*h*h/12.0)
+
+	// check rotation @ left support
+	E, q, L := 1e6, -15.0, 1.0
+	chk.Scalar(tst, "rz @ left", 1e-13, d.Sol.Y[d.Vid2node[0].GetEq("rz")], q*L*L*L/(24.0*E*e.Izz))
+
+	// check internal forces @ stations: sagging moment is positive
+	chk.IntAssert(len(e.Xsta), 3)
+	out := e.OutIpsData()
+	chk.IntAssert(len(out), 3)
+	for i, dat := range out {
+		x := dat.X[0]
+		chk.Scalar(tst, io.Sf("x @ sta %d", i), 1e-15, x, float64(i)/2.0)
+		chk.Scalar(tst, io.Sf("N @ sta %d", i), 1e-12, *dat.V["N"], 0)
+		chk.Scalar(tst, io.Sf("V @ sta %d", i), 1e-12, *dat.V["V"], q*(L/2.0-x))
+		chk.Scalar(tst, io.Sf("M @ sta %d", i), 1e-12, *dat.V["M"], -q*x*(L-x)/2.0)
+	}
+}
+
+func Test_beamsec01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beamsec01")
+
+	// rectangle
+	var sec BeamSection
+	if !sec.Init("rect", fun.Prms{&fun.Prm{N: "b", V: 0.2}, &fun.Prm{N: "h", V: 0.4}}) {
+		tst.Errorf("Init failed\n")
+		return
+	}
+	chk.Scalar(tst, "rect: A  ", 1e-15, sec.A, 0.08)
+	chk.Scalar(tst, "rect: Izz", 1e-15, sec.Izz, 0.2*0.064/12.0)
+	chk.Scalar(tst, "rect: Iyy", 1e-15, sec.Iyy, 0.4*0.008/12.0)
+	chk.Scalar(tst, "rect: J  ", 1e-15, sec.J, 0.4*0.008*(1.0/3.0-0.21*0.5*(1.0-0.0625/12.0)))
+
+	// circle
+	d := 0.5
+	if !sec.Init("circ", fun.Prms{&fun.Prm{N: "d", V: d}}) {
+		tst.Errorf("Init failed\n")
+		return
+	}
+	chk.Scalar(tst, "circ: A  ", 1e-15, sec.A, math.Pi*d*d/4.0)
+	chk.Scalar(tst, "circ: Izz", 1e-15, sec.Izz, math.Pi*math.Pow(d, 4)/64.0)
+	chk.Scalar(tst, "circ: Iyy", 1e-15, sec.Iyy, sec.Izz)
+	chk.Scalar(tst, "circ: J  ", 1e-15, sec.J, math.Pi*math.Pow(d, 4)/32.0)
+
+	// pipe: compare with difference of circles
+	var inner BeamSection
+	inner.Init("circ", fun.Prms{&fun.Prm{N: "d", V: d - 0.1}})
+	outer := sec
+	if !sec.Init("pipe", fun.Prms{&fun.Prm{N: "d", V: d}, &fun.Prm{N: "t", V: 0.05}}) {
+		tst.Errorf("Init failed\n")
+		return
+	}
+	chk.Scalar(tst, "pipe: A  ", 1e-15, sec.A, outer.A-inner.A)
+	chk.Scalar(tst, "pipe: Izz", 1e-15, sec.Izz, outer.Izz-inner.Izz)
+	chk.Scalar(tst, "pipe: J  ", 1e-15, sec.J, outer.J-inner.J)
+
+	// I/H: compare with composition of rectangles
+	b, h, tf, tw := 0.3, 0.5, 0.02, 0.01
+	if !sec.Init("ish", fun.Prms{&fun.Prm{N: "b", V: b}, &fun.Prm{N: "h", V: h}, &fun.Prm{N: "tf", V: tf}, &fun.Prm{N: "tw", V: tw}}) {
+		tst.Errorf("Init failed\n")
+		return
+	}
+	hw := h - 2.0*tf
+	yf := (h - tf) / 2.0 // distance from centroid of flanges to centroid of section
+	chk.Scalar(tst, "ish: A  ", 1e-15, sec.A, 2.0*b*tf+hw*tw)
+	chk.Scalar(tst, "ish: Izz", 1e-15, sec.Izz, 2.0*(b*tf*tf*tf/12.0+b*tf*yf*yf)+tw*hw*hw*hw/12.0)
+	chk.Scalar(tst, "ish: Iyy", 1e-15, sec.Iyy, 2.0*tf*b*b*b/12.0+hw*tw*tw*tw/12.0)
+
+	// explicitly given properties take precedence
+	A, Izz, Iyy, J := 1.0, 0.0, 0.0, 2.0
+	sec.Fill(&A, &Izz, &Iyy, &J)
+	chk.Vector(tst, "A, Izz, Iyy, J", 1e-15, []float64{A, Izz, Iyy, J}, []float64{1, sec.Izz, sec.Iyy, 2})
+
+	// errors
+	if sec.Init("rect", fun.Prms{&fun.Prm{N: "b", V: 0.2}}) {
+		tst.Errorf("Init should have failed because h is missing\n")
+		return
+	}
+	if sec.Init("triangle", nil) {
+		tst.Errorf("Init should have failed because of unknown section\n")
+		return
+	}
+}
+
+// beam_domain allocates the domain of the first region and stage and reads the last results
+func beam_domain(tst *testing.T) (d *Domain) {
+	distr := false
+	d = NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return nil
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !d.In(sum, len(sum.OutTimes)-1, true) {
+		tst.Errorf("cannot read results\n")
+		return nil
+	}
+	return
+}
+
+func Test_beam05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam05")
+
+	// run simulation
+	if !Start("data/beam05.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+	E, Izz := 1e6, 0.1*0.008/12.0
+	EI := E * Izz
+
+	// clamped beam with released rotation at right end == propped cantilever
+	e := d.Elems[0].(*Beam)
+	chk.Ints(tst, "hinge", e.Hinge, []int{5})
+	q, L := -1.0, 1.0
+	RB := -3.0 * q * L / 8.0 // reaction @ right end
+	for i, x := range e.Xsta {
+		a := L - x[0]
+		chk.Vector(tst, io.Sf("propped: F @ sta %d", i), 1e-12, e.Fsta[i], []float64{0, RB + q*a, a * (RB + q*a/2.0)})
+	}
+
+	// cantilever with rigid offsets at both ends
+	e = d.Elems[1].(*Beam)
+	chk.Scalar(tst, "offsets: L", 1e-15, e.L, 1)
+	chk.Vector(tst, "offsets: x @ first sta", 1e-15, e.Xsta[0], []float64{0.25, 1})
+	chk.Vector(tst, "offsets: x @ last sta", 1e-15, e.Xsta[e.Nsta-1], []float64{1.25, 1})
+	P, l, c := -1.0, 1.0, 0.5 // c is the length of the rigid offset @ tip
+	vb := P*l*l*l/(3.0*EI) + c*P*l*l/(2.0*EI)
+	θb := P*l*l/(2.0*EI) + c*P*l/EI
+	tip := d.Vid2node[3]
+	chk.Scalar(tst, "offsets: ux @ tip", 1e-15, d.Sol.Y[tip.GetEq("ux")], 0)
+	chk.Scalar(tst, "offsets: uy @ tip", 1e-13, d.Sol.Y[tip.GetEq("uy")], vb+c*θb)
+	chk.Scalar(tst, "offsets: rz @ tip", 1e-13, d.Sol.Y[tip.GetEq("rz")], θb)
+	for i, x := range e.Xsta {
+		a := 1.75 - x[0] // distance to load
+		chk.Vector(tst, io.Sf("offsets: F @ sta %d", i), 1e-12, e.Fsta[i], []float64{0, P, a * P})
+	}
+}
+
+func Test_beam06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam06")
+
+	// run simulation
+	if !Start("data/beam06.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// cantilever with tip moment bent into a quarter of circle: each element has a constant
+	// moment and no axial force; thus the chords keep their lengths and rotate by (k+½)Δ
+	n, L0, EI := 10, 0.1, 0.01
+	M := math.Pi / 2.0 * EI
+	Δ := M * L0 / EI // rotation per element
+	var x, y float64
+	for k := 0; k <= n; k++ {
+		nod := d.Vid2node[k]
+		chk.Scalar(tst, io.Sf("ux @ %d", k), 1e-9, d.Sol.Y[nod.GetEq("ux")], x-float64(k)*L0)
+		chk.Scalar(tst, io.Sf("uy @ %d", k), 1e-9, d.Sol.Y[nod.GetEq("uy")], y)
+		chk.Scalar(tst, io.Sf("rz @ %d", k), 1e-9, d.Sol.Y[nod.GetEq("rz")], float64(k)*Δ)
+		x += L0 * math.Cos((float64(k)+0.5)*Δ)
+		y += L0 * math.Sin((float64(k)+0.5)*Δ)
+	}
+
+	// tip close to the exact solution: x = ρ, y = ρ with ρ = EI / M
+	tip := d.Vid2node[n]
+	ρ := EI / M
+	chk.Scalar(tst, "x @ tip", 1e-3, 1+d.Sol.Y[tip.GetEq("ux")], ρ)
+	chk.Scalar(tst, "y @ tip", 1e-3, d.Sol.Y[tip.GetEq("uy")], ρ)
+
+	// internal forces (co-rotated frames)
+	for _, ele := range d.Elems {
+		e := ele.(*Beam)
+		for i := range e.Xsta {
+			chk.Vector(tst, io.Sf("F @ cell %d sta %d", e.Cid, i), 1e-9, e.Fsta[i], []float64{0, 0, M})
+		}
+	}
+}
+
+func Test_beam07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("beam07")
+
+	// start simulation
+	if !Start("data/beam07.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb: tip element
+	if true {
+		defer beam_DebugKb(&testKb{
+			tst: tst, eid: 9, tol: 1e-4, verb: chk.Verbose,
+			ni: -1, nj: -1, itmin: 1, itmax: -1, tmin: 0.5, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// cantilever along e0 = {1,1,1}/√3 with tip moment about e1 = {-1,1,0}/√2 (local y):
+	// planar bending in the e0-e2 plane with e2 = {-1,-1,2}/√6. Each element has a constant
+	// moment and no axial force; thus the chords keep their lengths and rotate by (k+½)Δ
+	n, L0, EI := 10, 0.1, 1.0
+	M := math.Pi / 2.0 * EI
+	Δ := M * L0 / EI // rotation per element
+	s3, s2, s6 := math.Sqrt(3), math.Sqrt(2), math.Sqrt(6)
+	e0 := []float64{1 / s3, 1 / s3, 1 / s3}
+	e1 := []float64{-1 / s2, 1 / s2, 0}
+	e2 := []float64{-1 / s6, -1 / s6, 2 / s6}
+	x := make([]float64, 3)
+	for k := 0; k <= n; k++ {
+		nod := d.Vid2node[k]
+		for i, key := range []string{"ux", "uy", "uz"} {
+			chk.Scalar(tst, io.Sf("%s @ %d", key, k), 1e-9, d.Sol.Y[nod.GetEq(key)], x[i]-float64(k)*L0*e0[i])
+		}
+		for i, key := range []string{"rx", "ry", "rz"} {
+			chk.Scalar(tst, io.Sf("%s @ %d", key, k), 1e-9, d.Sol.Y[nod.GetEq(key)], float64(k)*Δ*e1[i])
+		}
+		φ := (float64(k) + 0.5) * Δ
+		for i := 0; i < 3; i++ {
+			x[i] += L0 * (e0[i]*math.Cos(φ) - e2[i]*math.Sin(φ))
+		}
+	}
+
+	// tip close to the exact solution: ρ e0 - ρ e2 with ρ = EI / M
+	tip := d.Vid2node[n]
+	ρ := EI / M
+	for i, key := range []string{"ux", "uy", "uz"} {
+		chk.Scalar(tst, io.Sf("x%d @ tip", i), 1e-3, e0[i]+d.Sol.Y[tip.GetEq(key)], ρ*(e0[i]-e2[i]))
+	}
+
+	// internal forces (co-rotated frames)
+	for _, ele := range d.Elems {
+		e := ele.(*Beam)
+		for i := range e.Xsta {
+			chk.Vector(tst, io.Sf("F @ cell %d sta %d", e.Cid, i), 1e-9, e.Fsta[i], []float64{0, 0, 0, 0, M, 0})
+		}
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_c01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("c01")
+
+	// start simulation
+	if !Start("data/c01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// check dofs
+	for _, nod := range dom.Nodes {
+		chk.IntAssert(len(nod.Dofs), 1)
+		chk.StrAssert(nod.Dofs[0].Key, "c")
+	}
+
+	// read results
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !dom.ReadSol(sum.Dirout, sum.Fnkey, len(sum.OutTimes)-1) {
+		tst.Errorf("cannot read solution\n")
+		return
+	}
+
+	// analytical solution: c(y) = (exp(Pe) - exp(Pe・y/L)) / (exp(Pe) - 1) with Pe = q・L/D
+	L, Pe := 10.0, 5.0
+	for _, nod := range dom.Nodes {
+		y := nod.Vert.C[1]
+		c := dom.Sol.Y[nod.Dofs[0].Eq]
+		cana := (math.Exp(Pe) - math.Exp(Pe*y/L)) / (math.Exp(Pe) - 1.0)
+		chk.Scalar(tst, io.Sf("c(y=%5.2f)", y), 1e-2, c, cana)
+	}
+}
+
+func Test_c02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("c02")
+
+	// start simulation
+	if !Start("data/c02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer c_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: 200, tmax: 200,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_pc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("pc01")
+
+	// start simulation
+	if !Start("data/pc01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer pc_DebugKb(&testKb{
+			tst: tst, eid: 1, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: 500, tmax: 500,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_pc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("pc02")
+
+	// start simulation
+	if !Start("data/pc02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer pc_DebugKb(&testKb{
+			tst: tst, eid: 1, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: 500, tmax: 500,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_upc01(tst *testing.T) {
+
+	/* same as pc01 but with u-p elements and a very stiff solid; thus, the concentrations
+	   must be nearly equal to the ones computed with the rigid porous medium of pc01 */
+
+	//verbose()
+	chk.PrintTitle("upc01")
+
+	// reference: rigid porous medium
+	cref := c_at_verts(tst, "data/pc01.sim", nil)
+	if cref == nil {
+		return
+	}
+
+	// deformable porous medium
+	c := c_at_verts(tst, "data/upc01.sim", func() func() {
+		return upc_DebugKb(&testKb{
+			tst: tst, eid: 1, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: 500, tmax: 500,
+		})
+	})
+	if c == nil {
+		return
+	}
+
+	// check
+	if len(c) != len(cref) {
+		tst.Errorf("test failed: numbers of nodes with c are different: %d != %d\n", len(c), len(cref))
+		return
+	}
+	for vid, cval := range c {
+		io.Pforan("vid=%2d c=%12.8f cref=%12.8f\n", vid, cval, cref[vid])
+		if math.Abs(cval-cref[vid]) > 1e-3 {
+			tst.Errorf("test failed: c=%g @ vertex %d is different from reference c=%g\n", cval, vid, cref[vid])
+			return
+		}
+	}
+}
+
+func Test_pc03(tst *testing.T) {
+
+	/* same as pc01 but with sequential coupling; since transport does not affect the flow,
+	   the staggered solution must be equal to the monolithic one */
+
+	//verbose()
+	chk.PrintTitle("pc03")
+
+	// reference: monolithic coupling
+	cref := c_at_verts(tst, "data/pc01.sim", nil)
+	if cref == nil {
+		return
+	}
+
+	// sequential coupling
+	c := c_at_verts(tst, "data/pc03.sim", nil)
+	if c == nil {
+		return
+	}
+
+	// check
+	if len(c) != len(cref) {
+		tst.Errorf("test failed: numbers of nodes with c are different: %d != %d\n", len(c), len(cref))
+		return
+	}
+	for vid, cval := range c {
+		io.Pforan("vid=%2d c=%12.8f cref=%12.8f\n", vid, cval, cref[vid])
+		chk.Scalar(tst, io.Sf("c @ vertex %d", vid), 1e-6, cval, cref[vid])
+	}
+
+	// fluxes @ ips: upward seepage
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !d.In(sum, len(sum.OutTimes)-1, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+	for _, dat := range d.Elems[0].OutIpsData() {
+		qly, jay := *dat.V["qly"], *dat.V["jay"]
+		io.Pforan("y=%6.3f qly=%13.6e jay=%13.6e jdy=%13.6e\n", dat.X[1], qly, jay, *dat.V["jdy"])
+		if qly <= 0 {
+			tst.Errorf("test failed: Darcy flux must be upward: qly=%g\n", qly)
+			return
+		}
+		if jay < 0 || jay > qly*(1.0+1e-10) {
+			tst.Errorf("test failed: advective flux must be within [0, qly] since 0 <= c <= 1: jay=%g\n", jay)
+			return
+		}
+		chk.Scalar(tst, "thw (saturated)", 1e-10, *dat.V["thw"], 0.3)
+	}
+}
+
+func Test_pc04(tst *testing.T) {
+
+	/* same as pc03 but the transfer of flow variables to the transport equations fails; thus the
+	   time step must end with an error instead of solving the transport with stale flow variables */
+
+	//verbose()
+	chk.PrintTitle("pc04")
+
+	// start simulation
+	if !Start("data/pc03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// domain with failing transfer of flow variables
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if d == nil {
+		tst.Errorf("NewDomain failed\n")
+		return
+	}
+	defer func() {
+		if !d.InitLSol {
+			d.LinSol.Clean()
+		}
+	}()
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	if len(d.ElemStagg) == 0 {
+		tst.Errorf("test failed: there are no staggered elements\n")
+		return
+	}
+	for i, e := range d.ElemStagg {
+		d.ElemStagg[i] = &c_failpass{e}
+	}
+
+	// first time step
+	Δt := Global.Sim.Stages[0].Control.DtFunc.F(0, nil)
+	if LogErr(Global.DynCoefs.CalcBoth(Δt), "cannot compute dynamic coefficients") {
+		tst.Errorf("test failed\n")
+		return
+	}
+	d.Sol.T = Δt
+	d.Sol.Dt = Δt
+	var sum Summary
+	_, ok := run_iterations(Δt, Δt, d, &sum)
+	if ok {
+		tst.Errorf("test failed: time step must fail if the flow variables cannot be set\n")
+		return
+	}
+
+	// the monolithic pass must be restored
+	for _, e := range d.ElemStagg {
+		chk.IntAssert(e.(*c_failpass).ElemStaggered.(*ElemPC).Pass, 0)
+	}
+}
+
+// c_failpass wraps a staggered element such that the second pass (transfer of flow variables) fails
+type c_failpass struct {
+	ElemStaggered
+}
+
+// SetPass fails at the second pass
+func (o *c_failpass) SetPass(pass int, sol *Solution) (ok bool) {
+	if pass == 2 {
+		return false
+	}
+	return o.ElemStaggered.SetPass(pass, sol)
+}
+
+// c_at_verts runs simulation and returns the concentrations @ vertices at the last output time
+func c_at_verts(tst *testing.T, simfilepath string, debugKb func() (resetDebugKb func())) (c map[int]float64) {
+
+	// start simulation
+	if !Start(simfilepath, true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if debugKb != nil {
+		defer debugKb()()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// read results
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !d.In(sum, len(sum.OutTimes)-1, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+	c = make(map[int]float64)
+	for _, nod := range d.Nodes {
+		if eq := nod.GetEq("c"); eq >= 0 {
+			c[nod.Vert.Id] = d.Sol.Y[eq]
+		}
+	}
+	return
+}
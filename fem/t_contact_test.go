@@ -0,0 +1,290 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_contact01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("contact01")
+
+	// run simulation
+	if !Start("data/contact01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check: penalty => penetration = q / kn
+	E, ν, kn := 10000.0, 0.25, 1e5
+	M := E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν))
+	contact_check_oedometer(tst, 1.0/kn, M, 1e-10, 1e-14)
+
+	// check Jacobian
+	d := contact_domain(tst)
+	if d == nil {
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	contact_check_Kb(tst, d, sum, len(sum.OutTimes)-1, 1e-4)
+}
+
+func Test_contact02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("contact02")
+
+	// run simulation
+	if !Start("data/contact02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check: augmented Lagrangian => no penetration
+	E, ν := 10000.0, 0.25
+	M := E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν))
+	contact_check_oedometer(tst, 0, M, 1e-6, 1e-10)
+}
+
+func Test_contact03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("contact03")
+
+	// run simulation
+	if !Start("data/contact03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	d := contact_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// check contact state
+	μ := 0.3
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		for _, c := range d.Contacts.Nodes {
+			io.Pforan("t=%g vid=%d: gn=%g pn=%g pt=%v\n", t, c.Node.Vert.Id, c.Gn, c.Pn, c.PtC)
+			if t > 0 {
+				if c.Pn <= 0 {
+					tst.Errorf("node %d should be in contact at t=%g\n", c.Node.Vert.Id, t)
+					return
+				}
+				chk.Scalar(tst, "gn", 1e-15, c.Gn, -c.Pn/1e5)
+			}
+
+			// full sliding: friction opposes dragging in the x direction
+			if t > 1.5 {
+				chk.Scalar(tst, "|pt| - μ pn", 1e-10, la.VecNorm(c.PtC)-μ*c.Pn, 0)
+				if c.PtC[0] >= 0 {
+					tst.Errorf("friction at node %d should oppose dragging\n", c.Node.Vert.Id)
+					return
+				}
+			}
+		}
+	}
+
+	// check Jacobian
+	contact_check_Kb(tst, d, sum, len(sum.OutTimes)-1, 1e-4)
+}
+
+func Test_contact04(tst *testing.T) {
+
+	/* augmented Lagrangian with at most 10 augmentations per time step: at the end of the
+	   compression (t=1), the penetration is still above gtol but kn times the penetration is
+	   below fbmin; thus, in the first hold step, the augmentation happens at the first iteration
+	   and the internal variables must be backed up there */
+
+	//verbose()
+	chk.PrintTitle("contact04")
+
+	// run simulation
+	if !Start("data/contact04.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	d := contact_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// check: stresses (from internal variables) must be consistent with displacements
+	E, ν := 10000.0, 0.25
+	M := E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν))
+	H := 2.01
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		δ := 0.02 * min(t, 1)
+		q := d.Contacts.Nodes[0].Pn
+		io.Pforan("t=%g q=%g gn=%g\n", t, q, d.Contacts.Nodes[0].Gn)
+		if t == 1 && -d.Contacts.Nodes[0].Gn < 1e-10 {
+			tst.Errorf("test failed: penetration should be above gtol at t=%g\n", t)
+			return
+		}
+		for _, e := range d.Elems {
+			for _, s := range e.(*ElemU).States {
+				chk.Scalar(tst, io.Sf("σy @ t=%g", t), 1e-3, s.Sig[1], -q)
+			}
+		}
+		for _, n := range d.Nodes {
+			y := n.Vert.C[1]
+			uy := -q * y / M
+			if y > 1.005 {
+				uy = -δ + q*(H-y)/M
+			}
+			chk.Scalar(tst, io.Sf("uy @ %d", n.Vert.Id), 1e-7, d.Sol.Y[n.GetEq("uy")], uy)
+		}
+	}
+}
+
+// contact_domain allocates the domain of the first region and stage
+func contact_domain(tst *testing.T) (d *Domain) {
+	distr := false
+	d = NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return nil
+	}
+	chk.IntAssert(len(d.Contacts.Nodes), 2)
+	for _, c := range d.Contacts.Nodes {
+		chk.Scalar(tst, "area", 1e-15, c.Area, 0.5)
+	}
+	return
+}
+
+// contact_check_oedometer checks the oedometric compression of two blocks of height 1 after
+// the gap of 0.01 between them is closed by moving the top face downwards with uy = -0.01 t
+//  cn -- compliance of contact; i.e. penetration = cn * q
+func contact_check_oedometer(tst *testing.T, cn, M, tolq, tolu float64) {
+
+	// allocate domain
+	d := contact_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// check
+	g0, H := 0.01, 2.01
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		δ := 0.01 * t
+		q := max(0, (δ-g0)/(2.0/M+cn))
+		io.Pforan("t=%g q=%g\n", t, q)
+
+		// contact state
+		for _, c := range d.Contacts.Nodes {
+			chk.Scalar(tst, io.Sf("pn @ t=%g", t), tolq, c.Pn, q)
+			if q > 0 {
+				chk.Scalar(tst, io.Sf("gn @ t=%g", t), tolu, c.Gn, -cn*q)
+			} else {
+				chk.Scalar(tst, io.Sf("gn @ t=%g", t), 1e-15, c.Gn, g0-δ)
+			}
+		}
+
+		// vertical displacements
+		for _, n := range d.Nodes {
+			y := n.Vert.C[1]
+			uy := -q * y / M
+			if y > 1.005 {
+				uy = -δ + q*(H-y)/M
+			}
+			chk.Scalar(tst, io.Sf("uy @ %d", n.Vert.Id), tolu, d.Sol.Y[n.GetEq("uy")], uy)
+		}
+	}
+}
+
+// contact_check_Kb compares the contact contributions to Kb with numerical derivatives of
+// the contact forces in fb, during the time step ending at output index tidx
+func contact_check_Kb(tst *testing.T, d *Domain, sum *Summary, tidx int, tol float64) {
+
+	// pairing at the beginning of time step
+	if !d.In(sum, tidx-1, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+	yold := make([]float64, d.Ny)
+	copy(yold, d.Sol.Y)
+	d.Contacts.Detect(d.Sol)
+
+	// state at the end of time step
+	if !d.In(sum, tidx, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+	for i := 0; i < d.Ny; i++ {
+		d.Sol.ΔY[i] = d.Sol.Y[i] - yold[i]
+	}
+
+	// analytical Jacobian
+	fb := make([]float64, d.Ny)
+	d.Contacts.AddToRhs(fb, d.Sol)
+	var Kb la.Triplet
+	Kb.Init(d.Ny, d.Ny, d.Contacts.Nnz())
+	d.Contacts.AddToKb(&Kb)
+	K := Kb.ToMatrix(nil).ToDense()
+
+	// numerical Jacobian: central differences with a small step to avoid changes of state
+	h := 1e-6
+	fp := make([]float64, d.Ny)
+	fm := make([]float64, d.Ny)
+	for j := 0; j < d.Ny; j++ {
+		yj, Δyj := d.Sol.Y[j], d.Sol.ΔY[j]
+		for k, f := range [][]float64{fp, fm} {
+			δ := h * float64(1-2*k)
+			d.Sol.Y[j], d.Sol.ΔY[j] = yj+δ, Δyj+δ
+			la.VecFill(f, 0)
+			d.Contacts.AddToRhs(f, d.Sol)
+		}
+		d.Sol.Y[j], d.Sol.ΔY[j] = yj, Δyj
+		for i := 0; i < d.Ny; i++ {
+			dnum := -(fp[i] - fm[i]) / (2.0 * h)
+			chk.AnaNum(tst, io.Sf("K%d%d", i, j), tol, K[i][j], dnum, chk.Verbose)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/msolid"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_interface01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface01")
+
+	// start simulation
+	if !Start("data/itf01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// mesh with inserted interface
+	msh := Global.Sim.Regions[0].Msh
+	chk.IntAssert(len(msh.Verts), 8)
+	chk.IntAssert(len(msh.Cells), 3)
+	chk.Ints(tst, "interface verts", msh.Cells[2].Verts, []int{2, 3, 6, 7})
+	chk.Ints(tst, "top cell verts  ", msh.Cells[1].Verts, []int{7, 6, 4, 5})
+
+	// for debugging Kb
+	if true {
+		defer interface_DebugKb(&testKb{
+			tst: tst, eid: 2, tol: 1e-8, verb: chk.Verbose,
+			ni: 8, nj: 8, itmin: 0, itmax: -1, tmin: -1, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !d.In(sum, len(sum.OutTimes)-1, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+
+	// oedometric conditions
+	q, E, ν, kn := 100.0, 10000.0, 0.25, 1000.0
+	M := E * (1.0 - ν) / ((1.0 + ν) * (1.0 - 2.0*ν))
+
+	// interface: normal direction, tractions and opening
+	e := d.Elems[2].(*Interface)
+	for idx, _ := range e.IpsElem {
+		chk.Vector(tst, "n", 1e-15, e.Q[idx][0], []float64{0, 1})
+		chk.Vector(tst, "t", 1e-10, e.States[idx].Sig, []float64{-q, 0})
+		chk.Vector(tst, "w", 1e-13, e.States[idx].Phi, []float64{-q / kn, 0})
+	}
+
+	// vertical displacements
+	for _, n := range d.Nodes {
+		y := n.Vert.C[1]
+		uy := -q * y / M
+		if n.Vert.Id > 5 || y > 1.5 {
+			uy -= q / kn
+		}
+		chk.Scalar(tst, io.Sf("uy @ %d", n.Vert.Id), 1e-13, d.Sol.Y[n.GetEq("uy")], uy)
+	}
+}
+
+func Test_interface02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface02")
+
+	// start simulation
+	if !Start("data/itf02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer interface_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-7, verb: chk.Verbose,
+			ni: 8, nj: 8, itmin: 0, itmax: -1, tmin: -1, tmax: 2,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check
+	interface_check_path(tst, []float64{0, 1}, []string{"ux", "uy"})
+}
+
+func Test_interface03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface03")
+
+	// start simulation
+	if !Start("data/itf03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer interface_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-7, verb: chk.Verbose,
+			ni: 12, nj: 12, itmin: 0, itmax: -1, tmin: -1, tmax: 2,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check
+	interface_check_path(tst, []float64{0, 0, 1}, []string{"ux", "uy", "uz"})
+}
+
+// interface_check_path compares the results of the single interface element of a simulation
+// with prescribed displacements of the top face against a direct integration of the model
+func interface_check_path(tst *testing.T, n []float64, fnames []string) {
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	e := d.Elems[0].(*Interface)
+	Q := e.Q[0]
+	chk.Vector(tst, "n", 1e-15, Q[0], n)
+
+	// model
+	ndim := Global.Ndim
+	var mdl msolid.InterfaceM1
+	if mdl.Init(ndim, Global.Sim.Mdb.Get("itf1").Prms) != nil {
+		tst.Errorf("cannot initialise model\n")
+		return
+	}
+	s, _ := mdl.InitIntVars()
+
+	// prescribed displacements
+	fcns := make([]fun.Func, ndim)
+	for i, name := range fnames {
+		fcns[i] = Global.Sim.Functions.Get(name)
+	}
+
+	// strength parameters
+	c, tφ, σt := 10.0, math.Tan(math.Pi/6.0), 4.0
+
+	// check results
+	uold := make([]float64, ndim)
+	unew := make([]float64, ndim)
+	Δu := make([]float64, ndim)
+	Δw := make([]float64, ndim)
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+
+		// direct integration
+		for i := 0; i < ndim; i++ {
+			unew[i] = fcns[i].F(t, nil)
+			Δu[i] = unew[i] - uold[i]
+		}
+		la.MatVecMul(Δw, 1, Q, Δu)
+		if mdl.Update(s, Δw) != nil {
+			tst.Errorf("model update failed\n")
+			return
+		}
+		copy(uold, unew)
+		io.Pforan("t = %4.2f  w = %v  t = %v\n", t, s.Phi, s.Sig)
+
+		// compare
+		for idx, _ := range e.IpsElem {
+			chk.Vector(tst, io.Sf("t @ t=%g", t), 1e-10, e.States[idx].Sig, s.Sig)
+			chk.Vector(tst, io.Sf("w @ t=%g", t), 1e-13, e.States[idx].Phi, s.Phi)
+			chk.Vector(tst, io.Sf("α @ t=%g", t), 1e-13, e.States[idx].Alp, s.Alp)
+		}
+
+		// shear failure under compression and failure at the corner with tension cut-off
+		τ := la.VecNorm(s.Sig[1:])
+		if math.Abs(t-2) < 1e-8 {
+			chk.Scalar(tst, "tn @ t=2", 1e-10, s.Sig[0], -10)
+			chk.Scalar(tst, "ts @ t=2", 1e-10, τ, c+10*tφ)
+		}
+		if math.Abs(t-3) < 1e-8 {
+			chk.Scalar(tst, "tn @ t=3", 1e-10, s.Sig[0], σt)
+			chk.Scalar(tst, "ts @ t=3", 1e-10, τ, c-σt*tφ)
+		}
+	}
+}
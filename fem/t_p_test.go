@@ -5,6 +5,7 @@
 package fem
 
 import (
+	"math"
 	"sort"
 	"testing"
 
@@ -198,3 +199,180 @@ func Test_p02_(tst *testing.T) {
 		return
 	}
 }
+
+func Test_p03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("p03")
+
+	// run simulation
+	if !Start("data/p03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer p_DebugKb(&testKb{
+			tst: tst, eid: 3, tol: 1e-4, verb: chk.Verbose,
+			ni: 2, nj: 2, itmin: 0, itmax: -1, tmin: 5000, tmax: 5000,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_p04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("p04")
+
+	// run simulation
+	if !Start("data/p04.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer p_DebugKb(&testKb{
+			tst: tst, eid: 3, tol: 1e-4, verb: chk.Verbose,
+			ni: 2, nj: 2, itmin: 0, itmax: -1, tmin: 5000, tmax: 5000,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// read results
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	ntout := len(sum.OutTimes)
+	d.In(sum, ntout-1, true)
+
+	// ponding limits the liquid pressure at the top (smooth ramp => small tolerance)
+	for _, nod := range d.Nodes {
+		if nod.Vert.C[1] > 9.99 {
+			pl := d.Sol.Y[nod.GetEq("pl")]
+			io.Pforan("pl @ top = %v\n", pl)
+			if pl > 1e-2 {
+				tst.Errorf("test failed: pl=%g @ top must not exceed the ponding pressure\n", pl)
+				return
+			}
+		}
+	}
+}
+
+func Test_p05(tst *testing.T) {
+
+	/* drying of a column by evaporation at the top. The bottom is impermeable; thus the liquid
+	   pressure at the top decreases until the limiting suction is reached. Then, the boundary
+	   condition switches from flux-controlled (fl = 0) to head-controlled (pl = -pcmax) */
+
+	//verbose()
+	chk.PrintTitle("p05")
+
+	// run simulation
+	if !Start("data/p05.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer p_DebugKb(&testKb{
+			tst: tst, eid: 3, tol: 1e-4, verb: chk.Verbose,
+			ni: 2, nj: 2, itmin: 0, itmax: -1, tmin: 4000, tmax: 4000,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// top node
+	var top *Node
+	for _, nod := range d.Nodes {
+		if nod.Vert.C[1] > 9.99 {
+			top = nod
+			break
+		}
+	}
+	eqpl, eqfl := top.GetEq("pl"), top.GetEq("fl")
+
+	// check pressure and correction of flux @ top
+	plmin, qmax := -50.0, 0.001
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	nflux, nhead := 0, 0
+	for tidx, t := range sum.OutTimes {
+		d.In(sum, tidx, true)
+		pl, fl := d.Sol.Y[eqpl], d.Sol.Y[eqfl]
+		io.Pforan("t=%6g pl @ top = %10.6f fl @ top = %v\n", t, pl, fl)
+
+		// limiting suction is never exceeded (smooth ramp => small tolerance)
+		if pl < plmin-1e-2 {
+			tst.Errorf("test failed: pl=%g @ top must not be smaller than -pcmax = %g\n", pl, plmin)
+			return
+		}
+
+		// flux-controlled: evaporation is not reduced
+		if pl > plmin+1 {
+			if nhead > 0 {
+				tst.Errorf("test failed: condition cannot switch back to flux-controlled at t=%g\n", t)
+				return
+			}
+			if math.Abs(fl) > 1e-10 {
+				tst.Errorf("test failed: fl=%g must be zero when flux-controlled\n", fl)
+				return
+			}
+			nflux++
+			continue
+		}
+
+		// head-controlled: evaporation is reduced but there is no inflow
+		if math.Abs(pl-plmin) < 1e-2 {
+			q := qmax * math.Min(t/500.0, 1)
+			if fl <= 0 || fl > q {
+				tst.Errorf("test failed: fl=%g must be in (0, %g] when head-controlled\n", fl, q)
+				return
+			}
+			nhead++
+		}
+	}
+	if nflux == 0 || nhead == 0 {
+		tst.Errorf("test failed: condition must switch from flux-controlled to head-controlled. nflux=%d nhead=%d\n", nflux, nhead)
+	}
+}
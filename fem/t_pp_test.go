@@ -0,0 +1,128 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_pp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("pp01")
+
+	// start simulation
+	if !Start("data/pp01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// domain
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if dom == nil {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// set stage
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// nodes and elements
+	chk.IntAssert(len(dom.Nodes), 27)
+	chk.IntAssert(len(dom.Elems), 4)
+
+	// dofs
+	for _, nod := range dom.Nodes {
+		chk.IntAssert(len(nod.Dofs), 2)
+		chk.StrAssert(nod.Dofs[0].Key, "pl")
+		chk.StrAssert(nod.Dofs[1].Key, "pg")
+	}
+
+	// for debugging Kb
+	if true {
+		defer pp_DebugKb(&testKb{
+			tst: tst, eid: 3, tol: 1e-4, verb: chk.Verbose,
+			ni: 2, nj: 2, itmin: 0, itmax: -1, tmin: 5000, tmax: 5000,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_pp02(tst *testing.T) {
+
+	/* this test simulates the drainage of a column initially saturated by lowering the liquid
+	   pressure at the bottom; the final equilibrium state is compared with the analytical
+	   solution: hydrostatic liquid pressure with ρL = ρL0 + Cl・pl (water table at mid-height)
+	   and barometric gas pressure with ρG = ρG0 + Cg・pg and pg = 0 at the top */
+
+	//verbose()
+	chk.PrintTitle("pp02")
+
+	// start simulation
+	if !Start("data/pp02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain and read results
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !dom.ReadSol(sum.Dirout, sum.Fnkey, len(sum.OutTimes)-1) {
+		tst.Errorf("cannot read solution\n")
+		return
+	}
+
+	// analytical solution
+	mdl := dom.Elems[0].(*ElemPP).Mdl
+	H, g, pbot := 10.0, 10.0, 50.0
+	ρL0, Cl := mdl.RhoL0, mdl.Cl
+	ρG0, Cg := mdl.RhoG0, mdl.Cg
+	plana := func(z float64) float64 {
+		return (ρL0/Cl)*(1.0+Cl*pbot/ρL0)*math.Exp(-Cl*g*z) - ρL0/Cl
+	}
+	pgana := func(z float64) float64 {
+		return (ρG0 / Cg) * (math.Exp(Cg*g*(H-z)) - 1.0)
+	}
+
+	// check pressures
+	io.Pforan("t = %v\n", sum.OutTimes[len(sum.OutTimes)-1])
+	for _, nod := range dom.Nodes {
+		z := nod.Vert.C[1]
+		pl := dom.Sol.Y[nod.GetDof("pl").Eq]
+		pg := dom.Sol.Y[nod.GetDof("pg").Eq]
+		chk.AnaNum(tst, io.Sf("pl(z=%g)", z), 1e-3, pl, plana(z), chk.Verbose)
+		chk.AnaNum(tst, io.Sf("pg(z=%g)", z), 1e-4, pg, pgana(z), chk.Verbose)
+	}
+}
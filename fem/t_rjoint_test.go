@@ -37,3 +37,45 @@ func Test_rjoint01a(tst *testing.T) {
 		return
 	}
 }
+
+func Test_rjoint01b(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rjoint01b")
+
+	// start simulation
+	if !Start("data/rjoint01b.sim", true, chk.Verbose) {
+		tst.Errorf("Start failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb: joint element; the solid is confined; thus DτDσc ≠ 0
+	var σcmax float64
+	if true {
+		defer rjoint_DebugKb(&testKb{
+			tst: tst, eid: 2, tol: 1e-4, verb: chk.Verbose,
+			ni: -1, nj: -1, itmin: 1, itmax: -1, tmin: 0.5, tmax: -1,
+		})()
+		check := Global.DebugKb
+		Global.DebugKb = func(d *Domain, it int) {
+			check(d, it)
+			if e, ok := d.Elems[2].(*Rjoint); ok {
+				for _, s := range e.States {
+					σcmax = max(σcmax, s.Alp[2])
+				}
+			}
+		}
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("Run failed\n")
+		return
+	}
+
+	// make sure the confining stress was active
+	if σcmax <= 0 {
+		tst.Errorf("confining stress should be positive. σcmax = %g\n", σcmax)
+	}
+}
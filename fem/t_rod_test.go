@@ -0,0 +1,238 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_rod01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rod01")
+
+	// run simulation
+	if !Start("data/rod01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// check stresses
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+
+		// strain and expected stresses
+		ε := 0.004 * t
+		if t > 1 {
+			ε = 0.004 - 0.008*(t-1)
+		}
+		σ := []float64{rod01_bilinear(t, ε), rod01_cable(t, ε), 1000 * math.Min(ε, 0)}
+		io.Pforan("t=%g ε=%g σ=%v\n", t, ε, σ)
+
+		// check
+		for i, e := range d.Elems {
+			for _, s := range e.(*Rod).States {
+				chk.Scalar(tst, io.Sf("σ%d @ t=%g", i, t), 1e-11, s.Sig, σ[i])
+			}
+		}
+	}
+}
+
+func Test_rod02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rod02")
+
+	// run simulation
+	if !Start("data/rod02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// prestressed anchor in series with elastic rod: the force in the anchor is reduced because
+	// the elastic rod is compressed; the force must not change after the first time step
+	P, k1, k2 := 5.0, 100.0, 300.0
+	N := P * k2 / (k1 + k2)
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if t == 0 {
+			continue
+		}
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		anchor, rod := d.Elems[0].(*Rod), d.Elems[1].(*Rod)
+		for _, s := range anchor.States {
+			chk.Scalar(tst, io.Sf("N(anchor) @ t=%g", t), 1e-12, s.Sig*anchor.A, N)
+		}
+		for _, s := range rod.States {
+			chk.Scalar(tst, io.Sf("N(rod) @ t=%g", t), 1e-12, s.Sig*rod.A, -N)
+		}
+		chk.Scalar(tst, io.Sf("ux @ t=%g", t), 1e-15, d.Sol.Y[d.Vid2node[1].GetEq("ux")], -P/(k1+k2))
+	}
+}
+
+func Test_rod03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rod03")
+
+	// run simulation
+	if !Start("data/rod03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// check results
+	E, A := 1000.0, 0.3
+	a, h := 1.0, 0.5
+	L0 := math.Sqrt(a*a + h*h)
+	apex := d.Vid2node[1]
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+
+		// shallow truss: equilibrium @ deformed configuration: P = -2 N (h - v) / l
+		v := -d.Sol.Y[apex.GetEq("uy")]
+		l := math.Sqrt(a*a + (h-v)*(h-v))
+		N := E * A * (l - L0) / L0
+		io.Pforan("t=%g v=%g N=%g\n", t, v, N)
+		chk.Scalar(tst, io.Sf("ux(apex) @ t=%g", t), 1e-12, d.Sol.Y[apex.GetEq("ux")], 0)
+		chk.Scalar(tst, io.Sf("P @ t=%g", t), 1e-7, -2.0*N*(h-v)/l, 8*t)
+		for i := 0; i < 2; i++ {
+			for _, s := range d.Elems[i].(*Rod).States {
+				chk.Scalar(tst, io.Sf("N%d @ t=%g", i, t), 1e-10, s.Sig*A, N)
+			}
+		}
+		if t > 0 && v <= 8*t*L0*L0*L0/(2*E*A*h*h) {
+			tst.Errorf("deflection of truss must be larger than the linear one\n")
+		}
+
+		// rigid rotation (and shortening) of rod: σ = E (l - L0) / L0
+		l = math.Sqrt((1-t)*(1-t) + t*t)
+		for _, s := range d.Elems[2].(*Rod).States {
+			chk.Scalar(tst, io.Sf("σ2 @ t=%g", t), 1e-10, s.Sig, E*(l-1))
+		}
+	}
+}
+
+func Test_rod04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rod04")
+
+	// run simulation: the loaded node is held by unstressed cables at the beginning
+	if !Start("data/rod04.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// check results: 2 N sin(45°) = P and uy = -P L / (2 E A sin²(45°))
+	E, A, L := 1000.0, 0.1, math.Sqrt2
+	nod := d.Vid2node[1]
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		P := 0.1 * t
+		N := P / math.Sqrt2
+		io.Pforan("t=%g N=%g\n", t, N)
+		chk.Scalar(tst, io.Sf("ux @ t=%g", t), 1e-15, d.Sol.Y[nod.GetEq("ux")], 0)
+		chk.Scalar(tst, io.Sf("uy @ t=%g", t), 1e-15, d.Sol.Y[nod.GetEq("uy")], -P*L/(E*A))
+		for i, e := range d.Elems {
+			for _, s := range e.(*Rod).States {
+				chk.Scalar(tst, io.Sf("N%d @ t=%g", i, t), 1e-14, s.Sig*A, N)
+			}
+		}
+	}
+}
+
+// rod01_bilinear returns the stress in the bilinear rod with E=1000, sY=2 and H=250
+func rod01_bilinear(t, ε float64) float64 {
+	if t <= 1 { // loading
+		if ε <= 0.002 {
+			return 1000 * ε
+		}
+		return 2 + 200*(ε-0.002)
+	}
+	if ε >= -0.0008 { // elastic unloading from σ=2.4 @ ε=0.004 down to σ=-2.4
+		return 2.4 + 1000*(ε-0.004)
+	}
+	return -2.4 + 200*(ε+0.0008) // reverse yielding
+}
+
+// rod01_cable returns the stress in the cable with E=1000 and sY=3
+func rod01_cable(t, ε float64) float64 {
+	if t <= 1 { // loading
+		return math.Min(1000*ε, 3)
+	}
+	return math.Max(1000*(ε-0.001), 0) // plastic strain = 0.001
+}
@@ -0,0 +1,288 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_shell01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("shell01")
+
+	// run simulation
+	if !Start("data/shell01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// elements
+	for _, ele := range d.Elems {
+		e := ele.(*Shell)
+		chk.IntAssert(len(e.Umap), 6*e.Shp.Nverts)
+		chk.Matrix(tst, io.Sf("R @ cell %d", e.Cid), 1e-15, e.R, [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+		if e.Cid < 4 {
+			chk.IntAssert(len(e.Zs), 4)
+		} else {
+			chk.IntAssert(len(e.Zs), 2)
+		}
+	}
+
+	// cantilever strips with tip moment (ν = 0): constant curvature and no shear; the MITC4 and
+	// MITC9 solutions are exact: w = -M x² / (2 EI) and θy = M x / EI
+	M, b, t, E := 0.01, 0.5, 0.1, 1e4
+	EI := E * b * t * t * t / 12.0
+	for _, nod := range d.Nodes {
+		x := nod.Vert.C[0]
+		id := nod.Vert.Id
+		chk.IntAssert(len(nod.Dofs), 6)
+		chk.Scalar(tst, io.Sf("ux @ %d", id), 1e-12, d.Sol.Y[nod.GetEq("ux")], 0)
+		chk.Scalar(tst, io.Sf("uy @ %d", id), 1e-12, d.Sol.Y[nod.GetEq("uy")], 0)
+		chk.Scalar(tst, io.Sf("uz @ %d", id), 1e-11, d.Sol.Y[nod.GetEq("uz")], -M*x*x/(2.0*EI))
+		chk.Scalar(tst, io.Sf("rx @ %d", id), 1e-12, d.Sol.Y[nod.GetEq("rx")], 0)
+		chk.Scalar(tst, io.Sf("ry @ %d", id), 1e-11, d.Sol.Y[nod.GetEq("ry")], M*x/EI)
+		chk.Scalar(tst, io.Sf("rz @ %d", id), 1e-12, d.Sol.Y[nod.GetEq("rz")], 0)
+	}
+
+	// stress resultants: mxx = M / b
+	for _, ele := range d.Elems {
+		e := ele.(*Shell)
+		for idx := range e.IpsElem {
+			io.Pforan("cid=%d ip=%d res=%v\n", e.Cid, idx, e.Res[idx])
+			chk.Vector(tst, io.Sf("res @ cell %d ip %d", e.Cid, idx), 1e-10, e.Res[idx], []float64{0, 0, 0, M / b, 0, 0, 0, 0})
+		}
+	}
+}
+
+func Test_shell02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("shell02")
+
+	// run simulation
+	if !Start("data/shell02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// nodes: rotations are added to the nodes of the solid element that are connected to the shell
+	for _, nod := range d.Nodes {
+		ndof := 6
+		if nod.Vert.C[2] == 0 {
+			ndof = 3
+		}
+		chk.IntAssert(len(nod.Dofs), ndof)
+	}
+
+	// equations: solid first; then shells
+	e := d.Elems[1].(*Shell)
+	chk.Ints(tst, "umap @ 1", e.Umap, []int{
+		12, 13, 14, 24, 25, 26,
+		15, 16, 17, 27, 28, 29,
+		18, 19, 20, 30, 31, 32,
+		21, 22, 23, 33, 34, 35,
+	})
+	e = d.Elems[2].(*Shell)
+	chk.Ints(tst, "umap @ 2", e.Umap[6:18], []int{
+		36, 37, 38, 39, 40, 41,
+		42, 43, 44, 45, 46, 47,
+	})
+
+	// free edge deflects downwards
+	for _, vid := range []int{8, 9} {
+		nod := d.Vid2node[vid]
+		uz := d.Sol.Y[nod.GetEq("uz")]
+		io.Pforan("uz @ %d = %v\n", vid, uz)
+		if uz >= 0 {
+			tst.Errorf("uz @ %d should be negative: %v\n", vid, uz)
+		}
+	}
+}
+
+func Test_shell03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("shell03")
+
+	// start simulation
+	if !Start("data/shell03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb: MITC9 element
+	if true {
+		defer shell_DebugKb(&testKb{
+			tst: tst, eid: 64, tol: 1e-6, verb: chk.Verbose,
+			ni: 54, nj: 54, itmin: 0, itmax: -1, tmin: -1, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// Kirchhoff solution for simply-supported square plates (Navier):
+	//  w = 16 q a⁴ / (π⁶ D) Σ sin(mπ/2) sin(nπ/2) / (m n (m² + n²)²)
+	q, a, t, E := 1e-4, 1.0, 0.001, 1e4
+	D := E * t * t * t / 12.0
+	var sum float64
+	for m := 1; m < 200; m += 2 {
+		for n := 1; n < 200; n += 2 {
+			sum += math.Sin(float64(m)*math.Pi/2.0) * math.Sin(float64(n)*math.Pi/2.0) / (float64(m*n) * math.Pow(float64(m*m+n*n), 2))
+		}
+	}
+	wK := 16.0 * q * math.Pow(a, 4) * sum / (math.Pow(math.Pi, 6) * D)
+	chk.Scalar(tst, "α", 1e-9, wK*D/(q*math.Pow(a, 4)), 0.0040623526606)
+
+	// thin plates (t/a = 0.001): without shear locking, the deflections at the centre of the
+	// MITC4 (8x8) and MITC9 (4x4) meshes are close to the Kirchhoff solution
+	for _, vtag := range []int{-40, -41} {
+		verts := d.Msh.VertTag2verts[vtag]
+		w := d.Sol.Y[d.Vid2node[verts[0].Id].GetEq("uz")]
+		io.Pforan("w/wK @ %d = %v\n", vtag, w/wK)
+		chk.Scalar(tst, io.Sf("w/wK @ %d", vtag), 0.02, w/wK, 1)
+	}
+}
+
+func Test_shell04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("shell04")
+
+	// start simulation
+	if !Start("data/shell04.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb: elastoplastic layers
+	if true {
+		defer shell_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-5, verb: chk.Verbose,
+			ni: 24, nj: 24, itmin: 1, itmax: -1, tmin: 0.5, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// pure bending: the moment is constant along the strip
+	m0 := d.Elems[0].(*Shell).Res[0][3]
+	for _, ele := range d.Elems {
+		e := ele.(*Shell)
+		chk.IntAssert(len(e.Zs), 8)
+		for idx := range e.IpsElem {
+			chk.Scalar(tst, io.Sf("mxx @ cell %d ip %d", e.Cid, idx), 1e-7, e.Res[idx][3], m0)
+		}
+	}
+
+	// tip rotation θ = 0.2 => κ = θ/L = 5 κy with κy = 2 σy / (E t); thus the moment lies between
+	// the elastic limit My = σy t²/6 and the plastic limit under plane-strain (2/√3) Mp, where
+	// Mp = σy t²/4, and is smaller than the elastic moment E t³ κ / 12
+	E, σy, t, κ := 1e4, 10.0, 0.1, 0.1
+	My, Mp, Me := σy*t*t/6.0, σy*t*t/4.0, E*t*t*t*κ/12.0
+	m := math.Abs(m0)
+	io.Pforan("m = %v  My = %v  Mp = %v  Me = %v\n", m, My, Mp, Me)
+	if m < My || m > 2.0*Mp/math.Sqrt(3.0) || m > Me {
+		tst.Errorf("moment of layered elastoplastic strip is incorrect: %v\n", m)
+	}
+}
+
+func Test_shell05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("shell05")
+
+	// run simulation
+	if !Start("data/shell05.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// domain
+	d := beam_domain(tst)
+	if d == nil {
+		return
+	}
+
+	// characteristic lengths of damage model: kf = Gf / (h ft) + k0 / 2 with h = sqrt(area);
+	// the areas of the MITC4 and MITC9 elements are 0.5² and 1.0 * 0.5
+	E, ft, Gf := 1e4, 100.0, 10.0
+	κ0 := ft / E
+	for _, ele := range d.Elems {
+		e := ele.(*Shell)
+		h := 0.5
+		if e.Cid > 3 {
+			h = math.Sqrt(0.5)
+		}
+		for idx, states := range e.States {
+			for k, s := range states {
+				chk.Scalar(tst, io.Sf("kf @ cell %d ip %d layer %d", e.Cid, idx, k), 1e-14, s.Phi[0], Gf/(h*ft)+κ0/2.0)
+			}
+		}
+	}
+
+	// elastic range: same solution as with linear elasticity (see shell01)
+	M, b, t := 0.01, 0.5, 0.1
+	EI := E * b * t * t * t / 12.0
+	for _, nod := range d.Nodes {
+		x := nod.Vert.C[0]
+		id := nod.Vert.Id
+		chk.Scalar(tst, io.Sf("uz @ %d", id), 1e-11, d.Sol.Y[nod.GetEq("uz")], -M*x*x/(2.0*EI))
+		chk.Scalar(tst, io.Sf("ry @ %d", id), 1e-11, d.Sol.Y[nod.GetEq("ry")], M*x/EI)
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fem
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_t01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("t01")
+
+	// start simulation
+	if !Start("data/t01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// domain
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if dom == nil {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// set stage
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// nodes and elements
+	chk.IntAssert(len(dom.Nodes), 27)
+	chk.IntAssert(len(dom.Elems), 4)
+
+	// check dofs
+	for _, nod := range dom.Nodes {
+		chk.IntAssert(len(nod.Dofs), 1)
+		chk.StrAssert(nod.Dofs[0].Key, "temp")
+	}
+
+	// constraints
+	chk.IntAssert(len(dom.EssenBcs.Bcs), 3)
+	for _, c := range dom.EssenBcs.Bcs {
+		if c.Key != "temp" {
+			tst.Errorf("key %s is incorrect", c.Key)
+		}
+	}
+
+	// for debugging Kb
+	if true {
+		defer t_DebugKb(&testKb{
+			tst: tst, eid: 3, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: -1, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_t02(tst *testing.T) {
+
+	/* this test simulates the steady heat conduction along a column with prescribed temperature
+	   at the bottom and convection at the top; the temperature varies linearly with z */
+
+	//verbose()
+	chk.PrintTitle("t02")
+
+	// start simulation
+	if !Start("data/t02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain and read results
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !dom.ReadSol(sum.Dirout, sum.Fnkey, len(sum.OutTimes)-1) {
+		tst.Errorf("cannot read solution\n")
+		return
+	}
+
+	// analytical solution: k (θbot - θtop) / H = h (θtop - θair)
+	H, k, h, θbot, θair := 10.0, 2.0, 5.0, 50.0, 20.0
+	θtop := (k*θbot/H + h*θair) / (k/H + h)
+	for _, nod := range dom.Nodes {
+		z := nod.Vert.C[1]
+		θ := dom.Sol.Y[nod.GetDof("temp").Eq]
+		chk.AnaNum(tst, io.Sf("θ(z=%g)", z), 1e-10, θ, θbot-(θbot-θtop)*z/H, chk.Verbose)
+	}
+}
+
+func Test_t03(tst *testing.T) {
+
+	/* this test simulates the transient heat conduction along a column with a sudden temperature
+	   applied at the bottom and insulated top; the results are compared with the series solution */
+
+	//verbose()
+	chk.PrintTitle("t03")
+
+	// start simulation
+	if !Start("data/t03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+
+	// analytical solution: θ = θb [1 - Σ 4/((2n+1)π) sin(λn z) exp(-λn² D t)] with λn = (2n+1)π/(2H)
+	H, D, θb := 10.0, 2.0/2.0e3, 50.0
+	θana := func(z, t float64) float64 {
+		var res float64
+		for n := 0; n < 200; n++ {
+			c := float64(2*n+1) * math.Pi
+			λ := c / (2.0 * H)
+			res += 4.0 * math.Sin(λ*z) * math.Exp(-λ*λ*D*t) / c
+		}
+		return θb * (1.0 - res)
+	}
+
+	// check temperatures
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !dom.ReadSol(sum.Dirout, sum.Fnkey, tidx) {
+			tst.Errorf("cannot read solution\n")
+			return
+		}
+		io.Pforan("t = %v\n", t)
+		for _, nod := range dom.Nodes {
+			z := nod.Vert.C[1]
+			θ := dom.Sol.Y[nod.GetDof("temp").Eq]
+			chk.AnaNum(tst, io.Sf("θ(z=%g)", z), 0.25, θ, θana(z, t), chk.Verbose)
+		}
+	}
+}
+
+func Test_ut01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ut01")
+
+	// start simulation
+	if !Start("data/ut01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer ut_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-7, verb: chk.Verbose,
+			ni: -1, nj: -1, itmin: 0, itmax: -1, tmin: 1e5, tmax: 1e5,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+func Test_ut02(tst *testing.T) {
+
+	/* this test simulates the free thermal expansion of a column under plane-stress conditions;
+	   the strains are ε = α Δθ and the stresses are zero */
+
+	//verbose()
+	chk.PrintTitle("ut02")
+
+	// start simulation
+	if !Start("data/ut02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check displacements and stresses
+	α, E, Δθ := 1e-5, 10000.0, 50.0
+	ut_check_expansion(tst, α*Δθ, α*Δθ, []float64{0, 0, 0, 0}, E*α*Δθ*1e-10)
+}
+
+func Test_ut03(tst *testing.T) {
+
+	/* this test simulates the thermal expansion of a column restrained vertically under
+	   plane-stress conditions; the vertical stress is σy = -E α Δθ and εx = (1 + ν) α Δθ */
+
+	//verbose()
+	chk.PrintTitle("ut03")
+
+	// start simulation
+	if !Start("data/ut03.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// check displacements and stresses
+	α, ν, E, Δθ := 1e-5, 0.2, 10000.0, 50.0
+	ut_check_expansion(tst, (1.0+ν)*α*Δθ, 0, []float64{0, -E * α * Δθ, 0, 0}, E*α*Δθ*1e-10)
+}
+
+func Test_pt01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("pt01")
+
+	// start simulation
+	if !Start("data/pt01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// make sure to flush log
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer pt_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-8, verb: chk.Verbose,
+			ni: 9, nj: 9, itmin: 0, itmax: -1, tmin: 1000, tmax: 1000,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+}
+
+// ut_check_expansion checks the homogeneous solution of thermal expansion tests:
+// ux = εx x, uy = εy y and uniform stresses σ
+func ut_check_expansion(tst *testing.T, εx, εy float64, σ []float64, tolσ float64) {
+
+	// allocate domain and read results
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	if !dom.In(sum, len(sum.OutTimes)-1, true) {
+		tst.Errorf("cannot read results\n")
+		return
+	}
+
+	// displacements
+	for _, nod := range dom.Nodes {
+		x, y := nod.Vert.C[0], nod.Vert.C[1]
+		ux := dom.Sol.Y[nod.GetDof("ux").Eq]
+		uy := dom.Sol.Y[nod.GetDof("uy").Eq]
+		chk.AnaNum(tst, io.Sf("ux(%g,%g)", x, y), 1e-10, ux, εx*x, chk.Verbose)
+		chk.AnaNum(tst, io.Sf("uy(%g,%g)", x, y), 1e-10, uy, εy*y, chk.Verbose)
+	}
+
+	// stresses
+	for _, ele := range dom.Elems {
+		e := ele.(*ElemUT)
+		for idx := range e.U.IpsElem {
+			chk.Vector(tst, io.Sf("σ(cell=%d,ip=%d)", e.Id(), idx), tolσ, e.U.States[idx].Sig, σ)
+		}
+	}
+}
@@ -5,12 +5,14 @@
 package fem
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gofem/ana"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
 )
 
 func Test_sigini01(tst *testing.T) {
@@ -126,3 +128,247 @@ func Test_sigini02(tst *testing.T) {
 		sol.CheckStress(tst, t, σ, x, tols)
 	}
 }
+
+func Test_large01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("large01")
+
+	// start simulation
+	if !Start("data/large01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// output data is obtained once (as in out.Start); thus pointers must remain valid after d.In
+	e := d.Elems[0].(*ElemU)
+	dat := e.OutIpsData()
+
+	// check stresses and logarithmic strains at all output times
+	// homogeneous deformation: F = diag(λ1, λ2, 1) with λ1 = 1 + 0.5 t and λ2 = 1 - 0.2 t
+	μ, K := 100.0, 500.0
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		λ1, λ2 := 1.0+0.5*t, 1.0-0.2*t
+		J := λ1 * λ2
+		c := math.Pow(J, -2.0/3.0)
+		bb := []float64{c * λ1 * λ1, c * λ2 * λ2, c}
+		tr := (bb[0] + bb[1] + bb[2]) / 3.0
+		σref := make([]float64, 4)
+		for i := 0; i < 3; i++ {
+			σref[i] = K*(J-1.0) + μ*(bb[i]-tr)/J
+		}
+		εref := []float64{math.Log(λ1), math.Log(λ2), 0, 0}
+		for idx, _ := range e.IpsElem {
+			σ := e.States[idx].Sig
+			io.Pforan("t=%g σ = %v\n", t, σ)
+			chk.Vector(tst, io.Sf("σ @ t=%g", t), 1e-10, σ, σref)
+		}
+		for _, v := range dat {
+			ε := []float64{*v.V["ex"], *v.V["ey"], *v.V["ez"], *v.V["exy"]}
+			chk.Vector(tst, io.Sf("ε @ t=%g", t), 1e-13, ε, εref)
+		}
+	}
+}
+
+func Test_undrained01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("undrained01")
+
+	// start simulation
+	if !Start("data/undrained01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb
+	if true {
+		defer u_DebugKb(&testKb{
+			tst: tst, eid: 0, tol: 1e-6, verb: chk.Verbose,
+			ni: 8, nj: 8, itmin: 0, itmax: -1, tmin: -1, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	e := d.Elems[0].(*ElemU)
+	chk.Scalar(tst, "Kw", 1e-8, e.Kw, 2.2e6/0.3)
+
+	// oedometric conditions
+	q := 100.0
+	λ, G := 400.0, 400.0 // Lamé's coefficients: E = 1000, ν = 0.25
+	Kv := λ + 2.0*G
+
+	// check results
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+
+		// analytical solution: undrained @ t=1 and drained @ t=2
+		εy := -q / Kv
+		if t < 1.5 {
+			εy = -q / (Kv + e.Kw)
+		}
+		pwex := -e.Kw * εy
+		if t > 1.5 {
+			pwex = 0
+		}
+		io.Pforan("t = %v  εy = %v  pwex = %v\n", t, εy, pwex)
+
+		// displacements
+		for _, n := range d.Nodes {
+			uy := d.Sol.Y[n.GetEq("uy")]
+			chk.Scalar(tst, io.Sf("uy @ t=%g", t), 1e-13, uy, εy*n.Vert.C[1])
+		}
+
+		// effective stresses and excess pore-water pressures
+		for idx, _ := range e.IpsElem {
+			σ := e.States[idx].Sig
+			chk.Vector(tst, io.Sf("σ' @ t=%g", t), 1e-9, σ, []float64{λ * εy, Kv * εy, λ * εy, 0})
+			chk.Scalar(tst, io.Sf("pwex @ t=%g", t), 1e-9, e.Pwex[idx], pwex)
+		}
+	}
+}
+
+func Test_large02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("large02")
+
+	// start simulation
+	if !Start("data/large02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// for debugging Kb: distorted element with follower pressure on top face
+	if true {
+		defer u_DebugKb(&testKb{
+			tst: tst, eid: 1, tol: 1e-5, verb: chk.Verbose,
+			ni: 8, nj: 8, itmin: 0, itmax: -1, tmin: -1, tmax: -1,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// read results
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	ntout := len(sum.OutTimes)
+	d.In(sum, ntout-1, true)
+
+	// large strains: the right face is stretched by 40% and the top face is compressed
+	for _, n := range d.Nodes {
+		if n.Vert.C[0] > 1.99 {
+			chk.Scalar(tst, "ux @ right", 1e-15, d.Sol.Y[n.GetEq("ux")], 0.8)
+		}
+		if n.Vert.C[1] > 0.99 {
+			uy := d.Sol.Y[n.GetEq("uy")]
+			io.Pforan("uy @ top (x=%g) = %v\n", n.Vert.C[0], uy)
+			if uy > 0 {
+				tst.Errorf("top face must move downwards: uy = %v\n", uy)
+			}
+		}
+	}
+}
+
+func Test_vp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vp01")
+
+	// start simulation
+	if !Start("data/vp01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+	defer End()
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	d := NewDomain(Global.Sim.Regions[0], distr)
+	if !d.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+
+	// relaxation at held strain εy = -0.003 (Duvaut-Lions, N=1): the time increment Δt of the
+	// simulation must reach the model; otherwise the response would be elastic with q = qtr
+	//  q - qy = (qtr - qy) / (1 + γ Δt)^n  with  qtr = 2 G |εy|
+	G, εy, qy, γ, Δt := 1000.0, -0.003, 1.0, 0.5, 0.1
+	qtr := 2.0 * G * math.Abs(εy)
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !d.In(sum, tidx, true) {
+			tst.Errorf("cannot read results\n")
+			return
+		}
+		n := math.Floor(t/Δt + 0.5)
+		qana := qy + (qtr-qy)/math.Pow(1.0+γ*Δt, n)
+		e := d.Elems[0].(*ElemU)
+		for idx, _ := range e.IpsElem {
+			q := tsr.M_q(e.States[idx].Sig)
+			io.Pforan("t=%g q=%g qana=%g\n", t, q, qana)
+			chk.Scalar(tst, io.Sf("q @ t=%g", t), 1e-9, q, qana)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/cpmech/gofem/ana"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
@@ -285,3 +286,180 @@ func Test_up01b(tst *testing.T) {
 		chk.Panic("cannot run simulation\n")
 	}
 }
+
+func Test_up02(tst *testing.T) {
+
+	/* this test simulates the consolidation of a poroelastic column with Biot coefficient α = 0.8
+	   and compressible grains; the results are compared with the analytical solution.
+	   the conductivity is small so that consolidation is much slower than the (compressional)
+	   waves excited by the sudden load; these are damped by the θ=2/3, θ1=5/6, θ2=8/9 scheme */
+
+	// capture errors and flush log
+	defer End()
+
+	//verbose()
+	chk.PrintTitle("up02")
+
+	// start simulation
+	if !Start("data/up02.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// for debugging Kb
+	if true {
+		defer up_DebugKb(&testKb{
+			tst: tst, eid: 19, tol: 1e-7, verb: chk.Verbose,
+			ni: 4, nj: 4, itmin: 0, itmax: -1, tmin: 49.5, tmax: 50.5,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain and read results
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+
+	// analytical solution
+	mdl := dom.Elems[0].(*ElemUP).P.Mdl
+	var sol ana.PoroColumn
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "H", V: 10},
+		&fun.Prm{N: "E", V: 10000},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "alpha", V: mdl.Alpha},
+		&fun.Prm{N: "invM", V: 1.0 / mdl.BiotModulus(mdl.Nf0)},
+		&fun.Prm{N: "kl", V: mdl.Pkl},
+		&fun.Prm{N: "gamL", V: mdl.RhoL0 * mdl.Gref},
+		&fun.Prm{N: "q", V: 100},
+	})
+
+	// check pressure and settlement
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !dom.ReadSol(sum.Dirout, sum.Fnkey, tidx) {
+			tst.Errorf("cannot read solution\n")
+			return
+		}
+		io.Pforan("t = %v  Tv = %v  p0 = %v\n", t, sol.Tv(t), sol.P0)
+		for _, nod := range dom.Nodes {
+			if math.Abs(nod.Vert.C[0]) > 1e-8 {
+				continue
+			}
+			z := nod.Vert.C[1]
+			if dof := nod.GetDof("pl"); dof != nil {
+				sol.CheckPressure(tst, t, dom.Sol.Y[dof.Eq], z, 0.5)
+			}
+			if math.Abs(z-10) < 1e-8 {
+				uy := dom.Sol.Y[nod.GetDof("uy").Eq]
+				chk.Scalar(tst, "settlement", 1e-3, -uy, sol.Settlement(t))
+			}
+		}
+	}
+}
+
+func Test_mandel01(tst *testing.T) {
+
+	/* this test simulates Mandel's problem with Biot coefficient α = 0.8 and compressible grains;
+	   the pressure at the centre of the specimen rises above its initial (undrained) value at
+	   early times (Mandel-Cryer effect). a quarter of the specimen is discretised and the top
+	   plate is represented by tying the vertical displacements of the top nodes */
+
+	// capture errors and flush log
+	defer End()
+
+	//verbose()
+	chk.PrintTitle("mandel01")
+
+	// start simulation
+	if !Start("data/mandel01.sim", true, chk.Verbose) {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// for debugging Kb: element at the top-right corner
+	if true {
+		defer up_DebugKb(&testKb{
+			tst: tst, eid: 19, tol: 1e-7, verb: chk.Verbose,
+			ni: 4, nj: 4, itmin: 0, itmax: -1, tmin: 0.49, tmax: 0.51,
+		})()
+	}
+
+	// run simulation
+	if !Run() {
+		tst.Errorf("test failed\n")
+		return
+	}
+
+	// allocate domain
+	distr := false
+	dom := NewDomain(Global.Sim.Regions[0], distr)
+	if !dom.SetStage(0, Global.Sim.Stages[0], distr) {
+		tst.Errorf("SetStage failed\n")
+		return
+	}
+	sum := ReadSum(Global.Dirout, Global.Fnkey)
+
+	// analytical solution
+	mdl := dom.Elems[0].(*ElemUP).P.Mdl
+	var sol ana.Mandel
+	sol.Init(fun.Prms{
+		&fun.Prm{N: "a", V: 1},
+		&fun.Prm{N: "E", V: 10000},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "alpha", V: mdl.Alpha},
+		&fun.Prm{N: "invM", V: 1.0 / mdl.BiotModulus(mdl.Nf0)},
+		&fun.Prm{N: "kl", V: mdl.Pkl},
+		&fun.Prm{N: "gamL", V: mdl.RhoL0 * mdl.Gref},
+		&fun.Prm{N: "F", V: 100},
+	})
+	p0 := sol.Pressure(0, 0)
+	B, νu := sol.Undrained()
+	io.Pforan("B = %v  νu = %v  c = %v  p0 = %v\n", B, νu, sol.C, p0)
+
+	// check pressures and displacements
+	for tidx, t := range sum.OutTimes {
+		if tidx == 0 {
+			continue
+		}
+		if !dom.ReadSol(sum.Dirout, sum.Fnkey, tidx) {
+			tst.Errorf("cannot read solution\n")
+			return
+		}
+		for _, nod := range dom.Nodes {
+			x := nod.Vert.C
+			if dof := nod.GetDof("pl"); dof != nil {
+				sol.CheckPressure(tst, t, dom.Sol.Y[dof.Eq], x[0], 1.0)
+			}
+			u := []float64{dom.Sol.Y[nod.GetDof("ux").Eq], dom.Sol.Y[nod.GetDof("uy").Eq]}
+			sol.CheckDispl(tst, t, u, x, 1e-4)
+		}
+
+		// Mandel-Cryer effect: at t = 0.5, the analytical pressure at the centre is about 10% above p0
+		if tidx == 1 {
+			var pc float64
+			for _, nod := range dom.Nodes {
+				if math.Abs(nod.Vert.C[0]) < 1e-8 && math.Abs(nod.Vert.C[1]) < 1e-8 {
+					pc = dom.Sol.Y[nod.GetDof("pl").Eq]
+				}
+			}
+			pa := sol.Pressure(0, t)
+			io.Pforan("t = %v  pc = %v  pa = %v  p0 = %v\n", t, pc, pa, p0)
+			if pa <= p0 || pc-p0 < 0.5*(pa-p0) {
+				tst.Errorf("Mandel-Cryer effect is not captured: pc = %v  pa = %v  p0 = %v\n", pc, pa, p0)
+				return
+			}
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/cpmech/gofem/msolid"
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/num"
 )
 
@@ -248,6 +249,70 @@ func p_DebugKb(o *testKb) (resetDebugKb func()) {
 	return
 }
 
+// pp_DebugKb defines a global function to debug Kb for pp-elements
+//  Note: it returns a function to reset the global function
+func pp_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemPP); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.IpsElem)
+			states := make([]*mporous.State, nip)
+			statesBkp := make([]*mporous.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.States[i].GetCopy()
+				statesBkp[i] = e.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.States[i].Set(states[i])
+					e.StatesBkp[i].Set(statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.States[k].Set(states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.States[k].Set(statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("Kll", d, e, e.Plmap, e.Plmap, e.Kll, restore)
+			o.check("Klg", d, e, e.Plmap, e.Pgmap, e.Klg, restore)
+			o.check("Kgl", d, e, e.Pgmap, e.Plmap, e.Kgl, restore)
+			o.check("Kgg", d, e, e.Pgmap, e.Pgmap, e.Kgg, restore)
+		}
+	}
+	return
+}
+
 // u_DebugKb defines a global function to debug Kb for u-elements
 //  Note: it returns a function to reset the global function
 func u_DebugKb(o *testKb) (resetDebugKb func()) {
@@ -278,6 +343,10 @@ func u_DebugKb(o *testKb) (resetDebugKb func()) {
 				states[i] = e.States[i].GetCopy()
 				statesBkp[i] = e.StatesBkp[i].GetCopy()
 			}
+			pwex := make([]float64, len(e.Pwex))
+			pwexBkp := make([]float64, len(e.PwexBkp))
+			copy(pwex, e.Pwex)
+			copy(pwexBkp, e.PwexBkp)
 			o.aux_arrays(d)
 
 			// make sure to restore states and solution
@@ -286,6 +355,8 @@ func u_DebugKb(o *testKb) (resetDebugKb func()) {
 					e.States[i].Set(states[i])
 					e.StatesBkp[i].Set(statesBkp[i])
 				}
+				copy(e.Pwex, pwex)
+				copy(e.PwexBkp, pwexBkp)
 				copy(d.Sol.ΔY, o.ΔYbkp)
 			}()
 
@@ -295,11 +366,13 @@ func u_DebugKb(o *testKb) (resetDebugKb func()) {
 					for k := 0; k < nip; k++ {
 						e.States[k].Set(states[k])
 					}
+					copy(e.Pwex, pwex)
 					return
 				}
 				for k := 0; k < nip; k++ {
 					e.States[k].Set(statesBkp[k])
 				}
+				copy(e.Pwex, pwexBkp)
 			}
 
 			// check
@@ -384,6 +457,611 @@ func up_DebugKb(o *testKb) (resetDebugKb func()) {
 	return
 }
 
+// t_DebugKb defines a global function to debug Kb for t-elements
+//  Note: it returns a function to reset the global function
+func t_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemT); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy solution
+			o.aux_arrays(d)
+
+			// make sure to restore solution
+			defer func() {
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// check; there are no internal variables to be restored
+			o.check("Ktt", d, e, e.Tmap, e.Tmap, e.Ktt, func() {})
+		}
+	}
+	return
+}
+
+// ut_DebugKb defines a global function to debug Kb for ut-elements
+//  Note: it returns a function to reset the global function
+func ut_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemUT); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.U.IpsElem)
+			states := make([]*msolid.State, nip)
+			statesBkp := make([]*msolid.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.U.States[i].GetCopy()
+				statesBkp[i] = e.U.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.U.States[i].Set(states[i])
+					e.U.StatesBkp[i].Set(statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.U.States[k].Set(states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.U.States[k].Set(statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("Kuu", d, e, e.U.Umap, e.U.Umap, e.U.K, restore)
+			o.check("Kut", d, e, e.U.Umap, e.T.Tmap, e.Kut, restore)
+			o.check("Ktu", d, e, e.T.Tmap, e.U.Umap, la.MatAlloc(e.T.Nt, e.U.Nu), restore) // one-way coupling
+			o.check("Ktt", d, e, e.T.Tmap, e.T.Tmap, e.T.Ktt, restore)
+		}
+	}
+	return
+}
+
+// pt_DebugKb defines a global function to debug Kb for pt-elements
+//  Note: it returns a function to reset the global function
+func pt_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemPT); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.P.IpsElem)
+			states := make([]*mporous.State, nip)
+			statesBkp := make([]*mporous.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.P.States[i].GetCopy()
+				statesBkp[i] = e.P.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.P.States[i].Set(states[i])
+					e.P.StatesBkp[i].Set(statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.P.States[k].Set(states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.P.States[k].Set(statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("Kpp", d, e, e.P.Pmap, e.P.Pmap, e.P.Kpp, restore)
+			o.check("Kpt", d, e, e.P.Pmap, e.T.Tmap, e.Kpt, restore)
+			o.check("Ktt", d, e, e.T.Tmap, e.T.Tmap, e.T.Ktt, restore)
+		}
+	}
+	return
+}
+
+// c_DebugKb defines a global function to debug Kb for c-elements
+//  Note: it returns a function to reset the global function
+func c_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemC); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy solution
+			o.aux_arrays(d)
+
+			// make sure to restore solution
+			defer func() {
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// check; there are no internal variables to be restored
+			o.check("Kcc", d, e, e.Cmap, e.Cmap, e.Kcc, func() {})
+		}
+	}
+	return
+}
+
+// pc_DebugKb defines a global function to debug Kb for pc-elements
+//  Note: it returns a function to reset the global function
+func pc_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemPC); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.P.IpsElem)
+			states := make([]*mporous.State, nip)
+			statesBkp := make([]*mporous.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.P.States[i].GetCopy()
+				statesBkp[i] = e.P.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.P.States[i].Set(states[i])
+					e.P.StatesBkp[i].Set(statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.P.States[k].Set(states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.P.States[k].Set(statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("Kpp", d, e, e.P.Pmap, e.P.Pmap, e.P.Kpp, restore)
+			o.check("Kcc", d, e, e.C.Cmap, e.C.Cmap, e.C.Kcc, restore)
+			if !e.Lag && !e.Seq {
+				o.check("Kcp", d, e, e.C.Cmap, e.P.Pmap, e.Kcp, restore)
+			}
+		}
+	}
+	return
+}
+
+// upc_DebugKb defines a global function to debug Kb for upc-elements
+//  Note: it returns a function to reset the global function
+func upc_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*ElemUPC); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.UP.U.IpsElem)
+			u_states := make([]*msolid.State, nip)
+			p_states := make([]*mporous.State, nip)
+			u_statesBkp := make([]*msolid.State, nip)
+			p_statesBkp := make([]*mporous.State, nip)
+			for i := 0; i < nip; i++ {
+				u_states[i] = e.UP.U.States[i].GetCopy()
+				p_states[i] = e.UP.P.States[i].GetCopy()
+				u_statesBkp[i] = e.UP.U.StatesBkp[i].GetCopy()
+				p_statesBkp[i] = e.UP.P.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.UP.U.States[i].Set(u_states[i])
+					e.UP.P.States[i].Set(p_states[i])
+					e.UP.U.StatesBkp[i].Set(u_statesBkp[i])
+					e.UP.P.StatesBkp[i].Set(p_statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.UP.U.States[k].Set(u_states[k])
+						e.UP.P.States[k].Set(p_states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.UP.U.States[k].Set(u_statesBkp[k])
+					e.UP.P.States[k].Set(p_statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("Kuu", d, e, e.UP.U.Umap, e.UP.U.Umap, e.UP.U.K, restore)
+			o.check("Kpp", d, e, e.UP.P.Pmap, e.UP.P.Pmap, e.UP.P.Kpp, restore)
+			o.check("Kcc", d, e, e.C.Cmap, e.C.Cmap, e.C.Kcc, restore)
+			if !e.Lag && !e.Seq {
+				o.check("Kcp", d, e, e.C.Cmap, e.UP.P.Pmap, e.Kcp, restore)
+				o.check("Kcu", d, e, e.C.Cmap, e.UP.U.Umap, e.Kcu, restore)
+			}
+		}
+	}
+	return
+}
+
+// interface_DebugKb defines a global function to debug Kb for interface elements
+//  Note: it returns a function to reset the global function
+func interface_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*Interface); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.IpsElem)
+			states := make([]*msolid.State, nip)
+			statesBkp := make([]*msolid.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.States[i].GetCopy()
+				statesBkp[i] = e.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.States[i].Set(states[i])
+					e.StatesBkp[i].Set(statesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				if it == 0 {
+					for k := 0; k < nip; k++ {
+						e.States[k].Set(states[k])
+					}
+					return
+				}
+				for k := 0; k < nip; k++ {
+					e.States[k].Set(statesBkp[k])
+				}
+			}
+
+			// check
+			o.check("K", d, e, e.Umap, e.Umap, e.K, restore)
+		}
+	}
+	return
+}
+
+// shell_DebugKb defines a global function to debug Kb for shell elements
+//  Note: it returns a function to reset the global function
+func shell_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*Shell); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			nip := len(e.IpsElem)
+			nz := len(e.Zs)
+			states := make([][]*msolid.State, nip)
+			statesBkp := make([][]*msolid.State, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = make([]*msolid.State, nz)
+				statesBkp[i] = make([]*msolid.State, nz)
+				for k := 0; k < nz; k++ {
+					states[i][k] = e.States[i][k].GetCopy()
+					statesBkp[i][k] = e.StatesBkp[i][k].GetCopy()
+				}
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					for k := 0; k < nz; k++ {
+						e.States[i][k].Set(states[i][k])
+						e.StatesBkp[i][k].Set(statesBkp[i][k])
+					}
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function
+			restore := func() {
+				for i := 0; i < nip; i++ {
+					for k := 0; k < nz; k++ {
+						if it == 0 {
+							e.States[i][k].Set(states[i][k])
+						} else {
+							e.States[i][k].Set(statesBkp[i][k])
+						}
+					}
+				}
+			}
+
+			// check
+			o.check("K", d, e, e.Umap, e.Umap, e.K, restore)
+		}
+	}
+	return
+}
+
+// beam_DebugKb defines a global function to debug Kb for beam elements
+//  Note: it returns a function to reset the global function
+func beam_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*Beam); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore solution
+			defer func() {
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// check
+			o.check("K", d, e, e.Umap, e.Umap, e.K, func() {})
+		}
+	}
+	return
+}
+
+// rjoint_DebugKb defines a global function to debug Kb for rod-joint elements
+//  Note: 1) it returns a function to reset the global function
+//        2) the solid element is also updated when computing the numerical derivatives; thus
+//           the coupling through the confining stress (DτDσc) is checked as well
+func rjoint_DebugKb(o *testKb) (resetDebugKb func()) {
+
+	// define reset function
+	resetDebugKb = func() {
+		Global.DebugKb = nil
+	}
+
+	// define debug function
+	Global.DebugKb = func(d *Domain, it int) {
+
+		elem := d.Elems[o.eid]
+		if e, ok := elem.(*Rjoint); ok {
+
+			// skip?
+			o.it = it
+			o.t = d.Sol.T
+			if o.skip() {
+				return
+			}
+
+			// copy states and solution
+			sld := e.Sld
+			nip := len(e.States)
+			sldNip := len(sld.States)
+			states := make([]*msolid.OnedState, nip)
+			statesBkp := make([]*msolid.OnedState, nip)
+			for i := 0; i < nip; i++ {
+				states[i] = e.States[i].GetCopy()
+				statesBkp[i] = e.StatesBkp[i].GetCopy()
+			}
+			sldStates := make([]*msolid.State, sldNip)
+			sldStatesBkp := make([]*msolid.State, sldNip)
+			for i := 0; i < sldNip; i++ {
+				sldStates[i] = sld.States[i].GetCopy()
+				sldStatesBkp[i] = sld.StatesBkp[i].GetCopy()
+			}
+			o.aux_arrays(d)
+
+			// make sure to restore states and solution
+			defer func() {
+				for i := 0; i < nip; i++ {
+					e.States[i].Set(states[i])
+					e.StatesBkp[i].Set(statesBkp[i])
+				}
+				for i := 0; i < sldNip; i++ {
+					sld.States[i].Set(sldStates[i])
+					sld.StatesBkp[i].Set(sldStatesBkp[i])
+				}
+				copy(d.Sol.ΔY, o.ΔYbkp)
+			}()
+
+			// define restore function: also updates the solid because σc depends on its stresses
+			restore := func() {
+				for k := 0; k < nip; k++ {
+					if it == 0 {
+						e.States[k].Set(states[k])
+					} else {
+						e.States[k].Set(statesBkp[k])
+					}
+				}
+				for k := 0; k < sldNip; k++ {
+					if it == 0 {
+						sld.States[k].Set(sldStates[k])
+					} else {
+						sld.States[k].Set(sldStatesBkp[k])
+					}
+				}
+				sld.Update(d.Sol)
+			}
+
+			// joint matrix with solid equations first and then rod equations
+			nus, nur := sld.Nu, e.Rod.Nu
+			K := la.MatAlloc(e.Ny, e.Ny)
+			for i := 0; i < nus; i++ {
+				for j := 0; j < nus; j++ {
+					K[i][j] = e.Kss[i][j]
+				}
+				for j := 0; j < nur; j++ {
+					K[i][nus+j] = e.Ksr[i][j]
+					K[nus+j][i] = e.Krs[j][i]
+				}
+			}
+			for i := 0; i < nur; i++ {
+				for j := 0; j < nur; j++ {
+					K[nus+i][nus+j] = e.Krr[i][j]
+				}
+			}
+			umap := append(append([]int{}, sld.Umap...), e.Rod.Umap...)
+
+			// check
+			o.check("K", d, e, umap, umap, K, restore)
+		}
+	}
+	return
+}
+
 // skip skips test based on it and/or t
 func (o testKb) skip() bool {
 	if o.itmin >= 0 {
@@ -428,10 +1106,14 @@ func (o *testKb) aux_arrays(d *Domain) {
 // check performs the checking of Kb using numerical derivatives
 func (o *testKb) check(label string, d *Domain, e Elem, Imap, Jmap []int, Kana [][]float64, restore func()) {
 	var imap, jmap []int
-	if o.ni <= len(Imap) {
+	if o.ni < 0 {
+		imap = Imap
+	} else if o.ni <= len(Imap) {
 		imap = Imap[:o.ni]
 	}
-	if o.nj <= len(Jmap) {
+	if o.nj < 0 {
+		jmap = Jmap
+	} else if o.nj <= len(Jmap) {
 		jmap = Jmap[:o.nj]
 	}
 	//derivfcn := num.DerivFwd
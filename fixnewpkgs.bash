@@ -70,7 +70,7 @@ fix_pkgs_simple() {
     cd $HERE
 }
 
-for p in fem inp mconduct mporous mreten msolid out shp; do
+for p in fem inp mconduct mporous mreten msolid mtherm mtransp out shp; do
 #for p in inp; do
 #    fix_pkgs $p 1
     fix_pkgs_simple $p 1
@@ -0,0 +1,116 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"math"
+)
+
+// AnchorData holds data for ground anchors made of rod cells embedded in solids by means of
+// joint cells (rod-joint elements)
+//  Note: the rod cells within Lfree of the head vertex form the free length; the joints of these
+//        cells do not transfer shear (the tendon slides inside its sheath) and the rods are hence
+//        axially connected to the rest of the structure only at their ends. The remaining rod
+//        cells form the bond (grouted) length
+type AnchorData struct {
+	Tag   int     `json:"tag"`   // tag of rod cells of anchor
+	Head  int     `json:"head"`  // tag of vertex at the head of anchor
+	Lfree float64 `json:"lfree"` // free length measured from the head
+	Khead float64 `json:"khead"` // stiffness of locked head (bearing plate); 0 => 1000 E A / Lfree
+
+	// derived
+	HeadVid int          // id of head vertex
+	Dir     []float64    // [ndim] unit vector along anchor pointing from the bond length to the head
+	Free    map[int]bool // ids of rod cells in the free length
+	Bond    []int        // ids of rod cells in the bond length
+}
+
+// LockoffData holds data for applying the lock-off (jacking) force to anchors during a stage
+//  Note: the head of the anchor is free during the stage with the lock-off force and it is
+//        locked onto the bearing plate at the end of this stage; i.e. the force in the anchor
+//        changes with the deformation of the structure in the following stages
+type LockoffData struct {
+	Tag  int    `json:"tag"`  // tag of anchor (tag of its rod cells)
+	Func string `json:"func"` // name of function with the lock-off force
+}
+
+// SetAnchor splits the rod cells of an anchor into its free and bond lengths
+//  Note: 1) Lfree must coincide with a vertex of the rod cells
+//        2) returns false on errors
+func (o *Mesh) SetAnchor(a *AnchorData) (ok bool) {
+
+	// check
+	cells, found := o.CellTag2cells[a.Tag]
+	if LogErrCond(!found, "msh: cannot find rod cells with tag = %d to set anchor\n", a.Tag) {
+		return
+	}
+	verts, found := o.VertTag2verts[a.Head]
+	if LogErrCond(!found || len(verts) != 1, "msh: anchor with tag = %d requires one head vertex with tag = %d\n", a.Tag, a.Head) {
+		return
+	}
+	if LogErrCond(a.Lfree <= 0, "msh: free length of anchor with tag = %d must be positive. lfree = %g is incorrect\n", a.Tag, a.Lfree) {
+		return
+	}
+	a.HeadVid = verts[0].Id
+	xh := verts[0].C
+
+	// distance between vertex and head
+	dist := func(vid int) float64 {
+		d := 0.0
+		for i := 0; i < o.Ndim; i++ {
+			d += math.Pow(o.Verts[vid].C[i]-xh[i], 2.0)
+		}
+		return math.Sqrt(d)
+	}
+
+	// extent of cells and farthest vertex
+	dmin := make([]float64, len(cells))
+	dmax := make([]float64, len(cells))
+	tol := math.MaxFloat64
+	far, dfar, hashead := 0, 0.0, false
+	for k, c := range cells {
+		dmin[k], dmax[k] = math.MaxFloat64, 0
+		for _, v := range c.Verts {
+			d := dist(v)
+			dmin[k] = math.Min(dmin[k], d)
+			dmax[k] = math.Max(dmax[k], d)
+			if d > dfar {
+				far, dfar = v, d
+			}
+			if v == a.HeadVid {
+				hashead = true
+			}
+		}
+		tol = math.Min(tol, 1e-3*(dmax[k]-dmin[k]))
+	}
+	if LogErrCond(!hashead, "msh: head vertex of anchor with tag = %d does not belong to its rod cells\n", a.Tag) {
+		return
+	}
+	if LogErrCond(a.Lfree >= dfar-tol, "msh: free length of anchor with tag = %d must be smaller than its total length = %g. lfree = %g is incorrect\n", a.Tag, dfar, a.Lfree) {
+		return
+	}
+
+	// direction
+	a.Dir = make([]float64, o.Ndim)
+	for i := 0; i < o.Ndim; i++ {
+		a.Dir[i] = (xh[i] - o.Verts[far].C[i]) / dfar
+	}
+
+	// split cells
+	a.Free = make(map[int]bool)
+	a.Bond = make([]int, 0)
+	for k, c := range cells {
+		switch {
+		case dmax[k] <= a.Lfree+tol:
+			a.Free[c.Id] = true
+		case dmin[k] >= a.Lfree-tol:
+			a.Bond = append(a.Bond, c.Id)
+		default:
+			LogErrCond(true, "msh: free length of anchor with tag = %d must coincide with a vertex of its rod cells. lfree = %g is incorrect\n", a.Tag, a.Lfree)
+			return
+		}
+	}
+	return true
+}
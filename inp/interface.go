@@ -0,0 +1,136 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inp
+
+import (
+	"log"
+
+	"github.com/cpmech/gofem/shp"
+)
+
+// InterfaceData holds data for inserting zero-thickness interface cells along tagged faces
+type InterfaceData struct {
+	Ftag int `json:"ftag"` // tag of faces (edges in 2D) where interface cells are inserted
+	Ctag int `json:"ctag"` // tag of cells on the side whose vertices are duplicated
+	Tag  int `json:"tag"`  // tag of new interface cells
+}
+
+// interfaceTypes maps interface cell types to the geometry of their faces
+var interfaceTypes = map[string]string{
+	"int-lin2": "lin2",
+	"int-lin3": "lin3",
+	"int-tri3": "tri3",
+	"int-tri6": "tri6",
+	"int-qua4": "qua4",
+	"int-qua8": "qua8",
+}
+
+// faceReversed holds the local vertices of faces with reversed orientation
+var faceReversed = map[string][]int{
+	"lin2": {1, 0},
+	"lin3": {1, 0, 2},
+	"tri3": {0, 2, 1},
+	"tri6": {0, 2, 1, 5, 4, 3},
+	"qua4": {0, 3, 2, 1},
+	"qua8": {0, 3, 2, 1, 7, 6, 5, 4},
+}
+
+// InterfaceFaceType returns the geometry of the faces of an interface cell; e.g. "int-qua4" => "qua4"
+//  Note: returns "" if cellType does not correspond to an interface cell
+func InterfaceFaceType(cellType string) string {
+	return interfaceTypes[cellType]
+}
+
+// AddInterfaces inserts zero-thickness interface cells along faces tagged with ftag.
+// The vertices on these faces are duplicated and the new vertices are given to all cells
+// tagged with ctag (the "top" side). The vertices of the new interface cells are ordered
+// as [bottom face vertices..., top face vertices...] with the bottom face following the
+// orientation of the face of the cell on the other side (outward normal pointing to the top side)
+//  Note: 1) all vertices on the faces are duplicated, including the ones at the tips of the
+//           interface. Thus, the faces must separate regions of cells with different tags
+//        2) returns false on errors
+func (o *Mesh) AddInterfaces(ftag, ctag, itag int) (ok bool) {
+
+	// check
+	pairs, found := o.FaceTag2cells[ftag]
+	if LogErrCond(!found, "msh: cannot find faces with tag = %d to insert interfaces\n", ftag) {
+		return
+	}
+	if LogErrCond(itag >= 0, "msh: interface cells tags must be negative\n") {
+		return
+	}
+
+	// new interface cells
+	var cells []*Cell
+	dup := make(map[int]int) // maps old vertex id to new vertex id
+	for _, pair := range pairs {
+		c := pair.C
+		if c.Tag != ctag || c.Shp == nil {
+			continue
+		}
+
+		// face data
+		ftype := c.Shp.FaceType
+		rev, found := faceReversed[ftype]
+		if LogErrCond(!found, "msh: cannot insert interface along faces of type %q\n", ftype) {
+			return
+		}
+		lverts := shp.GetFaceLocalVerts(c.Type, pair.Fid)
+
+		// bottom (old) and top (new) vertices
+		nf := len(rev)
+		verts := make([]int, 2*nf)
+		for k, l := range rev {
+			vid := c.Verts[lverts[l]]
+			if _, has := dup[vid]; !has {
+				old := o.Verts[vid]
+				v := &Vert{Id: len(o.Verts), Tag: old.Tag, C: make([]float64, len(old.C))}
+				copy(v.C, old.C)
+				o.Verts = append(o.Verts, v)
+				if v.Tag < 0 {
+					o.VertTag2verts[v.Tag] = append(o.VertTag2verts[v.Tag], v)
+				}
+				dup[vid] = v.Id
+			}
+			verts[k] = vid
+			verts[nf+k] = dup[vid]
+		}
+
+		// new cell
+		cells = append(cells, &Cell{
+			Id:          len(o.Cells) + len(cells),
+			Tag:         itag,
+			Type:        "int-" + ftype,
+			Part:        c.Part,
+			Verts:       verts,
+			Shp:         shp.Get(ftype),
+			IsInterface: true,
+		})
+	}
+	if LogErrCond(len(cells) == 0, "msh: cannot find cells with tag = %d along faces with tag = %d\n", ctag, ftag) {
+		return
+	}
+
+	// give new vertices to cells on the top side
+	for _, c := range o.CellTag2cells[ctag] {
+		for j, vid := range c.Verts {
+			if newid, has := dup[vid]; has {
+				c.Verts[j] = newid
+			}
+		}
+	}
+
+	// add new cells
+	for _, c := range cells {
+		o.Cells = append(o.Cells, c)
+		o.CellTag2cells[c.Tag] = append(o.CellTag2cells[c.Tag], c)
+		o.Ctype2cells[c.Type] = append(o.Ctype2cells[c.Type], c)
+		o.Part2cells[c.Part] = append(o.Part2cells[c.Part], c)
+	}
+
+	// log
+	log.Printf("msh: interfaces inserted along faces with tag=%d: nverts=%d ncells=%d\n", ftag, len(dup), len(cells))
+	return true
+}
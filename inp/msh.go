@@ -44,8 +44,9 @@ type Cell struct {
 	Shp *shp.Shape // shape structure
 
 	// specific problems data
-	IsJoint   bool         // cell represents joint element
-	SeepVerts map[int]bool // local vertices ids of vertices on seepage faces
+	IsJoint     bool         // cell represents joint element
+	IsInterface bool         // cell represents zero-thickness interface element; Verts = [bottom face..., top face...]
+	SeepVerts   map[int]bool // local vertices ids of vertices on seepage faces
 }
 
 // CellFaceId structure
@@ -204,6 +205,12 @@ func ReadMsh(dir, fn string) *Mesh {
 		switch c.Type {
 		case "joint":
 			c.IsJoint = true
+		case "int-lin2", "int-lin3", "int-tri3", "int-tri6", "int-qua4", "int-qua8":
+			c.IsInterface = true
+			c.Shp = shp.Get(InterfaceFaceType(c.Type))
+			if LogErrCond(len(c.Verts) != 2*c.Shp.Nverts, "msh: interface cell %d must have %d vertices\n", c.Id, 2*c.Shp.Nverts) {
+				return nil
+			}
 		default:
 			c.Shp = shp.Get(c.Type)
 			if LogErrCond(c.Shp == nil, "msh: cannot find shape type == %q\n", c.Type) {
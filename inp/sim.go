@@ -37,6 +37,7 @@ type Data struct {
 	Debug   bool    `json:"debug"`   // activate debugging
 	Stat    bool    `json:"stat"`    // activate statistics
 	Wlevel  float64 `json:"wlevel"`  // water level; 0 means use max elevation
+	MassBal []int   `json:"massbal"` // tags of faces through which liquid may enter or leave the domain; if given, the liquid mass balance is computed at each time step
 
 	// options
 	React bool `json:"react"` // indicates whether or not reaction forces must be computed
@@ -195,9 +196,11 @@ type ElemData struct {
 type Region struct {
 
 	// input data
-	Desc      string      `json:"desc"`      // description of region. ex: ground, indenter, etc.
-	Mshfile   string      `json:"mshfile"`   // file path of file with mesh data
-	ElemsData []*ElemData `json:"elemsdata"` // list of elements data
+	Desc       string           `json:"desc"`       // description of region. ex: ground, indenter, etc.
+	Mshfile    string           `json:"mshfile"`    // file path of file with mesh data
+	ElemsData  []*ElemData      `json:"elemsdata"`  // list of elements data
+	Interfaces []*InterfaceData `json:"interfaces"` // interface cells to be inserted along tagged faces
+	Anchors    []*AnchorData    `json:"anchors"`    // ground anchors made of rods and rod-joints
 
 	// derived
 	Msh *Mesh // the mesh
@@ -238,6 +241,18 @@ type EleCond struct {
 	Extra string   `json:"extra"` // extra information. ex: '!λl:10'
 }
 
+// ContactData holds data for frictional contact between a set of slave nodes and master faces
+type ContactData struct {
+	Vtag   int     `json:"vtag"`   // tag of slave vertices
+	Ftag   int     `json:"ftag"`   // tag of master faces
+	Method string  `json:"method"` // enforcement method: "penalty" or "auglag" (augmented Lagrangian)
+	Kn     float64 `json:"kn"`     // normal penalty coefficient [stress/length]
+	Kt     float64 `json:"kt"`     // tangential penalty coefficient [stress/length]. 0 => kt = kn
+	Mu     float64 `json:"mu"`     // Coulomb friction coefficient
+	Gtol   float64 `json:"gtol"`   // auglag: tolerance on penetration
+	Naug   int     `json:"naug"`   // auglag: maximum number of augmentations per time step
+}
+
 // TimeControl holds data for defining the simulation time stepping
 type TimeControl struct {
 	Tf     float64 `json:"tf"`     // final time
@@ -287,6 +302,7 @@ type Stage struct {
 	Save       bool   `json:"save"`       // save stage data to binary file
 	Load       string `json:"load"`       // load stage data (filename) from binary file
 	Skip       bool   `json:"skip"`       // do not run stage
+	Continue   bool   `json:"continue"`   // continue from the state (primary and internal variables) of the previous stage
 
 	// specific problems data
 	HydroSt   bool           `json:"hydrost"`   // hydrostatic initial condition
@@ -297,10 +313,12 @@ type Stage struct {
 	Import    *ImportRes     `json:"import"`    // import results from another previous simulation
 
 	// conditions
-	EleConds []*EleCond `json:"eleconds"` // element conditions. ex: gravity or beam distributed loads
-	FaceBcs  []*FaceBc  `json:"facebcs"`  // face boundary conditions
-	SeamBcs  []*SeamBc  `json:"seambcs"`  // seam (3D) boundary conditions
-	NodeBcs  []*NodeBc  `json:"nodebcs"`  // node boundary conditions
+	EleConds []*EleCond     `json:"eleconds"` // element conditions. ex: gravity or beam distributed loads
+	FaceBcs  []*FaceBc      `json:"facebcs"`  // face boundary conditions
+	SeamBcs  []*SeamBc      `json:"seambcs"`  // seam (3D) boundary conditions
+	NodeBcs  []*NodeBc      `json:"nodebcs"`  // node boundary conditions
+	Contacts []*ContactData `json:"contacts"` // frictional contact conditions
+	Lockoffs []*LockoffData `json:"lockoffs"` // lock-off forces of anchors
 
 	// timecontrol
 	Control TimeControl `json:"control"` // time control
@@ -393,6 +411,20 @@ func ReadSim(dir, fn string, erasefiles bool) *Simulation {
 			return nil
 		}
 
+		// insert interfaces
+		for _, itf := range reg.Interfaces {
+			if LogErrCond(!reg.Msh.AddInterfaces(itf.Ftag, itf.Ctag, itf.Tag), "cannot insert interfaces") {
+				return nil
+			}
+		}
+
+		// split anchors into free and bond lengths
+		for _, anc := range reg.Anchors {
+			if LogErrCond(!reg.Msh.SetAnchor(anc), "cannot set anchor") {
+				return nil
+			}
+		}
+
 		// dependent variables
 		reg.etag2idx = make(map[int]int)
 		for j, ed := range reg.ElemsData {
@@ -475,6 +507,28 @@ func ReadSim(dir, fn string, erasefiles bool) *Simulation {
 			stg.Control.DtOut = stg.Control.DtoFunc.F(t, nil)
 		}
 
+		// contacts
+		for _, cd := range stg.Contacts {
+			if cd.Method == "" {
+				cd.Method = "penalty"
+			}
+			if LogErrCond(cd.Method != "penalty" && cd.Method != "auglag", "sim: contact method %q is invalid; use \"penalty\" or \"auglag\"\n", cd.Method) {
+				return nil
+			}
+			if LogErrCond(cd.Kn <= 0 || cd.Kt < 0 || cd.Mu < 0, "sim: contact parameters must satisfy kn > 0, kt ≥ 0 and mu ≥ 0. kn=%g, kt=%g and mu=%g are incorrect\n", cd.Kn, cd.Kt, cd.Mu) {
+				return nil
+			}
+			if cd.Kt == 0 {
+				cd.Kt = cd.Kn
+			}
+			if cd.Gtol <= 0 {
+				cd.Gtol = 1e-8
+			}
+			if cd.Naug <= 0 {
+				cd.Naug = 10
+			}
+		}
+
 		// first stage
 		if i == 0 {
 
@@ -35,6 +35,54 @@ func Test_msh01(tst *testing.T) {
 	chk.Scalar(tst, "ymax", 1e-17, msh.Ymax, 1)
 }
 
+func Test_msh02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("msh02")
+
+	msh := ReadMsh("data", "itf01.msh")
+	if msh == nil {
+		tst.Errorf("test failed\n")
+		return
+	}
+	chk.IntAssert(msh.Ndim, 3)
+
+	// insert interface between hex8 cells
+	if !msh.AddInterfaces(-20, -2, -3) {
+		tst.Errorf("AddInterfaces failed\n")
+		return
+	}
+	io.Pforan("%v\n", msh)
+	chk.IntAssert(len(msh.Verts), 16)
+	chk.IntAssert(len(msh.Cells), 3)
+	c := msh.Cells[2]
+	if !c.IsInterface || c.Shp.Type != "qua4" {
+		tst.Errorf("new cell must be an interface with qua4 faces\n")
+		return
+	}
+	chk.String(tst, c.Type, "int-qua4")
+	chk.Ints(tst, "interface verts", c.Verts, []int{4, 5, 6, 7, 12, 13, 14, 15})
+	chk.Ints(tst, "top cell verts  ", msh.Cells[1].Verts, []int{12, 13, 14, 15, 8, 9, 10, 11})
+	chk.Ints(tst, "bottom cell verts", msh.Cells[0].Verts, []int{0, 1, 2, 3, 4, 5, 6, 7})
+	chk.Vector(tst, "x12", 1e-17, msh.Verts[12].C, []float64{0, 0, 1})
+	chk.IntAssert(len(msh.VertTag2verts[-10]), 2)
+	chk.IntAssert(len(msh.CellTag2cells[-3]), 1)
+	chk.IntAssert(len(msh.Ctype2cells["int-qua4"]), 1)
+
+	// write and read again
+	io.WriteFileSD("/tmp/gofem/inp", "itf01.msh", msh.String())
+	msh2 := ReadMsh("/tmp/gofem/inp", "itf01.msh")
+	if msh2 == nil {
+		tst.Errorf("cannot read mesh with interface\n")
+		return
+	}
+	chk.IntAssert(len(msh2.Cells), 3)
+	if !msh2.Cells[2].IsInterface {
+		tst.Errorf("cell 2 must be an interface\n")
+		return
+	}
+}
+
 func Test_sim01(tst *testing.T) {
 
 	//verbose()
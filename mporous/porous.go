@@ -45,21 +45,30 @@ type Model struct {
 	Ncns2   bool    // use non-consistent method only for second order derivatives (see [1])
 
 	// parameters
-	Nf0   float64 // nf0: initial volume fraction of all fluids ~ porosity
-	RhoL0 float64 // ρL0: initial liquid real density
-	RhoG0 float64 // ρG0: initial gas real density
-	RhoS0 float64 // real (intrinsic) density of solids
-	BulkL float64 // liquid bulk moduli at temperature θini
-	RTg   float64 // R*Θ*g: initial gas constant
-	Gref  float64 // reference gravity, at time of measuring ksat, kgas
-	Pkl   float64 // isotrpic liquid saturated conductivity
-	Pkg   float64 // isotrpic gas saturated conductivity
+	Nf0   float64   // nf0: initial volume fraction of all fluids ~ porosity
+	RhoL0 float64   // ρL0: initial liquid real density
+	RhoG0 float64   // ρG0: initial gas real density
+	RhoS0 float64   // real (intrinsic) density of solids
+	BulkL float64   // liquid bulk moduli at temperature θini
+	RTg   float64   // R*Θ*g: initial gas constant
+	Gref  float64   // reference gravity, at time of measuring ksat, kgas
+	Kl    []float64 // [3] principal liquid saturated conductivities: klx, kly, klz
+	Kg    []float64 // [3] principal gas saturated conductivities: kgx, kgy, kgz
+	Rot   []float64 // [3] rotation angles [deg] of principal directions around x, y and z axes
+	BetaL float64   // βL: coefficient of volumetric thermal expansion of liquid
+	Bvis  float64   // coefficient of temperature-dependent viscosity of liquid: μ(θ) = μ(θref)・exp(-Bvis・(θ-θref))
+	Tref  float64   // θref: reference temperature
+	Alpha float64   // α: Biot coefficient
+	Ks    float64   // bulk modulus of solid grains; zero means incompressible grains
 
 	// derived
 	Cl    float64     // liquid compresssibility
 	Cg    float64     // gas compressibility
-	Klsat [][]float64 // klsat ÷ Gref
-	Kgsat [][]float64 // kgsat ÷ Gref
+	Cs    float64     // compressibility of solid grains = 1/Ks (zero if grains are incompressible)
+	Pkl   float64     // isotrpic liquid saturated conductivity; zero if anisotropic
+	Pkg   float64     // isotrpic gas saturated conductivity; zero if anisotropic
+	Klsat [][]float64 // klsat ÷ Gref; rotated tensor: R・diag(Kl)・tr(R) ÷ Gref
+	Kgsat [][]float64 // kgsat ÷ Gref; rotated tensor: R・diag(Kg)・tr(R) ÷ Gref
 
 	// conductivity and retention models
 	Cnd mconduct.Model // liquid-gas conductivity models
@@ -88,12 +97,14 @@ func (o *Model) Init(prms fun.Prms, cnd mconduct.Model, lrm mreten.Model) (err e
 	o.PcZero = 1e-10
 	o.MEtrial = true
 
-	// saturated conductivities
-	var klx, kly, klz float64
-	var kgx, kgy, kgz float64
+	// saturated conductivities and rotation angles
+	o.Kl = make([]float64, 3)
+	o.Kg = make([]float64, 3)
+	o.Rot = make([]float64, 3)
 
 	// read paramaters in
 	o.RTg = 1.0
+	o.Alpha = 1.0
 	for _, p := range prms {
 		switch p.N {
 		case "NmaxIt":
@@ -127,28 +138,78 @@ func (o *Model) Init(prms fun.Prms, cnd mconduct.Model, lrm mreten.Model) (err e
 		case "gref":
 			o.Gref = p.V
 		case "kl":
-			o.Pkl = p.V
-			klx, kly, klz = p.V, p.V, p.V
+			o.Kl[0], o.Kl[1], o.Kl[2] = p.V, p.V, p.V
 		case "kg":
-			o.Pkg = p.V
-			kgx, kgy, kgz = p.V, p.V, p.V
+			o.Kg[0], o.Kg[1], o.Kg[2] = p.V, p.V, p.V
+		case "klx":
+			o.Kl[0] = p.V
+		case "kly":
+			o.Kl[1] = p.V
+		case "klz":
+			o.Kl[2] = p.V
+		case "kgx":
+			o.Kg[0] = p.V
+		case "kgy":
+			o.Kg[1] = p.V
+		case "kgz":
+			o.Kg[2] = p.V
+		case "rx":
+			o.Rot[0] = p.V
+		case "ry":
+			o.Rot[1] = p.V
+		case "rz":
+			o.Rot[2] = p.V
+		case "betaL":
+			o.BetaL = p.V
+		case "bvis":
+			o.Bvis = p.V
+		case "Tref":
+			o.Tref = p.V
+		case "alpha":
+			o.Alpha = p.V
+		case "Ks":
+			o.Ks = p.V
 		default:
 			return chk.Err("mporous.Model: parameter named %q is incorrect\n", p.N)
 		}
 	}
 
+	// check Biot parameters
+	if o.Alpha <= 0 || o.Alpha > 1 {
+		return chk.Err("mporous.Model: Biot coefficient alpha must be in (0, 1]. alpha=%g\n", o.Alpha)
+	}
+	if o.Ks < 0 {
+		return chk.Err("mporous.Model: bulk modulus of grains Ks must be non-negative. Ks=%g\n", o.Ks)
+	}
+	if o.Ks > 0 && o.Alpha < o.Nf0 {
+		return chk.Err("mporous.Model: Biot coefficient alpha must not be smaller than porosity nf0 when grains are compressible. alpha=%g, nf0=%g\n", o.Alpha, o.Nf0)
+	}
+
 	// derived
 	o.Cl = o.RhoL0 / o.BulkL
 	o.Cg = 1.0 / o.RTg
-	o.Klsat = [][]float64{
-		{klx / o.Gref, 0, 0},
-		{0, kly / o.Gref, 0},
-		{0, 0, klz / o.Gref},
+	if o.Ks > 0 {
+		o.Cs = 1.0 / o.Ks
 	}
-	o.Kgsat = [][]float64{
-		{kgx / o.Gref, 0, 0},
-		{0, kgy / o.Gref, 0},
-		{0, 0, kgz / o.Gref},
+	o.Pkl, o.Pkg = 0, 0
+	if isotropic(o.Kl) {
+		o.Pkl = o.Kl[0]
+	}
+	if isotropic(o.Kg) {
+		o.Pkg = o.Kg[0]
+	}
+	R := RotationMatrix(o.Rot[0], o.Rot[1], o.Rot[2])
+	o.Klsat = make([][]float64, 3)
+	o.Kgsat = make([][]float64, 3)
+	for i := 0; i < 3; i++ {
+		o.Klsat[i] = make([]float64, 3)
+		o.Kgsat[i] = make([]float64, 3)
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				o.Klsat[i][j] += R[i][k] * o.Kl[k] * R[j][k] / o.Gref
+				o.Kgsat[i][j] += R[i][k] * o.Kg[k] * R[j][k] / o.Gref
+			}
+		}
 	}
 	return
 }
@@ -168,7 +229,7 @@ func (o Model) GetPrms(example bool) fun.Prms {
 			&fun.Prm{N: "kg", V: 1e-2},
 		}
 	}
-	return fun.Prms{
+	prms := fun.Prms{
 		&fun.Prm{N: "nf0", V: o.Nf0},
 		&fun.Prm{N: "RhoL0", V: o.RhoL0},
 		&fun.Prm{N: "RhoG0", V: o.RhoG0},
@@ -176,9 +237,35 @@ func (o Model) GetPrms(example bool) fun.Prms {
 		&fun.Prm{N: "BulkL", V: o.BulkL},
 		&fun.Prm{N: "RTg", V: o.RTg},
 		&fun.Prm{N: "gref", V: o.Gref},
-		&fun.Prm{N: "kl", V: o.Pkl},
-		&fun.Prm{N: "kg", V: o.Pkg},
 	}
+	if isotropic(o.Kl) {
+		prms = append(prms, &fun.Prm{N: "kl", V: o.Pkl})
+	} else {
+		prms = append(prms,
+			&fun.Prm{N: "klx", V: o.Kl[0]},
+			&fun.Prm{N: "kly", V: o.Kl[1]},
+			&fun.Prm{N: "klz", V: o.Kl[2]},
+		)
+	}
+	if isotropic(o.Kg) {
+		prms = append(prms, &fun.Prm{N: "kg", V: o.Pkg})
+	} else {
+		prms = append(prms,
+			&fun.Prm{N: "kgx", V: o.Kg[0]},
+			&fun.Prm{N: "kgy", V: o.Kg[1]},
+			&fun.Prm{N: "kgz", V: o.Kg[2]},
+		)
+	}
+	return append(prms,
+		&fun.Prm{N: "rx", V: o.Rot[0]},
+		&fun.Prm{N: "ry", V: o.Rot[1]},
+		&fun.Prm{N: "rz", V: o.Rot[2]},
+		&fun.Prm{N: "betaL", V: o.BetaL},
+		&fun.Prm{N: "bvis", V: o.Bvis},
+		&fun.Prm{N: "Tref", V: o.Tref},
+		&fun.Prm{N: "alpha", V: o.Alpha},
+		&fun.Prm{N: "Ks", V: o.Ks},
+	)
 }
 
 // InitState initialises a state structure with a continous solver and saturation starting at 1
@@ -191,6 +278,7 @@ func (o Model) InitState(s *State, ρL, ρG, pl, pg, divus float64) (err error)
 	s.RhoL = ρL
 	s.RhoG = ρG
 	s.Dpc = 0
+	s.Temp = o.Tref
 	s.Wet = false
 	pc := pg - pl
 	if pc > 0 {
@@ -316,6 +404,35 @@ func (o Model) Update(s *State, Δpl, Δpg, divusNew float64) (err error) {
 	return
 }
 
+// UpdateTemp updates the temperature and the (temperature-dependent) liquid density
+//  Note: this must be called by coupled thermo-hydraulic elements after Update
+func (o Model) UpdateTemp(s *State, Δθ float64) {
+	s.Temp += Δθ
+	s.RhoL -= o.BetaL * o.RhoL0 * Δθ
+}
+
+// ViscRatio returns the ratio of liquid viscosities μ(θref)/μ(θ) and its derivative
+//  Note: the conductivities are multiplied by this ratio; i.e. kl(θ) = kl(θref)・μ(θref)/μ(θ)
+func (o Model) ViscRatio(θ float64) (vr, dvrdθ float64) {
+	vr = math.Exp(o.Bvis * (θ - o.Tref))
+	dvrdθ = o.Bvis * vr
+	return
+}
+
+// BiotModulus returns the Biot modulus M of a saturated medium with porosity nf
+//  1/M = nf/Kl + (α - nf)/Ks
+//  Note: returns +Inf if both liquid and grains are incompressible
+func (o Model) BiotModulus(nf float64) (M float64) {
+	invM := (o.Alpha - nf) * o.Cs
+	if o.BulkL > 0 {
+		invM += nf / o.BulkL
+	}
+	if invM <= 0 {
+		return math.Inf(1)
+	}
+	return 1.0 / invM
+}
+
 // Ccb (Cc-bar) returns dsl/dpc consistent with the update method
 //  See Eq. (54) on page 618 of [1]
 func (o Model) Ccb(s *State) (dsldpc float64, err error) {
@@ -365,6 +482,27 @@ func (o Model) Ccd(s *State) (dCcdpc float64, err error) {
 	return
 }
 
+// isotropic checks whether the principal conductivities are all equal
+//  Note: returns true if k is not allocated yet; i.e. before Init
+func isotropic(k []float64) bool {
+	return len(k) < 3 || (k[0] == k[1] && k[1] == k[2])
+}
+
+// RotationMatrix computes the rotation matrix R = Rz・Ry・Rx corresponding to (successive) rotations
+// around the x, y and z axes. The angles are given in degrees
+//  Note: in 2D, only rz (rotation in the x-y plane) should be used; e.g. to set the bedding angle
+func RotationMatrix(rx, ry, rz float64) (R [][]float64) {
+	cx, sx := math.Cos(rx*math.Pi/180.0), math.Sin(rx*math.Pi/180.0)
+	cy, sy := math.Cos(ry*math.Pi/180.0), math.Sin(ry*math.Pi/180.0)
+	cz, sz := math.Cos(rz*math.Pi/180.0), math.Sin(rz*math.Pi/180.0)
+	R = [][]float64{
+		{cz * cy, cz*sy*sx - sz*cx, cz*sy*cx + sz*sx},
+		{sz * cy, sz*sy*sx + cz*cx, sz*sy*cx - cz*sx},
+		{-sy, cy * sx, cy * cx},
+	}
+	return
+}
+
 // GetModel returns (existent or new) model for porous media
 //  simfnk    -- unique simulation filename key
 //  matname   -- name of material
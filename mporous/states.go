@@ -21,6 +21,7 @@ type State struct {
 	RhoL  float64 // ρL: real (intrinsic) density of liquid
 	RhoG  float64 // ρG: real (intrinsic) density of gas
 	Dpc   float64 // Δpc: step increment of capillary pressure
+	Temp  float64 // θ: temperature
 	Wet   bool    // wetting flag
 }
 
@@ -35,6 +36,7 @@ func (o State) GetCopy() *State {
 		o.RhoL,
 		o.RhoG,
 		o.Dpc,
+		o.Temp,
 		o.Wet,
 	}
 }
@@ -49,6 +51,7 @@ func (o *State) Set(another *State) {
 	o.RhoL = another.RhoL
 	o.RhoG = another.RhoG
 	o.Dpc = another.Dpc
+	o.Temp = another.Temp
 	o.Wet = another.Wet
 }
 
@@ -104,7 +107,30 @@ func (o State) Lderivs(m *Model) (ρl, Cpl, dCpldpl, dklrdpl float64, err error)
 	return
 }
 
+// Tvars calculates variables for temperature-dependent liquid density (coupled thermo-hydraulic simulations)
+//  Note: ρL = ρL0 + Cl・(pl - pl0) - βL・ρL0・(θ - θ0); see Model.UpdateTemp. Thus
+//   Cθ     = ∂ρl/∂θ = -nf・sl・βL・ρL0
+//   dCθdpl = ∂Cθ/∂pl = ∂Cpl/∂θ
+func (o State) Tvars(m *Model) (Cθ, dCθdpl float64, err error) {
+
+	// n variables
+	ns := (1.0 - o.Divus) * o.Ns0
+	nf := 1.0 - ns
+
+	// moduli
+	Ccb, err := m.Ccb(&o)
+	if err != nil {
+		return
+	}
+	Cθ = -nf * o.Sl * m.BetaL * m.RhoL0
+	dCθdpl = nf * m.BetaL * m.RhoL0 * Ccb
+	return
+}
+
 // LSvars calculates variables for liquid-solid simulations
+//  Note: with Biot coefficient α and compressibility of grains Cs = 1/Ks, the storage
+//        coefficient gets the additional term ρL・sl²・(α - nf)・Cs and Cvs = α・sl・ρL.
+//        The change of porosity due to the compression of grains is neglected in nf.
 func (o State) LSvars(m *Model) (ρl, ρ, p, Cpl, Cvs float64, err error) {
 
 	// n variables; Eqs (13) and (28) of [1]
@@ -128,7 +154,12 @@ func (o State) LSvars(m *Model) (ρl, ρ, p, Cpl, Cvs float64, err error) {
 		return
 	}
 	Cpl = nf * (o.Sl*m.Cl - o.RhoL*Ccb) // Eq. (32a) of [1]
-	Cvs = o.Sl * o.RhoL                 // Eq. (32b) of [1]
+	Cvs = m.Alpha * o.Sl * o.RhoL       // Eq. (32b) of [1] with Biot coefficient
+
+	// compressible grains
+	if m.Cs > 0 {
+		Cpl += o.RhoL * o.Sl * o.Sl * (m.Alpha - nf) * m.Cs
+	}
 	return
 }
 
@@ -154,7 +185,7 @@ func (o State) LSderivs(m *Model) (ρl, ρ, Cpl, Cvs, dρdpl, dpdpl, dCpldpl, dC
 		return
 	}
 	Cpl = nf * (o.Sl*m.Cl - o.RhoL*Ccb) // Eq (32a) of [1]
-	Cvs = o.Sl * o.RhoL                 // Eq (32b) of [1]
+	Cvs = m.Alpha * o.Sl * o.RhoL       // Eq (32b) of [1] with Biot coefficient
 
 	// derivatives
 	Ccd, err := m.Ccd(&o)
@@ -163,15 +194,86 @@ func (o State) LSderivs(m *Model) (ρl, ρ, Cpl, Cvs, dρdpl, dpdpl, dCpldpl, dC
 	}
 
 	// derivatives w.r.t pl
-	dρdpl = nf * (o.Sl*m.Cl - o.RhoL*Ccb)      // Eq (A.9) of [1]
-	dpdpl = o.Sl + pc*Ccb                      // Eq (A.11) of [1]
-	dCpldpl = nf * (o.RhoL*Ccd - 2.0*Ccb*m.Cl) // Eq (A.2) of[1]
-	dCvsdpl = o.Sl*m.Cl - Ccb*o.RhoL           // Eq (A.4) of [1]
-	dklrdpl = -m.Cnd.DklrDsl(o.Sl) * Ccb       // Eq (A.7) of [1]
+	dρdpl = nf * (o.Sl*m.Cl - o.RhoL*Ccb)        // Eq (A.9) of [1]
+	dpdpl = o.Sl + pc*Ccb                        // Eq (A.11) of [1]
+	dCpldpl = nf * (o.RhoL*Ccd - 2.0*Ccb*m.Cl)   // Eq (A.2) of[1]
+	dCvsdpl = m.Alpha * (o.Sl*m.Cl - Ccb*o.RhoL) // Eq (A.4) of [1]
+	dklrdpl = -m.Cnd.DklrDsl(o.Sl) * Ccb         // Eq (A.7) of [1]
 
 	// derivatives w.r.t us (multipliers only)
 	dρldusM = o.Sl * o.RhoL * o.Ns0
 	dρdusM = (o.Sl*o.RhoL - m.RhoS0) * o.Ns0    // Eq (A.10) of [1]
 	dCpldusM = (o.Sl*m.Cl - o.RhoL*Ccb) * o.Ns0 // Eq (A.3) of [1]
+
+	// compressible grains
+	if m.Cs > 0 {
+		Cpl += o.RhoL * o.Sl * o.Sl * (m.Alpha - nf) * m.Cs
+		dCpldpl += (m.Alpha - nf) * m.Cs * o.Sl * (o.Sl*m.Cl - 2.0*o.RhoL*Ccb)
+		dCpldusM -= o.RhoL * o.Sl * o.Sl * m.Cs * o.Ns0
+	}
+	return
+}
+
+// LGvars calculates variables for liquid-gas simulations
+//  Note: the coefficients are such that
+//   dρl/dt = Cpl・dpl/dt + Cpg・dpg/dt
+//   dρg/dt = Dpl・dpl/dt + Dpg・dpg/dt
+func (o State) LGvars(m *Model) (ρl, ρg, Cpl, Cpg, Dpl, Dpg float64, err error) {
+
+	// n variables
+	ns := (1.0 - o.Divus) * o.Ns0
+	nf := 1.0 - ns
+	nl := nf * o.Sl
+	ng := nf * (1.0 - o.Sl)
+
+	// ρ variables
+	ρl = nl * o.RhoL
+	ρg = ng * o.RhoG
+
+	// moduli
+	Ccb, err := m.Ccb(&o)
+	if err != nil {
+		return
+	}
+	Cpl = nf * (o.Sl*m.Cl - o.RhoL*Ccb)
+	Cpg = nf * o.RhoL * Ccb
+	Dpl = nf * o.RhoG * Ccb
+	Dpg = nf * ((1.0-o.Sl)*m.Cg - o.RhoG*Ccb)
+	return
+}
+
+// LGderivs calculates derivatives for liquid-gas simulations
+//  Note: the derivatives of conductivities w.r.t pg are: dklrdpg = -dklrdpl and dkgrdpg = -dkgrdpl
+func (o State) LGderivs(m *Model) (dCpldpl, dCpldpg, dCpgdpl, dCpgdpg, dDpldpl, dDpldpg, dDpgdpl, dDpgdpg, dklrdpl, dkgrdpl float64, err error) {
+
+	// n variables
+	ns := (1.0 - o.Divus) * o.Ns0
+	nf := 1.0 - ns
+
+	// moduli and derivatives
+	Ccb, err := m.Ccb(&o)
+	if err != nil {
+		return
+	}
+	Ccd, err := m.Ccd(&o)
+	if err != nil {
+		return
+	}
+
+	// derivatives of coefficients of liquid equation
+	dCpldpl = nf * (o.RhoL*Ccd - 2.0*Ccb*m.Cl)
+	dCpldpg = nf * (Ccb*m.Cl - o.RhoL*Ccd)
+	dCpgdpl = dCpldpg
+	dCpgdpg = nf * o.RhoL * Ccd
+
+	// derivatives of coefficients of gas equation
+	dDpldpl = -nf * o.RhoG * Ccd
+	dDpldpg = nf * (Ccb*m.Cg + o.RhoG*Ccd)
+	dDpgdpl = dDpldpg
+	dDpgdpg = -nf * (2.0*Ccb*m.Cg + o.RhoG*Ccd)
+
+	// conductivity model derivatives
+	dklrdpl = -m.Cnd.DklrDsl(o.Sl) * Ccb
+	dkgrdpl = m.Cnd.DkgrDsg(1.0-o.Sl) * Ccb
 	return
 }
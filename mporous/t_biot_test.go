@@ -0,0 +1,175 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mporous
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/mconduct"
+	"github.com/cpmech/gofem/mreten"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/num"
+)
+
+func Test_biot01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("biot01")
+
+	// info
+	simfnk := "biot01"
+	matname := "mat1"
+	getnew := false
+	example := true
+
+	// conductivity model
+	cnd := mconduct.GetModel(simfnk, matname, "m1", getnew)
+	err := cnd.Init(cnd.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mconduct.Init failed: %v\n", err)
+		return
+	}
+
+	// liquid retention model
+	lrm := mreten.GetModel(simfnk, matname, "ref-m1", getnew)
+	err = lrm.Init(lrm.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mreten.Init failed: %v\n", err)
+		return
+	}
+
+	// porous model with Biot coefficient and compressible grains
+	mdl := GetModel(simfnk, matname, getnew)
+	prms := mdl.GetPrms(example)
+	prms = append(prms, &fun.Prm{N: "alpha", V: 0.8}, &fun.Prm{N: "Ks", V: 1e6})
+	err = mdl.Init(prms, cnd, lrm)
+	if err != nil {
+		tst.Errorf("mporous.Init failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "alpha", 1e-15, mdl.Alpha, 0.8)
+	chk.Scalar(tst, "Cs", 1e-15, mdl.Cs, 1e-6)
+
+	// saturated state
+	ρL, ρG, pl, pg, divus := mdl.RhoL0, mdl.RhoG0, 10.0, 0.0, 0.0
+	s, err := mdl.NewState(ρL, ρG, pl, pg, divus)
+	if err != nil {
+		tst.Errorf("NewState failed: %v\n", err)
+		return
+	}
+	_, _, p, Cpl, Cvs, err := s.LSvars(mdl)
+	if err != nil {
+		tst.Errorf("LSvars failed: %v\n", err)
+		return
+	}
+	Ccb, err := mdl.Ccb(s)
+	if err != nil {
+		tst.Errorf("Ccb failed: %v\n", err)
+		return
+	}
+	M := mdl.BiotModulus(mdl.Nf0)
+	io.Pforan("M = %v\n", M)
+	chk.Scalar(tst, "p  ", 1e-15, p, pl)
+	chk.Scalar(tst, "Cpl", 1e-15, Cpl, ρL/M-mdl.Nf0*ρL*Ccb)
+	chk.Scalar(tst, "Cvs", 1e-15, Cvs, mdl.Alpha*ρL)
+
+	// unsaturated state
+	pl0, pl1 := -2.0, -4.0
+	s0, err := mdl.NewState(ρL, ρG, pl0, pg, divus)
+	if err != nil {
+		tst.Errorf("NewState failed: %v\n", err)
+		return
+	}
+	divus1 := 0.01
+	s1 := s0.GetCopy()
+	err = mdl.Update(s1, pl1-pl0, 0, divus1)
+	if err != nil {
+		tst.Errorf("Update failed: %v\n", err)
+		return
+	}
+	_, _, Cpl, Cvs, _, _, dCpldpl, dCvsdpl, _, dCpldusM, _, _, err := s1.LSderivs(mdl)
+	if err != nil {
+		tst.Errorf("LSderivs failed: %v\n", err)
+		return
+	}
+	io.Pforan("sl = %v\n", s1.Sl)
+
+	// check derivatives w.r.t pl
+	var stmp State
+	tol := 1e-6
+	dCplnum := num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+		stmp.Set(s0)
+		mdl.Update(&stmp, x-pl0, 0, divus1)
+		_, _, _, res, _, _ = stmp.LSvars(mdl)
+		return
+	}, pl1)
+	dCvsnum := num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+		stmp.Set(s0)
+		mdl.Update(&stmp, x-pl0, 0, divus1)
+		_, _, _, _, res, _ = stmp.LSvars(mdl)
+		return
+	}, pl1)
+	chk.AnaNum(tst, "dCpl/dpl", tol, dCpldpl, dCplnum, chk.Verbose)
+	chk.AnaNum(tst, "dCvs/dpl", tol, dCvsdpl, dCvsnum, chk.Verbose)
+
+	// check derivative w.r.t divus
+	dCplnum = num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+		stmp.Set(s1)
+		stmp.Divus = x
+		_, _, _, res, _, _ = stmp.LSvars(mdl)
+		return
+	}, divus1)
+	chk.AnaNum(tst, "dCpl/dusM", tol, dCpldusM, dCplnum, chk.Verbose)
+
+	// consistency between LSvars and LSderivs
+	_, _, _, Cpl2, Cvs2, err := s1.LSvars(mdl)
+	if err != nil {
+		tst.Errorf("LSvars failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "Cpl", 1e-15, Cpl, Cpl2)
+	chk.Scalar(tst, "Cvs", 1e-15, Cvs, Cvs2)
+}
+
+func Test_biot02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("biot02")
+
+	// models
+	cnd := mconduct.GetModel("biot02", "mat1", "m1", false)
+	lrm := mreten.GetModel("biot02", "mat1", "ref-m1", false)
+	cnd.Init(cnd.GetPrms(true))
+	lrm.Init(lrm.GetPrms(true))
+	mdl := GetModel("biot02", "mat1", false)
+
+	// alpha smaller than porosity
+	prms := mdl.GetPrms(true)
+	prms = append(prms, &fun.Prm{N: "alpha", V: 0.2}, &fun.Prm{N: "Ks", V: 1e6})
+	if mdl.Init(prms, cnd, lrm) == nil {
+		tst.Errorf("Init should have failed with alpha < nf0\n")
+		return
+	}
+
+	// alpha out of range
+	prms = mdl.GetPrms(true)
+	prms = append(prms, &fun.Prm{N: "alpha", V: 1.2})
+	if mdl.Init(prms, cnd, lrm) == nil {
+		tst.Errorf("Init should have failed with alpha > 1\n")
+		return
+	}
+
+	// defaults: incompressible grains
+	err := mdl.Init(mdl.GetPrms(true), cnd, lrm)
+	if err != nil {
+		tst.Errorf("mporous.Init failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "alpha", 1e-15, mdl.Alpha, 1)
+	chk.Scalar(tst, "Cs", 1e-15, mdl.Cs, 0)
+	chk.Scalar(tst, "M/Kl", 1e-12, mdl.BiotModulus(0.3)/mdl.BulkL, 1.0/0.3)
+}
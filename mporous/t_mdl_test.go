@@ -5,11 +5,13 @@
 package mporous
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gofem/mconduct"
 	"github.com/cpmech/gofem/mreten"
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/plt"
 )
 
@@ -120,3 +122,157 @@ func Test_mdl01(tst *testing.T) {
 		mreten.PlotEnd(true)
 	}
 }
+
+func Test_mdl02(tst *testing.T) {
+
+	//utl.Tsilent = false
+	chk.PrintTitle("mdl02")
+
+	// conductivity and liquid retention models
+	simfnk, matname, getnew, example := "mdl02", "mat1", false, true
+	cnd := mconduct.GetModel(simfnk, matname, "m1", getnew)
+	err := cnd.Init(cnd.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mconduct.Init failed: %v\n", err)
+		return
+	}
+	lrm := mreten.GetModel(simfnk, matname, "ref-m1", getnew)
+	err = lrm.Init(lrm.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mreten.Init failed: %v\n", err)
+		return
+	}
+
+	// porous model with anisotropic conductivities rotated in the x-y plane
+	kx, ky, kz, gref := 1e-2, 1e-3, 2e-3, 10.0
+	mdl := GetModel(simfnk, matname, true)
+	prms := mdl.GetPrms(example)
+	prms = append(prms,
+		&fun.Prm{N: "klx", V: kx},
+		&fun.Prm{N: "kly", V: ky},
+		&fun.Prm{N: "klz", V: kz},
+		&fun.Prm{N: "rz", V: 30},
+	)
+	err = mdl.Init(prms, cnd, lrm)
+	if err != nil {
+		tst.Errorf("mporous.Init failed: %v\n", err)
+		return
+	}
+
+	// check
+	c, s := math.Sqrt(3.0)/2.0, 0.5
+	chk.Matrix(tst, "Klsat", 1e-17, mdl.Klsat, [][]float64{
+		{(kx*c*c + ky*s*s) / gref, (kx - ky) * c * s / gref, 0},
+		{(kx - ky) * c * s / gref, (kx*s*s + ky*c*c) / gref, 0},
+		{0, 0, kz / gref},
+	})
+	chk.Matrix(tst, "Kgsat", 1e-17, mdl.Kgsat, [][]float64{
+		{1e-3, 0, 0},
+		{0, 1e-3, 0},
+		{0, 0, 1e-3},
+	})
+
+	// rotation matrix must be orthogonal
+	R := RotationMatrix(10, 20, 30)
+	I := make([][]float64, 3)
+	for i := 0; i < 3; i++ {
+		I[i] = make([]float64, 3)
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				I[i][j] += R[i][k] * R[j][k]
+			}
+		}
+	}
+	chk.Matrix(tst, "R・tr(R)", 1e-15, I, [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+
+	// isotropic special case
+	chk.Scalar(tst, "Pkl", 1e-17, mdl.Pkl, 0)
+	chk.Scalar(tst, "Pkg", 1e-17, mdl.Pkg, 1e-2)
+	names := make(map[string]float64)
+	for _, p := range mdl.GetPrms(false) {
+		names[p.N] = p.V
+	}
+	if _, ok := names["kl"]; ok {
+		tst.Errorf("kl must not be given for anisotropic liquid conductivities\n")
+	}
+	chk.Scalar(tst, "klx", 1e-17, names["klx"], kx)
+	chk.Scalar(tst, "kg", 1e-17, names["kg"], 1e-2)
+	err = mdl.Init(mdl.GetPrms(false), cnd, lrm)
+	if err != nil {
+		tst.Errorf("mporous.Init failed: %v\n", err)
+		return
+	}
+	chk.Matrix(tst, "Klsat (again)", 1e-17, mdl.Klsat, [][]float64{
+		{(kx*c*c + ky*s*s) / gref, (kx - ky) * c * s / gref, 0},
+		{(kx - ky) * c * s / gref, (kx*s*s + ky*c*c) / gref, 0},
+		{0, 0, kz / gref},
+	})
+}
+
+func Test_mdl03(tst *testing.T) {
+
+	//utl.Tsilent = false
+	chk.PrintTitle("mdl03")
+
+	// conductivity and liquid retention models
+	simfnk, matname, getnew, example := "mdl03", "mat1", false, true
+	cnd := mconduct.GetModel(simfnk, matname, "m1", getnew)
+	err := cnd.Init(cnd.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mconduct.Init failed: %v\n", err)
+		return
+	}
+	lrm := mreten.GetModel(simfnk, matname, "ref-m1", getnew)
+	err = lrm.Init(lrm.GetPrms(example))
+	if err != nil {
+		tst.Errorf("mreten.Init failed: %v\n", err)
+		return
+	}
+
+	// porous model with temperature-dependent liquid
+	mdl := GetModel(simfnk, matname, true)
+	prms := mdl.GetPrms(example)
+	prms = append(prms,
+		&fun.Prm{N: "betaL", V: 2e-4},
+		&fun.Prm{N: "bvis", V: 0.02},
+		&fun.Prm{N: "Tref", V: 15},
+	)
+	err = mdl.Init(prms, cnd, lrm)
+	if err != nil {
+		tst.Errorf("mporous.Init failed: %v\n", err)
+		return
+	}
+
+	// unsaturated state
+	s, err := mdl.NewState(mdl.RhoL0, mdl.RhoG0, -5, 0, 0)
+	if err != nil {
+		tst.Errorf("mporous.NewState failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "θ", 1e-17, s.Temp, 15)
+
+	// check Cθ = ∂ρl/∂θ
+	Cθ, dCθdpl, err := s.Tvars(mdl)
+	if err != nil {
+		tst.Errorf("Tvars failed: %v\n", err)
+		return
+	}
+	ρlA, _, _ := s.Lvars(mdl)
+	mdl.UpdateTemp(s, 10)
+	ρlB, _, _ := s.Lvars(mdl)
+	chk.Scalar(tst, "θ", 1e-17, s.Temp, 25)
+	chk.Scalar(tst, "Cθ", 1e-15, Cθ, (ρlB-ρlA)/10.0)
+
+	// check ∂Cθ/∂pl
+	_, CplA, _ := s.Lvars(mdl)
+	mdl.UpdateTemp(s, -10)
+	_, CplB, _ := s.Lvars(mdl)
+	chk.Scalar(tst, "dCθdpl", 1e-15, dCθdpl, (CplA-CplB)/10.0)
+
+	// viscosity ratio
+	vr, dvrdθ := mdl.ViscRatio(15)
+	chk.Scalar(tst, "vr(θref)", 1e-17, vr, 1)
+	vr, dvrdθ = mdl.ViscRatio(40)
+	chk.Scalar(tst, "vr", 1e-15, vr, math.Exp(0.5))
+	chk.Scalar(tst, "dvrdθ", 1e-15, dvrdθ, 0.02*math.Exp(0.5))
+}
@@ -0,0 +1,315 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// IsoDamage implements an isotropic damage model with exponential softening
+//  σ = (1 - ω) De : εe   with   εe = ε0 + ε
+//  ε0 = Ce : σ0 are the elastic strains corresponding to the initial stresses σ0
+//  ω(κ) = 1 - (κ0/κ) exp(-(κ - κ0) / (κf - κ0))   for κ > κ0
+//  κ = max(εeq(εe)) over the history
+//  equivalent strain εeq:
+//   typ = 0: Mazars                   εeq = sqrt(Σ <εi>²)
+//   typ = 1: modified von Mises       εeq = a I1 + sqrt((a I1)² + 3 J2 / (k (1 + ν)²)) with a = (k-1) / (2 k (1 - 2ν))
+//  Note: if the fracture energy Gf is given, the crack-band approach is used and κf is computed
+//        from the characteristic length h of the element: κf = Gf / (h ft) + κ0 / 2, where ft = E κ0
+type IsoDamage struct {
+	SmallElasticity
+
+	// parameters
+	κ0   float64 // damage threshold
+	κf   float64 // parameter controlling the softening (used if Gf is not given)
+	Gf   float64 // fracture energy (per unit area)
+	k    float64 // ratio of compressive and tensile strengths (modified von Mises)
+	Typ  int     // type of equivalent strain: 0 = Mazars, 1 = modified von Mises
+	Ωmax float64 // maximum damage
+
+	// auxiliary
+	De   [][]float64 // elastic modulus
+	σe   []float64   // effective stress
+	dεeq []float64   // derivative of equivalent strain
+	ε3   []float64   // strain with εzz for plane-stress
+	λ    []float64   // eigenvalues [3]
+	P    [][]float64 // eigenprojectors [3][nsig]
+	ten  []float64   // auxiliary tensor
+}
+
+// add model to factory
+func init() {
+	allocators["dam"] = func() Model { return new(IsoDamage) }
+}
+
+// Init initialises model
+func (o *IsoDamage) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parse parameters
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	o.k, o.Ωmax = 10, 0.9999
+	var ft float64
+	for _, p := range prms {
+		switch p.N {
+		case "k0":
+			o.κ0 = p.V
+		case "ft":
+			ft = p.V
+		case "kf":
+			o.κf = p.V
+		case "Gf":
+			o.Gf = p.V
+		case "k":
+			o.k = p.V
+		case "typ":
+			o.Typ = int(p.V)
+		case "wmax":
+			o.Ωmax = p.V
+		case "E", "nu", "l", "G", "K", "rho":
+		default:
+			return chk.Err("dam: parameter named %q is incorrect\n", p.N)
+		}
+	}
+
+	// check parameters
+	if ft > 0 {
+		o.κ0 = ft / o.E
+	}
+	if o.κ0 <= 0 {
+		return chk.Err("dam: damage threshold k0 (or ft) must be positive. k0=%g is incorrect\n", o.κ0)
+	}
+	if o.Gf <= 0 && o.κf <= o.κ0 {
+		return chk.Err("dam: kf must be greater than k0 if Gf is not given. kf=%g and k0=%g are incorrect\n", o.κf, o.κ0)
+	}
+	if o.Typ < 0 || o.Typ > 1 {
+		return chk.Err("dam: typ must be 0 (Mazars) or 1 (modified von Mises). typ=%d is incorrect\n", o.Typ)
+	}
+
+	// auxiliary structures
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	o.σe = make([]float64, o.Nsig)
+	o.dεeq = make([]float64, o.Nsig)
+	o.ε3 = make([]float64, o.Nsig)
+	o.λ = make([]float64, 3)
+	o.P = tsr.M_AllocEigenprojs(o.Nsig)
+	o.ten = make([]float64, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o IsoDamage) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 30000},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "ft", V: 3},
+		&fun.Prm{N: "Gf", V: 0.1},
+		&fun.Prm{N: "typ", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Alp = {κ, ω}
+//  Phi = {κf, εe}
+func (o IsoDamage) InitIntVars() (s *State, err error) {
+	s = NewState(o.Nsig, 2, 1+o.Nsig, false)
+	s.Alp[0] = o.κ0
+	s.Phi[0] = o.κf
+	return
+}
+
+// SetLength sets the characteristic length of the element (crack-band approach)
+func (o IsoDamage) SetLength(s *State, h float64) (err error) {
+	if o.Gf <= 0 {
+		return
+	}
+	if h <= 0 {
+		return chk.Err("dam: characteristic length must be positive. h=%g is incorrect\n", h)
+	}
+	ft := o.E * o.κ0
+	s.Phi[0] = o.Gf/(h*ft) + o.κ0/2.0
+	if s.Phi[0] <= o.κ0 {
+		return chk.Err("dam: element is too large for the crack-band approach (snap-back). h=%g must be smaller than %g\n", h, 2.0*o.Gf/(ft*o.κ0))
+	}
+	return
+}
+
+// Update updates stresses for given strains
+//  Note: while the material is undamaged, the previous elastic strains are computed from the
+//        stresses; thus initial stresses set after InitIntVars are taken into account
+func (o *IsoDamage) Update(s *State, ε, Δε []float64) (err error) {
+
+	// check κf
+	if s.Phi[0] <= o.κ0 {
+		return chk.Err("dam: kf=%g must be greater than k0=%g. SetLength must be called if Gf is given\n", s.Phi[0], o.κ0)
+	}
+
+	// elastic strains
+	s.Loading = false
+	εe := s.Phi[1:]
+	if s.Alp[1] == 0 {
+		o.elastic_strains(εe, s.Sig)
+	}
+	for i := 0; i < o.Nsig; i++ {
+		εe[i] += Δε[i]
+	}
+
+	// history variable
+	εeq := o.eqstrain(εe, false)
+	if εeq > s.Alp[0] {
+		s.Alp[0] = εeq
+		s.Loading = true
+	}
+	s.Alp[1] = o.damage(s.Alp[0], s.Phi[0])
+
+	// stresses
+	err = o.SmallElasticity.CalcD(o.De, s)
+	if err != nil {
+		return
+	}
+	la.MatVecMul(s.Sig, 1.0-s.Alp[1], o.De, εe)
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *IsoDamage) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// secant modulus
+	err = o.SmallElasticity.CalcD(o.De, s)
+	if err != nil {
+		return
+	}
+	ω := s.Alp[1]
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] = (1.0 - ω) * o.De[i][j]
+		}
+	}
+
+	// loading => D = (1 - ω) De - dωdκ σe ⊗ dεeq/dε
+	if !s.Loading || ω >= o.Ωmax {
+		return
+	}
+	εe := s.Phi[1:]
+	o.eqstrain(εe, true)
+	dωdκ := o.dωdκ(s.Alp[0], s.Phi[0])
+	la.MatVecMul(o.σe, 1, o.De, εe)
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			D[i][j] -= dωdκ * o.σe[i] * o.dεeq[j]
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *IsoDamage) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// elastic_strains computes εe = Ce : σ (also valid for plane-stress since σzz = 0)
+func (o IsoDamage) elastic_strains(εe, σ []float64) {
+	trσ := σ[0] + σ[1] + σ[2]
+	for i := 0; i < o.Nsig; i++ {
+		εe[i] = (σ[i]-trσ*tsr.Im[i]/3.0)/(2.0*o.G) + trσ*tsr.Im[i]/(9.0*o.K)
+	}
+}
+
+// damage computes ω(κ)
+func (o IsoDamage) damage(κ, κf float64) (ω float64) {
+	if κ <= o.κ0 {
+		return 0
+	}
+	ω = 1.0 - (o.κ0/κ)*math.Exp(-(κ-o.κ0)/(κf-o.κ0))
+	return math.Min(ω, o.Ωmax)
+}
+
+// dωdκ computes the derivative of ω w.r.t κ
+func (o IsoDamage) dωdκ(κ, κf float64) float64 {
+	if κ <= o.κ0 || o.damage(κ, κf) >= o.Ωmax {
+		return 0
+	}
+	e := math.Exp(-(κ - o.κ0) / (κf - o.κ0))
+	return (o.κ0 / κ) * e * (1.0/κ + 1.0/(κf-o.κ0))
+}
+
+// eqstrain computes the equivalent strain and, if derivs == true, its derivative w.r.t ε (in dεeq)
+func (o *IsoDamage) eqstrain(ε []float64, derivs bool) (εeq float64) {
+
+	// strain with εzz for plane-stress
+	copy(o.ε3, ε)
+	czz := 0.0
+	if o.Pse {
+		czz = -o.Nu / (1.0 - o.Nu)
+		o.ε3[2] = czz * (ε[0] + ε[1])
+	}
+
+	// equivalent strain
+	switch o.Typ {
+
+	// Mazars
+	case 0:
+		err := tsr.M_EigenValsProjsNum(o.P, o.λ, o.ε3)
+		if err != nil {
+			chk.Panic("dam: cannot compute eigenvalues of strain tensor:\n%v", err)
+		}
+		for k := 0; k < 3; k++ {
+			if o.λ[k] > 0 {
+				εeq += o.λ[k] * o.λ[k]
+			}
+		}
+		εeq = math.Sqrt(εeq)
+		if derivs {
+			la.VecFill(o.dεeq, 0)
+			if εeq > 0 {
+				for k := 0; k < 3; k++ {
+					if o.λ[k] > 0 {
+						for i := 0; i < o.Nsig; i++ {
+							o.dεeq[i] += o.λ[k] * o.P[k][i] / εeq
+						}
+					}
+				}
+			}
+		}
+
+	// modified von Mises
+	case 1:
+		ν := o.Nu
+		a := (o.k - 1.0) / (2.0 * o.k * (1.0 - 2.0*ν))
+		c := 3.0 / (o.k * (1.0 + ν) * (1.0 + ν))
+		I1 := o.ε3[0] + o.ε3[1] + o.ε3[2]
+		for i := 0; i < o.Nsig; i++ {
+			o.ten[i] = o.ε3[i] - I1*tsr.Im[i]/3.0 // ten := dev(ε)
+		}
+		J2 := la.VecDot(o.ten, o.ten) / 2.0
+		r := math.Sqrt(a*a*I1*I1 + c*J2)
+		εeq = a*I1 + r
+		if derivs {
+			for i := 0; i < o.Nsig; i++ {
+				o.dεeq[i] = a * tsr.Im[i]
+				if r > 0 {
+					o.dεeq[i] += (a*a*I1*tsr.Im[i] + c*o.ten[i]/2.0) / r
+				}
+			}
+		}
+	}
+
+	// plane-stress: chain rule with εzz = czz (εxx + εyy)
+	if derivs && o.Pse {
+		o.dεeq[0] += czz * o.dεeq[2]
+		o.dεeq[1] += czz * o.dεeq[2]
+		o.dεeq[2] = 0
+	}
+	return
+}
@@ -34,6 +34,9 @@ func (o *DruckerPrager) Init(ndim int, pstress bool, prms fun.Prms) (err error)
 	if err != nil {
 		return
 	}
+	if pstress {
+		return chk.Err("dp: plane-stress analyses are not available\n")
+	}
 	for _, p := range prms {
 		switch p.N {
 		case "M":
@@ -5,6 +5,8 @@
 package msolid
 
 import (
+	"math"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
@@ -26,6 +28,9 @@ type Driver struct {
 	TolD    float64 // tolerance to check consistent matrix
 	VerD    bool    // verbose check of D
 	WithPC  bool    // with predictor-corrector data
+	Dt      float64 // time increment of each strain/stress increment (for rate-dependent models)
+	TolS    float64 // tolerance for stress-controlled increments with small-strain models
+	NmaxItS int     // max number of iterations for stress-controlled increments with small-strain models
 
 	// results
 	Res []*State    // stress/ivs results
@@ -34,6 +39,11 @@ type Driver struct {
 	// for checking consistent matrix
 	D [][]float64 // consistent matrix
 
+	// for stress-controlled increments with small-strain models
+	σtgt []float64   // target stress
+	Δεs  []float64   // strain increment
+	Di   [][]float64 // inverse of consistent matrix
+
 	// for predictor-corrector plots
 	precor []*State // predictor-corrector states
 }
@@ -51,7 +61,13 @@ func (o *Driver) Init(simfnk, modelname string, ndim int, pstress bool, prms fun
 		return
 	}
 	o.D = la.MatAlloc(o.nsig, o.nsig)
+	o.σtgt = make([]float64, o.nsig)
+	o.Δεs = make([]float64, o.nsig)
+	o.Di = la.MatAlloc(o.nsig, o.nsig)
 	o.TolD = 1e-8
+	o.TolS = 1e-10
+	o.NmaxItS = 20
+	o.Dt = 1
 	o.VerD = chk.Verbose
 	return
 }
@@ -71,6 +87,11 @@ func (o *Driver) Run(pth *Path) (err error) {
 		return chk.Err("cannot handle large-deformation models yet\n")
 	}
 
+	// rate-dependent models
+	if m, ok := o.model.(RateDependent); ok {
+		m.SetDt(o.Dt)
+	}
+
 	// allocate results arrays
 	nr := 1 + (pth.Size()-1)*pth.Nincs
 	if nr < 2 {
@@ -128,6 +149,8 @@ func (o *Driver) Run(pth *Path) (err error) {
 				copy(o.Eps[k], o.Eps[k-1])
 				if eup != nil {
 					err = eup.StrainUpdate(o.Res[k], Δσ)
+				} else {
+					err = o.stress_update(sml, k, Δσ)
 				}
 				if err != nil {
 					if !o.Silent {
@@ -208,10 +231,58 @@ func (o *Driver) Run(pth *Path) (err error) {
 	return
 }
 
+// stress_update finds the strains of small-strain models corresponding to the stress increment
+// Δσ using Newton's method:  Δε := Δε + inv(D)・(σold + Δσ - σ(εold + Δε))
+func (o *Driver) stress_update(sml Small, k int, Δσ []float64) (err error) {
+	for i := 0; i < o.nsig; i++ {
+		o.σtgt[i] = o.Res[k-1].Sig[i] + Δσ[i]
+		o.Δεs[i] = 0
+	}
+	tol := o.TolS * (1.0 + la.VecNorm(o.σtgt))
+	var r float64
+	for it := 0; it < o.NmaxItS; it++ {
+
+		// update stresses
+		la.VecAdd2(o.Eps[k], 1, o.Eps[k-1], 1, o.Δεs) // εnew = εold + Δε
+		o.Res[k].Set(o.Res[k-1])
+		err = sml.Update(o.Res[k], o.Eps[k], o.Δεs)
+		if err != nil {
+			return
+		}
+
+		// check residual
+		r = 0
+		for i := 0; i < o.nsig; i++ {
+			r = math.Max(r, math.Abs(o.σtgt[i]-o.Res[k].Sig[i]))
+		}
+		if r < tol {
+			return
+		}
+
+		// correct strain increment
+		firstIt := false
+		err = sml.CalcD(o.D, o.Res[k], firstIt)
+		if err != nil {
+			return
+		}
+		err = la.MatInvG(o.Di, o.D, 1e-10)
+		if err != nil {
+			return
+		}
+		for i := 0; i < o.nsig; i++ {
+			for j := 0; j < o.nsig; j++ {
+				o.Δεs[i] += o.Di[i][j] * (o.σtgt[j] - o.Res[k].Sig[j])
+			}
+		}
+	}
+	return chk.Err(_driver_err05, o.NmaxItS, r)
+}
+
 // error messages
 var (
 	_driver_err01 = "strain update failed\n%v\n"
 	_driver_err02 = "stress update failed\n%v\n"
 	_driver_err03 = "check of consistent matrix failed:\n %v\n\n"
 	_driver_err04 = "size of path is incorrect. Size=%d, Nincs=%d\n"
+	_driver_err05 = "stress-controlled increment did not converge after %d iterations. residual = %g\n"
 )
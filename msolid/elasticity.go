@@ -5,6 +5,8 @@
 package msolid
 
 import (
+	"math"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
@@ -16,6 +18,7 @@ import (
 type KGcalculator interface {
 	Init(prms fun.Prms) (err error)
 	Calc(s *State) (K, G float64)
+	Derivs(dKdσ, dGdσ []float64, s *State) // derivatives of K and G w.r.t σ
 }
 
 // kgcfactory holds KG calculators
@@ -38,18 +41,36 @@ func GetKgc(name string, prms fun.Prms) KGcalculator {
 
 // SmallElasticity implements linear/non-linear elasticity for small strain analyses
 type SmallElasticity struct {
-	Nsig  int          // number of stress components
-	E, Nu float64      // Young modulus and Poisson coefficient
-	L, G  float64      // Lame's coefficients. L == λ, G == μ
-	K     float64      // Bulk modulus
-	Pse   bool         // is plane-stress?
-	Kgc   KGcalculator // K and G calculator for non-linear models
+	Nsig   int          // number of stress components
+	E, Nu  float64      // Young modulus and Poisson coefficient
+	L, G   float64      // Lame's coefficients. L == λ, G == μ
+	K      float64      // Bulk modulus
+	Pse    bool         // is plane-stress?
+	Kgc    KGcalculator // K and G calculator for non-linear models
+	NmaxIt int          // max number of iterations for updating stresses with non-linear K and G
+	Iphi   int          // index of Δε in Phi for computing the consistent D with non-linear K and G; -1 => not saved
+	Nonlin bool         // accepts non-linear K and G ("!kgc:"); must be set by the embedding model before Init
+
+	// workspace for non-linear K and G
+	wσ0  []float64   // [nsig] initial stresses
+	wr   []float64   // [nsig] residual
+	wδσ  []float64   // [nsig] stress correction
+	wdev []float64   // [nsig] deviatoric strain increment
+	wdK  []float64   // [nsig] dK/dσ
+	wdG  []float64   // [nsig] dG/dσ
+	wJ   [][]float64 // [nsig][nsig] Jacobian
+	wJi  [][]float64 // [nsig][nsig] inverse of Jacobian
+	wDe  [][]float64 // [nsig][nsig] elastic modulus
 }
 
 // Init initialises this structure
+//  Note: non-linear K and G given by "!kgc:" are only accepted if Nonlin is true; then the
+//        combination of elastic constants is optional
 func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
 	o.Nsig = 2 * ndim
 	o.Pse = pstress
+	o.NmaxIt = 20
+	o.Iphi = -1
 	var has_E, has_ν, has_l, has_G, has_K bool
 	for _, p := range prms {
 		switch p.N {
@@ -65,16 +86,26 @@ func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error
 			o.K, has_K = p.V, true
 		}
 		if skgc, found := io.Keycode(p.Extra, "kgc"); found {
+			if !o.Nonlin {
+				return chk.Err("non-linear K and G (kgc=%q) are not available in this model\n", skgc)
+			}
 			o.Kgc = GetKgc(skgc, prms)
 			if o.Kgc == nil {
-				return chk.Err("cannot find kgc model named %s", skgc)
-			}
-			err = o.Kgc.Init(prms)
-			if err != nil {
-				return
+				return chk.Err("cannot find kgc model named %q or its parameters are incorrect\n", skgc)
 			}
 		}
 	}
+	if o.Kgc != nil {
+		o.wσ0 = make([]float64, o.Nsig)
+		o.wr = make([]float64, o.Nsig)
+		o.wδσ = make([]float64, o.Nsig)
+		o.wdev = make([]float64, o.Nsig)
+		o.wdK = make([]float64, o.Nsig)
+		o.wdG = make([]float64, o.Nsig)
+		o.wJ = la.MatAlloc(o.Nsig, o.Nsig)
+		o.wJi = la.MatAlloc(o.Nsig, o.Nsig)
+		o.wDe = la.MatAlloc(o.Nsig, o.Nsig)
+	}
 	switch {
 	case has_E && has_ν:
 		o.L = Calc_l_from_Enu(o.E, o.Nu)
@@ -93,7 +124,9 @@ func (o *SmallElasticity) Init(ndim int, pstress bool, prms fun.Prms) (err error
 		o.G = Calc_G_from_Knu(o.K, o.Nu)
 		o.L = Calc_l_from_Knu(o.K, o.Nu)
 	default:
-		return chk.Err("combination of Elastic constants is incorrect. options are {E,nu}, {l,G}, {K,G} and {K,nu}\n")
+		if o.Kgc == nil {
+			return chk.Err("combination of Elastic constants is incorrect. options are {E,nu}, {l,G}, {K,G} and {K,nu}\n")
+		}
 	}
 	return
 }
@@ -107,9 +140,15 @@ func (o SmallElasticity) GetPrms() fun.Prms {
 }
 
 // Update computes new stresses for new strain increment Δε
+//  Note: with non-linear K and G, the update is implicit: σ = σ0 + K(σ) tr(Δε) I + 2 G(σ) dev(Δε)
+//        and Δε is saved in s.Phi[Iphi:Iphi+nsig] (if Iphi >= 0) for computing the consistent
+//        modulus. The model embedding SmallElasticity must set Iphi and allocate Phi accordingly
 func (o SmallElasticity) Update(s *State, Δε []float64) (err error) {
 	σ := s.Sig
 	if o.Pse {
+		if o.Kgc != nil {
+			return chk.Err("plane-stress analysis does not work with nonlinear K and G\n")
+		}
 		c := o.E / (1.0 - o.Nu*o.Nu)
 		σ[0] += c * (Δε[0] + o.Nu*Δε[1])
 		σ[1] += c * (o.Nu*Δε[0] + Δε[1])
@@ -117,6 +156,9 @@ func (o SmallElasticity) Update(s *State, Δε []float64) (err error) {
 		σ[3] += c * (1.0 - o.Nu) * Δε[3]
 		return
 	}
+	if o.Kgc != nil {
+		return o.nonlinUpdate(s, Δε)
+	}
 	trΔε := Δε[0] + Δε[1] + Δε[2]
 	for i := 0; i < o.Nsig; i++ {
 		σ[i] += o.L*trΔε*tsr.Im[i] + 2.0*o.G*Δε[i]
@@ -150,9 +192,95 @@ func (o SmallElasticity) CalcD(D [][]float64, s *State) (err error) {
 			D[i][j] = o.K*tsr.Im[i]*tsr.Im[j] + 2*o.G*tsr.Psd[i][j]
 		}
 	}
+	if o.Kgc != nil && o.Iphi >= 0 {
+		return o.nonlinCalcD(D, s)
+	}
 	return
 }
 
+// non-linear elasticity ////////////////////////////////////////////////////////////////////////////
+
+// nonlinUpdate updates stresses using Newton's method to solve:
+//  r(σ) = σ - σ0 - K(σ) tr(Δε) I - 2 G(σ) dev(Δε) = 0
+func (o SmallElasticity) nonlinUpdate(s *State, Δε []float64) (err error) {
+
+	// auxiliary
+	σ := s.Sig
+	σ0, r, δσ, J, Ji := o.wσ0, o.wr, o.wδσ, o.wJ, o.wJi
+	copy(σ0, σ)
+	if o.Iphi >= 0 {
+		copy(s.Phi[o.Iphi:o.Iphi+o.Nsig], Δε)
+	}
+
+	// deviatoric strain increment
+	trΔε := Δε[0] + Δε[1] + Δε[2]
+	devΔε := o.wdev
+	for i := 0; i < o.Nsig; i++ {
+		devΔε[i] = Δε[i] - trΔε*tsr.Im[i]/3.0
+	}
+
+	// explicit predictor
+	K, G := o.Kgc.Calc(s)
+	for i := 0; i < o.Nsig; i++ {
+		σ[i] = σ0[i] + K*trΔε*tsr.Im[i] + 2.0*G*devΔε[i]
+	}
+
+	// Newton iterations
+	var rnorm float64
+	tol := 1e-12 * (1.0 + la.VecNorm(σ0) + la.VecNorm(σ))
+	for it := 0; it < o.NmaxIt; it++ {
+		K, G = o.Kgc.Calc(s)
+		rnorm = 0
+		for i := 0; i < o.Nsig; i++ {
+			r[i] = σ[i] - σ0[i] - K*trΔε*tsr.Im[i] - 2.0*G*devΔε[i]
+			rnorm += r[i] * r[i]
+		}
+		if math.Sqrt(rnorm) < tol {
+			return
+		}
+		o.nonlinJacobian(J, s, trΔε, devΔε)
+		err = la.MatInvG(Ji, J, 1e-13)
+		if err != nil {
+			return
+		}
+		la.MatVecMul(δσ, 1, Ji, r)
+		for i := 0; i < o.Nsig; i++ {
+			σ[i] -= δσ[i]
+		}
+	}
+	return chk.Err("non-linear elasticity: Newton's method did not converge after %d iterations. |r| = %g\n", o.NmaxIt, math.Sqrt(rnorm))
+}
+
+// nonlinCalcD computes the consistent modulus D := J⁻¹・De where J = dr/dσ and De is given in D
+func (o SmallElasticity) nonlinCalcD(D [][]float64, s *State) (err error) {
+	Δε := s.Phi[o.Iphi : o.Iphi+o.Nsig]
+	trΔε := Δε[0] + Δε[1] + Δε[2]
+	devΔε := o.wdev
+	for i := 0; i < o.Nsig; i++ {
+		devΔε[i] = Δε[i] - trΔε*tsr.Im[i]/3.0
+	}
+	o.nonlinJacobian(o.wJ, s, trΔε, devΔε)
+	err = la.MatInvG(o.wJi, o.wJ, 1e-13)
+	if err != nil {
+		return
+	}
+	la.MatCopy(o.wDe, 1, D)
+	la.MatMul(D, 1, o.wJi, o.wDe)
+	return
+}
+
+// nonlinJacobian computes J = dr/dσ = I - tr(Δε) I ⊗ dK/dσ - 2 dev(Δε) ⊗ dG/dσ
+func (o SmallElasticity) nonlinJacobian(J [][]float64, s *State, trΔε float64, devΔε []float64) {
+	dKdσ, dGdσ := o.wdK, o.wdG
+	o.Kgc.Derivs(dKdσ, dGdσ, s)
+	for i := 0; i < o.Nsig; i++ {
+		for j := 0; j < o.Nsig; j++ {
+			J[i][j] = -trΔε*tsr.Im[i]*dKdσ[j] - 2.0*devΔε[i]*dGdσ[j]
+		}
+		J[i][i] += 1
+	}
+}
+
 // converters ///////////////////////////////////////////////////////////////////////////////////////
 
 // -- E, ν -----------------------------------------------------
@@ -0,0 +1,258 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// HyperElast implements the common structure of compressible (isotropic) hyperelastic models
+// with decoupled strain energy functions written in terms of the invariants of b̄ = J^(-2/3) b:
+//  W = W1 (Ī1 - 3) + W2 (Ī2 - 3) + U(J)
+//  τ = J p I + dev(τ̄)   with   τ̄ = 2 (W1 + Ī1 W2) b̄ - 2 W2 b̄²   and   p = dU/dJ
+// where b = F・trans(F) is the left Cauchy-Green deformation tensor and τ is the Kirchhoff stress.
+// The volumetric penalty U(J) is selected with "vol":
+//  vol = 0: U = (K/2) (J - 1)²
+//  vol = 1: U = (K/2) (ln J)²
+//  vol = 2: U = (K/4) (J² - 1 - 2 ln J)
+//  Note: this structure is embedded by NeoHookean and MooneyRivlin
+type HyperElast struct {
+
+	// basic data
+	Nsig int // number of stress components
+
+	// parameters
+	W1  float64 // derivative of W w.r.t Ī1
+	W2  float64 // derivative of W w.r.t Ī2
+	K   float64 // bulk modulus
+	Vol int     // type of volumetric penalty
+
+	// auxiliary
+	J    float64     // det(F)
+	Fi   [][]float64 // inverse of F [3][3]
+	b    [][]float64 // left Cauchy-Green deformation [3][3]
+	bb   [][]float64 // b̄ [3][3]
+	bb2  [][]float64 // b̄² [3][3]
+	τ    [][]float64 // Kirchhoff stress [3][3]
+	δb   [][]float64 // increment of b [3][3]
+	δbb  [][]float64 // increment of b̄ [3][3]
+	δbb2 [][]float64 // increment of b̄² [3][3]
+	δτ   [][]float64 // increment of Kirchhoff stress [3][3]
+	σ    [][]float64 // Cauchy stress [3][3]
+}
+
+// init initialises auxiliary structures and checks parameters
+func (o *HyperElast) init(ndim int, pstress bool) (err error) {
+	if pstress {
+		return chk.Err("hyperelastic models do not work with plane-stress\n")
+	}
+	if o.K <= 0 {
+		return chk.Err("bulk modulus K must be positive. K=%g is incorrect\n", o.K)
+	}
+	if o.Vol < 0 || o.Vol > 2 {
+		return chk.Err("vol must be 0, 1 or 2. vol=%d is incorrect\n", o.Vol)
+	}
+	o.Nsig = 2 * ndim
+	o.Fi = tsr.Alloc2()
+	o.b = tsr.Alloc2()
+	o.bb = tsr.Alloc2()
+	o.bb2 = tsr.Alloc2()
+	o.τ = tsr.Alloc2()
+	o.δb = tsr.Alloc2()
+	o.δbb = tsr.Alloc2()
+	o.δbb2 = tsr.Alloc2()
+	o.δτ = tsr.Alloc2()
+	o.σ = tsr.Alloc2()
+	return
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o HyperElast) InitIntVars() (s *State, err error) {
+	s = NewState(o.Nsig, 0, 0, true)
+	for i := 0; i < 3; i++ {
+		s.F[i][i] = 1
+	}
+	return
+}
+
+// Update updates stresses for given deformation gradient
+func (o *HyperElast) Update(s *State, F, FΔ [][]float64) (err error) {
+
+	// Kirchhoff stress
+	la.MatCopy(s.F, 1, F)
+	err = o.kirchhoff(F)
+	if err != nil {
+		return
+	}
+
+	// Cauchy stress
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.σ[i][j] = o.τ[i][j] / o.J
+		}
+	}
+	tsr.Ten2Man(s.Sig, o.σ)
+	return
+}
+
+// CalcA computes tangent modulus A = (2/J) * ∂τ/∂b . b - σ palm I
+//  Note: the derivative ∂τ/∂b . b is computed from the directional derivatives of τ along
+//        δb = e_k ⊗ (b・e_l) + (b・e_l) ⊗ e_k, for each pair k,l
+func (o *HyperElast) CalcA(A [][][][]float64, s *State, firstIt bool) (err error) {
+
+	// Kirchhoff stress
+	err = o.kirchhoff(s.F)
+	if err != nil {
+		return
+	}
+	p := o.dUdJ(o.J)
+	dpdJ := o.dUdJ2(o.J)
+	c := math.Pow(o.J, -2.0/3.0)
+	I1 := o.bb[0][0] + o.bb[1][1] + o.bb[2][2]
+
+	// for each direction
+	var δJ, δI1 float64
+	for k := 0; k < 3; k++ {
+		for l := 0; l < 3; l++ {
+
+			// δb and δJ = J δkl
+			δJ = 0
+			if k == l {
+				δJ = o.J
+			}
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					o.δb[i][j] = 0
+					if i == k {
+						o.δb[i][j] += o.b[j][l]
+					}
+					if j == k {
+						o.δb[i][j] += o.b[i][l]
+					}
+				}
+			}
+
+			// δb̄ = J^(-2/3) δb - (2/3) δkl b̄ and δĪ1
+			δI1 = 0
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					o.δbb[i][j] = c * o.δb[i][j]
+					if k == l {
+						o.δbb[i][j] -= 2.0 * o.bb[i][j] / 3.0
+					}
+				}
+				δI1 += o.δbb[i][i]
+			}
+
+			// δ(b̄²)
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					o.δbb2[i][j] = 0
+					for m := 0; m < 3; m++ {
+						o.δbb2[i][j] += o.δbb[i][m]*o.bb[m][j] + o.bb[i][m]*o.δbb[m][j]
+					}
+				}
+			}
+
+			// δτ̄
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					o.δτ[i][j] = 2.0*o.W2*δI1*o.bb[i][j] + 2.0*(o.W1+I1*o.W2)*o.δbb[i][j] - 2.0*o.W2*o.δbb2[i][j]
+				}
+			}
+
+			// δτ = (p + J dp/dJ) δJ I + dev(δτ̄)
+			tr := (o.δτ[0][0] + o.δτ[1][1] + o.δτ[2][2]) / 3.0
+			for i := 0; i < 3; i++ {
+				o.δτ[i][i] += (p+o.J*dpdJ)*δJ - tr
+			}
+
+			// A_ijkl = δτ_ij / J - σ_il δ_jk
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					A[i][j][k][l] = o.δτ[i][j] / o.J
+					if j == k {
+						A[i][j][k][l] -= o.τ[i][l] / o.J
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// kirchhoff computes J, b, b̄, b̄² and the Kirchhoff stress τ
+func (o *HyperElast) kirchhoff(F [][]float64) (err error) {
+
+	// determinant of F
+	o.J, err = tsr.Inv(o.Fi, F)
+	if err != nil {
+		return
+	}
+	if o.J <= 0 {
+		return chk.Err("determinant of deformation gradient must be positive. J=%g is incorrect\n", o.J)
+	}
+
+	// left Cauchy-Green tensor and isochoric part
+	tsr.LeftCauchyGreenDef(o.b, F)
+	c := math.Pow(o.J, -2.0/3.0)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.bb[i][j] = c * o.b[i][j]
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.bb2[i][j] = 0
+			for m := 0; m < 3; m++ {
+				o.bb2[i][j] += o.bb[i][m] * o.bb[m][j]
+			}
+		}
+	}
+
+	// τ̄
+	I1 := o.bb[0][0] + o.bb[1][1] + o.bb[2][2]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.τ[i][j] = 2.0*(o.W1+I1*o.W2)*o.bb[i][j] - 2.0*o.W2*o.bb2[i][j]
+		}
+	}
+
+	// τ = J p I + dev(τ̄)
+	tr := (o.τ[0][0] + o.τ[1][1] + o.τ[2][2]) / 3.0
+	p := o.dUdJ(o.J)
+	for i := 0; i < 3; i++ {
+		o.τ[i][i] += o.J*p - tr
+	}
+	return
+}
+
+// dUdJ computes the derivative of the volumetric penalty: p = dU/dJ
+func (o HyperElast) dUdJ(J float64) float64 {
+	switch o.Vol {
+	case 1:
+		return o.K * math.Log(J) / J
+	case 2:
+		return o.K * (J - 1.0/J) / 2.0
+	}
+	return o.K * (J - 1.0)
+}
+
+// dUdJ2 computes the second derivative of the volumetric penalty: d²U/dJ²
+func (o HyperElast) dUdJ2(J float64) float64 {
+	switch o.Vol {
+	case 1:
+		return o.K * (1.0 - math.Log(J)) / (J * J)
+	case 2:
+		return o.K * (1.0 + 1.0/(J*J)) / 2.0
+	}
+	return o.K
+}
@@ -0,0 +1,263 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// InterfaceM1 implements a Mohr-Coulomb model with tension cut-off for zero-thickness
+// interface elements (joints, faults, soil-structure contact)
+//  Note: 1) the state is given in the local system of the interface: Sig = {tn, ts1, ts2}
+//           are the normal (tension is positive) and shear tractions; Phi = {wn, ws1, ws2}
+//           are the total opening and slip displacements; Alp = {ωpn, ωps} are the
+//           accumulated plastic opening and plastic slip
+//        2) yield functions:
+//            f1 = |ts| + tn tan(φ) - c   (shear)
+//            f2 = tn - σt                 (tension cut-off)
+//           with non-associated flow in shear (dilatancy angle ψ) and perfect plasticity
+//        3) after a return to the tension cut-off (alone or at the corner with f1),
+//           ApexReturn is set to true and Dgam holds the increment of the shear multiplier
+type InterfaceM1 struct {
+
+	// parameters
+	kn float64 // normal stiffness
+	ks float64 // shear stiffness
+	c  float64 // cohesion
+	φ  float64 // friction angle [deg]
+	ψ  float64 // dilatancy angle [deg]
+	σt float64 // tension cut-off (tensile strength)
+
+	// derived
+	Ndim int     // space dimension
+	tφ   float64 // tan(φ)
+	tψ   float64 // tan(ψ)
+
+	// auxiliary
+	ttr []float64 // trial tractions [ndim]
+}
+
+// Init initialises model
+func (o *InterfaceM1) Init(ndim int, prms fun.Prms) (err error) {
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "kn":
+			o.kn = p.V
+		case "ks":
+			o.ks = p.V
+		case "c":
+			o.c = p.V
+		case "phi":
+			o.φ = p.V
+		case "psi":
+			o.ψ = p.V
+		case "sigt":
+			o.σt = p.V
+		}
+	}
+
+	// check parameters
+	if o.kn <= 0 || o.ks <= 0 {
+		return chk.Err("interface-m1: stiffness parameters must be positive. kn=%g and ks=%g are incorrect\n", o.kn, o.ks)
+	}
+	if o.φ < 0 || o.φ >= 90 || o.ψ < 0 || o.ψ > o.φ {
+		return chk.Err("interface-m1: friction and dilatancy angles must satisfy 0 ≤ psi ≤ phi < 90. phi=%g and psi=%g are incorrect\n", o.φ, o.ψ)
+	}
+	if o.c < 0 || o.σt < 0 {
+		return chk.Err("interface-m1: cohesion and tension cut-off must be non-negative. c=%g and sigt=%g are incorrect\n", o.c, o.σt)
+	}
+	o.tφ = math.Tan(o.φ * math.Pi / 180.0)
+	o.tψ = math.Tan(o.ψ * math.Pi / 180.0)
+	if o.σt*o.tφ > o.c {
+		return chk.Err("interface-m1: tension cut-off must not be greater than the apex stress c/tan(phi) = %g. sigt=%g is incorrect\n", o.c/o.tφ, o.σt)
+	}
+
+	// auxiliary
+	o.Ndim = ndim
+	o.ttr = make([]float64, ndim)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o InterfaceM1) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "kn", V: 1e5},
+		&fun.Prm{N: "ks", V: 1e4},
+		&fun.Prm{N: "c", V: 10},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 0},
+		&fun.Prm{N: "sigt", V: 5},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o InterfaceM1) InitIntVars() (s *State, err error) {
+	s = NewState(o.Ndim, 2, o.Ndim, false) // 2:{ωpn,ωps}
+	return
+}
+
+// Update updates tractions for given increment of relative displacements Δw = {Δwn, Δws1, Δws2}
+func (o *InterfaceM1) Update(s *State, Δw []float64) (err error) {
+
+	// total relative displacements
+	for i := 0; i < o.Ndim; i++ {
+		s.Phi[i] += Δw[i]
+	}
+
+	// trial tractions
+	o.ttr[0] = s.Sig[0] + o.kn*Δw[0]
+	τtr := 0.0
+	for i := 1; i < o.Ndim; i++ {
+		o.ttr[i] = s.Sig[i] + o.ks*Δw[i]
+		τtr += o.ttr[i] * o.ttr[i]
+	}
+	τtr = math.Sqrt(τtr)
+	tntr := o.ttr[0]
+
+	// trial yield functions
+	f1 := τtr + tntr*o.tφ - o.c
+	f2 := tntr - o.σt
+
+	// elastic update
+	copy(s.Sig, o.ttr)
+	s.Dgam = 0
+	s.Loading = false
+	s.ApexReturn = false
+	if f1 <= 0 && f2 <= 0 {
+		return
+	}
+	s.Loading = true
+
+	// return to shear surface
+	if f1 > 0 {
+		Δγ := f1 / (o.ks + o.kn*o.tφ*o.tψ)
+		tn := tntr - o.kn*o.tψ*Δγ
+		if tn <= o.σt {
+			s.Sig[0] = tn
+			o.scale_shear(s, τtr-o.ks*Δγ, τtr)
+			s.Alp[0] += o.tψ * Δγ
+			s.Alp[1] += Δγ
+			s.Dgam = Δγ
+			return
+		}
+	}
+
+	// return to tension cut-off
+	s.ApexReturn = true
+	s.Sig[0] = o.σt
+	if τtr+o.σt*o.tφ-o.c <= 0 {
+		s.Alp[0] += f2 / o.kn
+		return
+	}
+
+	// return to corner
+	τc := o.c - o.σt*o.tφ
+	Δγ1 := (τtr - τc) / o.ks
+	Δγ2 := f2/o.kn - o.tψ*Δγ1
+	if Δγ2 < 0 {
+		return chk.Err("interface-m1: return to corner failed: Δγ2 = %g < 0\n", Δγ2)
+	}
+	o.scale_shear(s, τc, τtr)
+	s.Alp[0] += o.tψ*Δγ1 + Δγ2
+	s.Alp[1] += Δγ1
+	s.Dgam = Δγ1
+	return
+}
+
+// CalcD computes D = dt_new/dw_new consistent with Update
+func (o *InterfaceM1) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// elastic
+	for i := 0; i < o.Ndim; i++ {
+		for j := 0; j < o.Ndim; j++ {
+			D[i][j] = 0
+		}
+	}
+	if !s.Loading {
+		D[0][0] = o.kn
+		for i := 1; i < o.Ndim; i++ {
+			D[i][i] = o.ks
+		}
+		return
+	}
+
+	// shear traction and direction
+	τ := 0.0
+	for i := 1; i < o.Ndim; i++ {
+		τ += s.Sig[i] * s.Sig[i]
+	}
+	τ = math.Sqrt(τ)
+	τtr := τ + o.ks*s.Dgam
+	var m []float64 // shear direction
+	if τ > 0 {
+		m = make([]float64, o.Ndim)
+		for i := 1; i < o.Ndim; i++ {
+			m[i] = s.Sig[i] / τ
+		}
+	}
+
+	// tension cut-off: tn is constant
+	if s.ApexReturn {
+
+		// cut-off alone: elastic shear
+		if s.Dgam == 0 {
+			for i := 1; i < o.Ndim; i++ {
+				D[i][i] = o.ks
+			}
+			return
+		}
+
+		// corner: |ts| is constant; only the direction of ts changes
+		if m != nil {
+			for i := 1; i < o.Ndim; i++ {
+				for j := 1; j < o.Ndim; j++ {
+					D[i][j] = o.ks * τ * (delta(i, j) - m[i]*m[j]) / τtr
+				}
+			}
+		}
+		return
+	}
+
+	// shear surface
+	H := o.ks + o.kn*o.tφ*o.tψ
+	D[0][0] = o.kn - o.kn*o.kn*o.tψ*o.tφ/H
+	if m == nil {
+		return
+	}
+	for i := 1; i < o.Ndim; i++ {
+		D[0][i] = -o.kn * o.tψ * o.ks * m[i] / H
+		D[i][0] = -o.ks * o.kn * o.tφ * m[i] / H
+		for j := 1; j < o.Ndim; j++ {
+			D[i][j] = o.ks*(1.0-o.ks/H)*m[i]*m[j] + o.ks*τ*(delta(i, j)-m[i]*m[j])/τtr
+		}
+	}
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// scale_shear sets the magnitude of the shear traction (from the trial one)
+func (o *InterfaceM1) scale_shear(s *State, τnew, τtr float64) {
+	for i := 1; i < o.Ndim; i++ {
+		if τtr > 0 {
+			s.Sig[i] = τnew * o.ttr[i] / τtr
+		} else {
+			s.Sig[i] = 0
+		}
+	}
+}
+
+// delta returns the Kronecker delta
+func delta(i, j int) float64 {
+	if i == j {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,313 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// add calculators to factory
+func init() {
+	kgcfactory["hardin"] = func() KGcalculator { return new(KgcHardin) }
+	kgcfactory["duncan-chang"] = func() KGcalculator { return new(KgcDuncanChang) }
+	kgcfactory["small-strain"] = func() KGcalculator { return new(KgcSmallStrain) }
+}
+
+// KgcHardin implements a Hardin-type pressure-dependent shear modulus:
+//  G = G0 (p/pref)^ng   with p = max(p, pmin)
+//  K = κ G              with κ = 2 (1 + ν) / (3 (1 - 2 ν))
+type KgcHardin struct {
+	G0   float64 // shear modulus at reference pressure
+	Pref float64 // reference pressure
+	Ng   float64 // exponent
+	Pmin float64 // minimum pressure
+	Nu   float64 // Poisson's coefficient
+	κ    float64 // K/G ratio
+}
+
+// Init initialises calculator
+func (o *KgcHardin) Init(prms fun.Prms) (err error) {
+	o.Pref, o.Ng, o.Nu = 100, 0.5, 0.25
+	for _, p := range prms {
+		switch p.N {
+		case "G0":
+			o.G0 = p.V
+		case "pref":
+			o.Pref = p.V
+		case "ng":
+			o.Ng = p.V
+		case "pmin":
+			o.Pmin = p.V
+		case "nu":
+			o.Nu = p.V
+		}
+	}
+	if o.G0 <= 0 || o.Pref <= 0 {
+		return chk.Err("hardin: G0 and pref must be positive. G0=%g and pref=%g are incorrect\n", o.G0, o.Pref)
+	}
+	if o.Pmin <= 0 {
+		o.Pmin = 1e-3 * o.Pref
+	}
+	o.κ = 2.0 * (1.0 + o.Nu) / (3.0 * (1.0 - 2.0*o.Nu))
+	return
+}
+
+// Calc computes K and G
+func (o KgcHardin) Calc(s *State) (K, G float64) {
+	G = o.G0 * math.Pow(math.Max(tsr.M_p(s.Sig), o.Pmin)/o.Pref, o.Ng)
+	K = o.κ * G
+	return
+}
+
+// Derivs computes the derivatives of K and G w.r.t σ
+func (o KgcHardin) Derivs(dKdσ, dGdσ []float64, s *State) {
+	p := tsr.M_p(s.Sig)
+	var dGdp float64
+	if p > o.Pmin {
+		dGdp = o.Ng * o.G0 * math.Pow(p/o.Pref, o.Ng) / p
+	}
+	for i := 0; i < len(s.Sig); i++ {
+		dGdσ[i] = -dGdp * tsr.Im[i] / 3.0 // dp/dσ = -I/3
+		dKdσ[i] = o.κ * dGdσ[i]
+	}
+}
+
+// KgcDuncanChang implements the Duncan-Chang hyperbolic model (E-B version)
+//  Et = (1 - Rf S)² kh pa (σ3/pa)^nh    with S = min((σ1 - σ3) / qf, smax)
+//  qf = 2 (c cos(φ) + σ3 sin(φ)) / (1 - sin(φ))
+//  Kb = kb pa (σ3/pa)^mb                with Et/3 ≤ Kb ≤ 17 Et
+//  K = Kb and G = 3 Kb Et / (9 Kb - Et)
+//  Note: σ1 and σ3 are the major and minor principal stresses (compression is positive)
+//        and σ3 = max(σ3, smin)
+type KgcDuncanChang struct {
+
+	// parameters
+	Kh   float64 // modulus number
+	Nh   float64 // modulus exponent
+	Kb   float64 // bulk modulus number
+	Mb   float64 // bulk modulus exponent
+	Rf   float64 // failure ratio
+	C    float64 // cohesion
+	Phi  float64 // friction angle [deg]
+	Pa   float64 // atmospheric pressure
+	Smin float64 // minimum confining stress
+	Smax float64 // maximum stress level
+
+	// derived
+	sφ, cφ float64 // sin(φ) and cos(φ)
+
+	// auxiliary
+	λ []float64   // eigenvalues [3]
+	P [][]float64 // eigenprojectors [3][nsig]
+}
+
+// Init initialises calculator
+func (o *KgcDuncanChang) Init(prms fun.Prms) (err error) {
+	o.Nh, o.Mb, o.Rf, o.Pa, o.Smax = 0.5, 0.5, 0.9, 101.3, 0.95
+	for _, p := range prms {
+		switch p.N {
+		case "kh":
+			o.Kh = p.V
+		case "nh":
+			o.Nh = p.V
+		case "kb":
+			o.Kb = p.V
+		case "mb":
+			o.Mb = p.V
+		case "Rf":
+			o.Rf = p.V
+		case "c":
+			o.C = p.V
+		case "phi":
+			o.Phi = p.V
+		case "pa":
+			o.Pa = p.V
+		case "smin":
+			o.Smin = p.V
+		case "smax":
+			o.Smax = p.V
+		}
+	}
+	if o.Kh <= 0 || o.Kb <= 0 || o.Pa <= 0 {
+		return chk.Err("duncan-chang: kh, kb and pa must be positive. kh=%g, kb=%g and pa=%g are incorrect\n", o.Kh, o.Kb, o.Pa)
+	}
+	if o.Smax <= 0 || o.Smax >= 1 || o.Rf <= 0 || o.Rf > 1 {
+		return chk.Err("duncan-chang: smax and Rf must be in ]0,1[ and ]0,1]. smax=%g and Rf=%g are incorrect\n", o.Smax, o.Rf)
+	}
+	if o.Smin <= 0 {
+		o.Smin = 1e-3 * o.Pa
+	}
+	o.sφ = math.Sin(o.Phi * math.Pi / 180.0)
+	o.cφ = math.Cos(o.Phi * math.Pi / 180.0)
+	o.λ = make([]float64, 3)
+	return
+}
+
+// Calc computes K and G
+func (o *KgcDuncanChang) Calc(s *State) (K, G float64) {
+	Et, Kb := o.calc(nil, nil, s)
+	return Kb, 3.0 * Kb * Et / (9.0*Kb - Et)
+}
+
+// Derivs computes the derivatives of K and G w.r.t σ
+func (o *KgcDuncanChang) Derivs(dKdσ, dGdσ []float64, s *State) {
+	o.calc(dKdσ, dGdσ, s)
+}
+
+// calc computes Et and Kb and, if dKdσ != nil, the derivatives of K and G
+func (o *KgcDuncanChang) calc(dKdσ, dGdσ []float64, s *State) (Et, Kb float64) {
+
+	// principal stresses (compression is positive)
+	nsig := len(s.Sig)
+	if len(o.P) != 3 || len(o.P[0]) != nsig {
+		o.P = tsr.M_AllocEigenprojs(nsig)
+	}
+	err := tsr.M_EigenValsProjsNum(o.P, o.λ, s.Sig)
+	if err != nil {
+		chk.Panic("duncan-chang: cannot compute eigenvalues:\n%v", err)
+	}
+	imin, imax := 0, 0
+	for k := 1; k < 3; k++ {
+		if o.λ[k] < o.λ[imin] {
+			imin = k
+		}
+		if o.λ[k] > o.λ[imax] {
+			imax = k
+		}
+	}
+	if imin == imax {
+		imax = (imin + 1) % 3
+	}
+	σ1, σ3 := -o.λ[imin], -o.λ[imax]
+
+	// confining stress
+	dσ3 := 1.0 // derivative of clipped σ3 w.r.t σ3
+	if σ3 < o.Smin {
+		σ3, dσ3 = o.Smin, 0
+	}
+
+	// stress level
+	qf := 2.0 * (o.C*o.cφ + σ3*o.sφ) / (1.0 - o.sφ)
+	S := (σ1 - σ3) / qf
+	dSdσ1, dSdσ3 := 1.0/qf, -(1.0+S*2.0*o.sφ/(1.0-o.sφ))/qf
+	if S > o.Smax {
+		S, dSdσ1, dSdσ3 = o.Smax, 0, 0
+	}
+	if S < 0 {
+		S, dSdσ1, dSdσ3 = 0, 0, 0
+	}
+
+	// tangent Young's modulus
+	Ei := o.Kh * o.Pa * math.Pow(σ3/o.Pa, o.Nh)
+	a := 1.0 - o.Rf*S
+	Et = a * a * Ei
+	dEtdσ1 := -2.0 * a * o.Rf * Ei * dSdσ1
+	dEtdσ3 := a*a*o.Nh*Ei/σ3 - 2.0*a*o.Rf*Ei*dSdσ3
+
+	// bulk modulus
+	Kb = o.Kb * o.Pa * math.Pow(σ3/o.Pa, o.Mb)
+	dKbdσ1, dKbdσ3 := 0.0, o.Mb*Kb/σ3
+	if Kb < Et/3.0 {
+		Kb, dKbdσ1, dKbdσ3 = Et/3.0, dEtdσ1/3.0, dEtdσ3/3.0
+	}
+	if Kb > 17.0*Et {
+		Kb, dKbdσ1, dKbdσ3 = 17.0*Et, 17.0*dEtdσ1, 17.0*dEtdσ3
+	}
+	if dKdσ == nil {
+		return
+	}
+
+	// derivatives: dσ1/dσ = -P[imin] and dσ3/dσ = -P[imax] dσ3
+	den := (9.0*Kb - Et) * (9.0*Kb - Et)
+	dGdKb := -3.0 * Et * Et / den
+	dGdEt := 27.0 * Kb * Kb / den
+	for i := 0; i < nsig; i++ {
+		dσ1dσ, dσ3dσ := -o.P[imin][i], -o.P[imax][i]*dσ3
+		dKdσ[i] = dKbdσ1*dσ1dσ + dKbdσ3*dσ3dσ
+		dGdσ[i] = dGdKb*dKdσ[i] + dGdEt*(dEtdσ1*dσ1dσ+dEtdσ3*dσ3dσ)
+	}
+	return
+}
+
+// KgcSmallStrain implements a small-strain stiffness degradation curve.
+// The secant shear modulus follows the hyperbolic law G/G0 = 1 / (1 + a γ/γ07) which, written
+// in terms of the mobilised shear stress τ = q/√3, gives the tangent modulus:
+//  G = G0 (1 - x)²    with x = min(a τ / (G0 γ07), 1 - √rmin)
+//  G0 = G0ref (p/pref)^ng   with p = max(p, pmin)
+//  K = κ G0                 with κ = 2 (1 + ν) / (3 (1 - 2 ν))
+//  Note: rmin is the minimum ratio G/G0 (cut-off)
+type KgcSmallStrain struct {
+	KgcHardin         // small-strain (maximum) stiffness
+	Gam07     float64 // shear strain at which G/G0 = 0.722
+	A         float64 // coefficient of hyperbolic law
+	Rmin      float64 // minimum G/G0 ratio
+	xmax      float64 // maximum x
+}
+
+// Init initialises calculator
+func (o *KgcSmallStrain) Init(prms fun.Prms) (err error) {
+	err = o.KgcHardin.Init(prms)
+	if err != nil {
+		return
+	}
+	o.A, o.Rmin = 0.385, 0.1
+	for _, p := range prms {
+		switch p.N {
+		case "gam07":
+			o.Gam07 = p.V
+		case "a":
+			o.A = p.V
+		case "rmin":
+			o.Rmin = p.V
+		}
+	}
+	if o.Gam07 <= 0 || o.A <= 0 {
+		return chk.Err("small-strain: gam07 and a must be positive. gam07=%g and a=%g are incorrect\n", o.Gam07, o.A)
+	}
+	if o.Rmin <= 0 || o.Rmin > 1 {
+		return chk.Err("small-strain: rmin must be in ]0,1]. rmin=%g is incorrect\n", o.Rmin)
+	}
+	o.xmax = 1.0 - math.Sqrt(o.Rmin)
+	return
+}
+
+// Calc computes K and G
+func (o KgcSmallStrain) Calc(s *State) (K, G float64) {
+	K, G0 := o.KgcHardin.Calc(s)
+	x := o.A * tsr.M_q(s.Sig) / (math.Sqrt(3.0) * G0 * o.Gam07)
+	if x > o.xmax {
+		x = o.xmax
+	}
+	G = G0 * (1.0 - x) * (1.0 - x)
+	return
+}
+
+// Derivs computes the derivatives of K and G w.r.t σ
+func (o KgcSmallStrain) Derivs(dKdσ, dGdσ []float64, s *State) {
+	o.KgcHardin.Derivs(dKdσ, dGdσ, s) // dGdσ := dG0/dσ
+	_, G0 := o.KgcHardin.Calc(s)
+	q := tsr.M_q(s.Sig)
+	x := o.A * q / (math.Sqrt(3.0) * G0 * o.Gam07)
+	if x > o.xmax {
+		x = o.xmax
+		for i := 0; i < len(s.Sig); i++ {
+			dGdσ[i] *= (1.0 - x) * (1.0 - x)
+		}
+		return
+	}
+	var dqdσ_i float64
+	p := tsr.M_p(s.Sig)
+	for i := 0; i < len(s.Sig); i++ {
+		dqdσ_i = 0
+		if q > 0 {
+			dqdσ_i = 1.5 * (s.Sig[i] + p*tsr.Im[i]) / q // dq/dσ = 3/2 dev(σ)/q
+		}
+		dGdσ[i] = (1.0-x)*(1.0+x)*dGdσ[i] - 2.0*(1.0-x)*o.A*dqdσ_i/(math.Sqrt(3.0)*o.Gam07)
+	}
+}
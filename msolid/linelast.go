@@ -18,7 +18,12 @@ func init() {
 
 // Init initialises model
 func (o *LinElast) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
-	return o.SmallElasticity.Init(ndim, pstress, prms)
+	o.Nonlin = true
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if o.Kgc != nil {
+		o.Iphi = 0
+	}
+	return
 }
 
 // GetPrms gets (an example) of parameters
@@ -27,8 +32,13 @@ func (o LinElast) GetPrms() fun.Prms {
 }
 
 // InitIntVars initialises internal (secondary) variables
+//  Note: with non-linear K and G, Phi holds the last strain increment Δε
 func (o LinElast) InitIntVars() (s *State, err error) {
-	s = NewState(o.Nsig, 0, 0, false)
+	nphi := 0
+	if o.Iphi >= 0 {
+		nphi = o.Iphi + o.Nsig
+	}
+	s = NewState(o.Nsig, 0, nphi, false)
 	return
 }
 
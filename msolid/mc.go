@@ -0,0 +1,553 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// MohrCoulomb implements the Mohr-Coulomb plasticity model with linear hardening of cohesion,
+// non-associated flow (dilatancy angle) and an optional tension cut-off.
+//  Note: the return mapping is carried out in principal stresses (tension is positive)
+//        with σ1 ≥ σ2 ≥ σ3. Returns to the main plane, to the left/right edges, to the
+//        tension cut-off planes and to the apex are considered. The hardening variable α
+//        is the one conjugate to the cohesion; i.e. Δα = cos(φ)/sin(ψ)・Δεv^p for all returns
+type MohrCoulomb struct {
+	SmallElasticity
+
+	// parameters
+	c   float64 // cohesion
+	φ   float64 // friction angle [deg]
+	ψ   float64 // dilatancy angle [deg]
+	σt  float64 // tension cut-off
+	H   float64 // hardening modulus of cohesion
+	Tco bool    // use tension cut-off
+
+	// derived
+	sφ, cφ, sψ float64 // sin(φ), cos(φ) and sin(ψ)
+
+	// auxiliary
+	De  [][]float64 // elastic modulus in principal space [3][3]
+	λ   []float64   // eigenvalues [3]
+	P   [][]float64 // eigenprojectors [3][nsig]
+	ord []int       // ordering of eigenvalues: λ[ord[0]] ≥ λ[ord[1]] ≥ λ[ord[2]]
+	σtr []float64   // ordered principal trial stresses [3]
+	σp  []float64   // ordered principal updated stresses [3]
+	Dp  [][]float64 // ordered principal consistent modulus [3][3]
+	ten []float64   // auxiliary tensor
+
+	// workspace of return mapping; A and Ai are indexed by the size of the active set
+	wA   [][][]float64 // [4][nset][nset] Jacobian of active set
+	wAi  [][][]float64 // [4][nset][nset] inverse of Jacobian
+	wN   [][]float64   // [3][3] gradients of yield functions of active set
+	wM   [][]float64   // [3][3] gradients of plastic potentials of active set
+	wDeM [][]float64   // [3][3] De・m of active set
+	wNDe [][]float64   // [3][3] n・De of active set
+	wn   []float64     // [3] gradient of yield function of one plane
+	wm   []float64     // [3] gradient of plastic potential of one plane
+	wf   []float64     // [3] trial yield functions of active set
+	wΔγ  []float64     // [3] plastic multipliers of active set
+}
+
+// MC planes in ordered principal space: {i, j} means σi - σj + (σi + σj) sin(φ) - 2 c cos(φ) = 0
+//  0 -- main plane; 1 -- right edge plane; 2 -- left edge plane
+//  3, 4, 5 -- tension cut-off planes for σ1, σ2 and σ3
+var mcPlanes = [][]int{{0, 2}, {0, 1}, {1, 2}}
+
+// MC candidate active sets. They are tried in this order before the return to apex
+var mcActiveSets = [][]int{{0}, {0, 1}, {0, 2}, {3}, {3, 4}, {3, 4, 5}, {0, 3}, {0, 1, 3}, {0, 2, 3}}
+
+// add model to factory
+func init() {
+	allocators["mc"] = func() Model { return new(MohrCoulomb) }
+}
+
+// Init initialises model
+func (o *MohrCoulomb) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parse parameters
+	err = o.SmallElasticity.Init(ndim, pstress, prms)
+	if err != nil {
+		return
+	}
+	if pstress {
+		return chk.Err("mc: plane-stress analyses are not available\n")
+	}
+	o.ψ = -1
+	for _, p := range prms {
+		switch p.N {
+		case "c":
+			o.c = p.V
+		case "phi":
+			o.φ = p.V
+		case "psi":
+			o.ψ = p.V
+		case "sigt":
+			o.σt = p.V
+			o.Tco = true
+		case "H":
+			o.H = p.V
+		case "E", "nu", "l", "G", "K", "rho":
+		default:
+			return chk.Err("mc: parameter named %q is incorrect\n", p.N)
+		}
+	}
+
+	// check parameters
+	if o.ψ < 0 {
+		o.ψ = o.φ // associated flow by default
+	}
+	if o.φ < 0 || o.φ >= 90 || o.ψ > o.φ {
+		return chk.Err("mc: friction and dilatancy angles must satisfy 0 ≤ psi ≤ phi < 90. phi=%g and psi=%g are incorrect\n", o.φ, o.ψ)
+	}
+	if o.c < 0 {
+		return chk.Err("mc: cohesion must be non-negative. c=%g is incorrect\n", o.c)
+	}
+	o.sφ = math.Sin(o.φ * math.Pi / 180.0)
+	o.cφ = math.Cos(o.φ * math.Pi / 180.0)
+	o.sψ = math.Sin(o.ψ * math.Pi / 180.0)
+	if o.Tco && o.sφ > 0 && o.σt > o.c*o.cφ/o.sφ {
+		return chk.Err("mc: tension cut-off must not be greater than the apex stress c/tan(phi) = %g. sigt=%g is incorrect\n", o.c*o.cφ/o.sφ, o.σt)
+	}
+
+	// auxiliary structures
+	o.De = la.MatAlloc(3, 3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.De[i][j] = o.K - 2.0*o.G/3.0
+		}
+		o.De[i][i] += 2.0 * o.G
+	}
+	o.λ = make([]float64, 3)
+	o.P = tsr.M_AllocEigenprojs(o.Nsig)
+	o.ord = make([]int, 3)
+	o.σtr = make([]float64, 3)
+	o.σp = make([]float64, 3)
+	o.Dp = la.MatAlloc(3, 3)
+	o.ten = make([]float64, o.Nsig)
+	o.wA = make([][][]float64, 4)
+	o.wAi = make([][][]float64, 4)
+	for nset := 1; nset < 4; nset++ {
+		o.wA[nset] = la.MatAlloc(nset, nset)
+		o.wAi[nset] = la.MatAlloc(nset, nset)
+	}
+	o.wN = la.MatAlloc(3, 3)
+	o.wM = la.MatAlloc(3, 3)
+	o.wDeM = la.MatAlloc(3, 3)
+	o.wNDe = la.MatAlloc(3, 3)
+	o.wn = make([]float64, 3)
+	o.wm = make([]float64, 3)
+	o.wf = make([]float64, 3)
+	o.wΔγ = make([]float64, 3)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o MohrCoulomb) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "c", V: 10},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 10},
+		&fun.Prm{N: "H", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Alp[0] -- hardening variable α conjugate to the cohesion: c = c0 + H・α
+//  Phi[:nsig] -- trial stress; Phi[nsig] -- index of active set (-1 => apex)
+func (o MohrCoulomb) InitIntVars() (s *State, err error) {
+	s = NewState(o.Nsig, 1, o.Nsig+1, false)
+	return
+}
+
+// Update updates stresses for given strains
+func (o *MohrCoulomb) Update(s *State, ε, Δε []float64) (err error) {
+
+	// set flags
+	s.Loading = false    // => not elastoplastic
+	s.ApexReturn = false // => not return-to-apex
+	s.Dgam = 0           // Δγ := 0
+
+	// accessors
+	σ := s.Sig
+	α0 := &s.Alp[0]
+
+	// trial stress
+	var devΔε_i float64
+	trΔε := Δε[0] + Δε[1] + Δε[2]
+	for i := 0; i < o.Nsig; i++ {
+		devΔε_i = Δε[i] - trΔε*tsr.Im[i]/3.0
+		o.ten[i] = σ[i] + o.K*trΔε*tsr.Im[i] + 2.0*o.G*devΔε_i // ten := σtr
+	}
+	copy(s.Phi, o.ten)
+
+	// principal trial stresses
+	err = o.principal(o.ten)
+	if err != nil {
+		return
+	}
+
+	// trial yield functions
+	c := o.c + o.H*(*α0)
+	tol := 1e-10 * (1.0 + math.Abs(o.σtr[0]) + math.Abs(o.σtr[2]))
+	if o.yieldmax(o.σtr, c) <= tol {
+		copy(σ, o.ten) // σ := ten = σtr
+		return
+	}
+
+	// return to planes and edges
+	s.Loading = true
+	found := false
+	for iset, set := range mcActiveSets {
+		if !o.Tco && set[len(set)-1] > 2 {
+			continue
+		}
+		Δγ, e := o.solve(set, c)
+		if e != nil {
+			continue
+		}
+		ok := true
+		for _, v := range Δγ {
+			if v < 0 {
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		Δα := o.retmap(set, Δγ)
+		if o.σp[0] < o.σp[1]-tol || o.σp[1] < o.σp[2]-tol {
+			continue
+		}
+		if o.yieldmax(o.σp, c+o.H*Δα) > tol {
+			continue
+		}
+		found = true
+		*α0 += Δα
+		s.Phi[o.Nsig] = float64(iset)
+		for _, v := range Δγ {
+			s.Dgam += v
+		}
+		break
+	}
+
+	// return to apex
+	if !found {
+		if o.sφ < 1e-15 {
+			return chk.Err("mc: return mapping failed\n")
+		}
+		hv, e := o.volhard()
+		if e != nil {
+			return e
+		}
+		pmtr := (o.σtr[0] + o.σtr[1] + o.σtr[2]) / 3.0
+		cotφ := o.cφ / o.sφ
+		h := o.H * hv * cotφ
+		Δεv := (pmtr - c*cotφ) / (o.K + h)
+		pm := pmtr - o.K*Δεv
+		o.σp[0], o.σp[1], o.σp[2] = pm, pm, pm
+		*α0 += hv * Δεv
+		s.Phi[o.Nsig] = -1
+		s.Dgam = Δεv
+		s.ApexReturn = true
+	}
+
+	// assemble stress tensor
+	for i := 0; i < o.Nsig; i++ {
+		σ[i] = 0
+		for k := 0; k < 3; k++ {
+			σ[i] += o.σp[k] * o.P[o.ord[k]][i]
+		}
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *MohrCoulomb) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// elastic
+	if !s.Loading {
+		return o.SmallElasticity.CalcD(D, s)
+	}
+
+	// first iteration => trial stress equal to current stress
+	if firstIt {
+		copy(s.Phi, s.Sig)
+	}
+
+	// principal trial stresses
+	err = o.principal(s.Phi)
+	if err != nil {
+		return
+	}
+
+	// principal updated stresses
+	for k := 0; k < 3; k++ {
+		o.σp[k] = 0
+		for i := 0; i < o.Nsig; i++ {
+			o.σp[k] += s.Sig[i] * o.P[o.ord[k]][i]
+		}
+	}
+
+	// principal consistent modulus
+	if s.ApexReturn {
+		hv, e := o.volhard()
+		if e != nil {
+			return e
+		}
+		h := o.H * hv * o.cφ / o.sφ
+		a1 := o.K * h / (o.K + h)
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				o.Dp[i][j] = a1
+			}
+		}
+	} else {
+		iset := int(s.Phi[o.Nsig])
+		if iset < 0 || iset >= len(mcActiveSets) {
+			return chk.Err("mc: index of active set %d is invalid\n", iset)
+		}
+		err = o.principalD(mcActiveSets[iset])
+		if err != nil {
+			return
+		}
+	}
+
+	// spectral assembly
+	o.assemble(D)
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *MohrCoulomb) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, true)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// principal computes the ordered principal values of σ; eigenprojectors are saved in P
+func (o *MohrCoulomb) principal(σ []float64) (err error) {
+	err = tsr.M_EigenValsProjsNum(o.P, o.λ, σ)
+	if err != nil {
+		return
+	}
+	o.ord[0], o.ord[1], o.ord[2] = 0, 1, 2
+	for i := 0; i < 2; i++ {
+		for j := i + 1; j < 3; j++ {
+			if o.λ[o.ord[j]] > o.λ[o.ord[i]] {
+				o.ord[i], o.ord[j] = o.ord[j], o.ord[i]
+			}
+		}
+	}
+	for k := 0; k < 3; k++ {
+		o.σtr[k] = o.λ[o.ord[k]]
+	}
+	return
+}
+
+// plane computes the gradient n of the yield function, the gradient m of the plastic
+// potential and the constant part of the yield function of plane k
+func (o MohrCoulomb) plane(n, m []float64, k int, c float64) (f0 float64) {
+	n[0], n[1], n[2] = 0, 0, 0
+	m[0], m[1], m[2] = 0, 0, 0
+	if k < 3 {
+		i, j := mcPlanes[k][0], mcPlanes[k][1]
+		n[i], n[j] = 1.0+o.sφ, -1.0+o.sφ
+		m[i], m[j] = 1.0+o.sψ, -1.0+o.sψ
+		return -2.0 * c * o.cφ
+	}
+	n[k-3], m[k-3] = 1, 1
+	return -o.σt
+}
+
+// yieldmax returns the maximum value among all yield functions
+func (o *MohrCoulomb) yieldmax(σp []float64, c float64) (fmax float64) {
+	fmax = math.Inf(-1)
+	nplanes := 3
+	if o.Tco {
+		nplanes = 6
+	}
+	for k := 0; k < nplanes; k++ {
+		f := o.plane(o.wn, o.wm, k, c) + o.wn[0]*σp[0] + o.wn[1]*σp[1] + o.wn[2]*σp[2]
+		fmax = math.Max(fmax, f)
+	}
+	return
+}
+
+// hardening returns the derivative of the hardening variable w.r.t Δγ of plane k; i.e. -∂f/∂c.
+// On the shear planes, Δεv^p = 2 sin(ψ) Δγ; thus Δα = 2 cos(φ) Δγ = cos(φ)/sin(ψ)・Δεv^p
+func (o MohrCoulomb) hardening(k int) float64 {
+	if k < 3 {
+		return 2.0 * o.cφ
+	}
+	return 0
+}
+
+// volhard returns the derivative of the hardening variable w.r.t the volumetric plastic strain;
+// i.e. Δα = hv・Δεv^p, which is consistent with the returns to the shear planes (see hardening)
+func (o MohrCoulomb) volhard() (hv float64, err error) {
+	if o.H == 0 {
+		return
+	}
+	if o.sψ < 1e-15 {
+		return 0, chk.Err("mc: return to apex with hardening requires a positive dilatancy angle\n")
+	}
+	return o.cφ / o.sψ, nil
+}
+
+// jacobian computes the matrix A[k][l] = n_k・De・m_l + h_kl of the active set
+//  Note: the returned matrices are views of the workspace
+func (o *MohrCoulomb) jacobian(set []int) (A, N, DeM [][]float64) {
+	nset := len(set)
+	A, N, DeM = o.wA[nset], o.wN[:nset], o.wDeM[:nset]
+	for a, k := range set {
+		o.plane(N[a], o.wM[a], k, 0)
+		la.MatVecMul(DeM[a], 1, o.De, o.wM[a])
+	}
+	for a, k := range set {
+		for b, l := range set {
+			A[a][b] = la.VecDot(N[a], DeM[b]) + o.H*o.hardening(k)*o.hardening(l)
+		}
+	}
+	return
+}
+
+// solve computes the plastic multipliers of the active set
+//  Note: the returned slice is a view of the workspace
+func (o *MohrCoulomb) solve(set []int, c float64) (Δγ []float64, err error) {
+	A, _, _ := o.jacobian(set)
+	nset := len(set)
+	Ai := o.wAi[nset]
+	err = la.MatInvG(Ai, A, 1e-10)
+	if err != nil {
+		return
+	}
+	ftr := o.wf[:nset]
+	for a, k := range set {
+		ftr[a] = o.plane(o.wn, o.wm, k, c) + la.VecDot(o.wn, o.σtr)
+	}
+	Δγ = o.wΔγ[:nset]
+	la.MatVecMul(Δγ, 1, Ai, ftr)
+	return
+}
+
+// retmap computes the principal updated stresses and returns the increment of the hardening variable
+func (o *MohrCoulomb) retmap(set []int, Δγ []float64) (Δα float64) {
+	_, _, DeM := o.jacobian(set)
+	copy(o.σp, o.σtr)
+	for a, k := range set {
+		for i := 0; i < 3; i++ {
+			o.σp[i] -= Δγ[a] * DeM[a][i]
+		}
+		Δα += o.hardening(k) * Δγ[a]
+	}
+	return
+}
+
+// principalD computes the consistent modulus in ordered principal space:
+//  Dp = De - Σ_ab (De・m_a) Ai_ab (n_b・De)
+func (o *MohrCoulomb) principalD(set []int) (err error) {
+	A, N, DeM := o.jacobian(set)
+	nset := len(set)
+	Ai := o.wAi[nset]
+	err = la.MatInvG(Ai, A, 1e-10)
+	if err != nil {
+		return
+	}
+	NDe := o.wNDe[:nset]
+	for a := 0; a < nset; a++ {
+		la.MatVecMul(NDe[a], 1, o.De, N[a]) // De is symmetric
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			o.Dp[i][j] = o.De[i][j]
+			for a := 0; a < nset; a++ {
+				for b := 0; b < nset; b++ {
+					o.Dp[i][j] -= DeM[a][i] * Ai[a][b] * NDe[b][j]
+				}
+			}
+		}
+	}
+	return
+}
+
+// assemble assembles the Mandel consistent modulus from the principal quantities:
+//  D = Σ_ab Dp_ab P_a ⊗ P_b + Σ_a≠b f_ab (P_a ⊠ P_b)
+//  where f_ab = (σ_a - σ_b) / (εtr_a - εtr_b) = 2 G (σ_a - σ_b) / (σtr_a - σtr_b)
+func (o *MohrCoulomb) assemble(D [][]float64) {
+
+	// principal modulus in the order of eigenprojectors
+	var Dab, f float64
+	la.MatFill(D, 0)
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			Dab = o.Dp[o.rank(a)][o.rank(b)]
+			for i := 0; i < o.Nsig; i++ {
+				for j := 0; j < o.Nsig; j++ {
+					D[i][j] += Dab * o.P[a][i] * o.P[b][j]
+				}
+			}
+		}
+	}
+
+	// spin terms
+	var ra, rb, k, l, m, n int
+	var ci, cj float64
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			if a == b {
+				continue
+			}
+			ra, rb = o.rank(a), o.rank(b)
+			if math.Abs(o.σtr[ra]-o.σtr[rb]) < 1e-10*(1.0+math.Abs(o.σtr[ra])) {
+				f = o.Dp[ra][ra] - o.Dp[ra][rb]
+			} else {
+				f = 2.0 * o.G * (o.σp[ra] - o.σp[rb]) / (o.σtr[ra] - o.σtr[rb])
+			}
+			for i := 0; i < o.Nsig; i++ {
+				k, l, ci = mcMandel[i][0], mcMandel[i][1], mcMandelCoef(i)
+				for j := 0; j < o.Nsig; j++ {
+					m, n, cj = mcMandel[j][0], mcMandel[j][1], mcMandelCoef(j)
+					D[i][j] += f * ci * cj * 0.5 * (o.comp(o.P[a], k, m)*o.comp(o.P[b], l, n) +
+						o.comp(o.P[a], k, n)*o.comp(o.P[b], l, m))
+				}
+			}
+		}
+	}
+}
+
+// rank returns the position of eigenvalue a in the ordered principal space
+func (o MohrCoulomb) rank(a int) int {
+	for k := 0; k < 3; k++ {
+		if o.ord[k] == a {
+			return k
+		}
+	}
+	return -1
+}
+
+// comp returns the (i,j) tensor component of Mandel vector a; zero if not available (2D)
+func (o MohrCoulomb) comp(a []float64, i, j int) float64 {
+	if tsr.T2MI[i][j] >= o.Nsig {
+		return 0
+	}
+	return tsr.M2T(a, i, j)
+}
+
+// mcMandel maps Mandel components to tensor indices
+var mcMandel = [][]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {1, 2}, {2, 0}}
+
+// mcMandelCoef returns the Mandel coefficient of component i
+func mcMandelCoef(i int) float64 {
+	if i < 3 {
+		return 1
+	}
+	return math.Sqrt2
+}
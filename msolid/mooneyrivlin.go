@@ -0,0 +1,62 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// MooneyRivlin implements a compressible Mooney-Rivlin model
+//  W = C10 (Ī1 - 3) + C01 (Ī2 - 3) + U(J)
+//  Note: the initial shear modulus is μ = 2 (C10 + C01). See HyperElast for the volumetric penalty U(J)
+type MooneyRivlin struct {
+	HyperElast
+	C10 float64 // coefficient of Ī1
+	C01 float64 // coefficient of Ī2
+}
+
+// add model to factory
+func init() {
+	allocators["mooney-rivlin"] = func() Model { return new(MooneyRivlin) }
+}
+
+// Init initialises model
+func (o *MooneyRivlin) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "c10":
+			o.C10 = p.V
+		case "c01":
+			o.C01 = p.V
+		case "K":
+			o.K = p.V
+		case "vol":
+			o.Vol = int(p.V)
+		case "rho":
+		default:
+			return chk.Err("mooney-rivlin: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.C10+o.C01 <= 0 {
+		return chk.Err("mooney-rivlin: initial shear modulus 2 (c10 + c01) must be positive. c10=%g and c01=%g are incorrect\n", o.C10, o.C01)
+	}
+	o.W1, o.W2 = o.C10, o.C01
+
+	// auxiliary
+	return o.HyperElast.init(ndim, pstress)
+}
+
+// GetPrms gets (an example) of parameters
+func (o MooneyRivlin) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "c10", V: 0.4},
+		&fun.Prm{N: "c01", V: 0.1},
+		&fun.Prm{N: "K", V: 100},
+		&fun.Prm{N: "vol", V: 0},
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// NeoHookean implements a compressible Neo-Hookean model
+//  W = (μ/2) (Ī1 - 3) + U(J)
+//  Note: see HyperElast for the volumetric penalty U(J)
+type NeoHookean struct {
+	HyperElast
+	Mu float64 // shear modulus μ
+}
+
+// add model to factory
+func init() {
+	allocators["neo-hookean"] = func() Model { return new(NeoHookean) }
+}
+
+// Init initialises model
+func (o *NeoHookean) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parameters
+	for _, p := range prms {
+		switch p.N {
+		case "mu":
+			o.Mu = p.V
+		case "K":
+			o.K = p.V
+		case "vol":
+			o.Vol = int(p.V)
+		case "rho":
+		default:
+			return chk.Err("neo-hookean: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.Mu <= 0 {
+		return chk.Err("neo-hookean: shear modulus mu must be positive. mu=%g is incorrect\n", o.Mu)
+	}
+	o.W1, o.W2 = o.Mu/2.0, 0
+
+	// auxiliary
+	return o.HyperElast.init(ndim, pstress)
+}
+
+// GetPrms gets (an example) of parameters
+func (o NeoHookean) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "mu", V: 1},
+		&fun.Prm{N: "K", V: 100},
+		&fun.Prm{N: "vol", V: 0},
+	}
+}
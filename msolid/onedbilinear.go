@@ -0,0 +1,91 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// OnedBilinear implements an elastoplastic model with linear isotropic hardening for 1D elements
+//  Note: the yield function is f = |σ| - (σy + H ・ α) where α is the accumulated plastic strain
+type OnedBilinear struct {
+	E  float64 // Young modulus
+	Sy float64 // initial yield stress
+	H  float64 // hardening modulus (plastic); the elastoplastic modulus is E H / (E + H)
+}
+
+// add model to factory
+func init() {
+	onedallocators["oned-bilinear"] = func() OnedSolid { return new(OnedBilinear) }
+}
+
+// Init initialises model
+func (o *OnedBilinear) Init(ndim int, prms fun.Prms) (err error) {
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "sY":
+			o.Sy = p.V
+		case "H":
+			o.H = p.V
+		}
+	}
+	if o.E <= 0 || o.Sy <= 0 || o.H < 0 {
+		return chk.Err("oned-bilinear: E and sY must be positive and H must be non-negative. E=%g, sY=%g and H=%g are incorrect\n", o.E, o.Sy, o.H)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o OnedBilinear) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 2.0e8},
+		&fun.Prm{N: "sY", V: 2.5e5},
+		&fun.Prm{N: "H", V: 2.0e6},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Note: Alp[0] = α (accumulated plastic strain)
+func (o OnedBilinear) InitIntVars() (s *OnedState, err error) {
+	s = NewOnedState(1, 0)
+	return
+}
+
+// Update updates stresses for given strains
+func (o OnedBilinear) Update(s *OnedState, ε, Δε float64) (err error) {
+
+	// trial stress
+	α := &s.Alp[0]
+	σtr := s.Sig + o.E*Δε
+	ftr := math.Abs(σtr) - (o.Sy + o.H*(*α))
+
+	// elastic update
+	s.Dgam = 0
+	if ftr <= 0 {
+		s.Sig = σtr
+		s.Loading = false
+		return
+	}
+
+	// plastic update
+	s.Dgam = ftr / (o.E + o.H)
+	s.Sig = σtr - o.E*s.Dgam*fun.Sign(σtr)
+	*α += s.Dgam
+	s.Loading = true
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o OnedBilinear) CalcD(s *OnedState, firstIt bool) (float64, error) {
+	if s.Loading {
+		return o.E * o.H / (o.E + o.H), nil
+	}
+	return o.E, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// OnedUnilateral implements tension-only (cable) and compression-only (strut) models for 1D elements
+//  Note: 1) the stress is σ = E (ε - εp) if the elastic strain ε - εp is on the active side
+//           (tension for cables and compression for struts) or zero; otherwise the element is slack
+//           and σ = Eslack (ε - εp), where Eslack is a (small) optional modulus with default = 0.
+//           Unstressed members are active; thus nodes held by cables only have a regular stiffness
+//        2) if the strength sY > 0 is given, the stress on the active side is limited to sY
+//           (in absolute value) by perfect plasticity, with plastic strains εp
+//        3) this model is based on total strains; thus initial stresses are disregarded
+type OnedUnilateral struct {
+	E       float64 // Young modulus
+	Eslack  float64 // modulus when slack
+	Sy      float64 // strength (absolute value); 0 => unlimited
+	Tension bool    // tension-only (cable); otherwise compression-only (strut)
+}
+
+// add model to factory
+func init() {
+	onedallocators["oned-cable"] = func() OnedSolid { return &OnedUnilateral{Tension: true} }
+	onedallocators["oned-strut"] = func() OnedSolid { return &OnedUnilateral{Tension: false} }
+}
+
+// Init initialises model
+func (o *OnedUnilateral) Init(ndim int, prms fun.Prms) (err error) {
+	for _, p := range prms {
+		switch p.N {
+		case "E":
+			o.E = p.V
+		case "Eslack":
+			o.Eslack = p.V
+		case "sY":
+			o.Sy = p.V
+		}
+	}
+	if o.E <= 0 || o.Eslack < 0 || o.Sy < 0 {
+		return chk.Err("oned-cable/strut: E must be positive and Eslack and sY must be non-negative. E=%g, Eslack=%g and sY=%g are incorrect\n", o.E, o.Eslack, o.Sy)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o OnedUnilateral) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "E", V: 2.0e8},
+		&fun.Prm{N: "Eslack", V: 0},
+		&fun.Prm{N: "sY", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Note: Alp[0] = ε (total strain) and Alp[1] = εp (plastic strain)
+func (o OnedUnilateral) InitIntVars() (s *OnedState, err error) {
+	s = NewOnedState(2, 0)
+	return
+}
+
+// Update updates stresses for given strains
+func (o OnedUnilateral) Update(s *OnedState, ε, Δε float64) (err error) {
+
+	// elastic strain
+	s.Alp[0] += Δε
+	εe := s.Alp[0] - s.Alp[1]
+
+	// slack
+	s.Loading = false
+	if !o.active(εe) {
+		s.Sig = o.Eslack * εe
+		return
+	}
+
+	// active
+	s.Sig = o.E * εe
+	if o.Sy > 0 && o.E*math.Abs(εe) > o.Sy {
+		σy := o.Sy
+		if !o.Tension {
+			σy = -o.Sy
+		}
+		s.Alp[1] = s.Alp[0] - σy/o.E
+		s.Sig = σy
+		s.Loading = true
+	}
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o OnedUnilateral) CalcD(s *OnedState, firstIt bool) (float64, error) {
+	if s.Loading {
+		return 0, nil
+	}
+	if o.active(s.Alp[0] - s.Alp[1]) {
+		return o.E, nil
+	}
+	return o.Eslack, nil
+}
+
+// active returns whether the elastic strain εe is on the active side or zero
+func (o OnedUnilateral) active(εe float64) bool {
+	if o.Tension {
+		return εe >= 0
+	}
+	return εe <= 0
+}
@@ -59,6 +59,29 @@ func (o *Path) SetIsoCompS(ndim, nincs, niout int, P []float64) (err error) {
 	return o.init(ndim)
 }
 
+// SetPQstress sets a p-q path with w=1 (compression) given in terms of stresses
+//  Note: z is the axial direction; i.e. σz = -(p + 2q/3) and σx = σy = -(p - q/3)
+func (o *Path) SetPQstress(ndim, nincs, niout int, p0 float64, DP, DQ []float64) (err error) {
+
+	// constants
+	o.Nincs, o.Niout = nincs, niout
+
+	// stress path
+	n := 1 + len(DP)
+	o.Sx, o.Sy, o.Sz, o.UseS = make([]float64, n), make([]float64, n), make([]float64, n), utl.IntVals(n, 1)
+	p, q := p0, 0.0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			p += DP[i-1]
+			q += DQ[i-1]
+		}
+		o.Sx[i], o.Sy[i], o.Sz[i] = -(p - q/3.0), -(p - q/3.0), -(p + 2.0*q/3.0)
+	}
+
+	// set additional information
+	return o.init(ndim)
+}
+
 // SetPQstrain sets a p-q path with w=1 (compression); but given in terms of strains
 func (o *Path) SetPQstrain(ndim, nincs, niout int, K, G, p0 float64, DP, DQ []float64, noise float64) (err error) {
 
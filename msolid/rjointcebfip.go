@@ -0,0 +1,114 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// RjointCebFip implements the bond-slip law of the CEB-FIP Model Code for rod-joints
+//  The envelope is:
+//   τ = τmax (ω/s1)^α                       if 0  ≤ ω ≤ s1
+//   τ = τmax                                if s1 < ω ≤ s2
+//   τ = τmax - (τmax - τf) (ω - s2)/(s3 - s2) if s2 < ω ≤ s3  (softening)
+//   τ = τf                                  if ω > s3        (residual friction)
+//  Note: unloading and reloading are elastic with stiffness ks; see bondslip_update
+type RjointCebFip struct {
+	ks   float64 // unloading/reloading stiffness
+	τmax float64 // max bond stress
+	τf   float64 // residual (friction) bond stress
+	s1   float64 // slip at the beginning of the plateau
+	s2   float64 // slip at the end of the plateau
+	s3   float64 // slip at the beginning of the residual branch
+	α    float64 // exponent of the ascending branch
+}
+
+// add model to factory
+func init() {
+	rjointallocators["rjoint-cebfip"] = func() RjointModel { return new(RjointCebFip) }
+}
+
+// Init initialises model
+func (o *RjointCebFip) Init(prms fun.Prms) (err error) {
+	o.α = 0.4
+	for _, p := range prms {
+		switch p.N {
+		case "ks":
+			o.ks = p.V
+		case "taumax":
+			o.τmax = p.V
+		case "tauf":
+			o.τf = p.V
+		case "s1":
+			o.s1 = p.V
+		case "s2":
+			o.s2 = p.V
+		case "s3":
+			o.s3 = p.V
+		case "alp":
+			o.α = p.V
+		}
+	}
+	if o.ks <= 0 || o.τmax <= 0 || o.τf < 0 || o.τf > o.τmax || o.α <= 0 || o.α > 1 {
+		return chk.Err("rjoint-cebfip: parameters must satisfy ks > 0, taumax > 0, 0 ≤ tauf ≤ taumax and 0 < alp ≤ 1. ks=%g, taumax=%g, tauf=%g and alp=%g are incorrect\n", o.ks, o.τmax, o.τf, o.α)
+	}
+	if o.s1 <= 0 || o.s2 < o.s1 || o.s3 <= o.s2 {
+		return chk.Err("rjoint-cebfip: slips must satisfy 0 < s1 ≤ s2 < s3. s1=%g, s2=%g and s3=%g are incorrect\n", o.s1, o.s2, o.s3)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+//  Note: good bond conditions and unconfined concrete with fck = 30 MPa [kPa and m]
+func (o RjointCebFip) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "ks", V: 5e7},
+		&fun.Prm{N: "taumax", V: 13700},
+		&fun.Prm{N: "tauf", V: 5480},
+		&fun.Prm{N: "s1", V: 0.001},
+		&fun.Prm{N: "s2", V: 0.002},
+		&fun.Prm{N: "s3", V: 0.010},
+		&fun.Prm{N: "alp", V: 0.4},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o RjointCebFip) InitIntVars() (s *OnedState, err error) {
+	s = NewOnedState(2, 2) // 2:{ω,ωmax}  2:{qn1,qn2}
+	return
+}
+
+// Update updates stresses for given strains
+//  Note: the confining stress is not considered by this model
+func (o *RjointCebFip) Update(s *OnedState, σcNew, Δω float64) (err error) {
+	bondslip_update(s, o.ks, Δω, o.envelope)
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *RjointCebFip) CalcD(s *OnedState, firstIt bool) (DτDω, DτDσc float64, err error) {
+	return bondslip_calcD(s, o.ks, o.envelope), 0, nil
+}
+
+// envelope computes the envelope and its derivative
+func (o RjointCebFip) envelope(ω float64) (τ, DτDω float64) {
+	switch {
+	case ω <= o.s1:
+		if ω <= 0 {
+			return 0, math.Inf(1)
+		}
+		τ = o.τmax * math.Pow(ω/o.s1, o.α)
+		return τ, o.α * τ / ω
+	case ω <= o.s2:
+		return o.τmax, 0
+	case ω <= o.s3:
+		DτDω = -(o.τmax - o.τf) / (o.s3 - o.s2)
+		return o.τmax + DτDω*(ω-o.s2), DτDω
+	}
+	return o.τf, 0
+}
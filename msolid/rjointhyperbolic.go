@@ -0,0 +1,106 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// RjointHyperbolic implements a confinement-dependent hyperbolic bond-slip law for rod-joints
+//  The envelope is:
+//   τ = ks ω τu / (τu + ks ω)  with  τu = τu0 + μ σc
+//  where τu is the asymptotic (ultimate) bond stress and σc ≥ 0 is the confining stress
+//  Note: unloading and reloading are elastic with stiffness ks; see bondslip_update
+type RjointHyperbolic struct {
+	ks  float64 // initial stiffness
+	τu0 float64 // ultimate bond stress without confinement
+	μ   float64 // friction coefficient (confinement dependence)
+}
+
+// add model to factory
+func init() {
+	rjointallocators["rjoint-hyperbolic"] = func() RjointModel { return new(RjointHyperbolic) }
+}
+
+// Init initialises model
+func (o *RjointHyperbolic) Init(prms fun.Prms) (err error) {
+	for _, p := range prms {
+		switch p.N {
+		case "ks":
+			o.ks = p.V
+		case "tauu":
+			o.τu0 = p.V
+		case "mu":
+			o.μ = p.V
+		}
+	}
+	if o.ks <= 0 || o.τu0 <= 0 || o.μ < 0 {
+		return chk.Err("rjoint-hyperbolic: parameters must satisfy ks > 0, tauu > 0 and mu ≥ 0. ks=%g, tauu=%g and mu=%g are incorrect\n", o.ks, o.τu0, o.μ)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o RjointHyperbolic) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "ks", V: 1e4},
+		&fun.Prm{N: "tauu", V: 20},
+		&fun.Prm{N: "mu", V: 0.5},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+func (o RjointHyperbolic) InitIntVars() (s *OnedState, err error) {
+	s = NewOnedState(3, 2) // 3:{ω,ωmax,σc}  2:{qn1,qn2}
+	return
+}
+
+// Update updates stresses for given strains
+func (o *RjointHyperbolic) Update(s *OnedState, σcNew, Δω float64) (err error) {
+
+	// limit σcNew
+	if σcNew < 0 {
+		σcNew = 0
+	}
+	s.Alp[2] = σcNew
+
+	// update
+	bondslip_update(s, o.ks, Δω, o.envelope(σcNew))
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *RjointHyperbolic) CalcD(s *OnedState, firstIt bool) (DτDω, DτDσc float64, err error) {
+
+	// elastic
+	if !s.Loading {
+		return o.ks, 0, nil
+	}
+
+	// envelope at current (virgin) or max slip (reloading)
+	σc := s.Alp[2]
+	DτDω = bondslip_calcD(s, o.ks, o.envelope(σc))
+	ω := s.Alp[1]
+	if bondslip_onenv(s) {
+		ω = math.Abs(s.Alp[0])
+	}
+	if σc > 0 {
+		τu := o.τu0 + o.μ*σc
+		DτDσc = fun.Sign(s.Sig) * o.μ * math.Pow(o.ks*ω/(τu+o.ks*ω), 2.0)
+	}
+	return
+}
+
+// envelope returns the envelope for a given confining stress
+func (o RjointHyperbolic) envelope(σc float64) bondslipEnvelope {
+	τu := o.τu0 + o.μ*σc
+	return func(ω float64) (τ, DτDω float64) {
+		d := τu + o.ks*ω
+		return o.ks * ω * τu / d, o.ks * τu * τu / (d * d)
+	}
+}
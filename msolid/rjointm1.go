@@ -18,6 +18,11 @@ type RjointM1 struct {
 	μ   float64 // frictioin coefficient
 }
 
+// add model to factory
+func init() {
+	rjointallocators["rjoint-m1"] = func() RjointModel { return new(RjointM1) }
+}
+
 // Init initialises model
 func (o *RjointM1) Init(prms fun.Prms) (err error) {
 	for _, p := range prms {
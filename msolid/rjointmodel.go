@@ -0,0 +1,116 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// RjointModel defines the interface for bond-slip models of rod-joints (links/interface)
+//  Note: the states must have 2 Phi values {qn1,qn2} that are used by the rod-joint element
+type RjointModel interface {
+	Init(prms fun.Prms) error                                          // initialises model
+	GetPrms() fun.Prms                                                 // gets (an example) of parameters
+	InitIntVars() (*OnedState, error)                                  // initialises AND allocates internal (secondary) variables
+	Update(s *OnedState, σcNew, Δω float64) error                      // update state for given confining stress and slip increment
+	CalcD(s *OnedState, firstIt bool) (DτDω, DτDσc float64, err error) // computes derivatives consistent with Update
+}
+
+// GetRjointModel returns (existent or new) rod-joint model
+//  simfnk    -- unique simulation filename key
+//  matname   -- name of material
+//  modelname -- model name
+//  getnew    -- force a new allocation; i.e. do not use any model found in database
+//  Note: returns nil on errors
+func GetRjointModel(simfnk, matname, modelname string, getnew bool) RjointModel {
+
+	// get new model, regardless wheter it exists in database or not
+	if getnew {
+		allocator, ok := rjointallocators[modelname]
+		if !ok {
+			return nil
+		}
+		return allocator()
+	}
+
+	// search database
+	key := io.Sf("%s_%s_%s", simfnk, matname, modelname)
+	if model, ok := _rjointmodels[key]; ok {
+		return model
+	}
+
+	// if not found, get new
+	allocator, ok := rjointallocators[modelname]
+	if !ok {
+		return nil
+	}
+	model := allocator()
+	_rjointmodels[key] = model
+	return model
+}
+
+// rjointallocators holds all available rod-joint models; modelname => allocator
+var rjointallocators = map[string]func() RjointModel{}
+
+// _rjointmodels holds pre-allocated rod-joint models (internal); key => RjointModel
+var _rjointmodels = map[string]RjointModel{}
+
+// bond-slip models defined by envelopes ////////////////////////////////////////////////////////////
+
+// bondslipEnvelope computes the bond stress τ ≥ 0 along the monotonic envelope and its derivative
+// for a given absolute slip ω ≥ 0
+type bondslipEnvelope func(ω float64) (τ, DτDω float64)
+
+// bondslip_update updates the state of envelope-based bond-slip models
+//  Note: 1) Alp[0] = ω (slip) and Alp[1] = ωmax (max absolute slip reached)
+//        2) loading beyond ωmax follows the envelope; unloading and reloading are elastic with
+//           stiffness ks and are bounded by the envelope stress at ωmax
+func bondslip_update(s *OnedState, ks, Δω float64, env bondslipEnvelope) {
+	ω := &s.Alp[0]
+	ωmax := &s.Alp[1]
+	*ω += Δω
+
+	// virgin loading
+	a := math.Abs(*ω)
+	if a >= *ωmax {
+		*ωmax = a
+		τ, _ := env(a)
+		s.Sig = fun.Sign(*ω) * τ
+		s.Loading = true
+		return
+	}
+
+	// unloading or reloading
+	τ_tr := s.Sig + ks*Δω
+	τ_cap, _ := env(*ωmax)
+	if math.Abs(τ_tr) > τ_cap {
+		s.Sig = fun.Sign(τ_tr) * τ_cap
+		s.Loading = true
+		return
+	}
+	s.Sig = τ_tr
+	s.Loading = false
+}
+
+// bondslip_onenv tells whether the state is on the envelope (virgin loading)
+func bondslip_onenv(s *OnedState) bool {
+	return s.Loading && math.Abs(s.Alp[0]) >= s.Alp[1]
+}
+
+// bondslip_calcD computes DτDω consistent with bondslip_update
+//  Note: the derivative along the envelope is limited to ks
+func bondslip_calcD(s *OnedState, ks float64, env bondslipEnvelope) (DτDω float64) {
+	if !s.Loading {
+		return ks
+	}
+	if bondslip_onenv(s) {
+		_, DτDω = env(math.Abs(s.Alp[0]))
+		return math.Min(DτDω, ks)
+	}
+	return 0
+}
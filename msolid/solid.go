@@ -54,6 +54,16 @@ type SmallStrainUpdater interface {
 	StrainUpdate(s *State, Δσ []float64) error // updates strains for given stresses (small strains formulation)
 }
 
+// RateDependent defines models that depend on the time increment; e.g. viscoplastic models
+type RateDependent interface {
+	SetDt(Δt float64) // sets the time increment to be used in the next updates
+}
+
+// LengthDependent defines models that depend on the size of elements; e.g. for regularisation of softening
+type LengthDependent interface {
+	SetLength(s *State, h float64) error // sets the characteristic length h of element into state
+}
+
 // GetModel returns (existent or new) solid model
 //  simfnk    -- unique simulation filename key
 //  matname   -- name of material
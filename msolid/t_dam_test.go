@@ -0,0 +1,207 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_dam01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("dam01")
+
+	// Mazars and modified von Mises equivalent strains
+	for typ := 0; typ < 2; typ++ {
+
+		// allocate driver
+		ndim, pstress := 2, false
+		simfnk, modelname := "test", "dam"
+		var drv Driver
+		err := drv.Init(simfnk, modelname, ndim, pstress, []*fun.Prm{
+			&fun.Prm{N: "E", V: 1000},
+			&fun.Prm{N: "nu", V: 0.2},
+			&fun.Prm{N: "k0", V: 1e-3},
+			&fun.Prm{N: "kf", V: 5e-3},
+			&fun.Prm{N: "typ", V: float64(typ)},
+		})
+		drv.CheckD = true
+		drv.TolD = 1e-6
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+
+		// path
+		p0 := 0.0
+		DP := []float64{-1, -2, -1}
+		DQ := []float64{2, 4, 1}
+		nincs := 2
+		niout := 1
+		noise := 0.0
+		K, G := 1000.0/(3.0*0.6), 1000.0/2.4
+		var pth Path
+		err = pth.SetPQstrain(ndim, nincs, niout, K, G, p0, DP, DQ, noise)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+
+		// run
+		err = drv.Run(&pth)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		io.Pforan("typ=%d: ω = %v\n", typ, drv.Res[len(drv.Res)-1].Alp[1])
+	}
+}
+
+func Test_dam02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("dam02")
+
+	// model
+	E, ft, Gf, h := 30000.0, 3.0, 0.1, 10.0
+	var mdl IsoDamage
+	err := mdl.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: E},
+		&fun.Prm{N: "nu", V: 0},
+		&fun.Prm{N: "ft", V: ft},
+		&fun.Prm{N: "Gf", V: Gf},
+		&fun.Prm{N: "wmax", V: 1},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// state with characteristic length
+	s, err := mdl.InitIntVars()
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	err = mdl.SetLength(s, h)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	chk.Scalar(tst, "κf", 1e-15, s.Phi[0], Gf/(h*ft)+ft/(2.0*E))
+
+	// uniaxial strain until complete failure: dissipated energy per volume must be Gf/h
+	ε := make([]float64, 6)
+	Δε := make([]float64, 6)
+	var W, σold float64
+	nincs := 200000
+	εmax := 100.0 * s.Phi[0]
+	for i := 0; i < nincs; i++ {
+		Δε[0] = εmax / float64(nincs)
+		ε[0] += Δε[0]
+		err = mdl.Update(s, ε, Δε)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		W += (σold + s.Sig[0]) * Δε[0] / 2.0
+		σold = s.Sig[0]
+	}
+	io.Pforan("W = %v, Gf/h = %v\n", W, Gf/h)
+	chk.Scalar(tst, "W", 1e-5, W, Gf/h)
+
+	// snap-back
+	err = mdl.SetLength(s, 2.0*Gf*E/(ft*ft)*1.01)
+	if err == nil {
+		tst.Errorf("test failed: snap-back should have been detected\n")
+	}
+}
+
+func Test_dam03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("dam03")
+
+	// model
+	E, ν, ft, Gf, h := 30000.0, 0.2, 3.0, 0.1, 10.0
+	var mdl IsoDamage
+	err := mdl.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "E", V: E},
+		&fun.Prm{N: "nu", V: ν},
+		&fun.Prm{N: "ft", V: ft},
+		&fun.Prm{N: "Gf", V: Gf},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// missing characteristic length
+	s, err := mdl.InitIntVars()
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	ε := make([]float64, 6)
+	Δε := make([]float64, 6)
+	err = mdl.Update(s, ε, Δε)
+	if err == nil {
+		tst.Errorf("test failed: Update without SetLength should have failed\n")
+		return
+	}
+	err = mdl.SetLength(s, h)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// initial stresses
+	σ0 := []float64{-2, -2, -4, 0, 0, 0}
+	copy(s.Sig, σ0)
+
+	// elastic increment: σ = σ0 + De : Δε
+	Δε[0] = 1e-5
+	ε[0] = Δε[0]
+	err = mdl.Update(s, ε, Δε)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	l, G := E*ν/((1.0+ν)*(1.0-2.0*ν)), E/(2.0*(1.0+ν))
+	σ := []float64{σ0[0] + (l+2.0*G)*Δε[0], σ0[1] + l*Δε[0], σ0[2] + l*Δε[0], 0, 0, 0}
+	chk.Scalar(tst, "ω", 1e-17, s.Alp[1], 0)
+	chk.Vector(tst, "σ (elastic)", 1e-12, s.Sig, σ)
+
+	// damaging increment: σ = (1 - ω) De : (ε0 + ε)
+	Δε[0] = 5e-4
+	ε[0] += Δε[0]
+	err = mdl.Update(s, ε, Δε)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	εe := []float64{
+		(σ0[0]-ν*(σ0[1]+σ0[2]))/E + ε[0],
+		(σ0[1] - ν*(σ0[2]+σ0[0])) / E,
+		(σ0[2] - ν*(σ0[0]+σ0[1])) / E,
+	}
+	κ0 := ft / E
+	κf := Gf/(h*ft) + κ0/2.0
+	κ := εe[0] // Mazars: only εe[0] is positive
+	ω := 1.0 - (κ0/κ)*math.Exp(-(κ-κ0)/(κf-κ0))
+	trεe := εe[0] + εe[1] + εe[2]
+	for i := 0; i < 3; i++ {
+		σ[i] = (1.0 - ω) * (l*trεe + 2.0*G*εe[i])
+	}
+	io.Pforan("ω = %v\n", s.Alp[1])
+	chk.Scalar(tst, "κ", 1e-15, s.Alp[0], κ)
+	chk.Scalar(tst, "ω", 1e-12, s.Alp[1], ω)
+	chk.Vector(tst, "σ (damaged)", 1e-10, s.Sig, σ)
+}
@@ -0,0 +1,143 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/tsr"
+)
+
+// check_spatial_tangent checks A = (1/J) ∂τ/∂F . tr(F) - σ palm I numerically
+func check_spatial_tangent(tst *testing.T, modelname string, prms fun.Prms, F [][]float64, tol float64) {
+
+	// model
+	mdl := GetModel("test", modelname, modelname, true)
+	if mdl == nil {
+		tst.Errorf("test failed: cannot get model named %q\n", modelname)
+		return
+	}
+	err := mdl.Init(3, false, prms)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	large := mdl.(Large)
+	s, err := mdl.InitIntVars()
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// update and tangent
+	FΔ := tsr.Alloc2()
+	err = large.Update(s, F, FΔ)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	A := tsr.Alloc4()
+	err = large.CalcA(A, s, true)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	Fi := tsr.Alloc2()
+	J, err := tsr.Inv(Fi, F)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	σ := make([]float64, 6)
+	copy(σ, s.Sig)
+
+	// Kirchhoff stress component for perturbed F
+	Ftmp := tsr.Alloc2()
+	stmp, _ := mdl.InitIntVars()
+	τ := func(i, j, k, m int, x float64) float64 {
+		la.MatCopy(Ftmp, 1, F)
+		Ftmp[k][m] = x
+		large.Update(stmp, Ftmp, FΔ)
+		Jtmp, _ := tsr.Inv(Fi, Ftmp)
+		return Jtmp * tsr.M2T(stmp.Sig, i, j)
+	}
+
+	// check
+	var dτdF, anum float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					anum = 0
+					for m := 0; m < 3; m++ {
+						dτdF = num.DerivCen(func(x float64, args ...interface{}) float64 {
+							return τ(i, j, k, m, x)
+						}, F[k][m])
+						anum += dτdF * F[l][m] / J
+					}
+					if j == k {
+						anum -= tsr.M2T(σ, i, l)
+					}
+					chk.AnaNum(tst, io.Sf("A[%d][%d][%d][%d]", i, j, k, l), tol, A[i][j][k][l], anum, chk.Verbose)
+				}
+			}
+		}
+	}
+}
+
+func Test_hyperelast01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("hyperelast01")
+
+	// stress-free undeformed configuration
+	for _, name := range []string{"neo-hookean", "mooney-rivlin"} {
+		mdl := GetModel("test", name, name, true)
+		if mdl == nil {
+			tst.Errorf("test failed: cannot get model named %q\n", name)
+			return
+		}
+		err := mdl.Init(3, false, mdl.GetPrms())
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		s, _ := mdl.InitIntVars()
+		err = mdl.(Large).Update(s, s.F, tsr.Alloc2())
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		chk.Vector(tst, name+": σ(F=I)", 1e-15, s.Sig, make([]float64, 6))
+	}
+
+	// deformation gradient
+	F := [][]float64{
+		{1.2, 0.1, -0.05},
+		{0.15, 0.9, 0.2},
+		{0.0, -0.1, 1.1},
+	}
+
+	// spatial tangent consistency
+	for vol := 0; vol < 3; vol++ {
+		io.Pf("\nvol = %d\n", vol)
+		check_spatial_tangent(tst, "neo-hookean", []*fun.Prm{
+			&fun.Prm{N: "mu", V: 1.5},
+			&fun.Prm{N: "K", V: 10},
+			&fun.Prm{N: "vol", V: float64(vol)},
+		}, F, 1e-7)
+		check_spatial_tangent(tst, "mooney-rivlin", []*fun.Prm{
+			&fun.Prm{N: "c10", V: 0.6},
+			&fun.Prm{N: "c01", V: 0.15},
+			&fun.Prm{N: "K", V: 10},
+			&fun.Prm{N: "vol", V: float64(vol)},
+		}, F, 1e-7)
+	}
+}
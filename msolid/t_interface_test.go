@@ -0,0 +1,159 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+func Test_interface01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface01")
+
+	// parameters
+	prms := []*fun.Prm{
+		&fun.Prm{N: "kn", V: 100},
+		&fun.Prm{N: "ks", V: 50},
+		&fun.Prm{N: "c", V: 10},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 10},
+		&fun.Prm{N: "sigt", V: 5},
+	}
+	tφ := math.Tan(30.0 * math.Pi / 180.0)
+	τc := 10.0 - 5.0*tφ
+
+	// tests: 0=elastic, 1=shear, 2=tension cut-off, 3=corner
+	tests := []struct {
+		ndim int
+		mode int
+		Δw   []float64
+	}{
+		{3, 0, []float64{0.01, 0.02, -0.01}},
+		{3, 1, []float64{-0.1, 0.3, 0.2}},
+		{3, 2, []float64{0.1, 0.02, 0}},
+		{3, 3, []float64{0.1, 0.2, 0.1}},
+		{2, 0, []float64{-0.01, 0.05}},
+		{2, 1, []float64{-0.1, -0.4}},
+		{2, 2, []float64{0.1, 0.02}},
+		{2, 3, []float64{0.1, -0.3}},
+	}
+
+	for k, test := range tests {
+
+		// model
+		var mdl InterfaceM1
+		err := mdl.Init(test.ndim, prms)
+		if err != nil {
+			tst.Errorf("Init failed: %v\n", err)
+			return
+		}
+
+		// update
+		s0, _ := mdl.InitIntVars()
+		s := s0.GetCopy()
+		err = mdl.Update(s, test.Δw)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		io.Pforan("test %d: t = %v  α = %v\n", k, s.Sig, s.Alp)
+		chk.Vector(tst, "w", 1e-15, s.Phi, test.Δw)
+
+		// check state
+		τ := la.VecNorm(s.Sig[1:])
+		switch test.mode {
+		case 0:
+			if s.Loading {
+				tst.Errorf("test %d: update should be elastic\n", k)
+				return
+			}
+			chk.Scalar(tst, "tn", 1e-15, s.Sig[0], 100*test.Δw[0])
+		case 1:
+			if !s.Loading || s.ApexReturn {
+				tst.Errorf("test %d: update should be a return to the shear surface\n", k)
+				return
+			}
+			chk.Scalar(tst, "f1", 1e-13, τ+s.Sig[0]*tφ-10, 0)
+		case 2:
+			if !s.Loading || !s.ApexReturn || s.Dgam > 0 {
+				tst.Errorf("test %d: update should be a return to the tension cut-off\n", k)
+				return
+			}
+			chk.Scalar(tst, "tn ", 1e-15, s.Sig[0], 5)
+			chk.Scalar(tst, "τ  ", 1e-14, τ, 50*la.VecNorm(test.Δw[1:]))
+			chk.Scalar(tst, "ωpn", 1e-15, s.Alp[0], test.Δw[0]-0.05)
+		case 3:
+			if !s.Loading || !s.ApexReturn || s.Dgam <= 0 {
+				tst.Errorf("test %d: update should be a return to the corner\n", k)
+				return
+			}
+			chk.Scalar(tst, "tn", 1e-15, s.Sig[0], 5)
+			chk.Scalar(tst, "τ ", 1e-13, τ, τc)
+		}
+
+		// check consistent tangent
+		D := la.MatAlloc(test.ndim, test.ndim)
+		err = mdl.CalcD(D, s, false)
+		if err != nil {
+			tst.Errorf("CalcD failed: %v\n", err)
+			return
+		}
+		tmp := s0.GetCopy()
+		Δw := make([]float64, test.ndim)
+		for i := 0; i < test.ndim; i++ {
+			for j := 0; j < test.ndim; j++ {
+				dnum := num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+					copy(Δw, test.Δw)
+					Δw[j] = x
+					tmp.Set(s0)
+					mdl.Update(tmp, Δw)
+					return tmp.Sig[i]
+				}, test.Δw[j])
+				chk.AnaNum(tst, io.Sf("D[%d][%d]", i, j), 1e-7, D[i][j], dnum, chk.Verbose)
+			}
+		}
+	}
+}
+
+func Test_interface02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("interface02")
+
+	// wrong parameters
+	wrong := [][]*fun.Prm{
+		{&fun.Prm{N: "kn", V: 0}, &fun.Prm{N: "ks", V: 1}},
+		{&fun.Prm{N: "kn", V: 1}, &fun.Prm{N: "ks", V: 1}, &fun.Prm{N: "phi", V: 20}, &fun.Prm{N: "psi", V: 30}},
+		{&fun.Prm{N: "kn", V: 1}, &fun.Prm{N: "ks", V: 1}, &fun.Prm{N: "c", V: -1}},
+		{&fun.Prm{N: "kn", V: 1}, &fun.Prm{N: "ks", V: 1}, &fun.Prm{N: "c", V: 1}, &fun.Prm{N: "phi", V: 45}, &fun.Prm{N: "sigt", V: 2}},
+	}
+	for i, prms := range wrong {
+		var mdl InterfaceM1
+		if mdl.Init(2, prms) == nil {
+			tst.Errorf("Init with wrong parameters (%d) should have failed\n", i)
+			return
+		}
+	}
+
+	// default parameters
+	var mdl InterfaceM1
+	err := mdl.Init(3, mdl.GetPrms())
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	s, _ := mdl.InitIntVars()
+	chk.IntAssert(len(s.Sig), 3)
+	chk.IntAssert(len(s.Phi), 3)
+	chk.IntAssert(len(s.Alp), 2)
+}
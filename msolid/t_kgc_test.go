@@ -0,0 +1,254 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func check_kgc_derivs(tst *testing.T, name string, prms fun.Prms, σ []float64, tol float64) {
+
+	// calculator
+	kgc := GetKgc(name, prms)
+	if kgc == nil {
+		tst.Errorf("test failed: cannot get kgc named %q\n", name)
+		return
+	}
+
+	// state
+	nsig := len(σ)
+	s := NewState(nsig, 0, 0, false)
+	copy(s.Sig, σ)
+	K, G := kgc.Calc(s)
+	io.Pforan("%s: K = %v, G = %v\n", name, K, G)
+
+	// check derivatives
+	dKdσ := make([]float64, nsig)
+	dGdσ := make([]float64, nsig)
+	kgc.Derivs(dKdσ, dGdσ, s)
+	var tmp float64
+	for i := 0; i < nsig; i++ {
+		dKnum := num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+			tmp, s.Sig[i] = s.Sig[i], x
+			res, _ = kgc.Calc(s)
+			s.Sig[i] = tmp
+			return
+		}, s.Sig[i])
+		dGnum := num.DerivCen(func(x float64, args ...interface{}) (res float64) {
+			tmp, s.Sig[i] = s.Sig[i], x
+			_, res = kgc.Calc(s)
+			s.Sig[i] = tmp
+			return
+		}, s.Sig[i])
+		chk.AnaNum(tst, io.Sf("dKdσ[%d]", i), tol, dKdσ[i], dKnum, chk.Verbose)
+		chk.AnaNum(tst, io.Sf("dGdσ[%d]", i), tol, dGdσ[i], dGnum, chk.Verbose)
+	}
+}
+
+func Test_kgc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc01")
+
+	σ := []float64{-150, -80, -100, 30}
+
+	check_kgc_derivs(tst, "hardin", []*fun.Prm{
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+		&fun.Prm{N: "nu", V: 0.2},
+	}, σ, 1e-5)
+
+	check_kgc_derivs(tst, "duncan-chang", []*fun.Prm{
+		&fun.Prm{N: "kh", V: 300},
+		&fun.Prm{N: "nh", V: 0.5},
+		&fun.Prm{N: "kb", V: 250},
+		&fun.Prm{N: "mb", V: 0.4},
+		&fun.Prm{N: "Rf", V: 0.9},
+		&fun.Prm{N: "c", V: 5},
+		&fun.Prm{N: "phi", V: 30},
+	}, σ, 1e-5)
+
+	check_kgc_derivs(tst, "small-strain", []*fun.Prm{
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "gam07", V: 2e-4},
+	}, σ, 1e-5)
+}
+
+func Test_kgc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc02")
+
+	// allocate driver
+	ndim, pstress := 2, false
+	simfnk, modelname := "test", "lin-elast"
+	var drv Driver
+	err := drv.Init(simfnk, modelname, ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "nu", V: 0.2, Extra: "!kgc:small-strain"},
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+		&fun.Prm{N: "gam07", V: 2e-4},
+	})
+	drv.CheckD = true
+	drv.TolD = 1e-5
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// path
+	p0 := 100.0
+	DP := []float64{20, 40, -10}
+	DQ := []float64{30, 60, 10}
+	nincs := 2
+	niout := 1
+	noise := 0.0
+	K, G := 3e4, 2e4
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, K, G, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+}
+
+func Test_kgc03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc03")
+
+	// small-strain calculator
+	prms := []*fun.Prm{
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+		&fun.Prm{N: "nu", V: 0.2},
+		&fun.Prm{N: "gam07", V: 2e-4},
+	}
+	kgc := GetKgc("small-strain", prms).(*KgcSmallStrain)
+	hyperbolic_x := func(s *State) float64 {
+		_, G0 := kgc.KgcHardin.Calc(s)
+		return kgc.A * tsr.M_q(s.Sig) / (math.Sqrt(3.0) * G0 * kgc.Gam07)
+	}
+
+	// derivatives in the hyperbolic branch; i.e. without cut-off
+	σ := []float64{-100, -95, -105, 5}
+	s := NewState(len(σ), 0, 0, false)
+	copy(s.Sig, σ)
+	x := hyperbolic_x(s)
+	io.Pforan("x = %v (xmax = %v)\n", x, kgc.xmax)
+	if x <= 0 || x >= kgc.xmax {
+		tst.Errorf("test failed: stress state must be in the hyperbolic branch: x = %v\n", x)
+		return
+	}
+	check_kgc_derivs(tst, "small-strain", prms, σ, 1e-5)
+
+	// allocate driver
+	ndim, pstress := 2, false
+	var drv Driver
+	err := drv.Init("test", "lin-elast", ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "E", V: 1e4},
+		&fun.Prm{N: "nu", V: 0.2, Extra: "!kgc:small-strain"},
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+		&fun.Prm{N: "gam07", V: 2e-4},
+	})
+	drv.CheckD = true
+	drv.TolD = 1e-5
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// path: small deviatoric stresses such that the hyperbolic branch is used everywhere
+	p0 := 100.0
+	DP := []float64{5, 10}
+	DQ := []float64{8, 12}
+	nincs := 4
+	niout := 1
+	noise := 0.0
+	K, G := 6.6667e4, 5e4
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, K, G, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// check branch
+	for i, res := range drv.Res {
+		x = hyperbolic_x(res)
+		if x < 0 || x >= kgc.xmax {
+			tst.Errorf("test failed: state %d is not in the hyperbolic branch: x = %v\n", i, x)
+			return
+		}
+	}
+	io.Pforan("x @ end = %v\n", x)
+	if x < 0.1 {
+		tst.Errorf("test failed: shear modulus must degrade: x = %v\n", x)
+	}
+}
+
+func Test_kgc04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("kgc04")
+
+	// K and G from calculator: E is not required
+	prms := []*fun.Prm{
+		&fun.Prm{N: "nu", V: 0.25, Extra: "!kgc:hardin"},
+		&fun.Prm{N: "G0", V: 5e4},
+		&fun.Prm{N: "pref", V: 100},
+		&fun.Prm{N: "ng", V: 0.5},
+	}
+	var le LinElast
+	err := le.Init(3, false, prms)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	if le.Kgc == nil || le.Iphi != 0 {
+		tst.Errorf("test failed: lin-elast must use the kgc calculator\n")
+		return
+	}
+
+	// models that do not support non-linear K and G must reject the calculator
+	for _, name := range []string{"vm", "dp", "mc", "dam"} {
+		mdl := allocators[name]()
+		err = mdl.Init(3, false, append(prms, &fun.Prm{N: "E", V: 1e4}))
+		io.Pforan("%s: %v\n", name, err)
+		if err == nil {
+			tst.Errorf("test failed: %s must reject non-linear K and G\n", name)
+			return
+		}
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+func Test_mc01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc01")
+
+	// allocate driver
+	ndim, pstress := 2, false
+	simfnk, modelname := "test", "mc"
+	var drv Driver
+	err := drv.Init(simfnk, modelname, ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "c", V: 1},
+		&fun.Prm{N: "phi", V: 30},
+		&fun.Prm{N: "psi", V: 10},
+		&fun.Prm{N: "H", V: 0.5},
+	})
+	drv.CheckD = true
+	drv.TolD = 1e-6
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// mc model
+	mc := drv.model.(*MohrCoulomb)
+
+	// path: triaxial compression followed by extension
+	p0 := 0.0
+	DP := []float64{2, 3, 1, -1, 0}
+	DQ := []float64{4, 2, -3, -4, 3}
+	nincs := 1
+	niout := 1
+	noise := 1e-3
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, mc.K, mc.G, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// check yield condition at the end of plastic steps
+	for _, s := range drv.Res {
+		if !s.Loading {
+			continue
+		}
+		err = mc.principal(s.Sig)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		c := mc.c + mc.H*s.Alp[0]
+		chk.Scalar(tst, "f", 1e-10, mc.yieldmax(mc.σtr, c), 0)
+	}
+}
+
+func Test_mc02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc02")
+
+	// allocate driver
+	ndim, pstress := 3, false
+	simfnk, modelname := "test", "mc"
+	var drv Driver
+	err := drv.Init(simfnk, modelname, ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "c", V: 1},
+		&fun.Prm{N: "phi", V: 20},
+		&fun.Prm{N: "psi", V: 0},
+		&fun.Prm{N: "sigt", V: 0.5},
+		&fun.Prm{N: "H", V: 0.2},
+	})
+	drv.CheckD = true
+	drv.TolD = 1e-6
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// mc model
+	mc := drv.model.(*MohrCoulomb)
+
+	// path: tension => cut-off and apex
+	p0 := 0.0
+	DP := []float64{-0.3, -0.5, -1, 2}
+	DQ := []float64{0.2, 0.5, 0, 3}
+	nincs := 1
+	niout := 1
+	noise := 1e-3
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, mc.K, mc.G, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// check stresses
+	for _, s := range drv.Res {
+		err = mc.principal(s.Sig)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		if mc.σtr[0] > mc.σt+1e-10 {
+			tst.Errorf("test failed: tension cut-off is violated: σ1 = %g > %g\n", mc.σtr[0], mc.σt)
+			return
+		}
+	}
+}
+
+// mc_update runs the return mapping of mc with the elastic trial stresses σtr (ordered
+// principal values along x, y and z) and returns the ordered principal updated stresses
+func mc_update(tst *testing.T, mc *MohrCoulomb, σtr []float64) (s *State, σp []float64) {
+	s, err := mc.InitIntVars()
+	if err != nil {
+		tst.Errorf("InitIntVars failed: %v\n", err)
+		return
+	}
+	Δε := make([]float64, mc.Nsig)
+	p := (σtr[0] + σtr[1] + σtr[2]) / 3.0
+	for i := 0; i < 3; i++ {
+		Δε[i] = (σtr[i]-p)/(2.0*mc.G) + p/(3.0*mc.K)
+	}
+	err = mc.Update(s, Δε, Δε)
+	if err != nil {
+		tst.Errorf("Update failed: %v\n", err)
+		return
+	}
+	err = mc.principal(s.Sig)
+	if err != nil {
+		tst.Errorf("principal failed: %v\n", err)
+		return
+	}
+	σp = make([]float64, 3)
+	copy(σp, mc.σtr)
+	return
+}
+
+// mc_check_set checks the active set of a return mapping; iset < 0 means apex
+func mc_check_set(tst *testing.T, s *State, nsig, iset int) {
+	if !s.Loading {
+		tst.Errorf("plastic loading was expected\n")
+		return
+	}
+	if iset < 0 {
+		if !s.ApexReturn {
+			tst.Errorf("return to apex was expected. active set = %v\n", s.Phi[nsig])
+		}
+		return
+	}
+	if s.ApexReturn {
+		tst.Errorf("return to apex was not expected\n")
+		return
+	}
+	if int(s.Phi[nsig]) != iset {
+		tst.Errorf("active set %v is incorrect. %v was expected\n", mcActiveSets[int(s.Phi[nsig])], mcActiveSets[iset])
+	}
+}
+
+func Test_mc03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc03")
+
+	// model: perfectly plastic; without tension cut-off
+	var mc MohrCoulomb
+	K, G, c, φ, ψ := 1.5, 1.0, 1.0, 30.0, 10.0
+	err := mc.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "c", V: c},
+		&fun.Prm{N: "phi", V: φ},
+		&fun.Prm{N: "psi", V: ψ},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// closed-form solutions; e.g. de Souza Neto, Peric and Owen (2008) Box 8.5
+	sφ, cφ, sψ := math.Sin(φ*math.Pi/180.0), math.Cos(φ*math.Pi/180.0), math.Sin(ψ*math.Pi/180.0)
+	a1 := 2.0*G*(1.0+sψ/3.0) + 2.0*K*sψ
+	a2 := (4.0*G/3.0 - 2.0*K) * sψ
+	a3 := 2.0*G*(1.0-sψ/3.0) - 2.0*K*sψ
+	a := 4.0*G*(1.0+sφ*sψ/3.0) + 4.0*K*sφ*sψ
+	yield := func(σa, σb float64) float64 { return σa - σb + (σa+σb)*sφ - 2.0*c*cφ }
+	edge := func(fa, fb, b float64) (Δγa, Δγb float64) {
+		Δγa = (a*fa - b*fb) / (a*a - b*b)
+		Δγb = (a*fb - b*fa) / (a*a - b*b)
+		return
+	}
+
+	// main plane
+	t := []float64{1, 0, -3}
+	s, σp := mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 0)
+	Δγ := yield(t[0], t[2]) / a
+	chk.Vector(tst, "σ: main plane", 1e-10, σp, []float64{t[0] - a1*Δγ, t[1] + a2*Δγ, t[2] + a3*Δγ})
+
+	// edge with σ2 = σ3 (triaxial compression)
+	t = []float64{1, -2.8, -3}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 1)
+	Δγa, Δγb := edge(yield(t[0], t[2]), yield(t[0], t[1]), 2.0*G*(1.0+sφ+sψ-sφ*sψ/3.0)+4.0*K*sφ*sψ)
+	chk.Vector(tst, "σ: edge σ2=σ3", 1e-10, σp, []float64{
+		t[0] - a1*(Δγa+Δγb),
+		t[1] + a2*Δγa + a3*Δγb,
+		t[2] + a3*Δγa + a2*Δγb,
+	})
+	chk.Scalar(tst, "σ2 - σ3", 1e-10, σp[1]-σp[2], 0)
+
+	// edge with σ1 = σ2 (triaxial extension)
+	t = []float64{2, 1.8, -3}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 2)
+	Δγa, Δγb = edge(yield(t[0], t[2]), yield(t[1], t[2]), 2.0*G*(1.0-sφ-sψ-sφ*sψ/3.0)+4.0*K*sφ*sψ)
+	chk.Vector(tst, "σ: edge σ1=σ2", 1e-10, σp, []float64{
+		t[0] - a1*Δγa + a2*Δγb,
+		t[1] + a2*Δγa - a1*Δγb,
+		t[2] + a3*(Δγa+Δγb),
+	})
+	chk.Scalar(tst, "σ1 - σ2", 1e-10, σp[0]-σp[1], 0)
+
+	// apex
+	t = []float64{3, 2.9, 2.8}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, -1)
+	pa := c * cφ / sφ
+	chk.Vector(tst, "σ: apex", 1e-10, σp, []float64{pa, pa, pa})
+	chk.Scalar(tst, "Δεv", 1e-10, s.Dgam, ((t[0]+t[1]+t[2])/3.0-pa)/K)
+}
+
+func Test_mc04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc04")
+
+	// model: perfectly plastic; with tension cut-off
+	var mc MohrCoulomb
+	K, G, c, φ, ψ, σt := 1.5, 1.0, 1.0, 30.0, 10.0, 0.5
+	err := mc.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "c", V: c},
+		&fun.Prm{N: "phi", V: φ},
+		&fun.Prm{N: "psi", V: ψ},
+		&fun.Prm{N: "sigt", V: σt},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// closed-form solutions
+	sφ, cφ, sψ := math.Sin(φ*math.Pi/180.0), math.Cos(φ*math.Pi/180.0), math.Sin(ψ*math.Pi/180.0)
+	//  De = λ 1⊗1 + 2 G I; M = λ + 2 G; σ3c is σ3 on the main plane when σ1 = σt
+	λ, M := K-2.0*G/3.0, K+4.0*G/3.0
+	σ3c := (σt*(1.0+sφ) - 2.0*c*cφ) / (1.0 - sφ)
+
+	// cut-off of σ1
+	t := []float64{1, 0, -1}
+	s, σp := mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 3)
+	Δγ := (t[0] - σt) / M
+	chk.Vector(tst, "σ: cut-off σ1", 1e-10, σp, []float64{σt, t[1] - λ*Δγ, t[2] - λ*Δγ})
+
+	// cut-off of σ1 and σ2
+	t = []float64{1, 0.9, 0}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 4)
+	Δγ = (t[0] + t[1] - 2.0*σt) / (M + λ) // sum of both multipliers
+	chk.Vector(tst, "σ: cut-off σ1 and σ2", 1e-10, σp, []float64{σt, σt, t[2] - λ*Δγ})
+
+	// cut-off of all principal stresses
+	t = []float64{1.2, 1.1, 0.9}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 5)
+	chk.Vector(tst, "σ: cut-off σ1, σ2 and σ3", 1e-10, σp, []float64{σt, σt, σt})
+
+	// main plane and cut-off of σ1
+	//  σ1 = σ1tr - a1 Δγm - M Δγt = σt
+	//  σ3 = σ3tr + a3 Δγm - λ Δγt = σ3c
+	t = []float64{1, 0.6, -2}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 6)
+	a1 := 2.0*G*(1.0+sψ/3.0) + 2.0*K*sψ
+	a2 := (4.0*G/3.0 - 2.0*K) * sψ
+	a3 := 2.0*G*(1.0-sψ/3.0) - 2.0*K*sψ
+	r1, r3 := t[0]-σt, σ3c-t[2]
+	det := a1*λ + a3*M
+	Δγm := (r1*λ + r3*M) / det
+	Δγt := (a1*r3 - a3*r1) / -det
+	chk.Vector(tst, "σ: main plane and cut-off σ1", 1e-10, σp, []float64{σt, t[1] + a2*Δγm - λ*Δγt, σ3c})
+
+	// edge σ2 = σ3 and cut-off of σ1
+	t = []float64{2, -1.9, -2}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 7)
+	chk.Vector(tst, "σ: edge σ2=σ3 and cut-off σ1", 1e-10, σp, []float64{σt, σ3c, σ3c})
+
+	// edge σ1 = σ2 and cut-off of σ1
+	t = []float64{0.9, 0.7, -2.2}
+	s, σp = mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, 8)
+	chk.Vector(tst, "σ: edge σ1=σ2 and cut-off σ1", 1e-10, σp, []float64{σt, σt, σ3c})
+}
+
+func Test_mc05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mc05")
+
+	// model: with hardening of cohesion; without tension cut-off
+	var mc MohrCoulomb
+	K, G, c0, φ, ψ, H := 1.5, 1.0, 1.0, 30.0, 10.0, 0.5
+	err := mc.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "c", V: c0},
+		&fun.Prm{N: "phi", V: φ},
+		&fun.Prm{N: "psi", V: ψ},
+		&fun.Prm{N: "H", V: H},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// the hardening variable must be α = cos(φ)/sin(ψ)・Δεv^p for returns to planes, edges and apex
+	sφ, cφ, sψ := math.Sin(φ*math.Pi/180.0), math.Cos(φ*math.Pi/180.0), math.Sin(ψ*math.Pi/180.0)
+	hv := cφ / sψ
+	for _, t := range [][]float64{{1, 0, -3}, {1, -2.8, -3}, {2, 1.8, -3}, {3, 2.9, 2.8}} {
+		s, σp := mc_update(tst, &mc, t)
+		if !s.Loading {
+			tst.Errorf("plastic loading was expected\n")
+			return
+		}
+		Δεvp := ((t[0] + t[1] + t[2]) - (σp[0] + σp[1] + σp[2])) / (3.0 * K)
+		chk.Scalar(tst, "α", 1e-10, s.Alp[0], hv*Δεvp)
+		c := c0 + H*s.Alp[0]
+		chk.Scalar(tst, "f", 1e-10, mc.yieldmax(σp, c), 0)
+	}
+
+	// apex: closed-form solution
+	t := []float64{3, 2.9, 2.8}
+	s, σp := mc_update(tst, &mc, t)
+	mc_check_set(tst, s, mc.Nsig, -1)
+	cotφ := cφ / sφ
+	Δεv := ((t[0]+t[1]+t[2])/3.0 - c0*cotφ) / (K + H*hv*cotφ)
+	pa := (c0 + H*hv*Δεv) * cotφ
+	chk.Scalar(tst, "Δεv", 1e-10, s.Dgam, Δεv)
+	chk.Vector(tst, "σ: apex", 1e-10, σp, []float64{pa, pa, pa})
+
+	// errors: apex with hardening and without dilatancy
+	err = mc.Init(3, false, []*fun.Prm{
+		&fun.Prm{N: "K", V: K},
+		&fun.Prm{N: "G", V: G},
+		&fun.Prm{N: "c", V: c0},
+		&fun.Prm{N: "phi", V: φ},
+		&fun.Prm{N: "psi", V: 0},
+		&fun.Prm{N: "H", V: H},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	s, err = mc.InitIntVars()
+	if err != nil {
+		tst.Errorf("InitIntVars failed: %v\n", err)
+		return
+	}
+	Δε := []float64{1, 1, 1, 0, 0, 0}
+	if mc.Update(s, Δε, Δε) == nil {
+		tst.Errorf("Update should have failed because psi is zero\n")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_oned01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("oned01")
+
+	// bilinear model: E=1000, sY=2, H=250 => εy = 0.002 and Et = 200
+	mdl := GetOnedSolid("test", "bilinear", "oned-bilinear", true)
+	if err := mdl.Init(1, fun.Prms{&fun.Prm{N: "E", V: 1000}, &fun.Prm{N: "sY", V: 2}, &fun.Prm{N: "H", V: 250}}); err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+
+	// loading, unloading and reverse yielding
+	εs := []float64{0, 0.001, 0.002, 0.003, 0.004, 0.002, 0, -0.0008, -0.002, -0.004}
+	σs := []float64{0, 1, 2, 2.2, 2.4, 0.4, -1.6, -2.4, -2.64, -3.04}
+	Ds := []float64{1000, 1000, 1000, 200, 200, 1000, 1000, 1000, 200, 200}
+	oned_check_path(tst, mdl, εs, σs, Ds, 1e-12)
+}
+
+func Test_oned02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("oned02")
+
+	// cable: E=1000, sY=3
+	mdl := GetOnedSolid("test", "cable", "oned-cable", true)
+	if err := mdl.Init(1, fun.Prms{&fun.Prm{N: "E", V: 1000}, &fun.Prm{N: "sY", V: 3}}); err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	εs := []float64{0, -0.001, 0.001, 0.003, 0.004, 0.002, 0.001, 0, 0.0015}
+	σs := []float64{0, 0, 1, 3, 3, 1, 0, 0, 0.5}
+	Ds := []float64{0, 0, 1000, 1000, 0, 1000, 1000, 0, 1000} // unstressed @ ε=εp=0.001 => active
+	oned_check_path(tst, mdl, εs, σs, Ds, 1e-12)
+
+	// strut with slack modulus: E=1000, Eslack=10
+	mdl = GetOnedSolid("test", "strut", "oned-strut", true)
+	if err := mdl.Init(1, fun.Prms{&fun.Prm{N: "E", V: 1000}, &fun.Prm{N: "Eslack", V: 10}}); err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+	εs = []float64{0, 0.001, -0.001, -0.002, 0.002}
+	σs = []float64{0, 0.01, -1, -2, 0.02}
+	Ds = []float64{10, 10, 1000, 1000, 10}
+	oned_check_path(tst, mdl, εs, σs, Ds, 1e-12)
+
+	// errors
+	if mdl.Init(1, fun.Prms{&fun.Prm{N: "E", V: 0}}) == nil {
+		tst.Errorf("Init should have failed because E is zero\n")
+	}
+}
+
+// oned_check_path updates model along a path of strains and checks stresses and moduli
+func oned_check_path(tst *testing.T, mdl OnedSolid, εs, σs, Ds []float64, tol float64) {
+	s, err := mdl.InitIntVars()
+	if err != nil {
+		tst.Errorf("InitIntVars failed: %v\n", err)
+		return
+	}
+	for i := 1; i < len(εs); i++ {
+		err = mdl.Update(s, εs[i], εs[i]-εs[i-1])
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		D, err := mdl.CalcD(s, false)
+		if err != nil {
+			tst.Errorf("CalcD failed: %v\n", err)
+			return
+		}
+		io.Pforan("ε=%8.5f σ=%8.5f D=%g\n", εs[i], s.Sig, D)
+		chk.Scalar(tst, io.Sf("σ @ ε=%g", εs[i]), tol, s.Sig, σs[i])
+		chk.Scalar(tst, io.Sf("D @ ε=%g", εs[i]), tol, D, Ds[i])
+	}
+}
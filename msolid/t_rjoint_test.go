@@ -0,0 +1,106 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_rjoint01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rjoint01")
+
+	// CEB-FIP: τmax=10, τf=4, s1=0.1, s2=0.2, s3=0.5, α=0.4
+	mdl := GetRjointModel("test", "cebfip", "rjoint-cebfip", true)
+	if mdl == nil {
+		tst.Errorf("cannot get model\n")
+		return
+	}
+	err := mdl.Init(fun.Prms{
+		&fun.Prm{N: "ks", V: 1000},
+		&fun.Prm{N: "taumax", V: 10},
+		&fun.Prm{N: "tauf", V: 4},
+		&fun.Prm{N: "s1", V: 0.1},
+		&fun.Prm{N: "s2", V: 0.2},
+		&fun.Prm{N: "s3", V: 0.5},
+		&fun.Prm{N: "alp", V: 0.4},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+
+	// ascending, plateau, softening, unloading, reloading, residual and reversal
+	ωs := []float64{0, 0.05, 0.15, 0.35, 0.345, 0.349, 0.36, 0.6, 0.595, 0.59}
+	τs := []float64{0, 7.578582832551990, 10, 7, 2, 6, 6.8, 4, -1, -4}
+	Ds := []float64{1000, 60.62866266041592, 0, -20, 1000, 1000, -20, 0, 1000, 0}
+	σcs := make([]float64, len(ωs))
+	rjoint_check_path(tst, mdl, ωs, σcs, τs, Ds, nil, 1e-10)
+}
+
+func Test_rjoint02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rjoint02")
+
+	// hyperbolic: ks=100, τu0=10, μ=0.5
+	mdl := GetRjointModel("test", "hyperbolic", "rjoint-hyperbolic", true)
+	if mdl == nil {
+		tst.Errorf("cannot get model\n")
+		return
+	}
+	err := mdl.Init(fun.Prms{
+		&fun.Prm{N: "ks", V: 100},
+		&fun.Prm{N: "tauu", V: 10},
+		&fun.Prm{N: "mu", V: 0.5},
+	})
+	if err != nil {
+		tst.Errorf("Init failed: %v\n", err)
+		return
+	}
+
+	// virgin loading with σc=4 => τu=12; unloading; reloading with σc=0 up to the envelope at ωmax
+	ωs := []float64{0, 0.1, 0.09, 0.099}
+	σcs := []float64{4, 4, 4, -3}
+	τs := []float64{0, 120.0 / 22.0, 120.0/22.0 - 1, 5}
+	Ds := []float64{100, 14400.0 / 484.0, 100, 0}
+	DσcS := []float64{0, 0.5 * 100.0 / 484.0, 0, 0}
+	rjoint_check_path(tst, mdl, ωs, σcs, τs, Ds, DσcS, 1e-10)
+}
+
+// rjoint_check_path runs a slip path and compares bond stresses and derivatives
+func rjoint_check_path(tst *testing.T, mdl RjointModel, ωs, σcs, τs, Ds, DσcS []float64, tol float64) {
+	s, err := mdl.InitIntVars()
+	if err != nil {
+		tst.Errorf("InitIntVars failed: %v\n", err)
+		return
+	}
+	chk.IntAssert(len(s.Phi), 2)
+	ω := 0.0
+	for i, ωnew := range ωs {
+		err = mdl.Update(s, σcs[i], ωnew-ω)
+		if err != nil {
+			tst.Errorf("Update failed: %v\n", err)
+			return
+		}
+		ω = ωnew
+		DτDω, DτDσc, err := mdl.CalcD(s, false)
+		if err != nil {
+			tst.Errorf("CalcD failed: %v\n", err)
+			return
+		}
+		io.Pforan("ω=%8.5f τ=%10.6f DτDω=%10.5f DτDσc=%10.7f\n", ω, s.Sig, DτDω, DτDσc)
+		chk.Scalar(tst, io.Sf("τ(ω=%g)", ω), tol, s.Sig, τs[i])
+		chk.Scalar(tst, io.Sf("DτDω(ω=%g)", ω), tol, DτDω, Ds[i])
+		if DσcS != nil {
+			chk.Scalar(tst, io.Sf("DτDσc(ω=%g)", ω), tol, DτDσc, DσcS[i])
+		}
+	}
+}
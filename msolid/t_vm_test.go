@@ -9,6 +9,8 @@ import (
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
 )
 
 func Test_vm01(tst *testing.T) {
@@ -63,3 +65,179 @@ func Test_vm01(tst *testing.T) {
 	//if DPsaveFig {
 	//}
 }
+
+func Test_vm02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vm02")
+
+	// stress-controlled cycles with non-zero mean stresses: p = 1 and q = 0 → 3 → -1.5 → 3 ...
+	ndim, pstress := 2, false
+	p0 := 1.0
+	ncycles := 6
+	DP := make([]float64, 2*ncycles)
+	DQ := make([]float64, 2*ncycles)
+	DQ[0] = 3
+	for i := 1; i < len(DQ); i++ {
+		DQ[i] = 4.5
+		if i%2 == 1 {
+			DQ[i] = -4.5
+		}
+	}
+	nincs := 20
+	niout := 1
+	var pth Path
+	err := pth.SetPQstress(ndim, nincs, niout, p0, DP, DQ)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run simulation and return accumulated plastic strain α and axial strain εz at the end of each loading stage (q = 3)
+	run := func(prms fun.Prms) (drv *Driver, α, εz []float64) {
+		drv = new(Driver)
+		err := drv.Init("test", "vm", ndim, pstress, prms)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		err = drv.Run(&pth)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		for i := 1; i < pth.Size(); i += 2 {
+			k := i * nincs
+			α = append(α, drv.Res[k].Alp[0])
+			εz = append(εz, drv.Eps[k][2])
+		}
+		io.Pforan("α  = %v\n", α)
+		io.Pforan("εz = %v\n", εz)
+
+		// check stresses
+		for i := 1; i < pth.Size(); i++ {
+			σ := drv.Res[i*nincs].Sig
+			chk.Scalar(tst, "p", 1e-9, tsr.M_p(σ), p0)
+			chk.Scalar(tst, "σz", 1e-9, σ[2], pth.Sz[i])
+		}
+		return
+	}
+
+	// Armstrong-Frederick kinematic hardening: qy0 + C/γk = 4 > 3 but the stress range 4.5 is greater
+	// than 2 qy0; thus the back-stress is reversed at every cycle and the accumulated plastic strain
+	// grows at a constant rate (ratcheting of εz)
+	io.Pf("\nArmstrong-Frederick\n")
+	drv, α, εz := run(fun.Prms{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "C", V: 3},
+		&fun.Prm{N: "gamk", V: 1.5},
+	})
+	if drv == nil || len(α) != ncycles {
+		return
+	}
+	vm := drv.model.(*VonMises)
+	Δα0 := α[1] - α[0]
+	for c := 1; c < ncycles; c++ {
+		Δα := α[c] - α[c-1]
+		if Δα < 0.9*Δα0 || Δα0 < 0.1 {
+			tst.Errorf("test failed: accumulated plastic strain must grow under Armstrong-Frederick hardening. Δα = %g\n", Δα)
+			return
+		}
+		if εz[c] > εz[c-1]-0.01 {
+			tst.Errorf("test failed: axial strain must ratchet. εz = %g (previous = %g)\n", εz[c], εz[c-1])
+			return
+		}
+	}
+	βmax := vm.C / vm.γk
+	for k := 1; k < len(drv.Res); k++ {
+		if drv.Res[k].Alp[0] < drv.Res[k-1].Alp[0] {
+			tst.Errorf("test failed: accumulated plastic strain must not decrease\n")
+			return
+		}
+		qβ := tsr.M_q(drv.Res[k].Alp[1:])
+		if qβ > βmax+1e-12 {
+			tst.Errorf("test failed: back-stress %g is greater than saturation value %g\n", qβ, βmax)
+			return
+		}
+	}
+
+	// isotropic (Voce) hardening only (C = 0): the elastic domain expands to qy = 3 during the first
+	// loading stage; afterwards, the response is elastic (shakedown)
+	io.Pf("\nVoce, C = 0\n")
+	drv, α, εz = run(fun.Prms{
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.1},
+		&fun.Prm{N: "Qinf", V: 1.5},
+		&fun.Prm{N: "b", V: 2},
+	})
+	if drv == nil || len(α) != ncycles {
+		return
+	}
+	vm = drv.model.(*VonMises)
+	qy, _ := vm.qy(α[0])
+	chk.Scalar(tst, "qy", 1e-9, qy, 3)
+	for c := 1; c < ncycles; c++ {
+		chk.Scalar(tst, io.Sf("α @ cycle %d", c), 1e-12, α[c], α[0])
+		chk.Scalar(tst, io.Sf("εz @ cycle %d", c), 1e-10, εz[c], εz[0])
+	}
+}
+
+func Test_vm03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vm03")
+
+	// allocate driver
+	ndim, pstress := 2, true
+	simfnk, modelname := "test", "vm"
+	var drv Driver
+	err := drv.Init(simfnk, modelname, ndim, pstress, []*fun.Prm{
+		&fun.Prm{N: "E", V: 2.5},
+		&fun.Prm{N: "nu", V: 0.25},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.2},
+		&fun.Prm{N: "Qinf", V: 0.5},
+		&fun.Prm{N: "b", V: 2},
+		&fun.Prm{N: "C", V: 1.2},
+		&fun.Prm{N: "gamk", V: 3},
+	})
+	drv.CheckD = true
+	drv.TolD = 1e-7
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// vm model
+	vm := drv.model.(*VonMises)
+
+	// path
+	p0 := 0.0
+	DP := []float64{1, 2, -1, 0}
+	DQ := []float64{3, 4, -4, 4}
+	nincs := 2
+	niout := 1
+	noise := 0.1
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, vm.K, vm.G, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// check plane-stress condition
+	for _, s := range drv.Res {
+		chk.Scalar(tst, "σzz", 1e-10, s.Sig[2], 0)
+	}
+}
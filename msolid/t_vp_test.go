@@ -0,0 +1,224 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/tsr"
+)
+
+func run_vp_test(tst *testing.T, mdl string, N, Δt float64) (drv *Driver) {
+
+	// allocate driver
+	ndim, pstress := 2, false
+	simfnk, modelname := "test", "vp"
+	drv = new(Driver)
+	prms := []*fun.Prm{
+		&fun.Prm{N: "fluid", V: 0.5, Extra: "!mdl:" + mdl},
+		&fun.Prm{N: "N", V: N},
+		&fun.Prm{N: "sig0", V: 2},
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0.5},
+	}
+	if mdl == "dp" {
+		prms = append(prms, &fun.Prm{N: "M", V: 0.5}, &fun.Prm{N: "Mb", V: 0.5})
+	}
+	err := drv.Init(simfnk, modelname, ndim, pstress, prms)
+	drv.CheckD = true
+	drv.TolD = 1e-7
+	drv.Dt = Δt
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// path
+	p0 := 0.0
+	DP := []float64{3, 3, 2, 1, 0}
+	DQ := []float64{3, 4, 2, 1, 3}
+	nincs := 1
+	niout := 1
+	noise := 0.0
+	var pth Path
+	err = pth.SetPQstrain(ndim, nincs, niout, 1.5, 1, p0, DP, DQ, noise)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// run
+	err = drv.Run(&pth)
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+	}
+	return
+}
+
+func Test_vp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vp01")
+
+	// Duvaut-Lions and power law over von Mises and Drucker-Prager
+	for _, mdl := range []string{"vm", "dp"} {
+		for _, N := range []float64{1, 2.5} {
+			run_vp_test(tst, mdl, N, 0.2)
+		}
+	}
+}
+
+func Test_vp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vp02")
+
+	// very small and very large time increments
+	drv0 := run_vp_test(tst, "vm", 1, 1e-8)
+	drv1 := run_vp_test(tst, "vm", 1, 1e+8)
+	if drv0 == nil || drv1 == nil {
+		return
+	}
+
+	// first increment: viscous => elastic response; inviscid => on yield surface
+	ε := drv0.Eps[1]
+	trε := ε[0] + ε[1] + ε[2]
+	σe := make([]float64, 4)
+	for i := 0; i < 4; i++ {
+		σe[i] = 1.5*trε*tsr.Im[i] + 2.0*(ε[i]-trε*tsr.Im[i]/3.0)
+	}
+	chk.Vector(tst, "σ(Δt→0)", 1e-7, drv0.Res[1].Sig, σe)
+	qy := 2.0 + 0.5*drv1.Res[1].Alp[0]
+	chk.Scalar(tst, "q(Δt→∞)", 1e-7, tsr.M_q(drv1.Res[1].Sig), qy)
+}
+
+// vp_vm_driver allocates a driver for the viscoplastic model over perfectly plastic von Mises
+func vp_vm_driver(tst *testing.T, fluid, N, σ0, Δt float64) (drv *Driver) {
+	drv = new(Driver)
+	err := drv.Init("test", "vp", 2, false, []*fun.Prm{
+		&fun.Prm{N: "fluid", V: fluid, Extra: "!mdl:vm"},
+		&fun.Prm{N: "N", V: N},
+		&fun.Prm{N: "sig0", V: σ0},
+		&fun.Prm{N: "K", V: 1.5},
+		&fun.Prm{N: "G", V: 1},
+		&fun.Prm{N: "qy0", V: 2},
+		&fun.Prm{N: "H", V: 0},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return nil
+	}
+	drv.Dt = Δt
+	return
+}
+
+// vp_εq returns the deviatoric strain invariant εq = sqrt(2/3)・|dev(ε)|
+func vp_εq(ε []float64) float64 {
+	trε := ε[0] + ε[1] + ε[2]
+	var sum float64
+	for i := 0; i < len(ε); i++ {
+		e := ε[i] - trε*tsr.Im[i]/3.0
+		sum += e * e
+	}
+	return math.Sqrt(2.0 * sum / 3.0)
+}
+
+func Test_vp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vp03")
+
+	// creep at constant stress with q > qy: since the stress is constant, the viscoplastic strain
+	// rate is constant; i.e. dεq/dt = γ σ0 (sqrt(2/3) (q - qy) / σ0)^N / (sqrt(2/3) 3 G)
+	fluid, σ0, Δt, G, qy, q := 0.5, 2.0, 0.1, 1.0, 2.0, 3.0
+	nhold := 10
+	DP, DQ := make([]float64, 1+nhold), make([]float64, 1+nhold)
+	DP[0], DQ[0] = 1, q
+	for _, N := range []float64{1, 2.5} {
+		drv := vp_vm_driver(tst, fluid, N, σ0, Δt)
+		if drv == nil {
+			return
+		}
+		var pth Path
+		err := pth.SetPQstress(2, 1, 1, 0, DP, DQ)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		err = drv.Run(&pth)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		x := math.Sqrt(2.0/3.0) * (q - qy)
+		rate := fluid * σ0 * math.Pow(x/σ0, N) / (math.Sqrt(2.0/3.0) * 3.0 * G)
+		for k := 2; k < len(drv.Res); k++ {
+			chk.Scalar(tst, "q", 1e-8, tsr.M_q(drv.Res[k].Sig), q)
+			chk.Scalar(tst, io.Sf("Δεq/Δt (N=%g)", N), 1e-7, (vp_εq(drv.Eps[k])-vp_εq(drv.Eps[k-1]))/Δt, rate)
+		}
+	}
+}
+
+func Test_vp04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("vp04")
+
+	// relaxation at held strain: the overstress x = sqrt(2/3) (q - qy) decays according to
+	//  dx/dt = -γ σ0 (x / σ0)^N
+	// i.e. x = x0 exp(-t/τ) with τ = 1/γ if N = 1; otherwise x = σ0 ((x0/σ0)^(1-N) + (N-1) t/τ)^(1/(1-N))
+	fluid, σ0, Δt, qy := 0.5, 2.0, 0.01, 2.0
+	τ := 1.0 / fluid
+	nhold := 300
+	DP, DQ := make([]float64, 1+nhold), make([]float64, 1+nhold)
+	DQ[0] = 6
+	for _, N := range []float64{1, 2.5} {
+		drv := vp_vm_driver(tst, fluid, N, σ0, Δt)
+		if drv == nil {
+			return
+		}
+		var pth Path
+		err := pth.SetPQstrain(2, 1, 1, 1.5, 1, 0, DP, DQ, 0)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		err = drv.Run(&pth)
+		if err != nil {
+			tst.Errorf("test failed: %v\n", err)
+			return
+		}
+		x0 := math.Sqrt(2.0/3.0) * (tsr.M_q(drv.Res[1].Sig) - qy)
+		xprev := x0
+		for k := 2; k < len(drv.Res); k++ {
+
+			// implicit (backward Euler) update: x_{n} - x_{n+1} = γ Δt σ0 (x_{n+1} / σ0)^N
+			x := math.Sqrt(2.0/3.0) * (tsr.M_q(drv.Res[k].Sig) - qy)
+			if x <= 0 || x >= xprev {
+				tst.Errorf("test failed: overstress must decay towards the inviscid surface: x=%g, xprev=%g\n", x, xprev)
+				return
+			}
+			chk.Scalar(tst, io.Sf("Δx (N=%g)", N), 1e-10, xprev-x, Δt*σ0*math.Pow(x/σ0, N)/τ)
+			xprev = x
+
+			// analytical solution
+			t := float64(k-1) * Δt
+			xana := x0 * math.Exp(-t/τ)
+			if N != 1 {
+				xana = σ0 * math.Pow(math.Pow(x0/σ0, 1.0-N)+(N-1.0)*t/τ, 1.0/(1.0-N))
+			}
+			if math.Abs(x-xana) > 1e-2*x0 {
+				tst.Errorf("test failed: overstress x=%g is different from analytical x=%g @ t=%g (N=%g)\n", x, xana, t, N)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package msolid
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ViscoPlastic implements a viscoplastic regularisation of rate-independent models (Duvaut-Lions type).
+// The inviscid solution (σb, αb) is computed by the wrapped model from the same trial state and the
+// viscous solution is a fraction r of the plastic correction:
+//  σ = σtr - r (σtr - σb)   and   α = αn + r (αb - αn)
+// where r is found from the power law of the overstress F = |σtr - σb|:
+//  r F = γ Δt σ0 ((1 - r) F / σ0)^N
+//  Note: with N = 1, the classical Duvaut-Lions model is recovered: r = γ Δt / (1 + γ Δt).
+//        The wrapped model is given by the "mdl" keycode of any parameter; e.g. "!mdl:vm"
+type ViscoPlastic struct {
+	SmallElasticity
+
+	// parameters
+	Fluid float64 // fluidity parameter γ = 1/τ where τ is the relaxation time
+	N     float64 // exponent
+	Sig0  float64 // reference stress
+
+	// wrapped model
+	Mdl   Model // inviscid model
+	Inner Small // inviscid model specialisation
+
+	// auxiliary
+	Δt    float64     // time increment
+	nalp  int         // number of internal variables of inner model
+	nphi  int         // number of additional variables of inner model
+	sb    *State      // inviscid state
+	σtr   []float64   // trial stress
+	c     []float64   // plastic correction: c = σtr - σb
+	De    [][]float64 // elastic modulus
+	Db    [][]float64 // modulus of inviscid model
+	MaxIt int         // max number of iterations to compute r
+}
+
+// add model to factory
+func init() {
+	allocators["vp"] = func() Model { return new(ViscoPlastic) }
+}
+
+// Init initialises model
+func (o *ViscoPlastic) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
+
+	// parse parameters
+	o.N, o.Sig0, o.MaxIt = 1, 1, 50
+	var mdlname string
+	var iprms fun.Prms
+	for _, p := range prms {
+		if name, found := io.Keycode(p.Extra, "mdl"); found {
+			mdlname = name
+		}
+		switch p.N {
+		case "fluid":
+			o.Fluid = p.V
+		case "N":
+			o.N = p.V
+		case "sig0":
+			o.Sig0 = p.V
+		default:
+			iprms = append(iprms, p)
+		}
+	}
+	if o.Fluid <= 0 || o.N <= 0 || o.Sig0 <= 0 {
+		return chk.Err("vp: fluid, N and sig0 must be positive. fluid=%g, N=%g and sig0=%g are incorrect\n", o.Fluid, o.N, o.Sig0)
+	}
+
+	// elasticity
+	err = o.SmallElasticity.Init(ndim, pstress, iprms)
+	if err != nil {
+		return
+	}
+	if pstress {
+		return chk.Err("vp: plane-stress analyses are not available\n")
+	}
+
+	// inner model
+	if mdlname == "" || mdlname == "vp" {
+		return chk.Err("vp: name of inviscid model must be given with the 'mdl' keycode. %q is invalid\n", mdlname)
+	}
+	allocator, ok := allocators[mdlname]
+	if !ok {
+		return chk.Err("vp: cannot find inviscid model named %q\n", mdlname)
+	}
+	o.Mdl = allocator()
+	err = o.Mdl.Init(ndim, pstress, iprms)
+	if err != nil {
+		return
+	}
+	if o.Inner, ok = o.Mdl.(Small); !ok {
+		return chk.Err("vp: inviscid model %q must be a small-strain model\n", mdlname)
+	}
+	if m, ok := o.Mdl.(RateDependent); ok {
+		m.SetDt(o.Δt)
+	}
+
+	// auxiliary structures
+	o.sb, err = o.Mdl.InitIntVars()
+	if err != nil {
+		return
+	}
+	o.nalp, o.nphi = len(o.sb.Alp), len(o.sb.Phi)
+	o.σtr = make([]float64, o.Nsig)
+	o.c = make([]float64, o.Nsig)
+	o.De = la.MatAlloc(o.Nsig, o.Nsig)
+	o.Db = la.MatAlloc(o.Nsig, o.Nsig)
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o ViscoPlastic) GetPrms() fun.Prms {
+	return []*fun.Prm{
+		&fun.Prm{N: "fluid", V: 0.1, Extra: "!mdl:vm"},
+		&fun.Prm{N: "N", V: 1},
+		&fun.Prm{N: "sig0", V: 1},
+		&fun.Prm{N: "E", V: 1000},
+		&fun.Prm{N: "nu", V: 0.3},
+		&fun.Prm{N: "qy0", V: 1},
+		&fun.Prm{N: "H", V: 0},
+	}
+}
+
+// InitIntVars initialises internal (secondary) variables
+//  Phi = {Phi of inviscid model, σb, αb, r}
+func (o ViscoPlastic) InitIntVars() (s *State, err error) {
+	sb, err := o.Mdl.InitIntVars()
+	if err != nil {
+		return
+	}
+	s = NewState(o.Nsig, o.nalp, o.nphi+o.Nsig+o.nalp+1, false)
+	copy(s.Sig, sb.Sig)
+	copy(s.Alp, sb.Alp)
+	copy(s.Phi, sb.Phi)
+	return
+}
+
+// SetDt sets the time increment
+func (o *ViscoPlastic) SetDt(Δt float64) {
+	o.Δt = Δt
+	if m, ok := o.Mdl.(RateDependent); ok {
+		m.SetDt(Δt)
+	}
+}
+
+// Update updates stresses for given strains
+func (o *ViscoPlastic) Update(s *State, ε, Δε []float64) (err error) {
+
+	// trial stress
+	copy(o.σtr, s.Sig)
+	err = o.SmallElasticity.Update(&State{Sig: o.σtr}, Δε)
+	if err != nil {
+		return
+	}
+
+	// inviscid solution
+	o.get_inner(s)
+	err = o.Inner.Update(o.sb, ε, Δε)
+	if err != nil {
+		return
+	}
+	s.Loading = o.sb.Loading
+	s.ApexReturn = o.sb.ApexReturn
+	s.Dgam = o.sb.Dgam
+	copy(s.Phi, o.sb.Phi)
+	σb := s.Phi[o.nphi : o.nphi+o.Nsig]
+	αb := s.Phi[o.nphi+o.Nsig : o.nphi+o.Nsig+o.nalp]
+	copy(σb, o.sb.Sig)
+	copy(αb, o.sb.Alp)
+
+	// elastic update
+	if !s.Loading {
+		copy(s.Sig, o.sb.Sig)
+		copy(s.Alp, o.sb.Alp)
+		s.Phi[len(s.Phi)-1] = 0
+		return
+	}
+
+	// overstress
+	for i := 0; i < o.Nsig; i++ {
+		o.c[i] = o.σtr[i] - σb[i]
+	}
+	F := la.VecNorm(o.c)
+
+	// viscous fraction of plastic correction
+	r, err := o.calc_r(F)
+	if err != nil {
+		return
+	}
+	s.Phi[len(s.Phi)-1] = r
+
+	// viscous solution
+	for i := 0; i < o.Nsig; i++ {
+		s.Sig[i] = o.σtr[i] - r*o.c[i]
+	}
+	for i := 0; i < o.nalp; i++ {
+		s.Alp[i] += r * (αb[i] - s.Alp[i])
+	}
+	s.Dgam *= r
+	return
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *ViscoPlastic) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// inviscid modulus
+	σb := s.Phi[o.nphi : o.nphi+o.Nsig]
+	αb := s.Phi[o.nphi+o.Nsig : o.nphi+o.Nsig+o.nalp]
+	r := s.Phi[len(s.Phi)-1]
+	o.sb.Loading = s.Loading
+	o.sb.ApexReturn = s.ApexReturn
+	if r > 0 {
+		o.sb.Dgam = s.Dgam / r
+	}
+	copy(o.sb.Sig, σb)
+	copy(o.sb.Alp, αb)
+	copy(o.sb.Phi, s.Phi[:o.nphi])
+	err = o.Inner.CalcD(o.Db, o.sb, firstIt)
+	if err != nil {
+		return
+	}
+
+	// elastic
+	if !s.Loading || r >= 1 {
+		la.MatCopy(D, 1, o.Db)
+		return
+	}
+
+	// elastic modulus
+	err = o.SmallElasticity.CalcD(o.De, s)
+	if err != nil {
+		return
+	}
+
+	// plastic correction: σ = σtr - r c => c = (σ - σb) / (1 - r)
+	for i := 0; i < o.Nsig; i++ {
+		o.c[i] = (s.Sig[i] - σb[i]) / (1.0 - r)
+	}
+	F := la.VecNorm(o.c)
+
+	// derivative of r w.r.t F
+	var drdF float64
+	if F > 0 {
+		x := (1.0 - r) * F / o.Sig0
+		a := o.Fluid * o.Δt
+		gr := F * (1.0 + a*o.N*math.Pow(x, o.N-1.0))
+		gF := r - a*o.N*math.Pow(x, o.N-1.0)*(1.0-r)
+		drdF = -gF / gr
+	}
+
+	// consistent modulus
+	//  D = (1-r) De + r Db - (dr/dF / F) c ⊗ (c・(De - Db))
+	var cdD float64
+	for j := 0; j < o.Nsig; j++ {
+		cdD = 0
+		if F > 0 {
+			for k := 0; k < o.Nsig; k++ {
+				cdD += o.c[k] * (o.De[k][j] - o.Db[k][j])
+			}
+			cdD *= drdF / F
+		}
+		for i := 0; i < o.Nsig; i++ {
+			D[i][j] = (1.0-r)*o.De[i][j] + r*o.Db[i][j] - o.c[i]*cdD
+		}
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *ViscoPlastic) ContD(D [][]float64, s *State) (err error) {
+	return o.CalcD(D, s, false)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// get_inner copies the current state into the state of inviscid model
+func (o *ViscoPlastic) get_inner(s *State) {
+	o.sb.Loading = s.Loading
+	o.sb.ApexReturn = s.ApexReturn
+	o.sb.Dgam = s.Dgam
+	copy(o.sb.Sig, s.Sig)
+	copy(o.sb.Alp, s.Alp)
+	copy(o.sb.Phi, s.Phi[:o.nphi])
+}
+
+// calc_r solves r F - γ Δt σ0 ((1 - r) F / σ0)^N = 0 for r using Newton's method with bisection
+func (o ViscoPlastic) calc_r(F float64) (r float64, err error) {
+	a := o.Fluid * o.Δt
+	if F <= 0 || a <= 0 {
+		return
+	}
+	if math.Abs(o.N-1.0) < 1e-15 {
+		return a / (1.0 + a), nil
+	}
+	var g, dgdr, x float64
+	rmin, rmax := 0.0, 1.0
+	r = a / (1.0 + a)
+	for it := 0; it < o.MaxIt; it++ {
+		x = (1.0 - r) * F / o.Sig0
+		g = r*F - a*o.Sig0*math.Pow(x, o.N)
+		if math.Abs(g) < 1e-13*F {
+			return
+		}
+		if g > 0 {
+			rmax = r
+		} else {
+			rmin = r
+		}
+		dgdr = F * (1.0 + a*o.N*math.Pow(x, o.N-1.0))
+		r -= g / dgdr
+		if r <= rmin || r >= rmax {
+			r = (rmin + rmax) / 2.0
+		}
+	}
+	return 0, chk.Err("vp: cannot compute viscous fraction after %d iterations. F=%g\n", o.MaxIt, F)
+}
@@ -5,17 +5,35 @@
 package msolid
 
 import (
+	"math"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/tsr"
 )
 
-// VonMises implements von Mises plasticity model
+// VonMises implements von Mises plasticity model with isotropic and kinematic hardening
+//  isotropic (linear + Voce):  qy = qy0 + H α + Qinf (1 - exp(-b α))
+//  kinematic (Armstrong-Frederick):  dβ = 2/3 C dεp - γk dα β
+//  yield function:  f = sqrt(3/2) |dev(σ) - β| - qy
+//  Note: α = Alp[0] and the back-stress β is stored in Alp[1:1+nsig] if C > 0.
+//        Plane-stress is handled by iterating on εzz until σzz = 0
 type VonMises struct {
 	SmallElasticity
-	qy0 float64   // initial qy
-	H   float64   // hardening variable
-	ten []float64 // auxiliary tensor
+	qy0  float64     // initial qy
+	H    float64     // hardening variable
+	Qinf float64     // saturation value of Voce hardening
+	b    float64     // rate of Voce hardening
+	C    float64     // kinematic hardening modulus
+	γk   float64     // dynamic recovery coefficient of kinematic hardening
+	Kin  bool        // has kinematic hardening
+	ten  []float64   // auxiliary tensor
+	βn   []float64   // back-stress at beginning of step
+	η    []float64   // str - βn/d
+	D3   [][]float64 // auxiliary 'plane-strain' modulus (for plane-stress)
+	Δε3  []float64   // auxiliary strain increment with Δεzz (for plane-stress)
+	sbkp *State      // auxiliary state (for plane-stress)
 }
 
 // add model to factory
@@ -37,14 +55,30 @@ func (o *VonMises) Init(ndim int, pstress bool, prms fun.Prms) (err error) {
 			o.qy0 = p.V
 		case "H":
 			o.H = p.V
+		case "Qinf":
+			o.Qinf = p.V
+		case "b":
+			o.b = p.V
+		case "C":
+			o.C = p.V
+		case "gamk":
+			o.γk = p.V
 		case "E", "nu", "l", "G", "K", "rho":
 		default:
 			return chk.Err("vm: parameter named %q is incorrect\n", p.N)
 		}
 	}
+	o.Kin = o.C > 0
 
 	// auxiliary structures
 	o.ten = make([]float64, o.Nsig)
+	o.βn = make([]float64, o.Nsig)
+	o.η = make([]float64, o.Nsig)
+	if o.Pse {
+		o.D3 = la.MatAlloc(o.Nsig, o.Nsig)
+		o.Δε3 = make([]float64, o.Nsig)
+		o.sbkp, _ = o.InitIntVars()
+	}
 	return
 }
 
@@ -53,18 +87,106 @@ func (o VonMises) GetPrms() fun.Prms {
 	return []*fun.Prm{
 		&fun.Prm{N: "qy0", V: 0.5},
 		&fun.Prm{N: "H", V: 0},
+		&fun.Prm{N: "Qinf", V: 0},
+		&fun.Prm{N: "b", V: 0},
+		&fun.Prm{N: "C", V: 0},
+		&fun.Prm{N: "gamk", V: 0},
 	}
 }
 
 // InitIntVars initialises internal (secondary) variables
 func (o VonMises) InitIntVars() (s *State, err error) {
-	s = NewState(o.Nsig, 1, 0, false)
+	nalp := 1
+	if o.Kin {
+		nalp += o.Nsig
+	}
+	s = NewState(o.Nsig, nalp, 0, false)
 	return
 }
 
 // Update updates stresses for given strains
 func (o *VonMises) Update(s *State, ε, Δε []float64) (err error) {
 
+	// plane-strain or 3D
+	if !o.Pse {
+		return o.update(s, Δε)
+	}
+
+	// plane-stress: find Δεzz such that σzz = 0
+	o.sbkp.Set(s)
+	Δε3 := o.Δε3
+	copy(Δε3, Δε)
+	Δε3[2] = -o.Nu * (Δε[0] + Δε[1]) / (1.0 - o.Nu) // elastic predictor
+	var D22 float64
+	for it := 0; it < o.NmaxIt; it++ {
+		s.Set(o.sbkp)
+		err = o.update(s, Δε3)
+		if err != nil {
+			return
+		}
+		if math.Abs(s.Sig[2]) < 1e-12*(1.0+la.VecNorm(s.Sig)) {
+			return
+		}
+		D22 = o.K + 4.0*o.G/3.0
+		if s.Loading {
+			o.calcD(o.D3, s, s.Dgam)
+			D22 = o.D3[2][2]
+		}
+		Δε3[2] -= s.Sig[2] / D22
+	}
+	return chk.Err("vm: plane-stress iterations did not converge after %d iterations. σzz = %g\n", o.NmaxIt, s.Sig[2])
+}
+
+// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
+func (o *VonMises) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+
+	// set first Δγ
+	if firstIt {
+		s.Dgam = 0
+	}
+
+	// elastic
+	if !s.Loading {
+		return o.SmallElasticity.CalcD(D, s)
+	}
+
+	// elastoplastic => consistent stiffness
+	o.calcD(D, s, s.Dgam)
+	if o.Pse {
+		o.condense(D)
+	}
+	return
+}
+
+// ContD computes D = dσ_new/dε_new continuous
+func (o *VonMises) ContD(D [][]float64, s *State) (err error) {
+
+	// only elastic
+	if !s.Loading {
+		return o.SmallElasticity.CalcD(D, s)
+	}
+
+	// elastoplastic
+	o.calcD(D, s, 0)
+	if o.Pse {
+		o.condense(D)
+	}
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// qy computes the current yield stress and its derivative w.r.t α
+func (o VonMises) qy(α float64) (qy, dqydα float64) {
+	e := math.Exp(-o.b * α)
+	qy = o.qy0 + o.H*α + o.Qinf*(1.0-e)
+	dqydα = o.H + o.Qinf*o.b*e
+	return
+}
+
+// update updates stresses in 3D or plane-strain
+func (o *VonMises) update(s *State, Δε []float64) (err error) {
+
 	// set flags
 	s.Loading = false    // => not elastoplastic
 	s.ApexReturn = false // => not return-to-apex
@@ -83,87 +205,156 @@ func (o *VonMises) Update(s *State, ε, Δε []float64) (err error) {
 	}
 	ptr, qtr := tsr.M_p(o.ten), tsr.M_q(o.ten)
 
+	// linear isotropic hardening only => closed form solution
+	if !o.Kin && o.Qinf == 0 {
+
+		// trial yield function
+		ftr := qtr - o.qy0 - o.H*(*α0)
+
+		// elastic update
+		if ftr <= 0.0 {
+			copy(σ, o.ten) // σ := ten = σtr
+			return
+		}
+
+		// elastoplastic update
+		var str_i float64
+		hp := 3.0*o.G + o.H
+		s.Dgam = ftr / hp
+		*α0 += s.Dgam
+		pnew := ptr
+		m := 1.0 - s.Dgam*3.0*o.G/qtr
+		for i := 0; i < o.Nsig; i++ {
+			str_i = o.ten[i] + ptr*tsr.Im[i]
+			σ[i] = m*str_i - pnew*tsr.Im[i]
+		}
+		s.Loading = true
+		return
+	}
+
+	// back-stress at beginning of step
+	la.VecFill(o.βn, 0)
+	if o.Kin {
+		copy(o.βn, s.Alp[1:])
+	}
+
 	// trial yield function
-	ftr := qtr - o.qy0 - o.H*(*α0)
+	for i := 0; i < o.Nsig; i++ {
+		o.ten[i] += ptr * tsr.Im[i] // ten := str = dev(σtr)
+		o.η[i] = o.ten[i] - o.βn[i]
+	}
+	qy, _ := o.qy(*α0)
+	ftr := tsr.SQ3by2*la.VecNorm(o.η) - qy
 
 	// elastic update
 	if ftr <= 0.0 {
-		copy(σ, o.ten) // σ := ten = σtr
+		for i := 0; i < o.Nsig; i++ {
+			σ[i] = o.ten[i] - ptr*tsr.Im[i]
+		}
 		return
 	}
 
-	// elastoplastic update
-	var str_i float64
-	hp := 3.0*o.G + o.H
-	s.Dgam = ftr / hp
-	*α0 += s.Dgam
-	pnew := ptr
-	m := 1.0 - s.Dgam*3.0*o.G/qtr
+	// Newton's method to find Δγ
+	var g, dgdΔγ, d, nη, Nβn, dqy float64
+	Δγ := 0.0
+	tol := 1e-13 * (1.0 + o.qy0 + qtr)
+	converged := false
+	for it := 0; it < o.NmaxIt; it++ {
+		d = 1.0 + o.γk*Δγ
+		for i := 0; i < o.Nsig; i++ {
+			o.η[i] = o.ten[i] - o.βn[i]/d
+		}
+		nη = la.VecNorm(o.η)
+		qy, dqy = o.qy(*α0 + Δγ)
+		g = tsr.SQ3by2*nη - (3.0*o.G+o.C/d)*Δγ - qy
+		if math.Abs(g) < tol {
+			converged = true
+			break
+		}
+		Nβn = la.VecDot(o.η, o.βn) / nη
+		dgdΔγ = tsr.SQ3by2*o.γk*Nβn/(d*d) - 3.0*o.G - o.C/d + o.C*o.γk*Δγ/(d*d) - dqy
+		Δγ -= g / dgdΔγ
+		if Δγ < 0 {
+			Δγ = 0
+		}
+	}
+	if !converged {
+		return chk.Err("vm: return mapping did not converge after %d iterations. g = %g\n", o.NmaxIt, g)
+	}
+
+	// update stresses and internal variables
+	var n_i float64
 	for i := 0; i < o.Nsig; i++ {
-		str_i = o.ten[i] + ptr*tsr.Im[i]
-		σ[i] = m*str_i - pnew*tsr.Im[i]
+		n_i = tsr.SQ3by2 * o.η[i] / nη
+		σ[i] = o.ten[i] - 2.0*o.G*Δγ*n_i - ptr*tsr.Im[i]
+		if o.Kin {
+			s.Alp[1+i] = (o.βn[i] + 2.0*o.C*Δγ*n_i/3.0) / d
+		}
 	}
+	*α0 += Δγ
+	s.Dgam = Δγ
 	s.Loading = true
 	return
 }
 
-// CalcD computes D = dσ_new/dε_new consistent with StressUpdate
-func (o *VonMises) CalcD(D [][]float64, s *State, firstIt bool) (err error) {
+// calcD computes the consistent modulus in 3D or plane-strain for given Δγ
+//  Note: Δγ = 0 gives the continuum modulus
+func (o *VonMises) calcD(D [][]float64, s *State, Δγ float64) {
 
-	// set first Δγ
-	if firstIt {
-		s.Dgam = 0
+	// relative stress ξ = dev(σ) - β and unit normal N = ξ / |ξ|
+	σ := s.Sig
+	p := tsr.M_p(σ)
+	la.VecFill(o.βn, 0)
+	if o.Kin {
+		copy(o.βn, s.Alp[1:]) // βn := β_(n+1)
 	}
-
-	// elastic
-	if !s.Loading {
-		return o.SmallElasticity.CalcD(D, s)
+	for i := 0; i < o.Nsig; i++ {
+		o.ten[i] = σ[i] + p*tsr.Im[i] - o.βn[i] // ten := ξ
+	}
+	nξ := la.VecNorm(o.ten)
+	for i := 0; i < o.Nsig; i++ {
+		o.ten[i] /= nξ // ten := N
 	}
 
-	// elastoplastic => consistent stiffness
-	σ := s.Sig
-	Δγ := s.Dgam
-	p, q := tsr.M_p(σ), tsr.M_q(σ)
-	qtr := q + Δγ*3.0*o.G
-	m := 1.0 - Δγ*3.0*o.G/qtr
-	nstr := tsr.SQ2by3 * qtr // norm(str)
+	// recover quantities at beginning of step
+	d := 1.0 + o.γk*Δγ
 	for i := 0; i < o.Nsig; i++ {
-		o.ten[i] = (σ[i] + p*tsr.Im[i]) / (m * nstr) // ten := unit(str) = snew / (m * nstr)
+		o.βn[i] = d*o.βn[i] - 2.0*o.C*Δγ*tsr.SQ3by2*o.ten[i]/3.0 // βn := β_n
 	}
-	hp := 3.0*o.G + o.H
-	a1 := o.K
-	b2 := 6.0 * o.G * o.G * (Δγ/qtr - 1.0/hp)
+	Nβn := la.VecDot(o.ten, o.βn)
+	nη := nξ + (2.0*o.G+2.0*o.C/(3.0*d))*Δγ*tsr.SQ3by2 // |str - βn/d|
+
+	// derivative of Δγ w.r.t ε: dΔγdε = u
+	_, dqy := o.qy(s.Alp[0])
+	A := 3.0*o.G + o.C/d - o.C*o.γk*Δγ/(d*d) + dqy - tsr.SQ3by2*o.γk*Nβn/(d*d)
+	cu := tsr.SQ3by2 * 2.0 * o.G / A // u = cu * N
+
+	// modulus
+	c1 := 2.0 * o.G * tsr.SQ3by2
+	c2 := Δγ / nη
+	c3 := o.γk / (d * d)
+	var N_i, N_j, u_j float64
 	for i := 0; i < o.Nsig; i++ {
+		N_i = o.ten[i]
 		for j := 0; j < o.Nsig; j++ {
-			D[i][j] = 2.0*o.G*m*tsr.Psd[i][j] + a1*tsr.Im[i]*tsr.Im[j] + b2*o.ten[i]*o.ten[j]
+			N_j = o.ten[j]
+			u_j = cu * N_j
+			D[i][j] = o.K*tsr.Im[i]*tsr.Im[j] + 2.0*o.G*tsr.Psd[i][j] -
+				c1*(N_i*u_j+c2*(2.0*o.G*(tsr.Psd[i][j]-N_i*N_j)+c3*(o.βn[i]-Nβn*N_i)*u_j))
 		}
 	}
-	return
 }
 
-// ContD computes D = dσ_new/dε_new continuous
-func (o *VonMises) ContD(D [][]float64, s *State) (err error) {
-
-	// elastic part
-	err = o.SmallElasticity.CalcD(D, s)
-	if err != nil {
-		return
-	}
-
-	// only elastic
-	if !s.Loading {
-		return
-	}
-
-	// elastoplastic
-	σ := s.Sig
-	d1 := 3.0*o.G + o.H
-	a4 := 6.0 * o.G * o.G / d1
-	sno, _, _ := tsr.M_devσ(o.ten, σ) // ten := dev(σ)
+// condense applies the plane-stress condition to D: D := D - D[:,2] ⊗ D[2,:] / D[2][2]
+func (o VonMises) condense(D [][]float64) {
 	for i := 0; i < o.Nsig; i++ {
 		for j := 0; j < o.Nsig; j++ {
-			D[i][j] -= a4 * o.ten[i] * o.ten[j] / (sno * sno)
+			if i != 2 && j != 2 {
+				D[i][j] -= D[i][2] * D[2][j] / D[2][2]
+			}
 		}
 	}
-	return
+	for i := 0; i < o.Nsig; i++ {
+		D[i][2], D[2][i] = 0, 0
+	}
 }
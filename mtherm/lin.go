@@ -0,0 +1,107 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mtherm
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Lin implements a heat conduction model with conductivity varying linearly with temperature
+//  k(θ) = k0・(1 + bk・(θ - θref))
+//  ρc(θ) = ρc0・(1 + bc・(θ - θref))
+type Lin struct {
+
+	// parameters
+	k0   float64 // thermal conductivity at θref
+	bk   float64 // coefficient of linear variation of k with θ
+	ρc0  float64 // volumetric heat capacity at θref
+	bc   float64 // coefficient of linear variation of ρc with θ
+	α    float64 // coefficient of linear thermal expansion of solids
+	θref float64 // reference temperature
+}
+
+// add model to factory
+func init() {
+	allocators["lin"] = func() Model { return new(Lin) }
+}
+
+// GetPrms gets (an example) of parameters
+func (o Lin) GetPrms(example bool) fun.Prms {
+	if example {
+		return fun.Prms{
+			&fun.Prm{N: "k", V: 2.0},
+			&fun.Prm{N: "bk", V: 0.001},
+			&fun.Prm{N: "rhoc", V: 2.0e3},
+			&fun.Prm{N: "bc", V: 0.002},
+			&fun.Prm{N: "alp", V: 1e-5},
+			&fun.Prm{N: "Tref", V: 10},
+		}
+	}
+	return fun.Prms{
+		&fun.Prm{N: "k", V: o.k0},
+		&fun.Prm{N: "bk", V: o.bk},
+		&fun.Prm{N: "rhoc", V: o.ρc0},
+		&fun.Prm{N: "bc", V: o.bc},
+		&fun.Prm{N: "alp", V: o.α},
+		&fun.Prm{N: "Tref", V: o.θref},
+	}
+}
+
+// Init initialises this structure
+func (o *Lin) Init(prms fun.Prms) (err error) {
+	for _, p := range prms {
+		switch p.N {
+		case "k":
+			o.k0 = p.V
+		case "bk":
+			o.bk = p.V
+		case "rhoc":
+			o.ρc0 = p.V
+		case "bc":
+			o.bc = p.V
+		case "alp":
+			o.α = p.V
+		case "Tref":
+			o.θref = p.V
+		default:
+			return chk.Err("mtherm.Lin: parameter named %q is incorrect\n", p.N)
+		}
+	}
+	if o.k0 <= 0 {
+		return chk.Err("mtherm.Lin: thermal conductivity must be positive. k = %g is incorrect\n", o.k0)
+	}
+	return
+}
+
+// Cond returns the thermal conductivity k
+func (o Lin) Cond(θ float64) float64 {
+	return o.k0 * (1.0 + o.bk*(θ-o.θref))
+}
+
+// DcondDθ returns ∂k/∂θ
+func (o Lin) DcondDθ(θ float64) float64 {
+	return o.k0 * o.bk
+}
+
+// Capa returns the volumetric heat capacity ρ・c
+func (o Lin) Capa(θ float64) float64 {
+	return o.ρc0 * (1.0 + o.bc*(θ-o.θref))
+}
+
+// DcapaDθ returns ∂(ρ・c)/∂θ
+func (o Lin) DcapaDθ(θ float64) float64 {
+	return o.ρc0 * o.bc
+}
+
+// Alpha returns the coefficient of linear thermal expansion of solids
+func (o Lin) Alpha() float64 {
+	return o.α
+}
+
+// Tref returns the reference temperature
+func (o Lin) Tref() float64 {
+	return o.θref
+}
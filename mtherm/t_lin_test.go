@@ -0,0 +1,55 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mtherm
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/num"
+)
+
+func Test_lin01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("lin01")
+
+	mdl := GetModel("testsim", "mat1", "lin", false)
+	if mdl == nil {
+		tst.Errorf("test failed: cannot get model\n")
+		return
+	}
+	err := mdl.Init(mdl.GetPrms(true))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// values at reference temperature
+	θref := mdl.Tref()
+	chk.Scalar(tst, "k(θref) ", 1e-15, mdl.Cond(θref), 2.0)
+	chk.Scalar(tst, "ρc(θref)", 1e-12, mdl.Capa(θref), 2.0e3)
+	chk.Scalar(tst, "α", 1e-17, mdl.Alpha(), 1e-5)
+
+	// check derivatives
+	tol := 1e-10
+	for _, θ := range []float64{-20, 0, 10, 35, 80} {
+		dknum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+			return mdl.Cond(x)
+		}, θ)
+		dcnum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+			return mdl.Capa(x)
+		}, θ)
+		chk.AnaNum(tst, io.Sf("dk/dθ  @ θ=%g", θ), tol, mdl.DcondDθ(θ), dknum, chk.Verbose)
+		chk.AnaNum(tst, io.Sf("dρc/dθ @ θ=%g", θ), tol, mdl.DcapaDθ(θ), dcnum, chk.Verbose)
+	}
+
+	// wrong parameters
+	err = GetModel("testsim", "mat2", "lin", true).Init(nil)
+	if err == nil {
+		tst.Errorf("test failed: null conductivity should not be accepted\n")
+	}
+}
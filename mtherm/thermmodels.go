@@ -0,0 +1,79 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package mtherm implements models for heat conduction in solids and porous media
+package mtherm
+
+import (
+	"log"
+
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// Model defines heat conduction models
+//  Note: θ is the temperature
+type Model interface {
+	Init(prms fun.Prms) error      // Init initialises this structure
+	GetPrms(example bool) fun.Prms // gets (an example) of parameters
+	Cond(θ float64) float64        // Cond returns the thermal conductivity k
+	DcondDθ(θ float64) float64     // DcondDθ returns ∂k/∂θ
+	Capa(θ float64) float64        // Capa returns the volumetric heat capacity ρ・c
+	DcapaDθ(θ float64) float64     // DcapaDθ returns ∂(ρ・c)/∂θ
+	Alpha() float64                // Alpha returns the coefficient of linear thermal expansion of solids
+	Tref() float64                 // Tref returns the reference temperature; e.g. corresponding to null thermal strains
+}
+
+// GetModel returns (existent or new) heat conduction model
+//  simfnk    -- unique simulation filename key
+//  matname   -- name of material
+//  modelname -- model name
+//  getnew    -- force a new allocation; i.e. do not use any model found in database
+//  Note: returns nil on errors
+func GetModel(simfnk, matname, modelname string, getnew bool) Model {
+
+	// get new model, regardless whether it exists in database or not
+	if getnew {
+		allocator, ok := allocators[modelname]
+		if !ok {
+			return nil
+		}
+		return allocator()
+	}
+
+	// search database
+	key := io.Sf("%s_%s_%s", simfnk, matname, modelname)
+	if model, ok := _models[key]; ok {
+		return model
+	}
+
+	// if not found, get new
+	allocator, ok := allocators[modelname]
+	if !ok {
+		return nil
+	}
+	model := allocator()
+	_models[key] = model
+	return model
+}
+
+// LogModels prints to log information on existent and allocated Models
+func LogModels() {
+	l := "mtherm: available:"
+	for name, _ := range allocators {
+		l += " " + name
+	}
+	log.Println(l)
+	l = "mtherm: allocated:"
+	for key, _ := range _models {
+		l += " " + io.Sf("%q", key)
+	}
+	log.Println(l)
+}
+
+// allocators holds all available models
+var allocators = map[string]func() Model{}
+
+// _models holds pre-allocated models
+var _models = map[string]Model{}
@@ -0,0 +1,126 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mtransp
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/num"
+)
+
+func Test_transp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("transp01")
+
+	// Freundlich model
+	mdl := GetModel("testsim", "mat1", false)
+	err := mdl.Init(mdl.GetPrms(true))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// sorption derivatives
+	tol := 1e-8
+	for _, c := range []float64{0.1, 0.5, 1, 2.5} {
+		dsnum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+			s, _, _ := mdl.Sorb(x)
+			return s
+		}, c)
+		d2snum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+			_, ds, _ := mdl.Sorb(x)
+			return ds
+		}, c)
+		_, ds, d2s := mdl.Sorb(c)
+		chk.AnaNum(tst, io.Sf("ds/dc   @ c=%g", c), tol, ds, dsnum, chk.Verbose)
+		chk.AnaNum(tst, io.Sf("d²s/dc² @ c=%g", c), tol, d2s, d2snum, chk.Verbose)
+	}
+
+	// regularisation below cmin
+	s, ds, _ := mdl.Sorb(mdl.Cmin)
+	sr, dsr, _ := mdl.Sorb(mdl.Cmin / 2)
+	chk.Scalar(tst, "s(cmin/2)", 1e-17, sr, s/2)
+	chk.Scalar(tst, "ds(cmin/2)", 1e-17, dsr, ds/mdl.NF)
+
+	// linear model
+	lin := GetModel("testsim", "mat2", false)
+	err = lin.Init(fun.Prms{
+		&fun.Prm{N: "rhob", V: 1.6},
+		&fun.Prm{N: "Kd", V: 0.3},
+	})
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+	s, ds, _ = lin.Sorb(2)
+	chk.Scalar(tst, "linear: s ", 1e-17, s, 0.6)
+	chk.Scalar(tst, "linear: ds", 1e-17, ds, 0.3)
+
+	// wrong parameters
+	err = GetModel("testsim", "mat3", true).Init(fun.Prms{
+		&fun.Prm{N: "Kd", V: 0.3},
+		&fun.Prm{N: "Kf", V: 0.3},
+	})
+	if err == nil {
+		tst.Errorf("test failed: Kd and Kf together should not be accepted\n")
+	}
+}
+
+func Test_transp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("transp02")
+
+	mdl := GetModel("testsim", "mat1", true)
+	err := mdl.Init(mdl.GetPrms(true))
+	if err != nil {
+		tst.Errorf("test failed: %v\n", err)
+		return
+	}
+
+	// dispersion tensor for flow along x
+	θw := 0.3
+	D := [][]float64{{0, 0}, {0, 0}}
+	mdl.Disp(D, nil, nil, θw, []float64{2, 0})
+	Dd := θw * mdl.Tau * mdl.Dm
+	chk.Matrix(tst, "D", 1e-15, D, [][]float64{
+		{Dd + mdl.AL*2, 0},
+		{0, Dd + mdl.AT*2},
+	})
+
+	// derivatives w.r.t q
+	ndim := 2
+	q := []float64{1.5, -0.7}
+	dDdθw := [][]float64{{0, 0}, {0, 0}}
+	dDdq := make([][][]float64, ndim)
+	for i := 0; i < ndim; i++ {
+		dDdq[i] = [][]float64{{0, 0}, {0, 0}}
+	}
+	mdl.Disp(D, dDdθw, dDdq, θw, q)
+	tmp := [][]float64{{0, 0}, {0, 0}}
+	for i := 0; i < ndim; i++ {
+		for j := 0; j < ndim; j++ {
+			for k := 0; k < ndim; k++ {
+				dnum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+					qk := q[k]
+					q[k] = x
+					mdl.Disp(tmp, nil, nil, θw, q)
+					q[k] = qk
+					return tmp[i][j]
+				}, q[k])
+				chk.AnaNum(tst, io.Sf("dD%d%d/dq%d", i, j, k), 1e-9, dDdq[i][j][k], dnum, chk.Verbose)
+			}
+			dnum := num.DerivCen(func(x float64, args ...interface{}) float64 {
+				mdl.Disp(tmp, nil, nil, x, q)
+				return tmp[i][j]
+			}, θw)
+			chk.AnaNum(tst, io.Sf("dD%d%d/dθw", i, j), 1e-9, dDdθw[i][j], dnum, chk.Verbose)
+		}
+	}
+}
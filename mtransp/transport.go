@@ -0,0 +1,232 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package mtransp implements models for the transport of solutes in porous media
+package mtransp
+
+import (
+	"log"
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// Model holds material parameters for advection-dispersion of solutes with sorption and decay
+//  The balance of solute mass reads:
+//   (θw + ρb・ds/dc)・∂c/∂t + q・∇c - div(D・∇c) + λ・(θw・c + ρb・s) = 0
+//  where c is the concentration of solute in the liquid, s(c) is the sorbed concentration,
+//  θw is the volumetric water content, q is the Darcy flux and D is the hydrodynamic
+//  dispersion tensor (multiplied by θw):
+//   D = (θw・τ・Dm + aT・|q|)・I + (aL - aT)・q⊗q / |q|
+//  Sorption isotherms:
+//   none       : s = 0                   (Kd = Kf = 0)
+//   linear     : s = Kd・c               (Kd > 0)
+//   Freundlich : s = Kf・c^nF            (Kf > 0); regularised with a secant for c < cmin
+type Model struct {
+
+	// parameters
+	Dm   float64 // molecular diffusion coefficient in free liquid
+	Tau  float64 // tortuosity factor
+	AL   float64 // longitudinal dispersivity
+	AT   float64 // transverse dispersivity
+	RhoB float64 // dry bulk density of solids
+	Kd   float64 // linear distribution coefficient
+	Kf   float64 // Freundlich coefficient
+	NF   float64 // Freundlich exponent
+	Cmin float64 // minimum concentration for Freundlich isotherm; secant is used below cmin
+	Lam  float64 // λ: first-order decay rate of both dissolved and sorbed phases
+
+	// derived
+	Freu bool // Freundlich isotherm is used
+}
+
+// Init initialises this structure
+func (o *Model) Init(prms fun.Prms) (err error) {
+
+	// default values
+	o.Tau = 1
+	o.NF = 1
+	o.Cmin = 1e-8
+
+	// read paramaters in
+	for _, p := range prms {
+		switch p.N {
+		case "Dm":
+			o.Dm = p.V
+		case "tau":
+			o.Tau = p.V
+		case "aL":
+			o.AL = p.V
+		case "aT":
+			o.AT = p.V
+		case "rhob":
+			o.RhoB = p.V
+		case "Kd":
+			o.Kd = p.V
+		case "Kf":
+			o.Kf = p.V
+		case "nF":
+			o.NF = p.V
+		case "cmin":
+			o.Cmin = p.V
+		case "lam":
+			o.Lam = p.V
+		default:
+			return chk.Err("mtransp.Model: parameter named %q is incorrect\n", p.N)
+		}
+	}
+
+	// check
+	if o.Dm < 0 || o.AL < 0 || o.AT < 0 || o.Lam < 0 {
+		return chk.Err("mtransp.Model: Dm, aL, aT and lam must be non-negative. Dm=%g, aL=%g, aT=%g, lam=%g\n", o.Dm, o.AL, o.AT, o.Lam)
+	}
+	if o.Kd > 0 && o.Kf > 0 {
+		return chk.Err("mtransp.Model: either Kd (linear) or Kf (Freundlich) can be given; not both\n")
+	}
+	o.Freu = o.Kf > 0
+	if o.Freu && (o.NF <= 0 || o.Cmin <= 0) {
+		return chk.Err("mtransp.Model: Freundlich parameters nF and cmin must be positive. nF=%g, cmin=%g\n", o.NF, o.Cmin)
+	}
+	if (o.Kd > 0 || o.Freu) && o.RhoB <= 0 {
+		return chk.Err("mtransp.Model: bulk density rhob must be positive when sorption is considered. rhob=%g\n", o.RhoB)
+	}
+	return
+}
+
+// GetPrms gets (an example) of parameters
+func (o Model) GetPrms(example bool) fun.Prms {
+	if example {
+		return fun.Prms{
+			&fun.Prm{N: "Dm", V: 1e-9},
+			&fun.Prm{N: "tau", V: 0.5},
+			&fun.Prm{N: "aL", V: 0.1},
+			&fun.Prm{N: "aT", V: 0.01},
+			&fun.Prm{N: "rhob", V: 1.6},
+			&fun.Prm{N: "Kf", V: 0.5},
+			&fun.Prm{N: "nF", V: 0.8},
+			&fun.Prm{N: "lam", V: 1e-6},
+		}
+	}
+	return fun.Prms{
+		&fun.Prm{N: "Dm", V: o.Dm},
+		&fun.Prm{N: "tau", V: o.Tau},
+		&fun.Prm{N: "aL", V: o.AL},
+		&fun.Prm{N: "aT", V: o.AT},
+		&fun.Prm{N: "rhob", V: o.RhoB},
+		&fun.Prm{N: "Kd", V: o.Kd},
+		&fun.Prm{N: "Kf", V: o.Kf},
+		&fun.Prm{N: "nF", V: o.NF},
+		&fun.Prm{N: "cmin", V: o.Cmin},
+		&fun.Prm{N: "lam", V: o.Lam},
+	}
+}
+
+// Sorb computes the sorbed concentration s and its derivatives w.r.t c
+func (o Model) Sorb(c float64) (s, dsdc, d2sdc2 float64) {
+	if o.Freu {
+		if c < o.Cmin {
+			dsdc = o.Kf * math.Pow(o.Cmin, o.NF-1.0)
+			s = dsdc * c
+			return
+		}
+		s = o.Kf * math.Pow(c, o.NF)
+		dsdc = o.Kf * o.NF * math.Pow(c, o.NF-1.0)
+		d2sdc2 = o.Kf * o.NF * (o.NF - 1.0) * math.Pow(c, o.NF-2.0)
+		return
+	}
+	return o.Kd * c, o.Kd, 0
+}
+
+// Disp computes the hydrodynamic dispersion tensor D (multiplied by θw) and its derivatives
+//  Input:
+//   θw -- volumetric water content
+//   q  -- [ndim] Darcy flux
+//  Output:
+//   D      -- [ndim][ndim] dispersion tensor
+//   dDdθw  -- [ndim][ndim] ∂D/∂θw; may be nil
+//   dDdq   -- [ndim][ndim][ndim] ∂D/∂q; may be nil
+//  Note: the derivatives w.r.t q are taken as zero if |q| is null
+func (o Model) Disp(D, dDdθw [][]float64, dDdq [][][]float64, θw float64, q []float64) {
+	ndim := len(q)
+	qn := 0.0
+	for i := 0; i < ndim; i++ {
+		qn += q[i] * q[i]
+	}
+	qn = math.Sqrt(qn)
+	Dd := θw * o.Tau * o.Dm
+	for i := 0; i < ndim; i++ {
+		for j := 0; j < ndim; j++ {
+			D[i][j] = 0
+			if i == j {
+				D[i][j] = Dd + o.AT*qn
+			}
+			if qn > 0 {
+				D[i][j] += (o.AL - o.AT) * q[i] * q[j] / qn
+			}
+			if dDdθw != nil {
+				dDdθw[i][j] = 0
+				if i == j {
+					dDdθw[i][j] = o.Tau * o.Dm
+				}
+			}
+			if dDdq != nil {
+				for k := 0; k < ndim; k++ {
+					dDdq[i][j][k] = 0
+					if qn > 0 {
+						if i == j {
+							dDdq[i][j][k] = o.AT * q[k] / qn
+						}
+						dDdq[i][j][k] += (o.AL - o.AT) * (dlt(i, k)*q[j] + q[i]*dlt(j, k) - q[i]*q[j]*q[k]/(qn*qn)) / qn
+					}
+				}
+			}
+		}
+	}
+}
+
+// GetModel returns (existent or new) model for solute transport
+//  simfnk    -- unique simulation filename key
+//  matname   -- name of material
+//  getnew    -- force a new allocation; i.e. do not use any model found in database
+//  Note: returns nil on errors
+func GetModel(simfnk, matname string, getnew bool) *Model {
+
+	// get new model, regardless whether it exists in database or not
+	if getnew {
+		return new(Model)
+	}
+
+	// search database
+	key := io.Sf("%s_%s", simfnk, matname)
+	if model, ok := _models[key]; ok {
+		return model
+	}
+
+	// if not found, get new
+	model := new(Model)
+	_models[key] = model
+	return model
+}
+
+// LogModels prints to log information on existent and allocated Models
+func LogModels() {
+	l := "mtransp: allocated:"
+	for key, _ := range _models {
+		l += " " + io.Sf("%q", key)
+	}
+	log.Println(l)
+}
+
+// _models holds pre-allocated models
+var _models = map[string]*Model{}
+
+// dlt returns the Kronecker delta
+func dlt(i, j int) float64 {
+	if i == j {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import "github.com/cpmech/gosl/chk"
+
+// AnchorForce returns the axial force in the free length of an anchor at all selected output
+// times (see LoadResults)
+//  tag -- tag of anchor (tag of its rod cells)
+func AnchorForce(tag int) (P []float64) {
+	P = make([]float64, len(I))
+	for k, tidx := range I {
+		if !Dom.In(Sum, tidx, true) {
+			chk.Panic("cannot load results into domain; please check log file")
+		}
+		var found bool
+		P[k], found = Dom.Anchors.Force(tag)
+		if !found {
+			chk.Panic("cannot find rods in the free length of anchor with tag = %d", tag)
+		}
+	}
+	return
+}
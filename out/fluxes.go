@@ -0,0 +1,162 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"math"
+
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// Section implements a polyline crossing the domain; e.g. along a cut-off wall (2D only)
+//  Example: {{0,0}, {1,0}, {1,2}}
+//  Note: the normal of each segment A→B is n = (-Δy, Δx) / |AB|; i.e. the flux is positive when
+//        crossing the section from its right-hand side to its left-hand side
+type Section [][]float64
+
+// constants
+var (
+	SectionNdiv = 20 // number of subdivisions of each segment of sections
+)
+
+// FluxFace integrates the liquid discharge ∫(ρl・wl/ρL)・n dΓ over faces with a given tag
+//  Note: n is the outward normal; hence, positive values mean outflow.
+//        In 2D, the discharge is per unit thickness
+func FluxFace(ftag int) (Q float64) {
+	return face_flux(ftag, true)
+}
+
+// Flux integrates the liquid discharge ∫(ρl・wl/ρL)・n dΓ across this section
+//  Note: the midpoint rule is used with SectionNdiv subdivisions per segment
+func (o Section) Flux() (Q float64) {
+	if Dom.Msh.Ndim != 2 {
+		chk.Panic("sections are only available in 2D")
+	}
+	ρwl := make([]float64, 2)
+	x := make([]float64, 2)
+	for k := 1; k < len(o); k++ {
+		A, B := o[k-1], o[k]
+		dx, dy := B[0]-A[0], B[1]-A[1]
+		L := math.Sqrt(dx*dx + dy*dy)
+		n := []float64{-dy / L, dx / L}
+		dl := L / float64(SectionNdiv)
+		for i := 0; i < SectionNdiv; i++ {
+			t := (float64(i) + 0.5) / float64(SectionNdiv)
+			x[0], x[1] = A[0]+t*dx, A[1]+t*dy
+			e := find_elem_p(x)
+			if e == nil {
+				chk.Panic("cannot find p-element containing point %v of section", x)
+			}
+			ρL, err := e.LiqFlux(ρwl, x, Dom.Sol)
+			if err != nil {
+				chk.Panic("Section.Flux failed: %v", err)
+			}
+			Q += dl * la.VecDot(ρwl, n) / ρL
+		}
+	}
+	return
+}
+
+// Discharge computes the discharge and the cumulative volume at all selected output times (see LoadResults)
+//  flux -- a function computing the discharge; e.g. func() float64 { return FluxFace(-10) }
+//  Note: the cumulative volume is computed with the trapezoidal rule
+func Discharge(flux func() float64) (Q, V []float64) {
+	Q = make([]float64, len(I))
+	V = make([]float64, len(I))
+	for k, tidx := range I {
+		if !Dom.In(Sum, tidx, true) {
+			chk.Panic("cannot load results into domain; please check log file")
+		}
+		Q[k] = flux()
+		if k > 0 {
+			V[k] = V[k-1] + (Q[k]+Q[k-1])*(T[k]-T[k-1])/2.0
+		}
+	}
+	return
+}
+
+// MassBalance computes the liquid mass stored in the domain and the (cumulative) mass-balance error
+// at all selected output times (see LoadResults)
+//  ftags -- tags of all faces through which liquid may enter or leave the domain
+//  Output:
+//   M   -- stored mass of liquid: ∫ρl dΩ
+//   Err -- cumulative mass-balance error: M(t) - M(t0) + ∫∫ρl・wl・n dΓ dt
+//   Stp -- mass-balance error of each step: ΔM + ∫∫ρl・wl・n dΓ dt over the step
+func MassBalance(ftags ...int) (M, Err, Stp []float64) {
+	M = make([]float64, len(I))
+	Err = make([]float64, len(I))
+	Stp = make([]float64, len(I))
+	var Qold, Qnew, ΔV float64
+	for k, tidx := range I {
+		if !Dom.In(Sum, tidx, true) {
+			chk.Panic("cannot load results into domain; please check log file")
+		}
+		m, err := Dom.LiqMass()
+		if err != nil {
+			chk.Panic("MassBalance failed: %v", err)
+		}
+		M[k] = m
+		Qnew = 0
+		for _, tag := range ftags {
+			Qnew += face_flux(tag, false)
+		}
+		if k > 0 {
+			ΔV = (Qnew + Qold) * (T[k] - T[k-1]) / 2.0
+			Stp[k] = M[k] - M[k-1] + ΔV
+			Err[k] = Err[k-1] + Stp[k]
+		}
+		Qold = Qnew
+	}
+	return
+}
+
+// StepMassBalance returns the liquid mass-balance errors of all time steps computed during the run
+//  Note: the faces through which liquid may enter or leave the domain must be given in "massbal"
+//        (data section of the .sim file); otherwise the returned slices are empty
+//  Output:
+//   Ts  -- times at the end of each step
+//   Err -- mass-balance error of each step: ΔM + ∫∫ρl・wl・n dΓ dt over the step
+func StepMassBalance() (Ts, Err []float64) {
+	return Sum.MbTimes, Sum.MbErrs
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// find_elem_p finds the p-element containing point x
+func find_elem_p(x []float64) *fem.ElemP {
+	r := make([]float64, 3)
+	for _, ele := range Dom.Elems {
+		e := fem.ElemPofElem(ele)
+		if e == nil {
+			continue
+		}
+		if e.Shp.InvMap(r, x, e.X) != nil {
+			continue
+		}
+		tol := 1e-8
+		if e.Shp.Type[:3] == "tri" {
+			if r[0] >= -tol && r[1] >= -tol && r[0]+r[1] <= 1+tol {
+				return e
+			}
+			continue
+		}
+		if math.Abs(r[0]) <= 1+tol && math.Abs(r[1]) <= 1+tol {
+			return e
+		}
+	}
+	return nil
+}
+
+// face_flux integrates the liquid mass flux ∫ρl・wl・n dΓ over faces with a given tag
+//  volumetric -- divide ρl・wl by ρL; i.e. integrate the Darcy velocity
+func face_flux(ftag int, volumetric bool) (Q float64) {
+	Q, err := Dom.FaceLiqFlux(ftag, volumetric)
+	if err != nil {
+		chk.Panic("cannot compute flux over face: %v", err)
+	}
+	return
+}
@@ -83,6 +83,11 @@ func LoadResults(times []float64) {
 							utl.StrDblsMapAppend(&p.Vals, dof.Key, Dom.Sol.Y[dof.Eq])
 						}
 					}
+					if gn, pn, pt, found := Dom.Contacts.OutVals(vid); found {
+						utl.StrDblsMapAppend(&p.Vals, "gn", gn)
+						utl.StrDblsMapAppend(&p.Vals, "pn", pn)
+						utl.StrDblsMapAppend(&p.Vals, "pt", pt)
+					}
 				}
 
 				// handle integration point
@@ -0,0 +1,138 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"testing"
+
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_anchor01(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		} else {
+			fem.End()
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("anchor01")
+
+	// run FE simulation
+	if !fem.Start("data/anchor01.sim", true, chk.Verbose) {
+		chk.Panic("cannot start FE simulation")
+	}
+	if !fem.Run() {
+		chk.Panic("cannot run FE simulation")
+	}
+
+	// start analysis process
+	Start("data/anchor01.sim", 0, 0)
+	LoadResults(nil)
+
+	// free and bond lengths: 5 + 9 rod cells
+	dat := fem.Global.Sim.Regions[0].Anchors[0]
+	chk.IntAssert(len(dat.Free), 5)
+	chk.IntAssert(len(dat.Bond), 9)
+	chk.Vector(tst, "dir", 1e-7, dat.Dir, []float64{0, 1, 0})
+
+	// anchor force history: equal to the lock-off force
+	P := AnchorForce(-2)
+	io.Pforan("P = %v\n", P)
+	for k, t := range T {
+		chk.Scalar(tst, io.Sf("P(t=%g)", t), 1e-8, P[k], 0.05*t)
+	}
+
+	// debonded rod-joints do not transfer shear; the bond length carries the whole force
+	var nfree int
+	for _, ele := range Dom.Elems {
+		if e, ok := ele.(*fem.Rjoint); ok {
+			if dat.Free[e.Rod.Cid] {
+				chk.Scalar(tst, io.Sf("τ @ free joint %d", e.Cid), 1e-15, e.States[0].Sig, 0)
+				nfree += 1
+			}
+		}
+	}
+	chk.IntAssert(nfree, 5)
+
+	// axial force decreases along the bond length
+	Pmax := 0.0
+	for _, cid := range dat.Bond {
+		rod := Dom.Cid2elem[cid].(*fem.Rod)
+		for _, s := range rod.States {
+			if rod.A*s.Sig > Pmax {
+				Pmax = rod.A * s.Sig
+			}
+		}
+	}
+	io.Pforan("Pmax @ bond = %v\n", Pmax)
+	if Pmax > 0.05 || Pmax < 0.025 {
+		tst.Errorf("max axial force along bond length is incorrect: %v", Pmax)
+	}
+}
+
+func Test_anchor02(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		} else {
+			fem.End()
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("anchor02")
+
+	// run FE simulation
+	if !fem.Start("data/anchor02.sim", true, chk.Verbose) {
+		chk.Panic("cannot start FE simulation")
+	}
+	if !fem.Run() {
+		chk.Panic("cannot run FE simulation")
+	}
+
+	// start analysis process
+	Start("data/anchor02.sim", 1, 0)
+	LoadResults(nil)
+
+	// anchor force history
+	P := AnchorForce(-2)
+	io.Pforan("T = %v\n", T)
+	io.Pforan("P = %v\n", P)
+	chk.IntAssert(len(P), 10)
+
+	// stage # 0: equal to the lock-off force. stage # 1 starts with the locked-in force
+	P0 := 0.05
+	for k, t := range T {
+		if k < 6 {
+			chk.Scalar(tst, io.Sf("P(t=%g)", t), 1e-8, P[k], P0*t)
+		}
+	}
+
+	// stage # 1: the head is locked; thus the top face pulled by q = t - 1 stretches the free
+	// length and the anchor force increases linearly; the increase is smaller than the total
+	// load applied onto the top face, since the solid carries part of it
+	Q := 0.1 * 0.1 * (T[9] - 1.0)
+	ΔP := P[9] - P0
+	io.Pforan("ΔP = %v\n", ΔP)
+	if ΔP <= 0 || ΔP >= Q {
+		tst.Errorf("increase of anchor force due to loading is incorrect: %v", ΔP)
+	}
+	for k := 6; k < 10; k++ {
+		chk.Scalar(tst, io.Sf("ΔP(t=%g)", T[k]), 1e-8, P[k]-P0, ΔP*(T[k]-1.0)/(T[9]-1.0))
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2015 Dorival Pedroso and Raul Durand. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package out
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gofem/fem"
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_flux01(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		} else {
+			fem.End()
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("flux01")
+
+	// run FE simulation
+	if !fem.Start("data/flux01.sim", true, chk.Verbose) {
+		chk.Panic("cannot start FE simulation")
+	}
+	if !fem.Run() {
+		chk.Panic("cannot run FE simulation")
+	}
+
+	// start analysis process
+	Start("data/flux01.sim", 0, 0)
+	LoadResults(nil)
+
+	// analytical solution (saturated; steady state): upward discharge through a 2.5 m wide column
+	//  q = kl/gref * (-∂pl/∂y - ρL g) with ∂pl/∂y = -200/10
+	kl, gref, g, width := 0.01, 10.0, 10.0, 2.5
+	qana := width * (kl / gref) * (200.0/10.0 - g)
+	io.Pforan("qana = %v\n", qana)
+
+	// faces: outward normals
+	chk.Scalar(tst, "Q @ bottom", 1e-5, FluxFace(-10), -qana)
+	chk.Scalar(tst, "Q @ top   ", 1e-5, FluxFace(-12), qana)
+	chk.Scalar(tst, "Q @ left  ", 1e-12, FluxFace(-13), 0)
+
+	// sections
+	chk.Scalar(tst, "Q @ horizontal section", 1e-5, Section{{0, 6.3}, {2.5, 6.3}}.Flux(), qana)
+	chk.Scalar(tst, "Q @ stepped section   ", 1e-5, Section{{0, 3}, {1.25, 3}, {1.25, 7}, {2.5, 7}}.Flux(), qana)
+	chk.Scalar(tst, "Q @ reversed section  ", 1e-5, Section{{2.5, 5}, {0, 5}}.Flux(), -qana)
+
+	// discharge and cumulative volume
+	Q, V := Discharge(func() float64 { return FluxFace(-12) })
+	io.Pforan("Q = %v\n", Q)
+	io.Pforan("V = %v\n", V)
+	n := len(T)
+	for k := 1; k < n; k++ {
+		chk.Scalar(tst, io.Sf("Q(t=%g)", T[k]), 1e-5, Q[k], qana)
+	}
+	chk.Scalar(tst, "ΔV", 1e-4, V[n-1]-V[1], qana*(T[n-1]-T[1]))
+
+	// mass balance
+	M, Err, Stp := MassBalance(-10, -12)
+	io.Pforan("M   = %v\n", M)
+	io.Pforan("Err = %v\n", Err)
+	io.Pforan("Stp = %v\n", Stp)
+	for k := 2; k < n; k++ {
+		chk.Scalar(tst, io.Sf("mass balance error @ t=%g", T[k]), 1e-6, Stp[k], 0)
+	}
+}
+
+func Test_flux02(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		} else {
+			fem.End()
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("flux02")
+
+	// run FE simulation
+	if !fem.Start("data/flux02.sim", true, chk.Verbose) {
+		chk.Panic("cannot start FE simulation")
+	}
+	if !fem.Run() {
+		chk.Panic("cannot run FE simulation")
+	}
+
+	// start analysis process
+	Start("data/flux02.sim", 0, 0)
+	LoadResults(nil)
+
+	// inflow @ top, outflow @ bottom and storage
+	Qtop, Vtop := Discharge(func() float64 { return face_flux(-12, false) })
+	Qbot, Vbot := Discharge(func() float64 { return face_flux(-10, false) })
+	M, Err, _ := MassBalance(-10, -12)
+	n := len(T)
+	Vin, Vout, ΔM := -Vtop[n-1], Vbot[n-1], M[n-1]-M[0]
+	io.Pforan("Qtop = %v\n", Qtop)
+	io.Pforan("Qbot = %v\n", Qbot)
+	io.Pforan("Vin = %v  Vout = %v  ΔM = %v\n", Vin, Vout, ΔM)
+
+	// the process must be transient with both inflow and outflow
+	var ΔMmax float64
+	for k := 1; k < n; k++ {
+		ΔMmax = math.Max(ΔMmax, math.Abs(M[k]-M[0]))
+	}
+	if Vin <= 0 || Vout <= 0 || ΔMmax < 0.1*Vin {
+		tst.Errorf("test failed: infiltration must be transient. Vin=%g Vout=%g max(|ΔM|)=%g\n", Vin, Vout, ΔMmax)
+		return
+	}
+
+	// inflow - outflow = Δstorage (output intervals; coarse time integration of fluxes)
+	chk.Scalar(tst, "(Vin - Vout - ΔM)/Vin @ outputs", 5e-2, Err[n-1]/Vin, 0)
+
+	// inflow - outflow = Δstorage (time steps computed during the run)
+	tol := 2e-2
+	Ts, Stp := StepMassBalance()
+	chk.IntAssert(len(Ts), 300)
+	chk.Scalar(tst, "final t", 1e-10, Ts[len(Ts)-1], 3000)
+	var cum float64
+	for k, e := range Stp {
+		cum += e
+		if math.Abs(cum) > tol*Vin {
+			tst.Errorf("test failed: cumulative mass-balance error %g @ t=%g is too large\n", cum, Ts[k])
+			return
+		}
+	}
+	io.Pforan("cumulative mass-balance error = %v\n", cum)
+}
@@ -5,6 +5,7 @@
 package out
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gofem/ana"
@@ -213,3 +214,49 @@ func Test_out02(tst *testing.T) {
 		sol.CheckDispl(tst, t, []float64{ux[j], uy[j]}, x, tolu)
 	}
 }
+
+func Test_out03(tst *testing.T) {
+
+	// finalise analysis process and catch errors
+	defer func() {
+		if err := recover(); err != nil {
+			tst.Fail()
+			io.PfRed("ERROR: %v\n", err)
+		} else {
+			fem.End()
+		}
+	}()
+
+	// test title
+	//verbose()
+	chk.PrintTitle("out03")
+
+	// run FE simulation
+	if !fem.Start("data/large01.sim", true, chk.Verbose) {
+		chk.Panic("cannot start FE simulation")
+	}
+	if !fem.Run() {
+		chk.Panic("cannot run FE simulation")
+	}
+
+	// start analysis process
+	Start("data/large01.sim", 0, 0)
+
+	// define points
+	Define("a", P{{0, 0}})
+
+	// load results
+	LoadResults(nil)
+	chk.IntAssertLessThan(2, len(T))
+
+	// logarithmic strains of homogeneous deformation: F = diag(1 + 0.5 t, 1 - 0.2 t, 1)
+	ex := GetRes("ex", "a", 0)
+	ey := GetRes("ey", "a", 0)
+	ez := GetRes("ez", "a", 0)
+	exy := GetRes("exy", "a", 0)
+	for j, t := range T {
+		io.Pfyel("t=%g\n", t)
+		εref := []float64{math.Log(1.0 + 0.5*t), math.Log(1.0 - 0.2*t), 0, 0}
+		chk.Vector(tst, io.Sf("ε @ t=%g", t), 1e-13, []float64{ex[j], ey[j], ez[j], exy[j]}, εref)
+	}
+}
@@ -46,10 +46,11 @@ type Shape struct {
 	Gvec   []float64 // [nverts] G == dSdx. derivative of shape function
 
 	// scratchpad: face
-	Sf     []float64   // [facenverts] shape functions values
-	Fnvec  []float64   // [gndim] face normal vector multiplied by Jf
-	dSfdRf [][]float64 // [facenverts][gndim-1] derivatives of Sf w.r.t natural coordinates
-	dxfdRf [][]float64 // [gndim][gndim-1] derivatives of real coordinates w.r.t natural coordinates
+	Sf     []float64     // [facenverts] shape functions values
+	Fnvec  []float64     // [gndim] face normal vector multiplied by Jf
+	DFnvec [][][]float64 // [gndim][facenverts][gndim] derivatives of Fnvec w.r.t coordinates of face vertices
+	dSfdRf [][]float64   // [facenverts][gndim-1] derivatives of Sf w.r.t natural coordinates
+	dxfdRf [][]float64   // [gndim][gndim-1] derivatives of real coordinates w.r.t natural coordinates
 }
 
 // factory holds all Shapes available
@@ -234,6 +235,48 @@ func (o *Shape) CalcAtFaceIp(x [][]float64, ipf *Ipoint, idxface int) (err error
 	return
 }
 
+// CalcFaceNvecDeriv calculates the derivatives of Fnvec w.r.t the coordinates of face vertices
+//  Output:
+//   DFnvec[i][k][l] = dFnvec_i / dxf^k_l  where k is the local index of vertex on face
+//  Note: must be called after CalcAtFaceIp
+func (o *Shape) CalcFaceNvecDeriv() {
+
+	// skip 1D elements
+	if o.Gndim == 1 {
+		return
+	}
+
+	// 2D: Fnvec = {dy/dr, -dx/dr}
+	if o.Gndim == 2 {
+		for k := 0; k < o.FaceNverts; k++ {
+			o.DFnvec[0][k][0], o.DFnvec[0][k][1] = 0, o.dSfdRf[k][0]
+			o.DFnvec[1][k][0], o.DFnvec[1][k][1] = -o.dSfdRf[k][0], 0
+		}
+		return
+	}
+
+	// 3D: Fnvec = a × b with a = dx/dr and b = dx/ds
+	//  => dFnvec = da × b + a × db with da = e_l dSf/dr and db = e_l dSf/ds
+	a := []float64{o.dxfdRf[0][0], o.dxfdRf[1][0], o.dxfdRf[2][0]}
+	b := []float64{o.dxfdRf[0][1], o.dxfdRf[1][1], o.dxfdRf[2][1]}
+	for k := 0; k < o.FaceNverts; k++ {
+		dr, ds := o.dSfdRf[k][0], o.dSfdRf[k][1]
+		for l := 0; l < 3; l++ {
+			for i := 0; i < 3; i++ {
+				p, q := (i+1)%3, (i+2)%3 // cyclic: (a × b)_i = a_p b_q - a_q b_p
+				var v float64
+				if l == p {
+					v += dr*b[q] - a[q]*ds
+				}
+				if l == q {
+					v += a[p]*ds - dr*b[p]
+				}
+				o.DFnvec[i][k][l] = v
+			}
+		}
+	}
+}
+
 // AxisymGetRadius returns the x0 == radius for axisymmetric computations
 //  Note: must be called after CalcAtIp
 func (o *Shape) AxisymGetRadius(x [][]float64) (radius float64) {
@@ -268,6 +311,10 @@ func (o *Shape) init_scratchpad() {
 		o.dSfdRf = la.MatAlloc(o.FaceNverts, o.Gndim-1)
 		o.dxfdRf = la.MatAlloc(o.Gndim, o.Gndim-1)
 		o.Fnvec = make([]float64, o.Gndim)
+		o.DFnvec = make([][][]float64, o.Gndim)
+		for i := 0; i < o.Gndim; i++ {
+			o.DFnvec[i] = la.MatAlloc(o.FaceNverts, o.Gndim)
+		}
 	}
 
 	// lin data
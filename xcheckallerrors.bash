@@ -1,6 +1,6 @@
 #!/bin/bash
 
-GOFEM="ana shp inp msolid mconduct mreten mporous fem out"
+GOFEM="ana shp inp msolid mconduct mreten mporous mtherm mtransp fem out"
 
 for p in $GOFEM; do
     echo
@@ -27,6 +27,8 @@ pkgs = [
     ("mconduct", "models for liquid/gas conductivity in porous media"),
     ("mreten",   "models for liquid retention in porous media"),
     ("mporous",  "models for porous media"),
+    ("mtherm",   "models for heat conduction"),
+    ("mtransp",  "models for solute transport in porous media"),
     ("fem",      "finite element method"),
     ("out",      "results analyses and plotting"),
 ]